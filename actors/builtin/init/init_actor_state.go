@@ -89,3 +89,37 @@ func (s *State) MapAddressToNewID(store adt.Store, address addr.Address) (addr.A
 	autil.Assert(err == nil)
 	return idAddr, nil
 }
+
+// Checks the internal consistency of the state, returning any violations found.
+// The init actor's balance is not constrained.
+func (s *State) CheckStateInvariants(store adt.Store, _ abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("init")
+	acc.Require(s.NextID >= abi.ActorID(builtin.FirstNonSingletonActorId), "next ID %d is a singleton ID", s.NextID)
+	acc.Require(s.NetworkName != "", "network name is empty")
+
+	m, err := adt.AsMap(store, s.AddressMap)
+	if err != nil {
+		acc.Addf("failed to load address map: %v", err)
+		return acc.Violations()
+	}
+	mapped := make(map[abi.ActorID]addr.Address)
+	var actorID cbg.CborInt
+	err = m.ForEach(&actorID, func(key string) error {
+		a, err := addr.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		id := abi.ActorID(actorID)
+		acc.Require(a.Protocol() != addr.ID, "ID address %v is mapped", a)
+		acc.Require(id >= abi.ActorID(builtin.FirstNonSingletonActorId), "address %v mapped to singleton ID %d", a, id)
+		acc.Require(id < s.NextID, "address %v mapped to ID %d not less than next ID %d", a, id, s.NextID)
+		if prev, found := mapped[id]; found {
+			acc.Addf("addresses %v and %v both mapped to ID %d", prev, a, id)
+		}
+		mapped[id] = a
+		return nil
+	})
+	acc.RequireNoError(err, "failed to iterate address map")
+
+	return acc.Violations()
+}
@@ -0,0 +1,93 @@
+package builtin
+
+import (
+	"fmt"
+
+	addr "github.com/filecoin-project/go-address"
+)
+
+///// State invariant checking, shared by multiple built-in actors. /////
+
+// A violation of a state invariant.
+type InvariantViolation struct {
+	// Identifies the state in which the violation was found, e.g. "power" or "miner f0101".
+	Context string
+	// Describes the violation.
+	Message string
+}
+
+func (v InvariantViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Context, v.Message)
+}
+
+// Qualifies the context of violations found in an actor's state with the actor's address,
+// e.g. "miner" becomes "miner f0101".
+func WithActorAddress(violations []InvariantViolation, a addr.Address) []InvariantViolation {
+	qualified := make([]InvariantViolation, len(violations))
+	for i, v := range violations {
+		qualified[i] = InvariantViolation{Context: fmt.Sprintf("%s %v", v.Context, a), Message: v.Message}
+	}
+	return qualified
+}
+
+// Accumulates the violations found while checking state invariants.
+// Checks continue after a violation is found, so that all violations in a state may be reported together.
+type InvariantAccumulator struct {
+	context    string
+	violations *[]InvariantViolation
+}
+
+// Creates an accumulator that attributes violations to some context.
+func NewInvariantAccumulator(context string) *InvariantAccumulator {
+	return &InvariantAccumulator{
+		context:    context,
+		violations: &[]InvariantViolation{},
+	}
+}
+
+// Returns an accumulator that attributes violations to a different context, but records them
+// alongside those of this accumulator.
+func (a *InvariantAccumulator) WithContext(context string) *InvariantAccumulator {
+	return &InvariantAccumulator{
+		context:    context,
+		violations: a.violations,
+	}
+}
+
+// Records a violation.
+func (a *InvariantAccumulator) Addf(msg string, args ...interface{}) {
+	*a.violations = append(*a.violations, InvariantViolation{
+		Context: a.context,
+		Message: fmt.Sprintf(msg, args...),
+	})
+}
+
+// Records a violation if a predicate does not hold.
+func (a *InvariantAccumulator) Require(predicate bool, msg string, args ...interface{}) {
+	if !predicate {
+		a.Addf(msg, args...)
+	}
+}
+
+// Records a violation if err is not nil.
+// The provided message will be suffixed by ": %s" and the provided args suffixed by the err.
+func (a *InvariantAccumulator) RequireNoError(err error, msg string, args ...interface{}) {
+	if err != nil {
+		a.Addf(msg+": %s", append(args, err)...)
+	}
+}
+
+// Appends violations found by a separate check.
+func (a *InvariantAccumulator) AddAll(violations []InvariantViolation) {
+	*a.violations = append(*a.violations, violations...)
+}
+
+// Whether no violations have been recorded.
+func (a *InvariantAccumulator) IsEmpty() bool {
+	return len(*a.violations) == 0
+}
+
+// The violations recorded so far, in order.
+func (a *InvariantAccumulator) Violations() []InvariantViolation {
+	return *a.violations
+}
@@ -0,0 +1,27 @@
+package market
+
+import (
+	xerrors "golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// The changes to deal proposals and deal states between two versions of the market actor's state.
+type DealChanges struct {
+	Proposals []DealProposalArrayChange
+	States    []DealStateArrayChange
+}
+
+// Computes the deal proposals and deal states added, modified and removed between two versions of the market
+// actor's state, each in deal ID order.
+func DiffDeals(store adt.Store, before, after *State) (*DealChanges, error) {
+	proposals, err := DiffDealProposalArray(store, before.Proposals, after.Proposals)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff deal proposals: %w", err)
+	}
+	states, err := DiffDealStateArray(store, before.States, after.States)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff deal states: %w", err)
+	}
+	return &DealChanges{Proposals: proposals, States: states}, nil
+}
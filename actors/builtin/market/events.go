@@ -0,0 +1,16 @@
+package market
+
+import (
+	addr "github.com/filecoin-project/go-address"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+)
+
+// Emitted when a deal is published.
+type DealPublished struct {
+	DealID   abi.DealID
+	Client   addr.Address
+	Provider addr.Address
+}
+
+func (e *DealPublished) EventType() string { return "DealPublished" }
@@ -2,8 +2,10 @@ package market
 
 import (
 	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
 
 	cbg "github.com/whyrusleeping/cbor-gen"
+	xerrors "golang.org/x/xerrors"
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
@@ -30,6 +32,9 @@ func (a Actor) Exports() []interface{} {
 		7:                         a.OnMinerSectorsTerminate,
 		8:                         a.ComputeDataCommitment,
 		9:                         a.HandleInitTimeoutDeals,
+		10:                        a.BatchVerifyDealsOnSectorProveCommit,
+		11:                        a.VerifyDealsOnSectorPreCommit,
+		12:                        a.BatchComputeDataCommitment,
 	}
 }
 
@@ -164,8 +169,13 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 		rt.Abortf(exitcode.ErrNotFound, "failed to resolve provider address %v", providerRaw)
 	}
 
-	_, worker := builtin.RequestMinerControlAddrs(rt, provider)
-	if worker != rt.Message().Caller() {
+	// The deals may be published by the provider's worker or any of its control addresses.
+	_, worker, controlAddrs := builtin.RequestMinerControlAddrs(rt, provider)
+	callerIsProvider := rt.Message().Caller() == worker
+	for _, a := range controlAddrs {
+		callerIsProvider = callerIsProvider || rt.Message().Caller() == a
+	}
+	if !callerIsProvider {
 		rt.Abortf(exitcode.ErrForbidden, "caller is not provider %v", provider)
 	}
 
@@ -242,6 +252,7 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 			}
 
 			newDealIds = append(newDealIds, id)
+			rt.EmitEvent(&DealPublished{DealID: id, Client: client, Provider: provider})
 		}
 		propc, err := proposals.Root()
 		if err != nil {
@@ -301,16 +312,18 @@ func (a Actor) VerifyDealsOnSectorProveCommit(rt Runtime, params *VerifyDealsOnS
 		}
 
 		for _, dealID := range params.DealIDs {
-			deal, err := states.Get(dealID)
+			deal, err := getDealState(states, dealID)
 			if err != nil {
 				rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
 			}
-			proposal, err := proposals.Get(dealID)
+			proposal, err := getDealProposal(proposals, dealID)
 			if err != nil {
 				rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
 			}
 
-			validateDealCanActivate(rt, minerAddr, params.SectorExpiry, deal, proposal)
+			if err = validateDealCanActivate(rt, minerAddr, params.SectorExpiry, deal, proposal); err != nil {
+				rt.Abortf(exitcode.ErrIllegalArgument, "%s", err)
+			}
 
 			deal.SectorStartEpoch = rt.CurrEpoch()
 			err = states.Set(dealID, deal)
@@ -319,10 +332,7 @@ func (a Actor) VerifyDealsOnSectorProveCommit(rt Runtime, params *VerifyDealsOnS
 			}
 
 			// Compute deal weight
-			dealDuration := big.NewInt(int64(proposal.Duration()))
-			dealSize := big.NewIntUnsigned(uint64(proposal.PieceSize))
-			dealSpaceTime := big.Mul(dealDuration, dealSize)
-
+			dealSpaceTime := dealWeight(proposal)
 			if proposal.VerifiedDeal {
 				totalVerifiedDealSpaceTime = big.Add(totalVerifiedDealSpaceTime, dealSpaceTime)
 			} else {
@@ -343,6 +353,150 @@ func (a Actor) VerifyDealsOnSectorProveCommit(rt Runtime, params *VerifyDealsOnS
 	}
 }
 
+type SectorDeals struct {
+	DealIDs      []abi.DealID
+	SectorExpiry abi.ChainEpoch
+}
+
+type BatchVerifyDealsOnSectorProveCommitParams struct {
+	Sectors []SectorDeals
+}
+
+type SectorDealWeights struct {
+	Valid              bool // Whether the sector's deals were activated. The weights of an invalid sector are zero.
+	DealWeight         abi.DealWeight
+	VerifiedDealWeight abi.DealWeight
+}
+
+type BatchVerifyDealsOnSectorProveCommitReturn struct {
+	Sectors []SectorDealWeights // One for each sector in the parameters, in order.
+}
+
+// Verifies and activates the storage deals of a batch of sectors being ProveCommitted, as for VerifyDealsOnSectorProveCommit.
+// Each sector's deals are activated together or not at all: a sector with a missing or invalid deal is reported
+// as invalid, leaving its deals untouched, without affecting the other sectors in the batch.
+func (a Actor) BatchVerifyDealsOnSectorProveCommit(rt Runtime, params *BatchVerifyDealsOnSectorProveCommitParams) *BatchVerifyDealsOnSectorProveCommitReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Message().Caller()
+	results := make([]SectorDealWeights, len(params.Sectors))
+
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		states, err := AsDealStateArray(adt.AsStore(rt), st.States)
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "load states %v", err)
+		}
+
+		proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "load proposals %v", err)
+		}
+
+		for i, sector := range params.Sectors {
+			dealStates := make([]*DealState, len(sector.DealIDs))
+			dealProposals := make([]*DealProposal, len(sector.DealIDs))
+			valid := true
+			for j, dealID := range sector.DealIDs {
+				proposal, found, err := proposals.Get(dealID)
+				if err != nil {
+					rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
+				}
+				if !found {
+					valid = false
+					break
+				}
+				deal, err := getDealState(states, dealID)
+				if err != nil {
+					rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
+				}
+				if err = validateDealCanActivate(rt, minerAddr, sector.SectorExpiry, deal, proposal); err != nil {
+					valid = false
+					break
+				}
+				dealStates[j] = deal
+				dealProposals[j] = proposal
+			}
+			if !valid {
+				results[i] = SectorDealWeights{Valid: false, DealWeight: big.Zero(), VerifiedDealWeight: big.Zero()}
+				continue
+			}
+
+			totalDealSpaceTime := big.Zero()
+			totalVerifiedDealSpaceTime := big.Zero()
+			for j, dealID := range sector.DealIDs {
+				dealStates[j].SectorStartEpoch = rt.CurrEpoch()
+				if err = states.Set(dealID, dealStates[j]); err != nil {
+					rt.Abortf(exitcode.ErrIllegalState, "set deal %v", err)
+				}
+
+				dealSpaceTime := dealWeight(dealProposals[j])
+				if dealProposals[j].VerifiedDeal {
+					totalVerifiedDealSpaceTime = big.Add(totalVerifiedDealSpaceTime, dealSpaceTime)
+				} else {
+					totalDealSpaceTime = big.Add(totalDealSpaceTime, dealSpaceTime)
+				}
+			}
+			results[i] = SectorDealWeights{Valid: true, DealWeight: totalDealSpaceTime, VerifiedDealWeight: totalVerifiedDealSpaceTime}
+		}
+
+		st.States, err = states.Root()
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to flush deal states: %s", err)
+		}
+		return nil
+	})
+	return &BatchVerifyDealsOnSectorProveCommitReturn{Sectors: results}
+}
+
+type VerifyDealsOnSectorPreCommitParams struct {
+	Sectors []SectorDeals
+}
+
+// Verify that the storage deals of a batch of sectors being PreCommitted could be activated by the sectors,
+// aborting if any deal is missing, invalid, or included in more than one sector.
+// Unlike VerifyDealsOnSectorProveCommit, this does not modify the market's state.
+func (a Actor) VerifyDealsOnSectorPreCommit(rt Runtime, params *VerifyDealsOnSectorPreCommitParams) *adt.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Message().Caller()
+
+	var st State
+	rt.State().Readonly(&st)
+	states, err := AsDealStateArray(adt.AsStore(rt), st.States)
+	if err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "load states %v", err)
+	}
+
+	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+	if err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "load proposals %v", err)
+	}
+
+	seen := make(map[abi.DealID]bool)
+	for _, sector := range params.Sectors {
+		for _, dealID := range sector.DealIDs {
+			if seen[dealID] {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d included more than once", dealID)
+			}
+			seen[dealID] = true
+
+			proposal, found, err := proposals.Get(dealID)
+			if err != nil {
+				rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
+			} else if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no deal %d", dealID)
+			}
+			deal, err := getDealState(states, dealID)
+			if err != nil {
+				rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
+			}
+			if err = validateDealCanActivate(rt, minerAddr, sector.SectorExpiry, deal, proposal); err != nil {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d: %s", dealID, err)
+			}
+		}
+	}
+	return nil
+}
+
 type ComputeDataCommitmentParams struct {
 	DealIDs    []abi.DealID
 	SectorType abi.RegisteredProof
@@ -372,6 +526,62 @@ func (a Actor) ComputeDataCommitment(rt Runtime, params *ComputeDataCommitmentPa
 	return (*cbg.CborCid)(&commd)
 }
 
+type BatchComputeDataCommitmentParams struct {
+	Inputs []ComputeDataCommitmentParams
+}
+
+type SectorDataCommitment struct {
+	CommD *cid.Cid // The unsealed sector CID, or nil if it could not be computed.
+}
+
+type BatchComputeDataCommitmentReturn struct {
+	Sectors []SectorDataCommitment // One for each input in the parameters, in order.
+}
+
+// Computes the unsealed sector CID for each of a batch of sectors, as for ComputeDataCommitment.
+// A sector with a missing deal, or for which the CID cannot be computed, has no CID in the result,
+// without affecting the other sectors in the batch.
+func (a Actor) BatchComputeDataCommitment(rt Runtime, params *BatchComputeDataCommitmentParams) *BatchComputeDataCommitmentReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	results := make([]SectorDataCommitment, len(params.Inputs))
+
+	var st State
+	rt.State().Readonly(&st)
+	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
+	if err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "load proposals %v", err)
+	}
+
+	for i, input := range params.Inputs {
+		pieces := make([]abi.PieceInfo, 0, len(input.DealIDs))
+		valid := true
+		for _, dealID := range input.DealIDs {
+			proposal, found, err := proposals.Get(dealID)
+			if err != nil {
+				rt.Abortf(exitcode.ErrIllegalState, "get deal %v", err)
+			}
+			if !found {
+				valid = false
+				break
+			}
+			pieces = append(pieces, abi.PieceInfo{
+				PieceCID: proposal.PieceCID,
+				Size:     proposal.PieceSize,
+			})
+		}
+		if !valid {
+			continue
+		}
+
+		commd, err := rt.Syscalls().ComputeUnsealedSectorCID(input.SectorType, pieces)
+		if err != nil {
+			continue
+		}
+		results[i].CommD = &commd
+	}
+	return &BatchComputeDataCommitmentReturn{Sectors: results}
+}
+
 type OnMinerSectorsTerminateParams struct {
 	DealIDs []abi.DealID
 }
@@ -396,13 +606,13 @@ func (a Actor) OnMinerSectorsTerminate(rt Runtime, params *OnMinerSectorsTermina
 		}
 
 		for _, dealID := range params.DealIDs {
-			deal, err := proposals.Get(dealID)
+			deal, err := getDealProposal(proposals, dealID)
 			if err != nil {
 				rt.Abortf(exitcode.ErrIllegalState, "get deal: %v", err)
 			}
 			Assert(deal.Provider == minerAddr)
 
-			state, err := states.Get(dealID)
+			state, err := getDealState(states, dealID)
 			if err != nil {
 				rt.Abortf(exitcode.ErrIllegalState, "get deal: %v", err)
 			}
@@ -506,22 +716,30 @@ func (a Actor) HandleInitTimeoutDeals(rt Runtime, params *HandleInitTimeoutDeals
 // Checks
 ////////////////////////////////////////////////////////////////////////////////
 
-func validateDealCanActivate(rt Runtime, minerAddr addr.Address, sectorExpiration abi.ChainEpoch, deal *DealState, proposal *DealProposal) {
+func validateDealCanActivate(rt Runtime, minerAddr addr.Address, sectorExpiration abi.ChainEpoch, deal *DealState, proposal *DealProposal) error {
 	if proposal.Provider != minerAddr {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal has incorrect miner as its provider.")
+		return xerrors.Errorf("Deal has incorrect miner as its provider.")
 	}
 
 	if deal.SectorStartEpoch != epochUndefined {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal has already appeared in proven sector.")
+		return xerrors.Errorf("Deal has already appeared in proven sector.")
 	}
 
 	if rt.CurrEpoch() > proposal.StartEpoch {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal start epoch has already elapsed.")
+		return xerrors.Errorf("Deal start epoch has already elapsed.")
 	}
 
 	if proposal.EndEpoch > sectorExpiration {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal would outlive its containing sector.")
+		return xerrors.Errorf("Deal would outlive its containing sector.")
 	}
+	return nil
+}
+
+// The weight of a deal is the product of its size and duration.
+func dealWeight(proposal *DealProposal) abi.DealWeight {
+	dealDuration := big.NewInt(int64(proposal.Duration()))
+	dealSize := big.NewIntUnsigned(uint64(proposal.PieceSize))
+	return big.Mul(dealDuration, dealSize)
 }
 
 func validateDeal(rt Runtime, deal ClientDealProposal) {
@@ -572,7 +790,7 @@ func escrowAddress(rt Runtime, addr addr.Address) (nominal addr.Address, recipie
 
 	if codeID.Equals(builtin.StorageMinerActorCodeID) {
 		// Storage miner actor entry; implied funds recipient is the associated owner address.
-		ownerAddr, workerAddr := builtin.RequestMinerControlAddrs(rt, nominal)
+		ownerAddr, workerAddr, _ := builtin.RequestMinerControlAddrs(rt, nominal)
 		rt.ValidateImmediateCallerIs(ownerAddr, workerAddr)
 		return nominal, ownerAddr
 	}
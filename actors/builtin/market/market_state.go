@@ -9,6 +9,7 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
 	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
 	. "github.com/filecoin-project/specs-actors/actors/util"
 	"github.com/filecoin-project/specs-actors/actors/util/adt"
@@ -161,7 +162,7 @@ func (st *State) updatePendingDealState(rt Runtime, dealID abi.DealID, epoch abi
 
 	state.LastUpdatedEpoch = epoch
 
-	st.mutateDealStates(rt, func(states *DealMetaArray) {
+	st.mutateDealStates(rt, func(states *DealStateArray) {
 		if err := states.Set(dealID, state); err != nil {
 			rt.Abortf(exitcode.ErrPlaceholder, "failed to get deal: %v", err)
 		}
@@ -169,7 +170,7 @@ func (st *State) updatePendingDealState(rt Runtime, dealID abi.DealID, epoch abi
 	return
 }
 
-func (st *State) mutateDealStates(rt Runtime, f func(*DealMetaArray)) {
+func (st *State) mutateDealStates(rt Runtime, f func(*DealStateArray)) {
 	states, err := AsDealStateArray(adt.AsStore(rt), st.States)
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "failed to load deal states: %s", err)
@@ -185,7 +186,7 @@ func (st *State) mutateDealStates(rt Runtime, f func(*DealMetaArray)) {
 	st.States = rcid
 }
 
-func (st *State) mutateDealProposals(rt Runtime, f func(*DealArray)) {
+func (st *State) mutateDealProposals(rt Runtime, f func(*DealProposalArray)) {
 	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "failed to load deal proposals array: %s", err)
@@ -204,14 +205,14 @@ func (st *State) mutateDealProposals(rt Runtime, f func(*DealArray)) {
 func (st *State) deleteDeal(rt Runtime, dealID abi.DealID) {
 
 	var dealP *DealProposal
-	st.mutateDealProposals(rt, func(proposals *DealArray) {
-		p, err := proposals.Get(dealID)
+	st.mutateDealProposals(rt, func(proposals *DealProposalArray) {
+		p, err := getDealProposal(proposals, dealID)
 		if err != nil {
 			rt.Abortf(exitcode.ErrIllegalState, "failed to get deal before deleting it: %s", err)
 		}
 		dealP = p
 
-		if err := proposals.Delete(uint64(dealID)); err != nil {
+		if err := proposals.Delete(dealID); err != nil {
 			rt.Abortf(exitcode.ErrPlaceholder, "failed to delete deal: %v", err)
 		}
 	})
@@ -447,7 +448,7 @@ func (st *State) mustGetDeal(rt Runtime, dealID abi.DealID) *DealProposal {
 		rt.Abortf(exitcode.ErrIllegalState, "get proposal: %v", err)
 	}
 
-	proposal, err := proposals.Get(dealID)
+	proposal, err := getDealProposal(proposals, dealID)
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "get proposal: %v", err)
 	}
@@ -460,7 +461,7 @@ func (st *State) mustGetDealState(rt Runtime, dealID abi.DealID) *DealState {
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "get state state: %v", err)
 	}
-	state, err := states.Get(dealID)
+	state, err := getDealState(states, dealID)
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "get state state: %v", err)
 	}
@@ -468,6 +469,34 @@ func (st *State) mustGetDealState(rt Runtime, dealID abi.DealID) *DealState {
 	return state
 }
 
+// Gets the proposal for a deal. It is an error for the deal not to exist.
+func getDealProposal(proposals *DealProposalArray, dealID abi.DealID) (*DealProposal, error) {
+	proposal, found, err := proposals.Get(dealID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, xerrors.Errorf("deal %d not found", dealID)
+	}
+	return proposal, nil
+}
+
+// Gets the state of a deal, or an initial state with undefined epochs if none has been recorded.
+func getDealState(states *DealStateArray, dealID abi.DealID) (*DealState, error) {
+	state, found, err := states.Get(dealID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &DealState{
+			SectorStartEpoch: epochUndefined,
+			LastUpdatedEpoch: epochUndefined,
+			SlashEpoch:       epochUndefined,
+		}, nil
+	}
+	return state, nil
+}
+
 func (st *State) lockBalanceOrAbort(rt Runtime, addr addr.Address, amount abi.TokenAmount) {
 	if amount.LessThan(big.Zero()) {
 		rt.Abortf(exitcode.ErrIllegalArgument, "negative amount %v", amount)
@@ -527,3 +556,87 @@ func (st *State) MutateDealIDs(rt Runtime, f func(dbp *SetMultimap) error) {
 
 	st.DealIDsByParty = dipc
 }
+
+// Checks the internal consistency of the state, returning any violations found.
+// The balance is that of the market actor, which must equal the total held in escrow.
+func (st *State) CheckStateInvariants(store adt.Store, balance abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("market")
+
+	// Escrow and locked balances
+	escrowTable, err := adt.AsBalanceTable(store, st.EscrowTable)
+	if err != nil {
+		acc.Addf("failed to load escrow table: %v", err)
+	} else {
+		escrowTotal, err := escrowTable.Total()
+		acc.RequireNoError(err, "failed to total escrow table")
+		acc.Require(escrowTotal.Equals(balance), "escrow total %v != market balance %v", escrowTotal, balance)
+
+		if locked, err := adt.AsMap(store, st.LockedTable); err != nil {
+			acc.Addf("failed to load locked table: %v", err)
+		} else {
+			var lockedAmount abi.TokenAmount
+			err = locked.ForEach(&lockedAmount, func(key string) error {
+				a, err := addr.NewFromBytes([]byte(key))
+				if err != nil {
+					return err
+				}
+				acc.Require(lockedAmount.GreaterThanEqual(big.Zero()), "negative locked balance %v for %v", lockedAmount, a)
+				escrow, err := escrowTable.Get(a)
+				if err != nil {
+					acc.Addf("locked balance %v for %v with no escrow: %v", lockedAmount, a, err)
+					return nil
+				}
+				acc.Require(lockedAmount.LessThanEqual(escrow), "locked balance %v exceeds escrow %v for %v", lockedAmount, escrow, a)
+				return nil
+			})
+			acc.RequireNoError(err, "failed to iterate locked table")
+		}
+	}
+
+	// Deals
+	dbp, err := AsSetMultimap(store, st.DealIDsByParty)
+	if err != nil {
+		acc.Addf("failed to load deal ids by party: %v", err)
+		return acc.Violations()
+	}
+	requireIndexed := func(party addr.Address, dealID abi.DealID) {
+		set, found, err := dbp.get(adt.AddrKey(party))
+		if err != nil {
+			acc.Addf("failed to load deal ids for %v: %v", party, err)
+			return
+		}
+		has := false
+		if found {
+			has, err = set.Has(dealKey(dealID))
+			acc.RequireNoError(err, "failed to read deal ids for %v", party)
+		}
+		acc.Require(has, "deal %d missing from deal ids for %v", dealID, party)
+	}
+
+	if proposals, err := AsDealProposalArray(store, st.Proposals); err != nil {
+		acc.Addf("failed to load proposals: %v", err)
+	} else {
+		err = proposals.ForEach(func(dealID abi.DealID, proposal *DealProposal) error {
+			acc.Require(dealID < st.NextID, "deal %d not less than next ID %d", dealID, st.NextID)
+			acc.Require(proposal.StartEpoch < proposal.EndEpoch, "deal %d start %d not before end %d", dealID, proposal.StartEpoch, proposal.EndEpoch)
+			requireIndexed(proposal.Client, dealID)
+			requireIndexed(proposal.Provider, dealID)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate proposals")
+	}
+
+	if states, err := AsDealStateArray(store, st.States); err != nil {
+		acc.Addf("failed to load deal states: %v", err)
+	} else {
+		err = states.ForEach(func(id abi.DealID, state *DealState) error {
+			acc.Require(id < st.NextID, "deal state %d not less than next ID %d", id, st.NextID)
+			acc.Require(state.SlashEpoch == epochUndefined || state.SectorStartEpoch != epochUndefined,
+				"deal %d slashed at %d but never activated", id, state.SlashEpoch)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate deal states")
+	}
+
+	return acc.Violations()
+}
@@ -5,13 +5,16 @@ import (
 	"testing"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/actors/abi"
 	"github.com/filecoin-project/specs-actors/actors/abi/big"
 	"github.com/filecoin-project/specs-actors/actors/builtin"
 	"github.com/filecoin-project/specs-actors/actors/builtin/market"
 	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/actors/crypto"
 	"github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
 	"github.com/filecoin-project/specs-actors/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/support/mock"
@@ -281,6 +284,99 @@ func TestMarketActor(t *testing.T) {
 		// TODO: withdraws limited by slashing
 		// TODO: withdraws limited by locked balance
 	})
+
+	t.Run("BatchComputeDataCommitment", func(t *testing.T) {
+		rt, actor := setup()
+		actor.addProviderFunds(rt, provider, owner, worker, abi.NewTokenAmount(0))
+		actor.addParticipantFunds(rt, client, abi.NewTokenAmount(0))
+		dealIDs := actor.publishDeals(rt, owner, worker,
+			makeDealProposal(tutil.MakeCID("piece0"), client, provider, 10, 100),
+			makeDealProposal(tutil.MakeCID("piece1"), client, provider, 10, 100))
+
+		commd := tutil.MakeCID("commd")
+		rt.SetUnsealedCIDComputer(func(_ abi.RegisteredProof, pieces []abi.PieceInfo) (cid.Cid, error) {
+			require.Len(t, pieces, 2)
+			assert.Equal(t, tutil.MakeCID("piece0"), pieces[0].PieceCID)
+			assert.Equal(t, tutil.MakeCID("piece1"), pieces[1].PieceCID)
+			return commd, nil
+		})
+
+		// The second sector includes a deal that doesn't exist, which doesn't prevent computing the first's CID.
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		ret := rt.Call(actor.BatchComputeDataCommitment, &market.BatchComputeDataCommitmentParams{
+			Inputs: []market.ComputeDataCommitmentParams{
+				{DealIDs: dealIDs, SectorType: abi.RegisteredProof_StackedDRG32GiBSeal},
+				{DealIDs: []abi.DealID{dealIDs[0], 99}, SectorType: abi.RegisteredProof_StackedDRG32GiBSeal},
+			},
+		}).(*market.BatchComputeDataCommitmentReturn)
+		rt.Verify()
+
+		require.Len(t, ret.Sectors, 2)
+		require.NotNil(t, ret.Sectors[0].CommD)
+		assert.Equal(t, commd, *ret.Sectors[0].CommD)
+		assert.Nil(t, ret.Sectors[1].CommD)
+	})
+
+	t.Run("BatchVerifyDealsOnSectorProveCommit", func(t *testing.T) {
+		rt, actor := setup()
+		actor.addProviderFunds(rt, provider, owner, worker, abi.NewTokenAmount(0))
+		actor.addParticipantFunds(rt, client, abi.NewTokenAmount(0))
+		proposal := makeDealProposal(tutil.MakeCID("piece0"), client, provider, 10, 100)
+		dealIDs := actor.publishDeals(rt, owner, worker, proposal,
+			makeDealProposal(tutil.MakeCID("piece1"), client, provider, 10, 100))
+
+		// The second sector's deals are invalid because one of them is missing, so neither is activated.
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		ret := rt.Call(actor.BatchVerifyDealsOnSectorProveCommit, &market.BatchVerifyDealsOnSectorProveCommitParams{
+			Sectors: []market.SectorDeals{
+				{DealIDs: dealIDs[:1], SectorExpiry: 200},
+				{DealIDs: []abi.DealID{dealIDs[1], 99}, SectorExpiry: 200},
+			},
+		}).(*market.BatchVerifyDealsOnSectorProveCommitReturn)
+		rt.Verify()
+
+		expectedWeight := big.Mul(big.NewInt(int64(proposal.Duration())), big.NewIntUnsigned(uint64(proposal.PieceSize)))
+		assert.Equal(t, []market.SectorDealWeights{
+			{Valid: true, DealWeight: expectedWeight, VerifiedDealWeight: big.Zero()},
+			{Valid: false, DealWeight: big.Zero(), VerifiedDealWeight: big.Zero()},
+		}, ret.Sectors)
+
+		rt.GetState(&st)
+		states, err := market.AsDealStateArray(adt.AsStore(rt), st.States)
+		require.NoError(t, err)
+		state, found, err := states.Get(dealIDs[0])
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, rt.GetEpoch(), state.SectorStartEpoch)
+		_, found, err = states.Get(dealIDs[1])
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		// An activated deal cannot be activated again.
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		ret = rt.Call(actor.BatchVerifyDealsOnSectorProveCommit, &market.BatchVerifyDealsOnSectorProveCommitParams{
+			Sectors: []market.SectorDeals{{DealIDs: dealIDs[:1], SectorExpiry: 200}},
+		}).(*market.BatchVerifyDealsOnSectorProveCommitReturn)
+		rt.Verify()
+		assert.False(t, ret.Sectors[0].Valid)
+	})
+}
+
+// Makes a proposal for a deal without payment or collateral.
+func makeDealProposal(pieceCID cid.Cid, client, provider address.Address, start, end abi.ChainEpoch) market.DealProposal {
+	return market.DealProposal{
+		PieceCID:             pieceCID,
+		PieceSize:            abi.PaddedPieceSize(1 << 10),
+		Client:               client,
+		Provider:             provider,
+		StartEpoch:           start,
+		EndEpoch:             end,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
 }
 
 type marketActorTestHarness struct {
@@ -321,6 +417,23 @@ func (h *marketActorTestHarness) addParticipantFunds(rt *mock.Runtime, addr addr
 	rt.SetBalance(big.Add(rt.GetBalance(), amount))
 }
 
+// Publishes deals from the provider's worker, with client signatures that are taken to be valid.
+func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, owner, worker address.Address, proposals ...market.DealProposal) []abi.DealID {
+	deals := make([]market.ClientDealProposal, len(proposals))
+	for i := range proposals {
+		deals[i] = market.ClientDealProposal{Proposal: proposals[i]}
+	}
+	rt.SetCaller(worker, builtin.AccountActorCodeID)
+	rt.SetVerifier(func(crypto.Signature, address.Address, []byte) error { return nil })
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	rt.ExpectSend(proposals[0].Provider, builtin.MethodsMiner.ControlAddresses, nil, big.Zero(),
+		&miner.GetControlAddressesReturn{Owner: owner, Worker: worker}, exitcode.Ok)
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.Zero(), nil, exitcode.Ok)
+	ret := rt.Call(h.PublishStorageDeals, &market.PublishStorageDealsParams{Deals: deals}).(*market.PublishStorageDealsReturn)
+	rt.Verify()
+	return ret.IDs
+}
+
 func (h *marketActorTestHarness) expectProviderControlAddressesAndValidateCaller(rt *mock.Runtime, provider address.Address, owner address.Address, worker address.Address) {
 	rt.ExpectValidateCallerAddr(owner, worker)
 
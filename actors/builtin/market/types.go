@@ -2,95 +2,61 @@ package market
 
 import (
 	"github.com/ipfs/go-cid"
-	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/specs-actors/actors/abi"
-	. "github.com/filecoin-project/specs-actors/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
 )
 
 // A specialization of a array to deals.
 // It is an error to query for a key that doesn't exist.
+//
+// This wraps the generated DealProposalArray, preserving the prior API for existing callers.
+// Note that AsDealProposalArray now returns the generated type; use AsDealArray to obtain this wrapper.
 type DealArray struct {
-	*Array
+	*DealProposalArray
 }
 
-// Interprets a store as balance table with root `r`.
-func AsDealProposalArray(s Store, r cid.Cid) (*DealArray, error) {
-	a, err := AsArray(s, r)
+// Interprets a store as an array of deal proposals with root `r`.
+func AsDealArray(s adt.Store, r cid.Cid) (*DealArray, error) {
+	a, err := AsDealProposalArray(s, r)
 	if err != nil {
 		return nil, err
 	}
 	return &DealArray{a}, nil
 }
 
-// Returns the root cid of underlying AMT.
-func (t *DealArray) Root() (cid.Cid, error) {
-	return t.Array.Root()
-}
-
 // Gets the deal for a key. The entry must have been previously initialized.
 func (t *DealArray) Get(id abi.DealID) (*DealProposal, error) {
-	var value DealProposal
-	found, err := t.Array.Get(uint64(id), &value)
-	if err != nil {
-		return nil, err // The errors from Map carry good information, no need to wrap here.
-	}
-	if !found {
-		return nil, errors.Errorf("deal %d not found", id)
-	}
-	return &value, nil
-}
-
-func (t *DealArray) Set(k abi.DealID, value *DealProposal) error {
-	return t.Array.Set(uint64(k), value)
+	return getDealProposal(t.DealProposalArray, id)
 }
 
 func (t *DealArray) Delete(key uint64) error {
-	return t.Array.Delete(key)
+	return t.DealProposalArray.Delete(abi.DealID(key))
 }
 
-// A specialization of a array to deals.
-// It is an error to query for a key that doesn't exist.
+// A specialization of a array to deal states.
+// Querying for a key that doesn't exist returns a state with undefined epochs.
+//
+// This wraps the generated DealStateArray, preserving the prior API for existing callers.
+// Note that AsDealStateArray now returns the generated type; use AsDealMetaArray to obtain this wrapper.
 type DealMetaArray struct {
-	*Array
+	*DealStateArray
 }
 
-// Interprets a store as balance table with root `r`.
-func AsDealStateArray(s Store, r cid.Cid) (*DealMetaArray, error) {
-	dsa, err := AsArray(s, r)
+// Interprets a store as an array of deal states with root `r`.
+func AsDealMetaArray(s adt.Store, r cid.Cid) (*DealMetaArray, error) {
+	a, err := AsDealStateArray(s, r)
 	if err != nil {
 		return nil, err
 	}
-
-	return &DealMetaArray{dsa}, nil
-}
-
-// Returns the root cid of underlying AMT.
-func (t *DealMetaArray) Root() (cid.Cid, error) {
-	return t.Array.Root()
+	return &DealMetaArray{a}, nil
 }
 
-// Gets the deal for a key. The entry must have been previously initialized.
+// Gets the state for a deal, or an initial state if none has been recorded.
 func (t *DealMetaArray) Get(id abi.DealID) (*DealState, error) {
-	var value DealState
-	found, err := t.Array.Get(uint64(id), &value)
-	if err != nil {
-		return nil, err // The errors from Map carry good information, no need to wrap here.
-	}
-	if !found {
-		return &DealState{
-			SectorStartEpoch: epochUndefined,
-			LastUpdatedEpoch: epochUndefined,
-			SlashEpoch:       epochUndefined,
-		}, nil
-	}
-	return &value, nil
-}
-
-func (t *DealMetaArray) Set(k abi.DealID, value *DealState) error {
-	return t.Array.Set(uint64(k), value)
+	return getDealState(t.DealStateArray, id)
 }
 
 func (t *DealMetaArray) Delete(key uint64) error {
-	return t.Array.Delete(key)
+	return t.DealStateArray.Delete(abi.DealID(key))
 }
@@ -53,50 +53,67 @@ var MethodsPaych = struct {
 }{MethodConstructor, 2, 3, 4}
 
 var MethodsMarket = struct {
-	Constructor                    abi.MethodNum
-	AddBalance                     abi.MethodNum
-	WithdrawBalance                abi.MethodNum
-	HandleExpiredDeals             abi.MethodNum
-	PublishStorageDeals            abi.MethodNum
-	VerifyDealsOnSectorProveCommit abi.MethodNum
-	OnMinerSectorsTerminate        abi.MethodNum
-	ComputeDataCommitment          abi.MethodNum
-	HandleInitTimeoutDeals         abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9}
+	Constructor                         abi.MethodNum
+	AddBalance                          abi.MethodNum
+	WithdrawBalance                     abi.MethodNum
+	HandleExpiredDeals                  abi.MethodNum
+	PublishStorageDeals                 abi.MethodNum
+	VerifyDealsOnSectorProveCommit      abi.MethodNum
+	OnMinerSectorsTerminate             abi.MethodNum
+	ComputeDataCommitment               abi.MethodNum
+	HandleInitTimeoutDeals              abi.MethodNum
+	BatchVerifyDealsOnSectorProveCommit abi.MethodNum
+	VerifyDealsOnSectorPreCommit        abi.MethodNum
+	BatchComputeDataCommitment          abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
 
 var MethodsPower = struct {
-	Constructor              abi.MethodNum
-	CreateMiner              abi.MethodNum
-	DeleteMiner              abi.MethodNum
-	OnSectorProveCommit      abi.MethodNum
-	OnSectorTerminate        abi.MethodNum
-	OnFaultBegin             abi.MethodNum
-	OnFaultEnd               abi.MethodNum
-	OnSectorModifyWeightDesc abi.MethodNum
-	EnrollCronEvent          abi.MethodNum
-	OnEpochTickEnd           abi.MethodNum
-	UpdatePledgeTotal        abi.MethodNum
-	OnConsensusFault         abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	Constructor                   abi.MethodNum
+	CreateMiner                   abi.MethodNum
+	DeleteMiner                   abi.MethodNum
+	OnSectorProveCommit           abi.MethodNum
+	OnSectorTerminate             abi.MethodNum
+	OnFaultBegin                  abi.MethodNum
+	OnFaultEnd                    abi.MethodNum
+	OnSectorModifyWeightDesc      abi.MethodNum
+	EnrollCronEvent               abi.MethodNum
+	OnEpochTickEnd                abi.MethodNum
+	UpdatePledgeTotal             abi.MethodNum
+	OnConsensusFault              abi.MethodNum
+	BatchOnSectorProveCommit      abi.MethodNum
+	BatchOnSectorModifyWeightDesc abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}
 
 var MethodsMiner = struct {
-	Constructor            abi.MethodNum
-	ControlAddresses       abi.MethodNum
-	ChangeWorkerAddress    abi.MethodNum
-	ChangePeerID           abi.MethodNum
-	SubmitWindowedPoSt     abi.MethodNum
-	PreCommitSector        abi.MethodNum
-	ProveCommitSector      abi.MethodNum
-	ExtendSectorExpiration abi.MethodNum
-	TerminateSectors       abi.MethodNum
-	DeclareFaults          abi.MethodNum
-	DeclareFaultsRecovered abi.MethodNum
-	OnDeferredCronEvent    abi.MethodNum
-	CheckSectorProven      abi.MethodNum
-	AddLockedFund          abi.MethodNum
-	ReportConsensusFault   abi.MethodNum
-	WithdrawBalance        abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	Constructor             abi.MethodNum
+	ControlAddresses        abi.MethodNum
+	ChangeWorkerAddress     abi.MethodNum
+	ChangePeerID            abi.MethodNum
+	SubmitWindowedPoSt      abi.MethodNum
+	PreCommitSector         abi.MethodNum
+	ProveCommitSector       abi.MethodNum
+	ExtendSectorExpiration  abi.MethodNum
+	TerminateSectors        abi.MethodNum
+	DeclareFaults           abi.MethodNum
+	DeclareFaultsRecovered  abi.MethodNum
+	OnDeferredCronEvent     abi.MethodNum
+	CheckSectorProven       abi.MethodNum
+	AddLockedFund           abi.MethodNum
+	ReportConsensusFault    abi.MethodNum
+	WithdrawBalance         abi.MethodNum
+	ProveCommitSectors      abi.MethodNum
+	PreCommitSectorBatch    abi.MethodNum
+	DisputeWindowedPoSt     abi.MethodNum
+	ChangeOwnerAddress      abi.MethodNum
+	ChangeControlAddresses  abi.MethodNum
+	ChangeMultiaddrs        abi.MethodNum
+	WithdrawPreCommits      abi.MethodNum
+	ExtendSectorExpirations abi.MethodNum
+	GetSectorStatus         abi.MethodNum
+	GetDeadlineSummary      abi.MethodNum
+	MaskSectorNumbers       abi.MethodNum
+	GetInfo                 abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28}
 
 var MethodsVerifiedRegistry = struct {
 	Constructor       abi.MethodNum
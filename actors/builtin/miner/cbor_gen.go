@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/specs-actors/actors/abi"
 	peer "github.com/libp2p/go-libp2p-core/peer"
@@ -20,7 +21,7 @@ func (t *State) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{141}); err != nil {
+	if _, err := w.Write([]byte{144}); err != nil {
 		return err
 	}
 
@@ -45,6 +46,22 @@ func (t *State) MarshalCBOR(w io.Writer) error {
 		return xerrors.Errorf("failed to write cid field t.VestingFunds: %w", err)
 	}
 
+	// t.LockedRewards (big.Int) (struct)
+	if err := t.LockedRewards.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.VestingRewards (cid.Cid) (struct)
+
+	if err := cbg.WriteCid(w, t.VestingRewards); err != nil {
+		return xerrors.Errorf("failed to write cid field t.VestingRewards: %w", err)
+	}
+
+	// t.FeeDebt (big.Int) (struct)
+	if err := t.FeeDebt.MarshalCBOR(w); err != nil {
+		return err
+	}
+
 	// t.PreCommittedSectors (cid.Cid) (struct)
 
 	if err := cbg.WriteCid(w, t.PreCommittedSectors); err != nil {
@@ -57,15 +74,14 @@ func (t *State) MarshalCBOR(w io.Writer) error {
 		return xerrors.Errorf("failed to write cid field t.Sectors: %w", err)
 	}
 
-	// t.NewSectors (bitfield.BitField) (struct)
-	if err := t.NewSectors.MarshalCBOR(w); err != nil {
+	// t.AllocatedSectors (bitfield.BitField) (struct)
+	if err := t.AllocatedSectors.MarshalCBOR(w); err != nil {
 		return err
 	}
 
-	// t.SectorExpirations (cid.Cid) (struct)
-
-	if err := cbg.WriteCid(w, t.SectorExpirations); err != nil {
-		return xerrors.Errorf("failed to write cid field t.SectorExpirations: %w", err)
+	// t.NewSectors (bitfield.BitField) (struct)
+	if err := t.NewSectors.MarshalCBOR(w); err != nil {
+		return err
 	}
 
 	// t.Deadlines (cid.Cid) (struct)
@@ -74,24 +90,26 @@ func (t *State) MarshalCBOR(w io.Writer) error {
 		return xerrors.Errorf("failed to write cid field t.Deadlines: %w", err)
 	}
 
-	// t.Faults (bitfield.BitField) (struct)
-	if err := t.Faults.MarshalCBOR(w); err != nil {
-		return err
+	// t.SectorLocations (cid.Cid) (struct)
+
+	if err := cbg.WriteCid(w, t.SectorLocations); err != nil {
+		return xerrors.Errorf("failed to write cid field t.SectorLocations: %w", err)
 	}
 
-	// t.FaultEpochs (cid.Cid) (struct)
+	// t.PenaltyLedger (cid.Cid) (struct)
 
-	if err := cbg.WriteCid(w, t.FaultEpochs); err != nil {
-		return xerrors.Errorf("failed to write cid field t.FaultEpochs: %w", err)
+	if err := cbg.WriteCid(w, t.PenaltyLedger); err != nil {
+		return xerrors.Errorf("failed to write cid field t.PenaltyLedger: %w", err)
 	}
 
-	// t.Recoveries (bitfield.BitField) (struct)
-	if err := t.Recoveries.MarshalCBOR(w); err != nil {
-		return err
+	// t.PoStSnapshots (cid.Cid) (struct)
+
+	if err := cbg.WriteCid(w, t.PoStSnapshots); err != nil {
+		return xerrors.Errorf("failed to write cid field t.PoStSnapshots: %w", err)
 	}
 
-	// t.PostSubmissions (bitfield.BitField) (struct)
-	if err := t.PostSubmissions.MarshalCBOR(w); err != nil {
+	// t.ConsensusFault (miner.ConsensusFaultRecord) (struct)
+	if err := t.ConsensusFault.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
@@ -108,7 +126,7 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 13 {
+	if extra != 16 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -150,6 +168,36 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 
 		t.VestingFunds = c
 
+	}
+	// t.LockedRewards (big.Int) (struct)
+
+	{
+
+		if err := t.LockedRewards.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.LockedRewards: %w", err)
+		}
+
+	}
+	// t.VestingRewards (cid.Cid) (struct)
+
+	{
+
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.VestingRewards: %w", err)
+		}
+
+		t.VestingRewards = c
+
+	}
+	// t.FeeDebt (big.Int) (struct)
+
+	{
+
+		if err := t.FeeDebt.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.FeeDebt: %w", err)
+		}
+
 	}
 	// t.PreCommittedSectors (cid.Cid) (struct)
 
@@ -175,7 +223,7 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 		t.Sectors = c
 
 	}
-	// t.NewSectors (bitfield.BitField) (struct)
+	// t.AllocatedSectors (bitfield.BitField) (struct)
 
 	{
 
@@ -189,23 +237,32 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 				return err
 			}
 		} else {
-			t.NewSectors = new(bitfield.BitField)
-			if err := t.NewSectors.UnmarshalCBOR(br); err != nil {
-				return xerrors.Errorf("unmarshaling t.NewSectors pointer: %w", err)
+			t.AllocatedSectors = new(bitfield.BitField)
+			if err := t.AllocatedSectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.AllocatedSectors pointer: %w", err)
 			}
 		}
 
 	}
-	// t.SectorExpirations (cid.Cid) (struct)
+	// t.NewSectors (bitfield.BitField) (struct)
 
 	{
 
-		c, err := cbg.ReadCid(br)
+		pb, err := br.PeekByte()
 		if err != nil {
-			return xerrors.Errorf("failed to read cid field t.SectorExpirations: %w", err)
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.NewSectors = new(bitfield.BitField)
+			if err := t.NewSectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.NewSectors pointer: %w", err)
+			}
 		}
-
-		t.SectorExpirations = c
 
 	}
 	// t.Deadlines (cid.Cid) (struct)
@@ -220,61 +277,43 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 		t.Deadlines = c
 
 	}
-	// t.Faults (bitfield.BitField) (struct)
+	// t.SectorLocations (cid.Cid) (struct)
 
 	{
 
-		pb, err := br.PeekByte()
+		c, err := cbg.ReadCid(br)
 		if err != nil {
-			return err
-		}
-		if pb == cbg.CborNull[0] {
-			var nbuf [1]byte
-			if _, err := br.Read(nbuf[:]); err != nil {
-				return err
-			}
-		} else {
-			t.Faults = new(bitfield.BitField)
-			if err := t.Faults.UnmarshalCBOR(br); err != nil {
-				return xerrors.Errorf("unmarshaling t.Faults pointer: %w", err)
-			}
+			return xerrors.Errorf("failed to read cid field t.SectorLocations: %w", err)
 		}
 
+		t.SectorLocations = c
+
 	}
-	// t.FaultEpochs (cid.Cid) (struct)
+	// t.PenaltyLedger (cid.Cid) (struct)
 
 	{
 
 		c, err := cbg.ReadCid(br)
 		if err != nil {
-			return xerrors.Errorf("failed to read cid field t.FaultEpochs: %w", err)
+			return xerrors.Errorf("failed to read cid field t.PenaltyLedger: %w", err)
 		}
 
-		t.FaultEpochs = c
+		t.PenaltyLedger = c
 
 	}
-	// t.Recoveries (bitfield.BitField) (struct)
+	// t.PoStSnapshots (cid.Cid) (struct)
 
 	{
 
-		pb, err := br.PeekByte()
+		c, err := cbg.ReadCid(br)
 		if err != nil {
-			return err
-		}
-		if pb == cbg.CborNull[0] {
-			var nbuf [1]byte
-			if _, err := br.Read(nbuf[:]); err != nil {
-				return err
-			}
-		} else {
-			t.Recoveries = new(bitfield.BitField)
-			if err := t.Recoveries.UnmarshalCBOR(br); err != nil {
-				return xerrors.Errorf("unmarshaling t.Recoveries pointer: %w", err)
-			}
+			return xerrors.Errorf("failed to read cid field t.PoStSnapshots: %w", err)
 		}
 
+		t.PoStSnapshots = c
+
 	}
-	// t.PostSubmissions (bitfield.BitField) (struct)
+	// t.ConsensusFault (miner.ConsensusFaultRecord) (struct)
 
 	{
 
@@ -288,9 +327,9 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 				return err
 			}
 		} else {
-			t.PostSubmissions = new(bitfield.BitField)
-			if err := t.PostSubmissions.UnmarshalCBOR(br); err != nil {
-				return xerrors.Errorf("unmarshaling t.PostSubmissions pointer: %w", err)
+			t.ConsensusFault = new(ConsensusFaultRecord)
+			if err := t.ConsensusFault.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.ConsensusFault pointer: %w", err)
 			}
 		}
 
@@ -303,7 +342,7 @@ func (t *MinerInfo) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{134}); err != nil {
+	if _, err := w.Write([]byte{137}); err != nil {
 		return err
 	}
 
@@ -312,6 +351,11 @@ func (t *MinerInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.PendingOwnerChange (miner.OwnerChange) (struct)
+	if err := t.PendingOwnerChange.MarshalCBOR(w); err != nil {
+		return err
+	}
+
 	// t.Worker (address.Address) (struct)
 	if err := t.Worker.MarshalCBOR(w); err != nil {
 		return err
@@ -322,6 +366,20 @@ func (t *MinerInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.ControlAddresses ([]address.Address) (slice)
+	if len(t.ControlAddresses) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.ControlAddresses was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.ControlAddresses)))); err != nil {
+		return err
+	}
+	for _, v := range t.ControlAddresses {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
 	// t.PeerId (peer.ID) (string)
 	if len(t.PeerId) > cbg.MaxLength {
 		return xerrors.Errorf("Value in field t.PeerId was too long")
@@ -334,6 +392,27 @@ func (t *MinerInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.Multiaddrs ([][]uint8) (slice)
+	if len(t.Multiaddrs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Multiaddrs was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Multiaddrs)))); err != nil {
+		return err
+	}
+	for _, v := range t.Multiaddrs {
+		if len(v) > cbg.ByteArrayMaxLen {
+			return xerrors.Errorf("Byte array in field v was too long")
+		}
+
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(v)))); err != nil {
+			return err
+		}
+		if _, err := w.Write(v); err != nil {
+			return err
+		}
+	}
+
 	// t.SectorSize (abi.SectorSize) (uint64)
 
 	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorSize))); err != nil {
@@ -364,7 +443,7 @@ func (t *MinerInfo) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 6 {
+	if extra != 9 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -376,6 +455,27 @@ func (t *MinerInfo) UnmarshalCBOR(r io.Reader) error {
 			return xerrors.Errorf("unmarshaling t.Owner: %w", err)
 		}
 
+	}
+	// t.PendingOwnerChange (miner.OwnerChange) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.PendingOwnerChange = new(OwnerChange)
+			if err := t.PendingOwnerChange.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.PendingOwnerChange pointer: %w", err)
+			}
+		}
+
 	}
 	// t.Worker (address.Address) (struct)
 
@@ -407,6 +507,35 @@ func (t *MinerInfo) UnmarshalCBOR(r io.Reader) error {
 		}
 
 	}
+	// t.ControlAddresses ([]address.Address) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.ControlAddresses: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.ControlAddresses = make([]address.Address, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v address.Address
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.ControlAddresses[i] = v
+	}
+
 	// t.PeerId (peer.ID) (string)
 
 	{
@@ -417,6 +546,49 @@ func (t *MinerInfo) UnmarshalCBOR(r io.Reader) error {
 
 		t.PeerId = peer.ID(sval)
 	}
+	// t.Multiaddrs ([][]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Multiaddrs: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Multiaddrs = make([][]uint8, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+		{
+			var maj byte
+			var extra uint64
+			var err error
+
+			maj, extra, err = cbg.CborReadHeader(br)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Multiaddrs[i]: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+			t.Multiaddrs[i] = make([]byte, extra)
+			if _, err := io.ReadFull(br, t.Multiaddrs[i]); err != nil {
+				return err
+			}
+		}
+	}
+
 	// t.SectorSize (abi.SectorSize) (uint64)
 
 	{
@@ -459,32 +631,29 @@ func (t *MinerInfo) UnmarshalCBOR(r io.Reader) error {
 	return nil
 }
 
-func (t *Deadlines) MarshalCBOR(w io.Writer) error {
+func (t *Deadline) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{129}); err != nil {
+	if _, err := w.Write([]byte{130}); err != nil {
 		return err
 	}
 
-	// t.Due ([48]*bitfield.BitField) (array)
-	if len(t.Due) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.Due was too long")
+	// t.Partitions (cid.Cid) (struct)
+
+	if err := cbg.WriteCid(w, t.Partitions); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Partitions: %w", err)
 	}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Due)))); err != nil {
+	// t.PostSubmissions (bitfield.BitField) (struct)
+	if err := t.PostSubmissions.MarshalCBOR(w); err != nil {
 		return err
 	}
-	for _, v := range t.Due {
-		if err := v.MarshalCBOR(w); err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
-func (t *Deadlines) UnmarshalCBOR(r io.Reader) error {
+func (t *Deadline) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -495,60 +664,290 @@ func (t *Deadlines) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 1 {
+	if extra != 2 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.Due ([48]*bitfield.BitField) (array)
+	// t.Partitions (cid.Cid) (struct)
 
-	maj, extra, err = cbg.CborReadHeader(br)
-	if err != nil {
-		return err
-	}
+	{
 
-	if extra > cbg.MaxLength {
-		return fmt.Errorf("t.Due: array too large (%d)", extra)
-	}
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.Partitions: %w", err)
+		}
 
-	if maj != cbg.MajArray {
-		return fmt.Errorf("expected cbor array")
-	}
+		t.Partitions = c
 
-	if extra != 48 {
-		return fmt.Errorf("expected array to have 48 elements")
 	}
+	// t.PostSubmissions (bitfield.BitField) (struct)
 
-	t.Due = [48]*bitfield.BitField{}
-
-	for i := 0; i < int(extra); i++ {
+	{
 
-		var v bitfield.BitField
-		if err := v.UnmarshalCBOR(br); err != nil {
+		pb, err := br.PeekByte()
+		if err != nil {
 			return err
 		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.PostSubmissions = new(bitfield.BitField)
+			if err := t.PostSubmissions.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.PostSubmissions pointer: %w", err)
+			}
+		}
 
-		t.Due[i] = &v
 	}
-
 	return nil
 }
 
-func (t *SectorPreCommitOnChainInfo) MarshalCBOR(w io.Writer) error {
+func (t *Partition) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{131}); err != nil {
+	if _, err := w.Write([]byte{135}); err != nil {
 		return err
 	}
 
-	// t.Info (miner.SectorPreCommitInfo) (struct)
-	if err := t.Info.MarshalCBOR(w); err != nil {
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
 		return err
 	}
 
-	// t.PreCommitDeposit (big.Int) (struct)
-	if err := t.PreCommitDeposit.MarshalCBOR(w); err != nil {
+	// t.Faults (bitfield.BitField) (struct)
+	if err := t.Faults.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Recoveries (bitfield.BitField) (struct)
+	if err := t.Recoveries.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Expirations (cid.Cid) (struct)
+
+	if err := cbg.WriteCid(w, t.Expirations); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Expirations: %w", err)
+	}
+
+	// t.FaultEpochs (cid.Cid) (struct)
+
+	if err := cbg.WriteCid(w, t.FaultEpochs); err != nil {
+		return xerrors.Errorf("failed to write cid field t.FaultEpochs: %w", err)
+	}
+
+	// t.TotalPower (miner.PowerPair) (struct)
+	if err := t.TotalPower.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.FaultyPower (miner.PowerPair) (struct)
+	if err := t.FaultyPower.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *Partition) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 7 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	// t.Faults (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Faults = new(bitfield.BitField)
+			if err := t.Faults.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Faults pointer: %w", err)
+			}
+		}
+
+	}
+	// t.Recoveries (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Recoveries = new(bitfield.BitField)
+			if err := t.Recoveries.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Recoveries pointer: %w", err)
+			}
+		}
+
+	}
+	// t.Expirations (cid.Cid) (struct)
+
+	{
+
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.Expirations: %w", err)
+		}
+
+		t.Expirations = c
+
+	}
+	// t.FaultEpochs (cid.Cid) (struct)
+
+	{
+
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.FaultEpochs: %w", err)
+		}
+
+		t.FaultEpochs = c
+
+	}
+	// t.TotalPower (miner.PowerPair) (struct)
+
+	{
+
+		if err := t.TotalPower.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.TotalPower: %w", err)
+		}
+
+	}
+	// t.FaultyPower (miner.PowerPair) (struct)
+
+	{
+
+		if err := t.FaultyPower.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.FaultyPower: %w", err)
+		}
+
+	}
+	return nil
+}
+
+func (t *PowerPair) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.Raw (big.Int) (struct)
+	if err := t.Raw.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.QA (big.Int) (struct)
+	if err := t.QA.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PowerPair) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Raw (big.Int) (struct)
+
+	{
+
+		if err := t.Raw.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Raw: %w", err)
+		}
+
+	}
+	// t.QA (big.Int) (struct)
+
+	{
+
+		if err := t.QA.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.QA: %w", err)
+		}
+
+	}
+	return nil
+}
+
+func (t *SectorPreCommitOnChainInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{131}); err != nil {
+		return err
+	}
+
+	// t.Info (miner.SectorPreCommitInfo) (struct)
+	if err := t.Info.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.PreCommitDeposit (big.Int) (struct)
+	if err := t.PreCommitDeposit.MarshalCBOR(w); err != nil {
 		return err
 	}
 
@@ -631,7 +1030,7 @@ func (t *SectorPreCommitInfo) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{134}); err != nil {
+	if _, err := w.Write([]byte{136}); err != nil {
 		return err
 	}
 
@@ -693,6 +1092,18 @@ func (t *SectorPreCommitInfo) MarshalCBOR(w io.Writer) error {
 			return err
 		}
 	}
+
+	// t.ReplaceCapacity (bool) (bool)
+	if err := cbg.WriteBool(w, t.ReplaceCapacity); err != nil {
+		return err
+	}
+
+	// t.ReplaceSector (abi.SectorNumber) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.ReplaceSector))); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -707,7 +1118,7 @@ func (t *SectorPreCommitInfo) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 6 {
+	if extra != 8 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -845,6 +1256,37 @@ func (t *SectorPreCommitInfo) UnmarshalCBOR(r io.Reader) error {
 
 		t.Expiration = abi.ChainEpoch(extraI)
 	}
+	// t.ReplaceCapacity (bool) (bool)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.ReplaceCapacity = false
+	case 21:
+		t.ReplaceCapacity = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	// t.ReplaceSector (abi.SectorNumber) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.ReplaceSector = abi.SectorNumber(extra)
+
+	}
 	return nil
 }
 
@@ -853,7 +1295,7 @@ func (t *SectorOnChainInfo) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{132}); err != nil {
+	if _, err := w.Write([]byte{135}); err != nil {
 		return err
 	}
 
@@ -882,6 +1324,21 @@ func (t *SectorOnChainInfo) MarshalCBOR(w io.Writer) error {
 	if err := t.VerifiedDealWeight.MarshalCBOR(w); err != nil {
 		return err
 	}
+
+	// t.InitialPledge (big.Int) (struct)
+	if err := t.InitialPledge.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.ExpectedDayReward (big.Int) (struct)
+	if err := t.ExpectedDayReward.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.ExpectedStoragePledge (big.Int) (struct)
+	if err := t.ExpectedStoragePledge.MarshalCBOR(w); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -896,7 +1353,7 @@ func (t *SectorOnChainInfo) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 4 {
+	if extra != 7 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -951,6 +1408,33 @@ func (t *SectorOnChainInfo) UnmarshalCBOR(r io.Reader) error {
 			return xerrors.Errorf("unmarshaling t.VerifiedDealWeight: %w", err)
 		}
 
+	}
+	// t.InitialPledge (big.Int) (struct)
+
+	{
+
+		if err := t.InitialPledge.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.InitialPledge: %w", err)
+		}
+
+	}
+	// t.ExpectedDayReward (big.Int) (struct)
+
+	{
+
+		if err := t.ExpectedDayReward.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.ExpectedDayReward: %w", err)
+		}
+
+	}
+	// t.ExpectedStoragePledge (big.Int) (struct)
+
+	{
+
+		if err := t.ExpectedStoragePledge.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.ExpectedStoragePledge: %w", err)
+		}
+
 	}
 	return nil
 }
@@ -1034,57 +1518,23 @@ func (t *WorkerKeyChange) UnmarshalCBOR(r io.Reader) error {
 	return nil
 }
 
-func (t *SubmitWindowedPoStParams) MarshalCBOR(w io.Writer) error {
+func (t *OwnerChange) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{132}); err != nil {
+	if _, err := w.Write([]byte{129}); err != nil {
 		return err
 	}
 
-	// t.Deadline (uint64) (uint64)
-
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
-		return err
-	}
-
-	// t.Partitions ([]uint64) (slice)
-	if len(t.Partitions) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.Partitions was too long")
-	}
-
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Partitions)))); err != nil {
-		return err
-	}
-	for _, v := range t.Partitions {
-		if err := cbg.CborWriteHeader(w, cbg.MajUnsignedInt, uint64(v)); err != nil {
-			return err
-		}
-	}
-
-	// t.Proofs ([]abi.PoStProof) (slice)
-	if len(t.Proofs) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.Proofs was too long")
-	}
-
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Proofs)))); err != nil {
-		return err
-	}
-	for _, v := range t.Proofs {
-		if err := v.MarshalCBOR(w); err != nil {
-			return err
-		}
-	}
-
-	// t.Skipped (bitfield.BitField) (struct)
-	if err := t.Skipped.MarshalCBOR(w); err != nil {
+	// t.NewOwner (address.Address) (struct)
+	if err := t.NewOwner.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *SubmitWindowedPoStParams) UnmarshalCBOR(r io.Reader) error {
+func (t *OwnerChange) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1095,115 +1545,55 @@ func (t *SubmitWindowedPoStParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 4 {
+	if extra != 1 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.Deadline (uint64) (uint64)
+	// t.NewOwner (address.Address) (struct)
 
 	{
 
-		maj, extra, err = cbg.CborReadHeader(br)
-		if err != nil {
-			return err
-		}
-		if maj != cbg.MajUnsignedInt {
-			return fmt.Errorf("wrong type for uint64 field")
+		if err := t.NewOwner.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.NewOwner: %w", err)
 		}
-		t.Deadline = uint64(extra)
 
 	}
-	// t.Partitions ([]uint64) (slice)
+	return nil
+}
 
-	maj, extra, err = cbg.CborReadHeader(br)
-	if err != nil {
+func (t *PenaltyRecord) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-
-	if extra > cbg.MaxLength {
-		return fmt.Errorf("t.Partitions: array too large (%d)", extra)
-	}
-
-	if maj != cbg.MajArray {
-		return fmt.Errorf("expected cbor array")
-	}
-
-	if extra > 0 {
-		t.Partitions = make([]uint64, extra)
-	}
-
-	for i := 0; i < int(extra); i++ {
-
-		maj, val, err := cbg.CborReadHeader(br)
-		if err != nil {
-			return xerrors.Errorf("failed to read uint64 for t.Partitions slice: %w", err)
-		}
-
-		if maj != cbg.MajUnsignedInt {
-			return xerrors.Errorf("value read for array t.Partitions was not a uint, instead got %d", maj)
-		}
-
-		t.Partitions[i] = uint64(val)
-	}
-
-	// t.Proofs ([]abi.PoStProof) (slice)
-
-	maj, extra, err = cbg.CborReadHeader(br)
-	if err != nil {
+	if _, err := w.Write([]byte{131}); err != nil {
 		return err
 	}
 
-	if extra > cbg.MaxLength {
-		return fmt.Errorf("t.Proofs: array too large (%d)", extra)
-	}
-
-	if maj != cbg.MajArray {
-		return fmt.Errorf("expected cbor array")
-	}
-
-	if extra > 0 {
-		t.Proofs = make([]abi.PoStProof, extra)
-	}
-
-	for i := 0; i < int(extra); i++ {
-
-		var v abi.PoStProof
-		if err := v.UnmarshalCBOR(br); err != nil {
+	// t.Cause (miner.PenaltyCause) (int64)
+	if t.Cause >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Cause))); err != nil {
 			return err
 		}
-
-		t.Proofs[i] = v
-	}
-
-	// t.Skipped (bitfield.BitField) (struct)
-
-	{
-
-		if err := t.Skipped.UnmarshalCBOR(br); err != nil {
-			return xerrors.Errorf("unmarshaling t.Skipped: %w", err)
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Cause)-1)); err != nil {
+			return err
 		}
-
 	}
-	return nil
-}
 
-func (t *TerminateSectorsParams) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
-		return err
-	}
-	if _, err := w.Write([]byte{129}); err != nil {
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
 		return err
 	}
 
-	// t.Sectors (bitfield.BitField) (struct)
-	if err := t.Sectors.MarshalCBOR(w); err != nil {
+	// t.Amount (big.Int) (struct)
+	if err := t.Amount.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *TerminateSectorsParams) UnmarshalCBOR(r io.Reader) error {
+func (t *PenaltyRecord) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1214,10 +1604,35 @@ func (t *TerminateSectorsParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 1 {
+	if extra != 3 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
+	// t.Cause (miner.PenaltyCause) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Cause = PenaltyCause(extraI)
+	}
 	// t.Sectors (bitfield.BitField) (struct)
 
 	{
@@ -1238,11 +1653,20 @@ func (t *TerminateSectorsParams) UnmarshalCBOR(r io.Reader) error {
 			}
 		}
 
+	}
+	// t.Amount (big.Int) (struct)
+
+	{
+
+		if err := t.Amount.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Amount: %w", err)
+		}
+
 	}
 	return nil
 }
 
-func (t *ChangePeerIDParams) MarshalCBOR(w io.Writer) error {
+func (t *PenaltyRecords) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -1251,21 +1675,23 @@ func (t *ChangePeerIDParams) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.NewID (peer.ID) (string)
-	if len(t.NewID) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.NewID was too long")
+	// t.Records ([]miner.PenaltyRecord) (slice)
+	if len(t.Records) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Records was too long")
 	}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajTextString, uint64(len(t.NewID)))); err != nil {
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Records)))); err != nil {
 		return err
 	}
-	if _, err := w.Write([]byte(t.NewID)); err != nil {
-		return err
+	for _, v := range t.Records {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (t *ChangePeerIDParams) UnmarshalCBOR(r io.Reader) error {
+func (t *PenaltyRecords) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1280,49 +1706,77 @@ func (t *ChangePeerIDParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.NewID (peer.ID) (string)
+	// t.Records ([]miner.PenaltyRecord) (slice)
 
-	{
-		sval, err := cbg.ReadString(br)
-		if err != nil {
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Records: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Records = make([]PenaltyRecord, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v PenaltyRecord
+		if err := v.UnmarshalCBOR(br); err != nil {
 			return err
 		}
 
-		t.NewID = peer.ID(sval)
+		t.Records[i] = v
 	}
+
 	return nil
 }
 
-func (t *ProveCommitSectorParams) MarshalCBOR(w io.Writer) error {
+func (t *ConsensusFaultRecord) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{130}); err != nil {
+	if _, err := w.Write([]byte{131}); err != nil {
 		return err
 	}
 
-	// t.SectorNumber (abi.SectorNumber) (uint64)
-
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
-		return err
+	// t.FaultEpoch (abi.ChainEpoch) (int64)
+	if t.FaultEpoch >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.FaultEpoch))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.FaultEpoch)-1)); err != nil {
+			return err
+		}
 	}
 
-	// t.Proof ([]uint8) (slice)
-	if len(t.Proof) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.Proof was too long")
+	// t.ReportEpoch (abi.ChainEpoch) (int64)
+	if t.ReportEpoch >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.ReportEpoch))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.ReportEpoch)-1)); err != nil {
+			return err
+		}
 	}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.Proof)))); err != nil {
-		return err
-	}
-	if _, err := w.Write(t.Proof); err != nil {
+	// t.Reporter (address.Address) (struct)
+	if err := t.Reporter.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *ProveCommitSectorParams) UnmarshalCBOR(r io.Reader) error {
+func (t *ConsensusFaultRecord) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1333,116 +1787,158 @@ func (t *ProveCommitSectorParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 2 {
+	if extra != 3 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.SectorNumber (abi.SectorNumber) (uint64)
-
+	// t.FaultEpoch (abi.ChainEpoch) (int64)
 	{
-
-		maj, extra, err = cbg.CborReadHeader(br)
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
 		if err != nil {
 			return err
 		}
-		if maj != cbg.MajUnsignedInt {
-			return fmt.Errorf("wrong type for uint64 field")
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
 		}
-		t.SectorNumber = abi.SectorNumber(extra)
-
-	}
-	// t.Proof ([]uint8) (slice)
-
-	maj, extra, err = cbg.CborReadHeader(br)
-	if err != nil {
-		return err
-	}
 
-	if extra > cbg.ByteArrayMaxLen {
-		return fmt.Errorf("t.Proof: byte array too large (%d)", extra)
-	}
-	if maj != cbg.MajByteString {
-		return fmt.Errorf("expected byte array")
+		t.FaultEpoch = abi.ChainEpoch(extraI)
 	}
-	t.Proof = make([]byte, extra)
-	if _, err := io.ReadFull(br, t.Proof); err != nil {
-		return err
+	// t.ReportEpoch (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.ReportEpoch = abi.ChainEpoch(extraI)
+	}
+	// t.Reporter (address.Address) (struct)
+
+	{
+
+		if err := t.Reporter.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Reporter: %w", err)
+		}
+
 	}
 	return nil
 }
 
-func (t *ChangeWorkerAddressParams) MarshalCBOR(w io.Writer) error {
+func (t *WindowedPoStSnapshot) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{129}); err != nil {
+	if _, err := w.Write([]byte{135}); err != nil {
 		return err
 	}
 
-	// t.NewWorker (address.Address) (struct)
-	if err := t.NewWorker.MarshalCBOR(w); err != nil {
-		return err
+	// t.SubmissionEpoch (abi.ChainEpoch) (int64)
+	if t.SubmissionEpoch >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SubmissionEpoch))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.SubmissionEpoch)-1)); err != nil {
+			return err
+		}
 	}
-	return nil
-}
 
-func (t *ChangeWorkerAddressParams) UnmarshalCBOR(r io.Reader) error {
-	br := cbg.GetPeeker(r)
+	// t.ChallengeEpoch (abi.ChainEpoch) (int64)
+	if t.ChallengeEpoch >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.ChallengeEpoch))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.ChallengeEpoch)-1)); err != nil {
+			return err
+		}
+	}
 
-	maj, extra, err := cbg.CborReadHeader(br)
-	if err != nil {
+	// t.Partitions ([]uint64) (slice)
+	if len(t.Partitions) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Partitions was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Partitions)))); err != nil {
 		return err
 	}
-	if maj != cbg.MajArray {
-		return fmt.Errorf("cbor input should be of type array")
+	for _, v := range t.Partitions {
+		if err := cbg.CborWriteHeader(w, cbg.MajUnsignedInt, uint64(v)); err != nil {
+			return err
+		}
 	}
 
-	if extra != 1 {
-		return fmt.Errorf("cbor input had wrong number of fields")
+	// t.Proofs ([]abi.PoStProof) (slice)
+	if len(t.Proofs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Proofs was too long")
 	}
 
-	// t.NewWorker (address.Address) (struct)
-
-	{
-
-		if err := t.NewWorker.UnmarshalCBOR(br); err != nil {
-			return xerrors.Errorf("unmarshaling t.NewWorker: %w", err)
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Proofs)))); err != nil {
+		return err
+	}
+	for _, v := range t.Proofs {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
 		}
+	}
 
+	// t.Sectors ([]abi.SectorInfo) (slice)
+	if len(t.Sectors) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Sectors was too long")
 	}
-	return nil
-}
 
-func (t *ExtendSectorExpirationParams) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Sectors)))); err != nil {
 		return err
 	}
-	if _, err := w.Write([]byte{130}); err != nil {
-		return err
+	for _, v := range t.Sectors {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
 	}
 
-	// t.SectorNumber (abi.SectorNumber) (uint64)
-
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
+	// t.Proven (bitfield.BitField) (struct)
+	if err := t.Proven.MarshalCBOR(w); err != nil {
 		return err
 	}
 
-	// t.NewExpiration (abi.ChainEpoch) (int64)
-	if t.NewExpiration >= 0 {
-		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.NewExpiration))); err != nil {
-			return err
-		}
-	} else {
-		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.NewExpiration)-1)); err != nil {
-			return err
-		}
+	// t.Disputed (bool) (bool)
+	if err := cbg.WriteBool(w, t.Disputed); err != nil {
+		return err
 	}
 	return nil
 }
 
-func (t *ExtendSectorExpirationParams) UnmarshalCBOR(r io.Reader) error {
+func (t *WindowedPoStSnapshot) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1453,25 +1949,36 @@ func (t *ExtendSectorExpirationParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 2 {
+	if extra != 7 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.SectorNumber (abi.SectorNumber) (uint64)
-
+	// t.SubmissionEpoch (abi.ChainEpoch) (int64)
 	{
-
-		maj, extra, err = cbg.CborReadHeader(br)
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
 		if err != nil {
 			return err
 		}
-		if maj != cbg.MajUnsignedInt {
-			return fmt.Errorf("wrong type for uint64 field")
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
 		}
-		t.SectorNumber = abi.SectorNumber(extra)
 
+		t.SubmissionEpoch = abi.ChainEpoch(extraI)
 	}
-	// t.NewExpiration (abi.ChainEpoch) (int64)
+	// t.ChallengeEpoch (abi.ChainEpoch) (int64)
 	{
 		maj, extra, err := cbg.CborReadHeader(br)
 		var extraI int64
@@ -1494,52 +2001,71 @@ func (t *ExtendSectorExpirationParams) UnmarshalCBOR(r io.Reader) error {
 			return fmt.Errorf("wrong type for int64 field: %d", maj)
 		}
 
-		t.NewExpiration = abi.ChainEpoch(extraI)
+		t.ChallengeEpoch = abi.ChainEpoch(extraI)
 	}
-	return nil
-}
+	// t.Partitions ([]uint64) (slice)
 
-func (t *DeclareFaultsParams) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
 		return err
 	}
-	if _, err := w.Write([]byte{129}); err != nil {
-		return err
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Partitions: array too large (%d)", extra)
 	}
 
-	// t.Faults ([]miner.FaultDeclaration) (slice)
-	if len(t.Faults) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.Faults was too long")
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
 	}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Faults)))); err != nil {
-		return err
+	if extra > 0 {
+		t.Partitions = make([]uint64, extra)
 	}
-	for _, v := range t.Faults {
-		if err := v.MarshalCBOR(w); err != nil {
-			return err
+
+	for i := 0; i < int(extra); i++ {
+
+		maj, val, err := cbg.CborReadHeader(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read uint64 for t.Partitions slice: %w", err)
+		}
+
+		if maj != cbg.MajUnsignedInt {
+			return xerrors.Errorf("value read for array t.Partitions was not a uint, instead got %d", maj)
 		}
+
+		t.Partitions[i] = uint64(val)
 	}
-	return nil
-}
 
-func (t *DeclareFaultsParams) UnmarshalCBOR(r io.Reader) error {
-	br := cbg.GetPeeker(r)
+	// t.Proofs ([]abi.PoStProof) (slice)
 
-	maj, extra, err := cbg.CborReadHeader(br)
+	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
 		return err
 	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Proofs: array too large (%d)", extra)
+	}
+
 	if maj != cbg.MajArray {
-		return fmt.Errorf("cbor input should be of type array")
+		return fmt.Errorf("expected cbor array")
 	}
 
-	if extra != 1 {
-		return fmt.Errorf("cbor input had wrong number of fields")
+	if extra > 0 {
+		t.Proofs = make([]abi.PoStProof, extra)
 	}
 
-	// t.Faults ([]miner.FaultDeclaration) (slice)
+	for i := 0; i < int(extra); i++ {
+
+		var v abi.PoStProof
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Proofs[i] = v
+	}
+
+	// t.Sectors ([]abi.SectorInfo) (slice)
 
 	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
@@ -1547,7 +2073,7 @@ func (t *DeclareFaultsParams) UnmarshalCBOR(r io.Reader) error {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("t.Faults: array too large (%d)", extra)
+		return fmt.Errorf("t.Sectors: array too large (%d)", extra)
 	}
 
 	if maj != cbg.MajArray {
@@ -1555,23 +2081,61 @@ func (t *DeclareFaultsParams) UnmarshalCBOR(r io.Reader) error {
 	}
 
 	if extra > 0 {
-		t.Faults = make([]FaultDeclaration, extra)
+		t.Sectors = make([]abi.SectorInfo, extra)
 	}
 
 	for i := 0; i < int(extra); i++ {
 
-		var v FaultDeclaration
+		var v abi.SectorInfo
 		if err := v.UnmarshalCBOR(br); err != nil {
 			return err
 		}
 
-		t.Faults[i] = v
+		t.Sectors[i] = v
+	}
+
+	// t.Proven (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Proven = new(bitfield.BitField)
+			if err := t.Proven.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Proven pointer: %w", err)
+			}
+		}
+
 	}
+	// t.Disputed (bool) (bool)
 
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.Disputed = false
+	case 21:
+		t.Disputed = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
 	return nil
 }
 
-func (t *DeclareFaultsRecoveredParams) MarshalCBOR(w io.Writer) error {
+func (t *WindowedPoStSnapshots) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -1580,15 +2144,15 @@ func (t *DeclareFaultsRecoveredParams) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.Recoveries ([]miner.RecoveryDeclaration) (slice)
-	if len(t.Recoveries) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.Recoveries was too long")
+	// t.Snapshots ([]miner.WindowedPoStSnapshot) (slice)
+	if len(t.Snapshots) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Snapshots was too long")
 	}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Recoveries)))); err != nil {
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Snapshots)))); err != nil {
 		return err
 	}
-	for _, v := range t.Recoveries {
+	for _, v := range t.Snapshots {
 		if err := v.MarshalCBOR(w); err != nil {
 			return err
 		}
@@ -1596,7 +2160,7 @@ func (t *DeclareFaultsRecoveredParams) MarshalCBOR(w io.Writer) error {
 	return nil
 }
 
-func (t *DeclareFaultsRecoveredParams) UnmarshalCBOR(r io.Reader) error {
+func (t *WindowedPoStSnapshots) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1611,7 +2175,7 @@ func (t *DeclareFaultsRecoveredParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.Recoveries ([]miner.RecoveryDeclaration) (slice)
+	// t.Snapshots ([]miner.WindowedPoStSnapshot) (slice)
 
 	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
@@ -1619,7 +2183,7 @@ func (t *DeclareFaultsRecoveredParams) UnmarshalCBOR(r io.Reader) error {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("t.Recoveries: array too large (%d)", extra)
+		return fmt.Errorf("t.Snapshots: array too large (%d)", extra)
 	}
 
 	if maj != cbg.MajArray {
@@ -1627,70 +2191,148 @@ func (t *DeclareFaultsRecoveredParams) UnmarshalCBOR(r io.Reader) error {
 	}
 
 	if extra > 0 {
-		t.Recoveries = make([]RecoveryDeclaration, extra)
+		t.Snapshots = make([]WindowedPoStSnapshot, extra)
 	}
 
 	for i := 0; i < int(extra); i++ {
 
-		var v RecoveryDeclaration
+		var v WindowedPoStSnapshot
 		if err := v.UnmarshalCBOR(br); err != nil {
 			return err
 		}
 
-		t.Recoveries[i] = v
+		t.Snapshots[i] = v
 	}
 
 	return nil
 }
 
-func (t *ReportConsensusFaultParams) MarshalCBOR(w io.Writer) error {
+func (t *SectorLocation) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{131}); err != nil {
+	if _, err := w.Write([]byte{130}); err != nil {
 		return err
 	}
 
-	// t.BlockHeader1 ([]uint8) (slice)
-	if len(t.BlockHeader1) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.BlockHeader1 was too long")
-	}
+	// t.Deadline (uint64) (uint64)
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.BlockHeader1)))); err != nil {
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
 		return err
 	}
-	if _, err := w.Write(t.BlockHeader1); err != nil {
+
+	// t.Partition (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Partition))); err != nil {
 		return err
 	}
 
-	// t.BlockHeader2 ([]uint8) (slice)
-	if len(t.BlockHeader2) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.BlockHeader2 was too long")
-	}
+	return nil
+}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.BlockHeader2)))); err != nil {
+func (t *SectorLocation) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
 		return err
 	}
-	if _, err := w.Write(t.BlockHeader2); err != nil {
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Deadline = uint64(extra)
+
+	}
+	// t.Partition (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Partition = uint64(extra)
+
+	}
+	return nil
+}
+
+func (t *SubmitWindowedPoStParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{133}); err != nil {
 		return err
 	}
 
-	// t.BlockHeaderExtra ([]uint8) (slice)
-	if len(t.BlockHeaderExtra) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.BlockHeaderExtra was too long")
+	// t.Deadline (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
+		return err
 	}
 
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.BlockHeaderExtra)))); err != nil {
+	// t.Partitions ([]uint64) (slice)
+	if len(t.Partitions) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Partitions was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Partitions)))); err != nil {
 		return err
 	}
-	if _, err := w.Write(t.BlockHeaderExtra); err != nil {
+	for _, v := range t.Partitions {
+		if err := cbg.CborWriteHeader(w, cbg.MajUnsignedInt, uint64(v)); err != nil {
+			return err
+		}
+	}
+
+	// t.Proofs ([]abi.PoStProof) (slice)
+	if len(t.Proofs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Proofs was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Proofs)))); err != nil {
+		return err
+	}
+	for _, v := range t.Proofs {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.Skipped (bitfield.BitField) (struct)
+	if err := t.Skipped.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Optimistic (bool) (bool)
+	if err := cbg.WriteBool(w, t.Optimistic); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *ReportConsensusFaultParams) UnmarshalCBOR(r io.Reader) error {
+func (t *SubmitWindowedPoStParams) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1701,127 +2343,2606 @@ func (t *ReportConsensusFaultParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 3 {
+	if extra != 5 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.BlockHeader1 ([]uint8) (slice)
+	// t.Deadline (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Deadline = uint64(extra)
+
+	}
+	// t.Partitions ([]uint64) (slice)
 
 	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
 		return err
 	}
 
-	if extra > cbg.ByteArrayMaxLen {
-		return fmt.Errorf("t.BlockHeader1: byte array too large (%d)", extra)
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Partitions: array too large (%d)", extra)
 	}
-	if maj != cbg.MajByteString {
-		return fmt.Errorf("expected byte array")
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
 	}
-	t.BlockHeader1 = make([]byte, extra)
-	if _, err := io.ReadFull(br, t.BlockHeader1); err != nil {
-		return err
+
+	if extra > 0 {
+		t.Partitions = make([]uint64, extra)
 	}
-	// t.BlockHeader2 ([]uint8) (slice)
+
+	for i := 0; i < int(extra); i++ {
+
+		maj, val, err := cbg.CborReadHeader(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read uint64 for t.Partitions slice: %w", err)
+		}
+
+		if maj != cbg.MajUnsignedInt {
+			return xerrors.Errorf("value read for array t.Partitions was not a uint, instead got %d", maj)
+		}
+
+		t.Partitions[i] = uint64(val)
+	}
+
+	// t.Proofs ([]abi.PoStProof) (slice)
 
 	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
 		return err
 	}
 
-	if extra > cbg.ByteArrayMaxLen {
-		return fmt.Errorf("t.BlockHeader2: byte array too large (%d)", extra)
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Proofs: array too large (%d)", extra)
 	}
-	if maj != cbg.MajByteString {
-		return fmt.Errorf("expected byte array")
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
 	}
-	t.BlockHeader2 = make([]byte, extra)
-	if _, err := io.ReadFull(br, t.BlockHeader2); err != nil {
-		return err
+
+	if extra > 0 {
+		t.Proofs = make([]abi.PoStProof, extra)
 	}
-	// t.BlockHeaderExtra ([]uint8) (slice)
+
+	for i := 0; i < int(extra); i++ {
+
+		var v abi.PoStProof
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Proofs[i] = v
+	}
+
+	// t.Skipped (bitfield.BitField) (struct)
+
+	{
+
+		if err := t.Skipped.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Skipped: %w", err)
+		}
+
+	}
+	// t.Optimistic (bool) (bool)
 
 	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
 		return err
 	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.Optimistic = false
+	case 21:
+		t.Optimistic = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	return nil
+}
 
-	if extra > cbg.ByteArrayMaxLen {
-		return fmt.Errorf("t.BlockHeaderExtra: byte array too large (%d)", extra)
+func (t *TerminateSectorsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
 	}
-	if maj != cbg.MajByteString {
-		return fmt.Errorf("expected byte array")
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
 	}
-	t.BlockHeaderExtra = make([]byte, extra)
-	if _, err := io.ReadFull(br, t.BlockHeaderExtra); err != nil {
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *GetControlAddressesReturn) MarshalCBOR(w io.Writer) error {
+func (t *TerminateSectorsParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	return nil
+}
+
+func (t *ChangePeerIDParams) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{130}); err != nil {
+	if _, err := w.Write([]byte{129}); err != nil {
 		return err
 	}
 
-	// t.Owner (address.Address) (struct)
-	if err := t.Owner.MarshalCBOR(w); err != nil {
-		return err
+	// t.NewID (peer.ID) (string)
+	if len(t.NewID) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.NewID was too long")
 	}
 
-	// t.Worker (address.Address) (struct)
-	if err := t.Worker.MarshalCBOR(w); err != nil {
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajTextString, uint64(len(t.NewID)))); err != nil {
 		return err
 	}
-	return nil
-}
+	if _, err := w.Write([]byte(t.NewID)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ChangePeerIDParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.NewID (peer.ID) (string)
+
+	{
+		sval, err := cbg.ReadString(br)
+		if err != nil {
+			return err
+		}
+
+		t.NewID = peer.ID(sval)
+	}
+	return nil
+}
+
+func (t *ChangeMultiaddrsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.NewMultiaddrs ([][]uint8) (slice)
+	if len(t.NewMultiaddrs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.NewMultiaddrs was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.NewMultiaddrs)))); err != nil {
+		return err
+	}
+	for _, v := range t.NewMultiaddrs {
+		if len(v) > cbg.ByteArrayMaxLen {
+			return xerrors.Errorf("Byte array in field v was too long")
+		}
+
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(v)))); err != nil {
+			return err
+		}
+		if _, err := w.Write(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ChangeMultiaddrsParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.NewMultiaddrs ([][]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.NewMultiaddrs: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.NewMultiaddrs = make([][]uint8, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+		{
+			var maj byte
+			var extra uint64
+			var err error
+
+			maj, extra, err = cbg.CborReadHeader(br)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.NewMultiaddrs[i]: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+			t.NewMultiaddrs[i] = make([]byte, extra)
+			if _, err := io.ReadFull(br, t.NewMultiaddrs[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *WithdrawPreCommitsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *WithdrawPreCommitsParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	return nil
+}
+
+func (t *MaskSectorNumbersParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *MaskSectorNumbersParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	return nil
+}
+
+func (t *ProveCommitSectorParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
+		return err
+	}
+
+	// t.Proof ([]uint8) (slice)
+	if len(t.Proof) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Proof was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.Proof)))); err != nil {
+		return err
+	}
+	if _, err := w.Write(t.Proof); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ProveCommitSectorParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.SectorNumber = abi.SectorNumber(extra)
+
+	}
+	// t.Proof ([]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.ByteArrayMaxLen {
+		return fmt.Errorf("t.Proof: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+	t.Proof = make([]byte, extra)
+	if _, err := io.ReadFull(br, t.Proof); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ProveCommitSectorsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Sectors ([]miner.ProveCommitSectorParams) (slice)
+	if len(t.Sectors) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Sectors was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Sectors)))); err != nil {
+		return err
+	}
+	for _, v := range t.Sectors {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ProveCommitSectorsParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors ([]miner.ProveCommitSectorParams) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Sectors: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Sectors = make([]ProveCommitSectorParams, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v ProveCommitSectorParams
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Sectors[i] = v
+	}
+
+	return nil
+}
+
+func (t *PreCommitSectorBatchParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Sectors ([]miner.SectorPreCommitInfo) (slice)
+	if len(t.Sectors) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Sectors was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Sectors)))); err != nil {
+		return err
+	}
+	for _, v := range t.Sectors {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *PreCommitSectorBatchParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors ([]miner.SectorPreCommitInfo) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Sectors: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Sectors = make([]SectorPreCommitInfo, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v SectorPreCommitInfo
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Sectors[i] = v
+	}
+
+	return nil
+}
+
+func (t *DisputeWindowedPoStParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
+		return err
+	}
+
+	// t.PoStIndex (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.PoStIndex))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *DisputeWindowedPoStParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Deadline = uint64(extra)
+
+	}
+	// t.PoStIndex (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.PoStIndex = uint64(extra)
+
+	}
+	return nil
+}
+
+func (t *ChangeWorkerAddressParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.NewWorker (address.Address) (struct)
+	if err := t.NewWorker.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ChangeWorkerAddressParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.NewWorker (address.Address) (struct)
+
+	{
+
+		if err := t.NewWorker.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.NewWorker: %w", err)
+		}
+
+	}
+	return nil
+}
+
+func (t *ChangeOwnerAddressParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.NewOwner (address.Address) (struct)
+	if err := t.NewOwner.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ChangeOwnerAddressParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.NewOwner (address.Address) (struct)
+
+	{
+
+		if err := t.NewOwner.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.NewOwner: %w", err)
+		}
+
+	}
+	return nil
+}
+
+func (t *ChangeControlAddressesParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.NewControlAddrs ([]address.Address) (slice)
+	if len(t.NewControlAddrs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.NewControlAddrs was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.NewControlAddrs)))); err != nil {
+		return err
+	}
+	for _, v := range t.NewControlAddrs {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ChangeControlAddressesParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.NewControlAddrs ([]address.Address) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.NewControlAddrs: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.NewControlAddrs = make([]address.Address, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v address.Address
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.NewControlAddrs[i] = v
+	}
+
+	return nil
+}
+
+func (t *ExtendSectorExpirationParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
+		return err
+	}
+
+	// t.NewExpiration (abi.ChainEpoch) (int64)
+	if t.NewExpiration >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.NewExpiration))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.NewExpiration)-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ExtendSectorExpirationParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.SectorNumber = abi.SectorNumber(extra)
+
+	}
+	// t.NewExpiration (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.NewExpiration = abi.ChainEpoch(extraI)
+	}
+	return nil
+}
+
+func (t *ExtendSectorExpirationsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Extensions ([]miner.ExpirationExtension) (slice)
+	if len(t.Extensions) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Extensions was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Extensions)))); err != nil {
+		return err
+	}
+	for _, v := range t.Extensions {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ExtendSectorExpirationsParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Extensions ([]miner.ExpirationExtension) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Extensions: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Extensions = make([]ExpirationExtension, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v ExpirationExtension
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Extensions[i] = v
+	}
+
+	return nil
+}
+
+func (t *DeclareFaultsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Faults ([]miner.FaultDeclaration) (slice)
+	if len(t.Faults) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Faults was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Faults)))); err != nil {
+		return err
+	}
+	for _, v := range t.Faults {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *DeclareFaultsParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Faults ([]miner.FaultDeclaration) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Faults: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Faults = make([]FaultDeclaration, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v FaultDeclaration
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Faults[i] = v
+	}
+
+	return nil
+}
+
+func (t *DeclareFaultsRecoveredParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.Recoveries ([]miner.RecoveryDeclaration) (slice)
+	if len(t.Recoveries) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Recoveries was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Recoveries)))); err != nil {
+		return err
+	}
+	for _, v := range t.Recoveries {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *DeclareFaultsRecoveredParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Recoveries ([]miner.RecoveryDeclaration) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Recoveries: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Recoveries = make([]RecoveryDeclaration, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v RecoveryDeclaration
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Recoveries[i] = v
+	}
+
+	return nil
+}
+
+func (t *ReportConsensusFaultParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{131}); err != nil {
+		return err
+	}
+
+	// t.BlockHeader1 ([]uint8) (slice)
+	if len(t.BlockHeader1) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.BlockHeader1 was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.BlockHeader1)))); err != nil {
+		return err
+	}
+	if _, err := w.Write(t.BlockHeader1); err != nil {
+		return err
+	}
+
+	// t.BlockHeader2 ([]uint8) (slice)
+	if len(t.BlockHeader2) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.BlockHeader2 was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.BlockHeader2)))); err != nil {
+		return err
+	}
+	if _, err := w.Write(t.BlockHeader2); err != nil {
+		return err
+	}
+
+	// t.BlockHeaderExtra ([]uint8) (slice)
+	if len(t.BlockHeaderExtra) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.BlockHeaderExtra was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(t.BlockHeaderExtra)))); err != nil {
+		return err
+	}
+	if _, err := w.Write(t.BlockHeaderExtra); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ReportConsensusFaultParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.BlockHeader1 ([]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.ByteArrayMaxLen {
+		return fmt.Errorf("t.BlockHeader1: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+	t.BlockHeader1 = make([]byte, extra)
+	if _, err := io.ReadFull(br, t.BlockHeader1); err != nil {
+		return err
+	}
+	// t.BlockHeader2 ([]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.ByteArrayMaxLen {
+		return fmt.Errorf("t.BlockHeader2: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+	t.BlockHeader2 = make([]byte, extra)
+	if _, err := io.ReadFull(br, t.BlockHeader2); err != nil {
+		return err
+	}
+	// t.BlockHeaderExtra ([]uint8) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.ByteArrayMaxLen {
+		return fmt.Errorf("t.BlockHeaderExtra: byte array too large (%d)", extra)
+	}
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("expected byte array")
+	}
+	t.BlockHeaderExtra = make([]byte, extra)
+	if _, err := io.ReadFull(br, t.BlockHeaderExtra); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *GetControlAddressesReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{131}); err != nil {
+		return err
+	}
+
+	// t.Owner (address.Address) (struct)
+	if err := t.Owner.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Worker (address.Address) (struct)
+	if err := t.Worker.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.ControlAddrs ([]address.Address) (slice)
+	if len(t.ControlAddrs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.ControlAddrs was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.ControlAddrs)))); err != nil {
+		return err
+	}
+	for _, v := range t.ControlAddrs {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *GetControlAddressesReturn) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Owner (address.Address) (struct)
+
+	{
+
+		if err := t.Owner.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Owner: %w", err)
+		}
+
+	}
+	// t.Worker (address.Address) (struct)
+
+	{
+
+		if err := t.Worker.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Worker: %w", err)
+		}
+
+	}
+	// t.ControlAddrs ([]address.Address) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.ControlAddrs: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.ControlAddrs = make([]address.Address, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v address.Address
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.ControlAddrs[i] = v
+	}
+
+	return nil
+}
+
+func (t *GetSectorStatusParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *GetSectorStatusParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.SectorNumber = abi.SectorNumber(extra)
+
+	}
+	return nil
+}
+
+func (t *GetDeadlineSummaryReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.Deadline (miner.DeadlineInfo) (struct)
+	if err := t.Deadline.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Partitions ([]miner.PartitionSummary) (slice)
+	if len(t.Partitions) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Partitions was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.Partitions)))); err != nil {
+		return err
+	}
+	for _, v := range t.Partitions {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *GetDeadlineSummaryReturn) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Deadline (miner.DeadlineInfo) (struct)
+
+	{
+
+		if err := t.Deadline.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Deadline: %w", err)
+		}
+
+	}
+	// t.Partitions ([]miner.PartitionSummary) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Partitions: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Partitions = make([]PartitionSummary, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v PartitionSummary
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Partitions[i] = v
+	}
+
+	return nil
+}
+
+func (t *CheckSectorProvenParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *CheckSectorProvenParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.SectorNumber = abi.SectorNumber(extra)
+
+	}
+	return nil
+}
+
+func (t *WithdrawBalanceParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{129}); err != nil {
+		return err
+	}
+
+	// t.AmountRequested (big.Int) (struct)
+	if err := t.AmountRequested.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *WithdrawBalanceParams) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.AmountRequested (big.Int) (struct)
+
+	{
+
+		if err := t.AmountRequested.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.AmountRequested: %w", err)
+		}
+
+	}
+	return nil
+}
+
+func (t *CronEventPayload) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.EventType (miner.CronEventType) (int64)
+	if t.EventType >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.EventType))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.EventType)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *CronEventPayload) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.EventType (miner.CronEventType) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.EventType = CronEventType(extraI)
+	}
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	return nil
+}
+
+func (t *FaultDeclaration) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{131}); err != nil {
+		return err
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
+		return err
+	}
+
+	// t.Partition (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Partition))); err != nil {
+		return err
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *FaultDeclaration) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Deadline = uint64(extra)
+
+	}
+	// t.Partition (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Partition = uint64(extra)
+
+	}
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	return nil
+}
+
+func (t *RecoveryDeclaration) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{131}); err != nil {
+		return err
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
+		return err
+	}
+
+	// t.Partition (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Partition))); err != nil {
+		return err
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *RecoveryDeclaration) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Deadline = uint64(extra)
+
+	}
+	// t.Partition (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Partition = uint64(extra)
+
+	}
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	return nil
+}
+
+func (t *ExpirationExtension) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{130}); err != nil {
+		return err
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.NewExpiration (abi.ChainEpoch) (int64)
+	if t.NewExpiration >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.NewExpiration))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.NewExpiration)-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ExpirationExtension) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Sectors (bitfield.BitField) (struct)
+
+	{
+
+		pb, err := br.PeekByte()
+		if err != nil {
+			return err
+		}
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
+			}
+		} else {
+			t.Sectors = new(bitfield.BitField)
+			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			}
+		}
+
+	}
+	// t.NewExpiration (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.NewExpiration = abi.ChainEpoch(extraI)
+	}
+	return nil
+}
+
+func (t *SectorStatus) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{134}); err != nil {
+		return err
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorNumber))); err != nil {
+		return err
+	}
+
+	// t.Status (miner.SectorStatusCode) (int64)
+	if t.Status >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Status))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Status)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Expiration (abi.ChainEpoch) (int64)
+	if t.Expiration >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Expiration))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Expiration)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Assigned (bool) (bool)
+	if err := cbg.WriteBool(w, t.Assigned); err != nil {
+		return err
+	}
+
+	// t.Deadline (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
+		return err
+	}
+
+	// t.Partition (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Partition))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *SectorStatus) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 6 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.SectorNumber (abi.SectorNumber) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.SectorNumber = abi.SectorNumber(extra)
+
+	}
+	// t.Status (miner.SectorStatusCode) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Status = SectorStatusCode(extraI)
+	}
+	// t.Expiration (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Expiration = abi.ChainEpoch(extraI)
+	}
+	// t.Assigned (bool) (bool)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
+	}
+	switch extra {
+	case 20:
+		t.Assigned = false
+	case 21:
+		t.Assigned = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+	}
+	// t.Deadline (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Deadline = uint64(extra)
+
+	}
+	// t.Partition (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Partition = uint64(extra)
+
+	}
+	return nil
+}
+
+func (t *DeadlineInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{135}); err != nil {
+		return err
+	}
+
+	// t.CurrentEpoch (abi.ChainEpoch) (int64)
+	if t.CurrentEpoch >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.CurrentEpoch))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.CurrentEpoch)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.PeriodStart (abi.ChainEpoch) (int64)
+	if t.PeriodStart >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.PeriodStart))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.PeriodStart)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Index (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Index))); err != nil {
+		return err
+	}
+
+	// t.Open (abi.ChainEpoch) (int64)
+	if t.Open >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Open))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Open)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Close (abi.ChainEpoch) (int64)
+	if t.Close >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Close))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Close)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Challenge (abi.ChainEpoch) (int64)
+	if t.Challenge >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Challenge))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Challenge)-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.FaultCutoff (abi.ChainEpoch) (int64)
+	if t.FaultCutoff >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.FaultCutoff))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.FaultCutoff)-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *DeadlineInfo) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 7 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.CurrentEpoch (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.CurrentEpoch = abi.ChainEpoch(extraI)
+	}
+	// t.PeriodStart (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.PeriodStart = abi.ChainEpoch(extraI)
+	}
+	// t.Index (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Index = uint64(extra)
+
+	}
+	// t.Open (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Open = abi.ChainEpoch(extraI)
+	}
+	// t.Close (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Close = abi.ChainEpoch(extraI)
+	}
+	// t.Challenge (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.Challenge = abi.ChainEpoch(extraI)
+	}
+	// t.FaultCutoff (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.FaultCutoff = abi.ChainEpoch(extraI)
+	}
+	return nil
+}
+
+func (t *PartitionSummary) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{135}); err != nil {
+		return err
+	}
+
+	// t.Index (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Index))); err != nil {
+		return err
+	}
+
+	// t.SectorCount (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.SectorCount))); err != nil {
+		return err
+	}
+
+	// t.FaultCount (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.FaultCount))); err != nil {
+		return err
+	}
+
+	// t.RecoveryCount (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.RecoveryCount))); err != nil {
+		return err
+	}
+
+	// t.Proven (bool) (bool)
+	if err := cbg.WriteBool(w, t.Proven); err != nil {
+		return err
+	}
+
+	// t.TotalPower (miner.PowerPair) (struct)
+	if err := t.TotalPower.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.FaultyPower (miner.PowerPair) (struct)
+	if err := t.FaultyPower.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PartitionSummary) UnmarshalCBOR(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+
+	maj, extra, err := cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 7 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Index (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Index = uint64(extra)
+
+	}
+	// t.SectorCount (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.SectorCount = uint64(extra)
+
+	}
+	// t.FaultCount (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.FaultCount = uint64(extra)
+
+	}
+	// t.RecoveryCount (uint64) (uint64)
 
-func (t *GetControlAddressesReturn) UnmarshalCBOR(r io.Reader) error {
-	br := cbg.GetPeeker(r)
+	{
 
-	maj, extra, err := cbg.CborReadHeader(br)
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.RecoveryCount = uint64(extra)
+
+	}
+	// t.Proven (bool) (bool)
+
+	maj, extra, err = cbg.CborReadHeader(br)
 	if err != nil {
 		return err
 	}
-	if maj != cbg.MajArray {
-		return fmt.Errorf("cbor input should be of type array")
+	if maj != cbg.MajOther {
+		return fmt.Errorf("booleans must be major type 7")
 	}
-
-	if extra != 2 {
-		return fmt.Errorf("cbor input had wrong number of fields")
+	switch extra {
+	case 20:
+		t.Proven = false
+	case 21:
+		t.Proven = true
+	default:
+		return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
 	}
-
-	// t.Owner (address.Address) (struct)
+	// t.TotalPower (miner.PowerPair) (struct)
 
 	{
 
-		if err := t.Owner.UnmarshalCBOR(br); err != nil {
-			return xerrors.Errorf("unmarshaling t.Owner: %w", err)
+		if err := t.TotalPower.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.TotalPower: %w", err)
 		}
 
 	}
-	// t.Worker (address.Address) (struct)
+	// t.FaultyPower (miner.PowerPair) (struct)
 
 	{
 
-		if err := t.Worker.UnmarshalCBOR(br); err != nil {
-			return xerrors.Errorf("unmarshaling t.Worker: %w", err)
+		if err := t.FaultyPower.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.FaultyPower: %w", err)
 		}
 
 	}
 	return nil
 }
 
-func (t *CheckSectorProvenParams) MarshalCBOR(w io.Writer) error {
+func (t *SectorProven) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{129}); err != nil {
+	if _, err := w.Write([]byte{130}); err != nil {
 		return err
 	}
 
@@ -1831,10 +4952,20 @@ func (t *CheckSectorProvenParams) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.Expiration (abi.ChainEpoch) (int64)
+	if t.Expiration >= 0 {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Expiration))); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.Expiration)-1)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (t *CheckSectorProvenParams) UnmarshalCBOR(r io.Reader) error {
+func (t *SectorProven) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1845,7 +4976,7 @@ func (t *CheckSectorProvenParams) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 1 {
+	if extra != 2 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -1863,53 +4994,35 @@ func (t *CheckSectorProvenParams) UnmarshalCBOR(r io.Reader) error {
 		t.SectorNumber = abi.SectorNumber(extra)
 
 	}
-	return nil
-}
-
-func (t *WithdrawBalanceParams) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
-		return err
-	}
-	if _, err := w.Write([]byte{129}); err != nil {
-		return err
-	}
-
-	// t.AmountRequested (big.Int) (struct)
-	if err := t.AmountRequested.MarshalCBOR(w); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (t *WithdrawBalanceParams) UnmarshalCBOR(r io.Reader) error {
-	br := cbg.GetPeeker(r)
-
-	maj, extra, err := cbg.CborReadHeader(br)
-	if err != nil {
-		return err
-	}
-	if maj != cbg.MajArray {
-		return fmt.Errorf("cbor input should be of type array")
-	}
-
-	if extra != 1 {
-		return fmt.Errorf("cbor input had wrong number of fields")
-	}
-
-	// t.AmountRequested (big.Int) (struct)
-
+	// t.Expiration (abi.ChainEpoch) (int64)
 	{
-
-		if err := t.AmountRequested.UnmarshalCBOR(br); err != nil {
-			return xerrors.Errorf("unmarshaling t.AmountRequested: %w", err)
+		maj, extra, err := cbg.CborReadHeader(br)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
 		}
 
+		t.Expiration = abi.ChainEpoch(extraI)
 	}
 	return nil
 }
 
-func (t *CronEventPayload) MarshalCBOR(w io.Writer) error {
+func (t *FaultDetected) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -1918,25 +5031,19 @@ func (t *CronEventPayload) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.EventType (miner.CronEventType) (int64)
-	if t.EventType >= 0 {
-		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.EventType))); err != nil {
-			return err
-		}
-	} else {
-		if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajNegativeInt, uint64(-t.EventType)-1)); err != nil {
-			return err
-		}
+	// t.Faults (bitfield.BitField) (struct)
+	if err := t.Faults.MarshalCBOR(w); err != nil {
+		return err
 	}
 
-	// t.Sectors (bitfield.BitField) (struct)
-	if err := t.Sectors.MarshalCBOR(w); err != nil {
+	// t.FailedRecoveries (bitfield.BitField) (struct)
+	if err := t.FailedRecoveries.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *CronEventPayload) UnmarshalCBOR(r io.Reader) error {
+func (t *FaultDetected) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -1951,32 +5058,28 @@ func (t *CronEventPayload) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.EventType (miner.CronEventType) (int64)
+	// t.Faults (bitfield.BitField) (struct)
+
 	{
-		maj, extra, err := cbg.CborReadHeader(br)
-		var extraI int64
+
+		pb, err := br.PeekByte()
 		if err != nil {
 			return err
 		}
-		switch maj {
-		case cbg.MajUnsignedInt:
-			extraI = int64(extra)
-			if extraI < 0 {
-				return fmt.Errorf("int64 positive overflow")
+		if pb == cbg.CborNull[0] {
+			var nbuf [1]byte
+			if _, err := br.Read(nbuf[:]); err != nil {
+				return err
 			}
-		case cbg.MajNegativeInt:
-			extraI = int64(extra)
-			if extraI < 0 {
-				return fmt.Errorf("int64 negative oveflow")
+		} else {
+			t.Faults = new(bitfield.BitField)
+			if err := t.Faults.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Faults pointer: %w", err)
 			}
-			extraI = -1 - extraI
-		default:
-			return fmt.Errorf("wrong type for int64 field: %d", maj)
 		}
 
-		t.EventType = CronEventType(extraI)
 	}
-	// t.Sectors (bitfield.BitField) (struct)
+	// t.FailedRecoveries (bitfield.BitField) (struct)
 
 	{
 
@@ -1990,9 +5093,9 @@ func (t *CronEventPayload) UnmarshalCBOR(r io.Reader) error {
 				return err
 			}
 		} else {
-			t.Sectors = new(bitfield.BitField)
-			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
-				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			t.FailedRecoveries = new(bitfield.BitField)
+			if err := t.FailedRecoveries.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.FailedRecoveries pointer: %w", err)
 			}
 		}
 
@@ -2000,12 +5103,12 @@ func (t *CronEventPayload) UnmarshalCBOR(r io.Reader) error {
 	return nil
 }
 
-func (t *FaultDeclaration) MarshalCBOR(w io.Writer) error {
+func (t *PoStDisputed) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{130}); err != nil {
+	if _, err := w.Write([]byte{131}); err != nil {
 		return err
 	}
 
@@ -2015,14 +5118,20 @@ func (t *FaultDeclaration) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.Sectors (bitfield.BitField) (struct)
-	if err := t.Sectors.MarshalCBOR(w); err != nil {
+	// t.PoStIndex (uint64) (uint64)
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.PoStIndex))); err != nil {
+		return err
+	}
+
+	// t.Faults (bitfield.BitField) (struct)
+	if err := t.Faults.MarshalCBOR(w); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *FaultDeclaration) UnmarshalCBOR(r io.Reader) error {
+func (t *PoStDisputed) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -2033,7 +5142,7 @@ func (t *FaultDeclaration) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 2 {
+	if extra != 3 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -2051,7 +5160,21 @@ func (t *FaultDeclaration) UnmarshalCBOR(r io.Reader) error {
 		t.Deadline = uint64(extra)
 
 	}
-	// t.Sectors (bitfield.BitField) (struct)
+	// t.PoStIndex (uint64) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.PoStIndex = uint64(extra)
+
+	}
+	// t.Faults (bitfield.BitField) (struct)
 
 	{
 
@@ -2065,9 +5188,9 @@ func (t *FaultDeclaration) UnmarshalCBOR(r io.Reader) error {
 				return err
 			}
 		} else {
-			t.Sectors = new(bitfield.BitField)
-			if err := t.Sectors.UnmarshalCBOR(br); err != nil {
-				return xerrors.Errorf("unmarshaling t.Sectors pointer: %w", err)
+			t.Faults = new(bitfield.BitField)
+			if err := t.Faults.UnmarshalCBOR(br); err != nil {
+				return xerrors.Errorf("unmarshaling t.Faults pointer: %w", err)
 			}
 		}
 
@@ -2075,7 +5198,7 @@ func (t *FaultDeclaration) UnmarshalCBOR(r io.Reader) error {
 	return nil
 }
 
-func (t *RecoveryDeclaration) MarshalCBOR(w io.Writer) error {
+func (t *PreCommitsWithdrawn) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -2084,20 +5207,28 @@ func (t *RecoveryDeclaration) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.Deadline (uint64) (uint64)
-
-	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, uint64(t.Deadline))); err != nil {
+	// t.Sectors (bitfield.BitField) (struct)
+	if err := t.Sectors.MarshalCBOR(w); err != nil {
 		return err
 	}
 
-	// t.Sectors (bitfield.BitField) (struct)
-	if err := t.Sectors.MarshalCBOR(w); err != nil {
+	// t.DealIDs ([]abi.DealID) (slice)
+	if len(t.DealIDs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.DealIDs was too long")
+	}
+
+	if _, err := w.Write(cbg.CborEncodeMajorType(cbg.MajArray, uint64(len(t.DealIDs)))); err != nil {
 		return err
 	}
+	for _, v := range t.DealIDs {
+		if err := cbg.CborWriteHeader(w, cbg.MajUnsignedInt, uint64(v)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (t *RecoveryDeclaration) UnmarshalCBOR(r io.Reader) error {
+func (t *PreCommitsWithdrawn) UnmarshalCBOR(r io.Reader) error {
 	br := cbg.GetPeeker(r)
 
 	maj, extra, err := cbg.CborReadHeader(br)
@@ -2112,20 +5243,6 @@ func (t *RecoveryDeclaration) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
-	// t.Deadline (uint64) (uint64)
-
-	{
-
-		maj, extra, err = cbg.CborReadHeader(br)
-		if err != nil {
-			return err
-		}
-		if maj != cbg.MajUnsignedInt {
-			return fmt.Errorf("wrong type for uint64 field")
-		}
-		t.Deadline = uint64(extra)
-
-	}
 	// t.Sectors (bitfield.BitField) (struct)
 
 	{
@@ -2147,5 +5264,38 @@ func (t *RecoveryDeclaration) UnmarshalCBOR(r io.Reader) error {
 		}
 
 	}
+	// t.DealIDs ([]abi.DealID) (slice)
+
+	maj, extra, err = cbg.CborReadHeader(br)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.DealIDs: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.DealIDs = make([]abi.DealID, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		maj, val, err := cbg.CborReadHeader(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read uint64 for t.DealIDs slice: %w", err)
+		}
+
+		if maj != cbg.MajUnsignedInt {
+			return xerrors.Errorf("value read for array t.DealIDs was not a uint, instead got %d", maj)
+		}
+
+		t.DealIDs[i] = abi.DealID(val)
+	}
+
 	return nil
 }
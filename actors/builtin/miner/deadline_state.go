@@ -0,0 +1,135 @@
+package miner
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/go-bitfield"
+	cid "github.com/ipfs/go-cid"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// The partitions of sectors due for Window PoSt at one deadline in each proving period.
+type Deadline struct {
+	// Partitions due at this deadline, in the order they were created.
+	// Partition indices are local to the deadline, and not re-used.
+	Partitions cid.Cid // Array, AMT[PartitionIndex]Partition
+
+	// Records successful PoSt submission in the current proving period by partition index.
+	// The presence of a partition index indicates on-time PoSt received.
+	PostSubmissions *abi.BitField
+}
+
+// The state of a partition within a deadline, as of the current proving period.
+type PartitionSummary struct {
+	Index         uint64
+	SectorCount   uint64
+	FaultCount    uint64
+	RecoveryCount uint64
+	Proven        bool // Whether a PoSt for the partition has been accepted in the current proving period.
+	TotalPower    PowerPair
+	FaultyPower   PowerPair
+}
+
+func ConstructDeadline(emptyArrayCid cid.Cid) *Deadline {
+	return &Deadline{
+		Partitions:      emptyArrayCid,
+		PostSubmissions: abi.NewBitField(),
+	}
+}
+
+// Counts the partitions at the deadline, including any that no longer hold sectors.
+func (dl *Deadline) PartitionCount(store adt.Store) (uint64, error) {
+	partitions, err := AsPartitionArray(store, dl.Partitions)
+	if err != nil {
+		return 0, err
+	}
+	return partitions.Length(), nil
+}
+
+// Loads a partition by index, failing if there is no such partition.
+func (dl *Deadline) LoadPartition(store adt.Store, partIdx uint64) (*Partition, error) {
+	partitions, err := AsPartitionArray(store, dl.Partitions)
+	if err != nil {
+		return nil, err
+	}
+	partition, found, err := partitions.Get(partIdx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load partition %d: %w", partIdx, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("no partition %d", partIdx)
+	}
+	return partition, nil
+}
+
+// Stores a partition at an index, which must be no greater than the number of partitions.
+func (dl *Deadline) SavePartition(store adt.Store, partIdx uint64, partition *Partition) error {
+	partitions, err := AsPartitionArray(store, dl.Partitions)
+	if err != nil {
+		return err
+	}
+	if partIdx > partitions.Length() {
+		return fmt.Errorf("partition index %d skips past %d partitions", partIdx, partitions.Length())
+	}
+	if err = partitions.Set(partIdx, partition); err != nil {
+		return fmt.Errorf("failed to store partition %d: %w", partIdx, err)
+	}
+	dl.Partitions, err = partitions.Root()
+	return err
+}
+
+// Iterates the partitions at the deadline, in index order.
+func (dl *Deadline) ForEachPartition(store adt.Store, cb func(partIdx uint64, partition *Partition) error) error {
+	partitions, err := AsPartitionArray(store, dl.Partitions)
+	if err != nil {
+		return err
+	}
+	return partitions.ForEach(cb)
+}
+
+// Adds partition indices to the set of PoSt submissions.
+func (dl *Deadline) AddPoStSubmissions(partIdxs *abi.BitField) (err error) {
+	dl.PostSubmissions, err = bitfield.MergeBitFields(dl.PostSubmissions, partIdxs)
+	return err
+}
+
+// Removes all PoSt submissions.
+func (dl *Deadline) ClearPoStSubmissions() {
+	dl.PostSubmissions = abi.NewBitField()
+}
+
+// Summarizes each partition at the deadline, in index order.
+func (dl *Deadline) PartitionSummaries(store adt.Store) ([]PartitionSummary, error) {
+	var summaries []PartitionSummary
+	err := dl.ForEachPartition(store, func(partIdx uint64, partition *Partition) error {
+		sectorCount, err := partition.Sectors.Count()
+		if err != nil {
+			return err
+		}
+		faultCount, err := partition.Faults.Count()
+		if err != nil {
+			return err
+		}
+		recoveryCount, err := partition.Recoveries.Count()
+		if err != nil {
+			return err
+		}
+		proven, err := dl.PostSubmissions.IsSet(partIdx)
+		if err != nil {
+			return err
+		}
+		summaries = append(summaries, PartitionSummary{
+			Index:         partIdx,
+			SectorCount:   sectorCount,
+			FaultCount:    faultCount,
+			RecoveryCount: recoveryCount,
+			Proven:        proven,
+			TotalPower:    partition.TotalPower,
+			FaultyPower:   partition.FaultyPower,
+		})
+		return nil
+	})
+	return summaries, err
+}
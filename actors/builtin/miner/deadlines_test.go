@@ -117,260 +117,6 @@ func makeDeadline(currEpoch, periodStart abi.ChainEpoch, deadline uint64, deadli
 	}
 }
 
-func TestPartitionsForDeadline(t *testing.T) {
-	t.Run("empty deadlines", func(t *testing.T) {
-		dl := deadlineWithSectors(t, [miner.WPoStPeriodDeadlines]uint64{})
-		firstIndex, sectorCount, err := miner.PartitionsForDeadline(dl, 0)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, uint64(0), sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, miner.WPoStPeriodDeadlines-1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, uint64(0), sectorCount)
-	})
-
-	t.Run("single sector at first deadline", func(t *testing.T) {
-		dl := deadlineWithSectors(t, [miner.WPoStPeriodDeadlines]uint64{1})
-		firstIndex, sectorCount, err := miner.PartitionsForDeadline(dl, 0)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, uint64(1), sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Zero(t, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, miner.WPoStPeriodDeadlines-1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Zero(t, sectorCount)
-	})
-
-	t.Run("single sector at non-first deadline", func(t *testing.T) {
-		dl := deadlineWithSectors(t, [miner.WPoStPeriodDeadlines]uint64{0, 1})
-		firstIndex, sectorCount, err := miner.PartitionsForDeadline(dl, 0)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, uint64(0), sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, uint64(1), sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 2)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Equal(t, uint64(0), sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, miner.WPoStPeriodDeadlines-1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Equal(t, uint64(0), sectorCount)
-	})
-
-	t.Run("deadlines with one full partitions", func(t *testing.T) {
-		dl := deadlinesWithFullPartitions(t, 1)
-		firstIndex, sectorCount, err := miner.PartitionsForDeadline(dl, 0)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, partSize, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Equal(t, partSize, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, miner.WPoStPeriodDeadlines-1)
-		require.NoError(t, err)
-		assert.Equal(t, miner.WPoStPeriodDeadlines-1, firstIndex)
-		assert.Equal(t, partSize, sectorCount)
-	})
-
-	t.Run("partial partitions", func(t *testing.T) {
-		dl := deadlineWithSectors(t, [miner.WPoStPeriodDeadlines]uint64{
-			0: partSize - 1,
-			1: partSize,
-			2: partSize - 2,
-			3: partSize,
-			4: partSize - 3,
-			5: partSize,
-		})
-		firstIndex, sectorCount, err := miner.PartitionsForDeadline(dl, 0)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, partSize-1, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Equal(t, partSize, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 2)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(2), firstIndex)
-		assert.Equal(t, partSize-2, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 5)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(5), firstIndex)
-		assert.Equal(t, partSize, sectorCount)
-	})
-
-	t.Run("multiple partitions", func(t *testing.T) {
-		dl := deadlineWithSectors(t, [miner.WPoStPeriodDeadlines]uint64{
-			0: partSize,       // 1 partition 1 total
-			1: partSize * 2,   // 2 partitions 3 total
-			2: partSize*4 - 1, // 4 partitions 7 total
-			3: partSize * 6,   // 6 partitions 13 total
-			4: partSize*8 - 1, // 8 partitions 21 total
-			5: partSize * 9,   // 9 partitions 30 total
-		})
-
-		firstIndex, sectorCount, err := miner.PartitionsForDeadline(dl, 0)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(0), firstIndex)
-		assert.Equal(t, partSize, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(1), firstIndex)
-		assert.Equal(t, partSize*2, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 2)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(3), firstIndex)
-		assert.Equal(t, partSize*4-1, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 3)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(7), firstIndex)
-		assert.Equal(t, partSize*6, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 4)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(13), firstIndex)
-		assert.Equal(t, partSize*8-1, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, 5)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(21), firstIndex)
-		assert.Equal(t, partSize*9, sectorCount)
-
-		firstIndex, sectorCount, err = miner.PartitionsForDeadline(dl, miner.WPoStPeriodDeadlines-1)
-		require.NoError(t, err)
-		assert.Equal(t, uint64(30), firstIndex)
-		assert.Equal(t, uint64(0), sectorCount)
-	})
-}
-
-func TestComputePartitionsSectors(t *testing.T) {
-	t.Run("no partitions due at empty deadline", func(t *testing.T) {
-		dls := miner.ConstructDeadlines()
-		dls.Due[1] = bf(0, 1)
-
-		// No partitions at deadline 0
-		_, err := miner.ComputePartitionsSectors(dls, 0, []uint64{0})
-		require.Error(t, err)
-
-		// No partitions at deadline 2
-		_, err = miner.ComputePartitionsSectors(dls, 2, []uint64{0})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 2, []uint64{1})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 2, []uint64{2})
-		require.Error(t, err)
-	})
-	t.Run("single sector", func(t *testing.T) {
-		dls := miner.ConstructDeadlines()
-		dls.Due[1] = bf(0, 1)
-		partitions, err := miner.ComputePartitionsSectors(dls, 1, []uint64{0})
-		require.NoError(t, err)
-		assert.Equal(t, 1, len(partitions))
-		assertBfEqual(t, bf(0, 1), partitions[0])
-	})
-	t.Run("full partition", func(t *testing.T) {
-		dls := miner.ConstructDeadlines()
-		dls.Due[10] = bf(1234, partSize)
-		partitions, err := miner.ComputePartitionsSectors(dls, 10, []uint64{0})
-		require.NoError(t, err)
-		assert.Equal(t, 1, len(partitions))
-		assertBfEqual(t, bf(1234, partSize), partitions[0])
-	})
-	t.Run("full plus partial partition", func(t *testing.T) {
-		dls := miner.ConstructDeadlines()
-		dls.Due[10] = bf(5555, partSize+1)
-		partitions, err := miner.ComputePartitionsSectors(dls, 10, []uint64{0}) // First partition
-		require.NoError(t, err)
-		assert.Equal(t, 1, len(partitions))
-		assertBfEqual(t, bf(5555, partSize), partitions[0])
-
-		partitions, err = miner.ComputePartitionsSectors(dls, 10, []uint64{1}) // Second partition
-		require.NoError(t, err)
-		assert.Equal(t, 1, len(partitions))
-		assertBfEqual(t, bf(5555+partSize, 1), partitions[0])
-
-		partitions, err = miner.ComputePartitionsSectors(dls, 10, []uint64{0, 1}) // Both partitions
-		require.NoError(t, err)
-		assert.Equal(t, 2, len(partitions))
-		assertBfEqual(t, bf(5555, partSize), partitions[0])
-		assertBfEqual(t, bf(5555+partSize, 1), partitions[1])
-	})
-	t.Run("multiple partitions", func(t *testing.T) {
-		dls := miner.ConstructDeadlines()
-		dls.Due[1] = bf(0, 3*partSize+1)
-		partitions, err := miner.ComputePartitionsSectors(dls, 1, []uint64{0, 1, 2, 3})
-		require.NoError(t, err)
-		assert.Equal(t, 4, len(partitions))
-		assertBfEqual(t, bf(0, partSize), partitions[0])
-		assertBfEqual(t, bf(1*partSize, partSize), partitions[1])
-		assertBfEqual(t, bf(2*partSize, partSize), partitions[2])
-		assertBfEqual(t, bf(3*partSize, 1), partitions[3])
-	})
-	t.Run("partitions numbered across deadlines", func(t *testing.T) {
-		dls := miner.ConstructDeadlines()
-		dls.Due[1] = bf(0, 3*partSize+1)
-		dls.Due[3] = bf(3*partSize+1, 1)
-		dls.Due[5] = bf(3*partSize+1+1, 2*partSize)
-
-		partitions, err := miner.ComputePartitionsSectors(dls, 1, []uint64{0, 1, 2, 3})
-		require.NoError(t, err)
-		assert.Equal(t, 4, len(partitions))
-
-		partitions, err = miner.ComputePartitionsSectors(dls, 3, []uint64{4})
-		require.NoError(t, err)
-		assert.Equal(t, 1, len(partitions))
-		assertBfEqual(t, bf(3*partSize+1, 1), partitions[0])
-
-		partitions, err = miner.ComputePartitionsSectors(dls, 5, []uint64{5, 6})
-		require.NoError(t, err)
-		assert.Equal(t, 2, len(partitions))
-		assertBfEqual(t, bf(3*partSize+1+1, partSize), partitions[0])
-		assertBfEqual(t, bf(3*partSize+1+1+partSize, partSize), partitions[1])
-
-		// Mismatched deadline/partition pairs
-		_, err = miner.ComputePartitionsSectors(dls, 1, []uint64{4})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 2, []uint64{4})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 3, []uint64{0})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 3, []uint64{3})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 3, []uint64{5})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 4, []uint64{5})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 5, []uint64{0})
-		require.Error(t, err)
-		_, err = miner.ComputePartitionsSectors(dls, 5, []uint64{7})
-		require.Error(t, err)
-	})
-}
-
 //
 // Deadlines Utils
 //
@@ -391,33 +137,3 @@ func bf(first uint64, count uint64) *abi.BitField {
 	}
 	return bitfield.NewFromSet(values)
 }
-
-func deadlinesWithFullPartitions(t *testing.T, n uint64) *miner.Deadlines {
-	gen := [miner.WPoStPeriodDeadlines]uint64{}
-	for i := range gen {
-		gen[i] = partSize * n
-	}
-	return deadlineWithSectors(t, gen)
-}
-
-// accepts an array were the value at each index indicates how many sectors are in the partition of the returned Deadlines
-// Example:
-// gen := [miner.WPoStPeriodDeadlines]uint64{1, 42, 89, 0} returns a deadline with:
-// 1  sectors at deadlineIdx 0
-// 42 sectors at deadlineIdx 1
-// 89 sectors at deadlineIdx 2
-// 0  sectors at deadlineIdx 3-47
-func deadlineWithSectors(t *testing.T, gen [miner.WPoStPeriodDeadlines]uint64) *miner.Deadlines {
-	// ensure there are no duplicate sectors across partitions
-	var sectorIdx uint64
-	dls := miner.ConstructDeadlines()
-	for partition, numSectors := range gen {
-		var sectors []uint64
-		for i := uint64(0); i < numSectors; i++ {
-			sectors = append(sectors, sectorIdx)
-			sectorIdx++
-		}
-		require.NoError(t, dls.AddToDeadline(uint64(partition), sectors...))
-	}
-	return dls
-}
@@ -0,0 +1,25 @@
+package miner
+
+import (
+	xerrors "golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// Computes the sectors added, modified and removed between two versions of a miner's state, in sector number order.
+func DiffSectors(store adt.Store, before, after *State) ([]SectorOnChainInfoArrayChange, error) {
+	changes, err := DiffSectorOnChainInfoArray(store, before.Sectors, after.Sectors)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff sectors: %w", err)
+	}
+	return changes, nil
+}
+
+// Computes the pre-committed sectors added, modified and removed between two versions of a miner's state.
+func DiffPreCommits(store adt.Store, before, after *State) ([]SectorPreCommitOnChainInfoMapChange, error) {
+	changes, err := DiffSectorPreCommitOnChainInfoMap(store, before.PreCommittedSectors, after.PreCommittedSectors)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff pre-committed sectors: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,38 @@
+package miner
+
+import (
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+)
+
+// Emitted when a pre-committed sector is proven and activated.
+type SectorProven struct {
+	SectorNumber abi.SectorNumber
+	Expiration   abi.ChainEpoch
+}
+
+func (e *SectorProven) EventType() string { return "SectorProven" }
+
+// Emitted when sectors are detected faulty due to a missing PoSt.
+type FaultDetected struct {
+	Faults           *abi.BitField // Sectors newly detected as faulty.
+	FailedRecoveries *abi.BitField // Faulty sectors declared as recovering, but not proven.
+}
+
+func (e *FaultDetected) EventType() string { return "FaultDetected" }
+
+// Emitted when a Window PoSt is successfully disputed.
+type PoStDisputed struct {
+	Deadline  uint64        // The deadline at which the disputed PoSt was submitted.
+	PoStIndex uint64        // The index of the disputed PoSt among those submitted at the deadline.
+	Faults    *abi.BitField // Sectors proven by the PoSt, newly faulty as a result.
+}
+
+func (e *PoStDisputed) EventType() string { return "PoStDisputed" }
+
+// Emitted when pre-committed sectors are withdrawn, releasing their deals.
+type PreCommitsWithdrawn struct {
+	Sectors *abi.BitField // The withdrawn sectors.
+	DealIDs []abi.DealID  // Deals that were to be included in the withdrawn sectors.
+}
+
+func (e *PreCommitsWithdrawn) EventType() string { return "PreCommitsWithdrawn" }
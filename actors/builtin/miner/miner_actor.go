@@ -10,6 +10,7 @@ import (
 	cid "github.com/ipfs/go-cid"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	cbg "github.com/whyrusleeping/cbor-gen"
+	xerrors "golang.org/x/xerrors"
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
@@ -58,6 +59,18 @@ func (a Actor) Exports() []interface{} {
 		14:                        a.AddLockedFund,
 		15:                        a.ReportConsensusFault,
 		16:                        a.WithdrawBalance,
+		17:                        a.ProveCommitSectors,
+		18:                        a.PreCommitSectorBatch,
+		19:                        a.DisputeWindowedPoSt,
+		20:                        a.ChangeOwnerAddress,
+		21:                        a.ChangeControlAddresses,
+		22:                        a.ChangeMultiaddrs,
+		23:                        a.WithdrawPreCommits,
+		24:                        a.ExtendSectorExpirations,
+		25:                        a.GetSectorStatus,
+		26:                        a.GetDeadlineSummary,
+		27:                        a.MaskSectorNumbers,
+		28:                        a.GetInfo,
 	}
 }
 
@@ -73,6 +86,7 @@ type ConstructorParams = power.MinerConstructorParams
 
 func (a Actor) Constructor(rt Runtime, params *ConstructorParams) *adt.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.InitActorAddr)
+	checkPeerID(rt, params.PeerId)
 
 	owner := resolveOwnerAddress(rt, params.OwnerAddr)
 	worker := resolveWorkerAddress(rt, params.WorkerAddr)
@@ -87,13 +101,10 @@ func (a Actor) Constructor(rt Runtime, params *ConstructorParams) *adt.EmptyValu
 		rt.Abortf(exitcode.ErrIllegalState, "failed to construct initial state: %v", err)
 	}
 
-	emptyDeadlines := ConstructDeadlines()
-	emptyDeadlinesCid := rt.Store().Put(emptyDeadlines)
-
 	ppBoundary, err := assignProvingPeriodBoundary(rt.Message().Receiver(), rt.CurrEpoch(), rt.Syscalls().HashBlake2b)
 	builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to assign proving period boundary")
 
-	state := ConstructState(emptyArray, emptyMap, emptyDeadlinesCid, owner, worker, params.PeerId, params.SectorSize, ppBoundary)
+	state := ConstructState(emptyArray, emptyMap, owner, worker, params.PeerId, params.SectorSize, ppBoundary)
 	rt.State().Create(state)
 
 	// Register cron callback for epoch before the next proving period starts.
@@ -110,8 +121,9 @@ func (a Actor) Constructor(rt Runtime, params *ConstructorParams) *adt.EmptyValu
 /////////////
 
 type GetControlAddressesReturn struct {
-	Owner  addr.Address
-	Worker addr.Address
+	Owner        addr.Address
+	Worker       addr.Address
+	ControlAddrs []addr.Address
 }
 
 func (a Actor) ControlAddresses(rt Runtime, _ *adt.EmptyValue) *GetControlAddressesReturn {
@@ -119,9 +131,44 @@ func (a Actor) ControlAddresses(rt Runtime, _ *adt.EmptyValue) *GetControlAddres
 	var st State
 	rt.State().Readonly(&st)
 	return &GetControlAddressesReturn{
-		Owner:  st.Info.Owner,
-		Worker: st.Info.Worker,
+		Owner:        st.Info.Owner,
+		Worker:       st.Info.Worker,
+		ControlAddrs: st.Info.ControlAddresses,
+	}
+}
+
+// Returns the miner's info, including the peer ID and multiaddrs at which it may be dialed.
+func (a Actor) GetInfo(rt Runtime, _ *adt.EmptyValue) *MinerInfo {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.State().Readonly(&st)
+	return st.GetInfo()
+}
+
+type ChangeControlAddressesParams struct {
+	NewControlAddrs []addr.Address
+}
+
+// Replaces the miner's control addresses, which may submit Window PoSts and pre-commit and prove sectors on behalf of
+// the worker. An empty list removes all control addresses.
+func (a Actor) ChangeControlAddresses(rt Runtime, params *ChangeControlAddressesParams) *adt.EmptyValue {
+	if len(params.NewControlAddrs) > ControlAddressesMax {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many control addresses %d, max %d", len(params.NewControlAddrs), ControlAddressesMax)
 	}
+
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		rt.ValidateImmediateCallerIs(st.Info.Owner)
+		requireNotSlashed(rt, &st)
+
+		var controlAddrs []addr.Address
+		for _, raw := range params.NewControlAddrs {
+			controlAddrs = append(controlAddrs, resolveControlAddress(rt, raw))
+		}
+		st.Info.ControlAddresses = controlAddrs
+		return nil
+	})
+	return nil
 }
 
 type ChangeWorkerAddressParams struct {
@@ -133,6 +180,7 @@ func (a Actor) ChangeWorkerAddress(rt Runtime, params *ChangeWorkerAddressParams
 	var st State
 	rt.State().Transaction(&st, func() interface{} {
 		rt.ValidateImmediateCallerIs(st.Info.Owner)
+		requireNotSlashed(rt, &st)
 
 		worker := resolveWorkerAddress(rt, params.NewWorker)
 
@@ -153,20 +201,107 @@ func (a Actor) ChangeWorkerAddress(rt Runtime, params *ChangeWorkerAddressParams
 	return nil
 }
 
+type ChangeOwnerAddressParams struct {
+	NewOwner addr.Address
+}
+
+// Proposes or confirms a change of owner address.
+// If invoked by the current owner, proposes a new owner address, or revokes a proposal if the address is the current
+// owner's. The proposal is visible as the pending owner change.
+// If invoked by the proposed owner address, with that same address, confirms the change, and the proposed owner
+// becomes the owner.
+func (a Actor) ChangeOwnerAddress(rt Runtime, params *ChangeOwnerAddressParams) *adt.EmptyValue {
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		validCallers := []addr.Address{st.Info.Owner}
+		if st.Info.PendingOwnerChange != nil {
+			validCallers = append(validCallers, st.Info.PendingOwnerChange.NewOwner)
+		}
+		rt.ValidateImmediateCallerIs(validCallers...)
+		requireNotSlashed(rt, &st)
+
+		newOwner := resolveOwnerAddress(rt, params.NewOwner)
+		if rt.Message().Caller() == st.Info.Owner {
+			// This may replace another pending change.
+			if newOwner == st.Info.Owner {
+				st.Info.PendingOwnerChange = nil
+			} else {
+				st.Info.PendingOwnerChange = &OwnerChange{NewOwner: newOwner}
+			}
+		} else {
+			if newOwner != st.Info.PendingOwnerChange.NewOwner {
+				rt.Abortf(exitcode.ErrIllegalArgument, "new owner %v does not match proposed owner %v",
+					newOwner, st.Info.PendingOwnerChange.NewOwner)
+			}
+			st.Info.Owner = newOwner
+			st.Info.PendingOwnerChange = nil
+		}
+		return nil
+	})
+	return nil
+}
+
 type ChangePeerIDParams struct {
 	NewID peer.ID
 }
 
+// Replaces the miner's peer ID, which must be non-empty. May be invoked by the worker.
 func (a Actor) ChangePeerID(rt Runtime, params *ChangePeerIDParams) *adt.EmptyValue {
+	// A miner may be constructed without a peer ID, but may not change to an empty one.
+	if len(params.NewID) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid empty peer ID")
+	}
+	checkPeerID(rt, params.NewID)
+
 	var st State
 	rt.State().Transaction(&st, func() interface{} {
 		rt.ValidateImmediateCallerIs(st.Info.Worker)
+		requireNotSlashed(rt, &st)
 		st.Info.PeerId = params.NewID
 		return nil
 	})
 	return nil
 }
 
+type ChangeMultiaddrsParams struct {
+	NewMultiaddrs [][]byte
+}
+
+// Replaces the multiaddrs at which the miner may be dialed. May be invoked by the owner or the worker.
+func (a Actor) ChangeMultiaddrs(rt Runtime, params *ChangeMultiaddrsParams) *adt.EmptyValue {
+	checkMultiaddrs(rt, params.NewMultiaddrs)
+
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		rt.ValidateImmediateCallerIs(st.Info.Worker, st.Info.Owner)
+		requireNotSlashed(rt, &st)
+		st.Info.Multiaddrs = params.NewMultiaddrs
+		return nil
+	})
+	return nil
+}
+
+// Checks that a peer ID is within the maximum size.
+func checkPeerID(rt Runtime, peerID peer.ID) {
+	if len(peerID) > MaxPeerIDLength {
+		rt.Abortf(exitcode.ErrIllegalArgument, "peer ID size of %d exceeds maximum size of %d", len(peerID), MaxPeerIDLength)
+	}
+}
+
+// Checks that each multiaddr is non-empty, and their total size within the maximum.
+func checkMultiaddrs(rt Runtime, multiaddrs [][]byte) {
+	totalSize := 0
+	for _, ma := range multiaddrs {
+		if len(ma) == 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid empty multiaddr")
+		}
+		totalSize += len(ma)
+	}
+	if totalSize > MaxMultiaddrData {
+		rt.Abortf(exitcode.ErrIllegalArgument, "multiaddr size of %d exceeds maximum of %d", totalSize, MaxMultiaddrData)
+	}
+}
+
 //////////////////
 // WindowedPoSt //
 //////////////////
@@ -175,17 +310,21 @@ func (a Actor) ChangePeerID(rt Runtime, params *ChangePeerIDParams) *adt.EmptyVa
 type SubmitWindowedPoStParams struct {
 	// The deadline index which the submission targets.
 	Deadline uint64
-	// The partition indices being proven.
-	// Partitions are counted across all deadlines, such that all partition indices in the second deadline are greater
-	// than the partition numbers in the first deadlines.
+	// The indices of the partitions being proven, within the deadline.
 	Partitions []uint64
 	// Parallel array of proofs corresponding to the partitions.
 	Proofs []abi.PoStProof
-	// Sectors skipped while proving that weren't already declared faulty
+	// Sectors skipped while proving that weren't already declared faulty.
+	// These are recorded as faults and penalized as if declared, and excluded from the proof.
 	Skipped abi.BitField
+	// Whether to accept the proof optimistically, without verification, subject to dispute.
+	Optimistic bool
 }
 
-// Invoked by miner's worker address to submit their fallback post
+// Invoked by miner's worker address to submit their fallback post.
+// Sectors listed as skipped become faulty, and the proof is taken to cover the remaining sectors.
+// The proof is verified on submission, unless the submission opts in to optimistic acceptance. An optimistic proof
+// is not verified, but may be disputed with DisputeWindowedPoSt for WPoStDisputeWindow epochs.
 func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams) *adt.EmptyValue {
 	if uint64(len(params.Partitions)) > WPoStMessagePartitionsMax {
 		rt.Abortf(exitcode.ErrIllegalArgument, "too many partitions %d, max %d", len(params.Partitions), WPoStMessagePartitionsMax)
@@ -201,7 +340,8 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 	var penalty abi.TokenAmount
 	var recoveredSectors []*SectorOnChainInfo
 	rt.State().Transaction(&st, func() interface{} {
-		rt.ValidateImmediateCallerIs(st.Info.Worker)
+		rt.ValidateImmediateCallerIs(workerAndControlAddresses(&st.Info)...)
+		requireNotSlashed(rt, &st)
 
 		// Every epoch is during some deadline's challenge window.
 		// Rather than require it in the parameters, compute it from the current epoch.
@@ -223,33 +363,56 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 		// Vesting will be at most one proving period old if computed in the cron callback.
 		verifyPledgeMeetsInitialRequirements(rt, &st)
 
-		deadlines, err := st.LoadDeadlines(adt.AsStore(rt))
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
-
 		// Traverse earlier submissions and enact detected faults.
 		// This isn't strictly necessary, but keeps the power table up to date eagerly and can force payment
 		// of penalties if locked pledge drops too low.
-		detectedFaultSectors, penalty = checkMissingPoStFaults(rt, &st, store, deadlines, deadline.PeriodStart, deadline.Index, currEpoch)
-
-		// Work out which sectors are due in the declared partitions at this deadline.
-		partitionsSectors, err := ComputePartitionsSectors(deadlines, deadline.Index, params.Partitions)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compute partitions sectors at deadline %d, partitions %s",
-			deadline.Index, params.Partitions)
+		detectedFaultSectors, penalty = checkMissingPoStFaults(rt, &st, store, deadline.PeriodStart, deadline.Index, currEpoch)
 
-		provenSectors, err := abi.BitFieldUnion(partitionsSectors...)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union %d partitions of sectors", len(partitionsSectors))
+		dl, err := st.LoadDeadline(store, deadline.Index)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", deadline.Index)
 
-		// TODO WPOST (follow-up): process Skipped as faults
+		// Check the partitions are due at this deadline, and not yet proven in this period.
+		postedPartitions := bitfield.NewFromSet(params.Partitions)
+		postedCount, err := postedPartitions.Count()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count partitions")
+		contains, err := abi.BitFieldContainsAny(dl.PostSubmissions, postedPartitions)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect post partitions")
+		if contains || postedCount != uint64(len(params.Partitions)) {
+			rt.Abortf(exitcode.ErrIllegalArgument, "duplicate PoSt partition")
+		}
+		partitionCount, err := dl.PartitionCount(store)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count partitions at deadline %d", deadline.Index)
+
+		partitions := make([]*Partition, len(params.Partitions))
+		for i, partIdx := range params.Partitions {
+			if partIdx >= partitionCount {
+				rt.Abortf(exitcode.ErrIllegalArgument, "invalid partition %d at deadline %d with %d partitions",
+					partIdx, deadline.Index, partitionCount)
+			}
+			partitions[i], err = dl.LoadPartition(store, partIdx)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partition %d at deadline %d", partIdx, deadline.Index)
+		}
 
-		// Extract a fault set relevant to the sectors being submitted, for expansion into a map.
-		declaredFaults, err := bitfield.IntersectBitField(provenSectors, st.Faults)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect proof sectors with faults")
+		// Record skipped sectors as newly declared faults, to be masked from the proof along with earlier faults.
+		skippedFaultSectors, skippedPenalty := processSkippedFaults(rt, &st, store, currEpoch, deadline.PeriodStart, partitions, &params.Skipped)
+		detectedFaultSectors = append(detectedFaultSectors, skippedFaultSectors...)
+		penalty = big.Add(penalty, skippedPenalty)
+
+		// Work out which sectors are to be proven, and which are faulty and masked from the proof.
+		var partitionsSectors, partitionsFaults, partitionsRecoveries []*abi.BitField
+		for _, partition := range partitions {
+			_, expectedFaults, err := partition.ProvingSectors()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compute partition faults")
+			partitionsSectors = append(partitionsSectors, partition.Sectors)
+			partitionsFaults = append(partitionsFaults, expectedFaults)
+			partitionsRecoveries = append(partitionsRecoveries, partition.Recoveries)
+		}
 
-		declaredRecoveries, err := bitfield.IntersectBitField(declaredFaults, st.Recoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect recoveries with faults")
+		provenSectors, err := abi.BitFieldUnion(partitionsSectors...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union %d partitions of sectors", len(partitionsSectors))
 
-		expectedFaults, err := bitfield.SubtractBitField(declaredFaults, declaredRecoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract recoveries from faults")
+		expectedFaults, err := abi.BitFieldUnion(partitionsFaults...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union partition faults")
 
 		nonFaults, err := bitfield.SubtractBitField(provenSectors, expectedFaults)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to diff bitfields")
@@ -268,46 +431,54 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 		sectorInfos, err := st.LoadSectorInfosWithFaultMask(store, provenSectors, expectedFaults, abi.SectorNumber(goodSectorNo))
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector infos")
 
-		// Verify the proof.
-		// A failed verification doesn't immediately cause a penalty; the miner can try again.
-		verifyWindowedPost(rt, deadline.Challenge, sectorInfos, params.Proofs)
+		// Record the submission
+		err = dl.AddPoStSubmissions(postedPartitions)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record submissions for partitions %s", params.Partitions)
 
-		// Record the successful submission
-		postedPartitions := bitfield.NewFromSet(params.Partitions)
-		contains, err := abi.BitFieldContainsAny(st.PostSubmissions, postedPartitions)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect post partitions")
-		if contains {
-			rt.Abortf(exitcode.ErrIllegalArgument, "duplicate PoSt partition")
+		if params.Optimistic {
+			// The proof is accepted without verification.
+			// Instead it is retained so that anyone may dispute it with DisputeWindowedPoSt within the dispute window.
+			_, err = st.RecordPoStSnapshot(store, deadline, &WindowedPoStSnapshot{
+				SubmissionEpoch: currEpoch,
+				ChallengeEpoch:  deadline.Challenge,
+				Partitions:      params.Partitions,
+				Proofs:          params.Proofs,
+				Sectors:         asSectorInfos(sectorInfos),
+				Proven:          nonFaults,
+			})
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record PoSt snapshot")
+		} else {
+			err = verifyWindowedPost(rt, deadline.Challenge, asSectorInfos(sectorInfos), params.Proofs)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "window post failed")
 		}
-		err = st.AddPoStSubmissions(postedPartitions)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record submissions for partitions %s", params.Partitions)
 
 		// If the PoSt was successful, the declared recoveries should be restored
-		err = st.RemoveFaults(store, declaredRecoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove recoveries from faults")
-
-		err = st.RemoveRecoveries(declaredRecoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove recoveries")
-
-		// Load info for recovered sectors for recovery of power outside this state transaction.
-		empty, err = declaredRecoveries.IsEmpty()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check if bitfield was empty: %s")
-
-		if !empty {
-			sectorsByNumber := map[abi.SectorNumber]*SectorOnChainInfo{}
-			for _, s := range sectorInfos {
-				sectorsByNumber[s.Info.SectorNumber] = s
-			}
-
-			_ = declaredRecoveries.ForEach(func(i uint64) error {
-				recoveredSectors = append(recoveredSectors, sectorsByNumber[abi.SectorNumber(i)])
+		sectorsByNumber := map[abi.SectorNumber]*SectorOnChainInfo{}
+		for _, s := range sectorInfos {
+			sectorsByNumber[s.Info.SectorNumber] = s
+		}
+		for i, partition := range partitions {
+			var recovered []*SectorOnChainInfo
+			err = partitionsRecoveries[i].ForEach(func(sectorNo uint64) error {
+				recovered = append(recovered, sectorsByNumber[abi.SectorNumber(sectorNo)])
 				return nil
 			})
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to iterate recoveries")
+
+			err = partition.RecoverFaults(store, st.Info.SectorSize, recovered...)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove recoveries from faults")
+			recoveredSectors = append(recoveredSectors, recovered...)
+
+			err = dl.SavePartition(store, params.Partitions[i], partition)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store partition %d", params.Partitions[i])
 		}
+
+		err = st.SaveDeadline(store, deadline.Index, dl)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d", deadline.Index)
 		return nil
 	})
 
-	// Remove power for new faults, and burn penalties.
+	// Remove power for new faults (both detected and skipped), and burn penalties.
 	requestBeginFaults(rt, st.Info.SectorSize, detectedFaultSectors)
 	burnFundsAndNotifyPledgeChange(rt, penalty)
 
@@ -318,6 +489,149 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 	return nil
 }
 
+type DisputeWindowedPoStParams struct {
+	Deadline  uint64 // The deadline at which the disputed PoSt was submitted.
+	PoStIndex uint64 // The index of the disputed PoSt among those accepted optimistically at the deadline, in order of submission.
+}
+
+// Verifies a Window PoSt that was accepted optimistically, without verification. May be invoked by anyone within the
+// dispute window following the submission.
+// If the proof is invalid, the sectors it proved become faulty and the miner is penalized, with a share of the
+// penalty paid to the disputer. A dispute of a valid proof is rejected.
+func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams) *adt.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	disputer := rt.Message().Caller()
+	if params.Deadline >= WPoStPeriodDeadlines {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid deadline %d of %d", params.Deadline, WPoStPeriodDeadlines)
+	}
+
+	currEpoch := rt.CurrEpoch()
+	store := adt.AsStore(rt)
+	var st State
+	rt.State().Readonly(&st)
+	requireNotSlashed(rt, &st)
+
+	snapshot, found, err := st.GetPoStSnapshot(store, params.Deadline, params.PoStIndex)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load PoSt snapshot")
+	if !found || snapshot.Disputed {
+		rt.Abortf(exitcode.ErrNotFound, "no PoSt %d at deadline %d to dispute", params.PoStIndex, params.Deadline)
+	}
+	if currEpoch >= snapshot.SubmissionEpoch+WPoStDisputeWindow {
+		rt.Abortf(exitcode.ErrIllegalArgument, "dispute window for PoSt submitted at %d has ended", snapshot.SubmissionEpoch)
+	}
+	if err = verifyWindowedPost(rt, snapshot.ChallengeEpoch, snapshot.Sectors, snapshot.Proofs); err == nil {
+		rt.Abortf(exitcode.ErrIllegalArgument, "failed to dispute valid PoSt")
+	}
+
+	var faultSectors []*SectorOnChainInfo
+	var penalty abi.TokenAmount
+	rt.State().Transaction(&st, func() interface{} {
+		err := st.MarkPoStSnapshotDisputed(store, params.Deadline, params.PoStIndex)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to mark PoSt snapshot disputed")
+
+		deadline, _ := st.DeadlineInfo(currEpoch)
+		dl, err := st.LoadDeadline(store, params.Deadline)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", params.Deadline)
+
+		var faultSets []*abi.BitField
+		for _, partIdx := range snapshot.Partitions {
+			partition, err := dl.LoadPartition(store, partIdx)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partition %d", partIdx)
+
+			// Sectors that have since become faulty, or been terminated or expired, are not penalized again.
+			candidates, err := bitfield.IntersectBitField(snapshot.Proven, partition.Sectors)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect proven sectors with partition")
+			candidates, err = bitfield.SubtractBitField(candidates, partition.Faults)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract faults from proven sectors")
+
+			partitionFaults, err := st.LoadSectorInfos(store, candidates)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load proven sectors")
+			err = partition.AddFaults(store, st.Info.SectorSize, deadline.PeriodStart, partitionFaults...)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add faults")
+			err = dl.SavePartition(store, partIdx, partition)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store partition %d", partIdx)
+
+			faultSets = append(faultSets, candidates)
+			faultSectors = append(faultSectors, partitionFaults...)
+		}
+		err = st.SaveDeadline(store, params.Deadline, dl)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d", params.Deadline)
+
+		faultSet, err := abi.BitFieldUnion(faultSets...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union faults")
+
+		penalty, err = unlockPenalty(&st, store, currEpoch, PenaltyInvalidPoSt, faultSectors, pledgePenaltyForSectorUndeclaredFault)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge sector penalty")
+
+		rt.EmitEvent(&PoStDisputed{Deadline: params.Deadline, PoStIndex: params.PoStIndex, Faults: faultSet})
+		return nil
+	})
+
+	// Remove power for the new faults.
+	requestBeginFaults(rt, st.Info.SectorSize, faultSectors)
+
+	// Reward the disputer from the penalty, and burn the remainder.
+	reward := rewardForDisputedWindowedPoSt(penalty)
+	if reward.GreaterThan(big.Zero()) {
+		_, code := rt.Send(disputer, builtin.MethodSend, nil, reward)
+		builtin.RequireSuccess(rt, code, "failed to reward disputer")
+	}
+	burnFunds(rt, big.Sub(penalty, reward))
+	notifyPledgeChanged(rt, penalty.Neg())
+	return nil
+}
+
+// Records sectors skipped in a Window PoSt as faults, charging the declared fault penalty for them.
+// Skipped sectors must be among those of the partitions being proven. Skipped sectors that were already faulty
+// remain so, and any recovery declared for them is retracted.
+// Returns the sectors that are newly faulty, and the penalty unlocked for them.
+func processSkippedFaults(rt Runtime, st *State, store adt.Store, currEpoch, periodStart abi.ChainEpoch,
+	partitions []*Partition, skipped *abi.BitField) ([]*SectorOnChainInfo, abi.TokenAmount) {
+	empty, err := skipped.IsEmpty()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to check if skipped sectors is empty")
+	if empty {
+		return nil, big.Zero()
+	}
+
+	var partitionsSectors []*abi.BitField
+	for _, partition := range partitions {
+		partitionsSectors = append(partitionsSectors, partition.Sectors)
+	}
+	provenSectors, err := abi.BitFieldUnion(partitionsSectors...)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union partition sectors")
+	contains, err := abi.BitFieldContainsAll(provenSectors, skipped)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check skipped sectors")
+	if !contains {
+		rt.Abortf(exitcode.ErrIllegalArgument, "skipped sectors must be in the proven partitions")
+	}
+
+	var faultSectors []*SectorOnChainInfo
+	for _, partition := range partitions {
+		partitionSkipped, err := bitfield.IntersectBitField(skipped, partition.Sectors)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect skipped sectors with partition")
+
+		// Retract recoveries declared for skipped sectors.
+		err = partition.RemoveRecoveries(partitionSkipped)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove recoveries")
+
+		newFaults, err := bitfield.SubtractBitField(partitionSkipped, partition.Faults)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract existing faults from skipped sectors")
+		newFaultSectors, err := st.LoadSectorInfos(store, newFaults)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load skipped sectors")
+
+		err = partition.AddFaults(store, st.Info.SectorSize, periodStart, newFaultSectors...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add skipped faults")
+		faultSectors = append(faultSectors, newFaultSectors...)
+	}
+	if len(faultSectors) == 0 {
+		return nil, big.Zero()
+	}
+
+	penalty, err := unlockPenalty(st, store, currEpoch, PenaltyDeclaredFault, faultSectors, pledgePenaltyForSectorDeclaredFault)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge fault fee")
+	return faultSectors, penalty
+}
+
 ///////////////////////
 // Sector Commitment //
 ///////////////////////
@@ -325,35 +639,20 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 // Proposals must be posted on chain via sma.PublishStorageDeals before PreCommitSector.
 // Optimization: PreCommitSector could contain a list of deals that are not published yet.
 func (a Actor) PreCommitSector(rt Runtime, params *SectorPreCommitInfo) *adt.EmptyValue {
-	if params.Expiration <= rt.CurrEpoch() {
-		rt.Abortf(exitcode.ErrIllegalArgument, "sector expiration %v must be after now (%v)", params.Expiration, rt.CurrEpoch())
-	}
-
 	store := adt.AsStore(rt)
 	var st State
+	var debtRepaid abi.TokenAmount
 	newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
-		rt.ValidateImmediateCallerIs(st.Info.Worker)
-		if _, found, err := st.GetPrecommittedSector(store, params.SectorNumber); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to check precommit %v: %v", params.SectorNumber, err)
-		} else if found {
-			rt.Abortf(exitcode.ErrIllegalArgument, "sector %v already precommitted", params.SectorNumber)
-		}
-
-		if found, err := st.HasSectorNo(store, params.SectorNumber); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to check sector %v: %v", params.SectorNumber, err)
-		} else if found {
-			rt.Abortf(exitcode.ErrIllegalArgument, "sector %v already committed", params.SectorNumber)
-		}
-
-		// Check expiry is exactly *the epoch before* the start of a proving period.
-		expiryMod := (params.Expiration + 1) % WPoStProvingPeriod
-		if expiryMod != st.Info.ProvingPeriodBoundary {
-			rt.Abortf(exitcode.ErrIllegalArgument, "invalid expiration %d, must be on proving period boundary %d mod %d",
-				params.Expiration, st.Info.ProvingPeriodBoundary, WPoStProvingPeriod)
-		}
+		rt.ValidateImmediateCallerIs(workerAndControlAddresses(&st.Info)...)
+		requireNotSlashed(rt, &st)
+		validatePreCommit(rt, &st, store, params)
 
 		newlyVestedFund, err := st.UnlockVestedFunds(store, rt.CurrEpoch())
-		availableBalance := st.GetAvailableBalance(rt.CurrentBalance())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to vest new funds")
+		debtRepaid = st.RepayDebt(rt.CurrentBalance())
+		requireNoFeeDebt(rt, &st)
+
+		availableBalance := big.Sub(st.GetAvailableBalance(rt.CurrentBalance()), debtRepaid)
 		depositReq := precommitDeposit(st.GetSectorSize(), params.Expiration-rt.CurrEpoch())
 		if availableBalance.LessThan(depositReq) {
 			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for pre-commit deposit: %v", depositReq)
@@ -370,10 +669,13 @@ func (a Actor) PreCommitSector(rt Runtime, params *SectorPreCommitInfo) *adt.Emp
 		if err != nil {
 			rt.Abortf(exitcode.ErrIllegalState, "failed to write pre-committed sector %v: %v", params.SectorNumber, err)
 		}
+		err = st.AllocateSectorNumber(params.SectorNumber)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to allocate sector number %v", params.SectorNumber)
 
 		return newlyVestedFund
 	}).(abi.TokenAmount)
 
+	burnFunds(rt, debtRepaid)
 	notifyPledgeChanged(rt, newlyVestedAmount.Neg())
 
 	bf := abi.NewBitField()
@@ -396,6 +698,240 @@ func (a Actor) PreCommitSector(rt Runtime, params *SectorPreCommitInfo) *adt.Emp
 	return nil
 }
 
+type PreCommitSectorBatchParams struct {
+	Sectors []SectorPreCommitInfo
+}
+
+// Pre-commits a batch of sectors, as for PreCommitSector.
+// The deals of all the sectors are checked together with the storage market actor, the sum of the sectors'
+// deposits is locked at once, and a single cron event is enrolled to check the expiry of all the sectors.
+// Aborts if any sector is invalid.
+func (a Actor) PreCommitSectorBatch(rt Runtime, params *PreCommitSectorBatchParams) *adt.EmptyValue {
+	if len(params.Sectors) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors to pre-commit")
+	}
+
+	store := adt.AsStore(rt)
+	var st State
+	sectorNos := abi.NewBitField()
+	var sectorDeals []market.SectorDeals
+	maxSealDuration := abi.ChainEpoch(0)
+	var debtRepaid abi.TokenAmount
+	newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
+		rt.ValidateImmediateCallerIs(workerAndControlAddresses(&st.Info)...)
+		requireNotSlashed(rt, &st)
+
+		newlyVestedFund, err := st.UnlockVestedFunds(store, rt.CurrEpoch())
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to vest new funds: %s", err)
+		}
+		debtRepaid = st.RepayDebt(rt.CurrentBalance())
+		requireNoFeeDebt(rt, &st)
+
+		totalDepositReq := big.Zero()
+		for i := range params.Sectors {
+			precommit := &params.Sectors[i]
+			// A sector number repeated within the batch is rejected here as already pre-committed,
+			// since each earlier sector is written before the next is validated.
+			validatePreCommit(rt, &st, store, precommit)
+			sectorNos.Set(uint64(precommit.SectorNumber))
+
+			if len(precommit.DealIDs) > 0 {
+				sectorDeals = append(sectorDeals, market.SectorDeals{DealIDs: precommit.DealIDs, SectorExpiry: precommit.Expiration})
+			}
+			if msd := MaxSealDuration[precommit.RegisteredProof]; msd > maxSealDuration {
+				maxSealDuration = msd
+			}
+
+			depositReq := precommitDeposit(st.GetSectorSize(), precommit.Expiration-rt.CurrEpoch())
+			totalDepositReq = big.Add(totalDepositReq, depositReq)
+			err = st.PutPrecommittedSector(store, &SectorPreCommitOnChainInfo{
+				Info:             *precommit,
+				PreCommitDeposit: depositReq,
+				PreCommitEpoch:   rt.CurrEpoch(),
+			})
+			if err != nil {
+				rt.Abortf(exitcode.ErrIllegalState, "failed to write pre-committed sector %v: %v", precommit.SectorNumber, err)
+			}
+			err = st.AllocateSectorNumber(precommit.SectorNumber)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to allocate sector number %v", precommit.SectorNumber)
+		}
+
+		availableBalance := big.Sub(st.GetAvailableBalance(rt.CurrentBalance()), debtRepaid)
+		if availableBalance.LessThan(totalDepositReq) {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for pre-commit deposit: %v", totalDepositReq)
+		}
+		st.AddPreCommitDeposit(totalDepositReq)
+		st.AssertBalanceInvariants(rt.CurrentBalance())
+
+		return newlyVestedFund
+	}).(abi.TokenAmount)
+
+	// Check that the deals of all sectors could be activated when proven.
+	if len(sectorDeals) > 0 {
+		_, code := rt.Send(
+			builtin.StorageMarketActorAddr,
+			builtin.MethodsMarket.VerifyDealsOnSectorPreCommit,
+			&market.VerifyDealsOnSectorPreCommitParams{Sectors: sectorDeals},
+			abi.NewTokenAmount(0),
+		)
+		builtin.RequireSuccess(rt, code, "failed to verify deals")
+	}
+
+	burnFunds(rt, debtRepaid)
+	notifyPledgeChanged(rt, newlyVestedAmount.Neg())
+
+	// Request a single deferred Cron check for PreCommit expiry of all the sectors, after the longest of their
+	// seal durations.
+	cronPayload := CronEventPayload{
+		EventType: CronEventPreCommitExpiry,
+		Sectors:   sectorNos,
+	}
+	enrollCronEvent(rt, rt.CurrEpoch()+maxSealDuration+1, &cronPayload)
+	return nil
+}
+
+type WithdrawPreCommitsParams struct {
+	Sectors *abi.BitField
+}
+
+// Withdraws pre-committed sectors that have not yet been proven, such as those for which sealing failed.
+// A part of each sector's pre-commit deposit is refunded, shrinking the later the withdrawal within the sector's
+// maximum seal duration, and the remainder is burnt.
+// Nothing is sent to the market actor for the deals the sectors were to include, which are listed only in the
+// PreCommitsWithdrawn event. They remain unactivated and may be included in other sectors, or else are timed out by
+// the market actor once their start epoch has passed.
+// Aborts if no sectors are listed, or if any sector is not pre-committed or its maximum seal duration has passed.
+func (a Actor) WithdrawPreCommits(rt Runtime, params *WithdrawPreCommitsParams) *adt.EmptyValue {
+	empty, err := params.Sectors.IsEmpty()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to check if sectors to withdraw is empty")
+	if empty {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors to withdraw")
+	}
+
+	store := adt.AsStore(rt)
+	currEpoch := rt.CurrEpoch()
+	var st State
+	var dealIDs []abi.DealID
+	depositToBurn := big.Zero()
+	rt.State().Transaction(&st, func() interface{} {
+		rt.ValidateImmediateCallerIs(workerAndControlAddresses(&st.Info)...)
+		requireNotSlashed(rt, &st)
+
+		totalDeposit := big.Zero()
+		err := params.Sectors.ForEach(func(i uint64) error {
+			sectorNo := abi.SectorNumber(i)
+			precommit, found, err := st.GetPrecommittedSector(store, sectorNo)
+			if err != nil {
+				return fmt.Errorf("failed to load pre-committed sector %v: %w", sectorNo, err)
+			}
+			if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no pre-committed sector %v", sectorNo)
+			}
+
+			msd, ok := MaxSealDuration[precommit.Info.RegisteredProof]
+			if !ok {
+				rt.Abortf(exitcode.ErrIllegalState, "no max seal duration for proof type: %d", precommit.Info.RegisteredProof)
+			}
+			elapsed := currEpoch - precommit.PreCommitEpoch
+			if elapsed > msd {
+				rt.Abortf(exitcode.ErrIllegalArgument, "pre-commit of sector %v expired at %v", sectorNo, precommit.PreCommitEpoch+msd)
+			}
+
+			refund := precommitDepositRefund(precommit.PreCommitDeposit, elapsed, msd)
+			totalDeposit = big.Add(totalDeposit, precommit.PreCommitDeposit)
+			depositToBurn = big.Add(depositToBurn, big.Sub(precommit.PreCommitDeposit, refund))
+			dealIDs = append(dealIDs, precommit.Info.DealIDs...)
+			return st.DeletePrecommittedSector(store, sectorNo)
+		})
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to withdraw pre-commits")
+
+		err = st.RecordPenalty(store, currEpoch, PenaltyPreCommitWithdrawn, params.Sectors, depositToBurn)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record withdrawn deposit penalty")
+
+		// The refunded part of the deposits becomes available balance.
+		st.AddPreCommitDeposit(totalDeposit.Neg())
+		return nil
+	})
+
+	// This deposit was locked separately to pledge collateral so there's no pledge change here.
+	burnFunds(rt, depositToBurn)
+	rt.EmitEvent(&PreCommitsWithdrawn{Sectors: params.Sectors, DealIDs: dealIDs})
+	return nil
+}
+
+type MaskSectorNumbersParams struct {
+	Sectors *abi.BitField
+}
+
+// Marks sector numbers as allocated, so that they may not be pre-committed.
+// This allows a miner to reserve the numbers already used by another sealing pipeline, when migrating from it.
+// Numbers that are already allocated are ignored. Only the owner may mask sector numbers.
+func (a Actor) MaskSectorNumbers(rt Runtime, params *MaskSectorNumbersParams) *adt.EmptyValue {
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		rt.ValidateImmediateCallerIs(st.Info.Owner)
+		requireNotSlashed(rt, &st)
+		err := st.MaskSectorNumbers(params.Sectors)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to mask sector numbers")
+		return nil
+	})
+	return nil
+}
+
+// Checks that a sector may be pre-committed: that it has not already been pre-committed or committed, has a known
+// proof type, expires on a proving period boundary in the future, and that any sector it is to replace may be replaced.
+func validatePreCommit(rt Runtime, st *State, store adt.Store, params *SectorPreCommitInfo) {
+	if params.Expiration <= rt.CurrEpoch() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "sector expiration %v must be after now (%v)", params.Expiration, rt.CurrEpoch())
+	}
+
+	if _, ok := MaxSealDuration[params.RegisteredProof]; !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no max seal duration set for proof type: %d", params.RegisteredProof)
+	}
+
+	if _, found, err := st.GetPrecommittedSector(store, params.SectorNumber); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to check precommit %v: %v", params.SectorNumber, err)
+	} else if found {
+		rt.Abortf(exitcode.ErrIllegalArgument, "sector %v already precommitted", params.SectorNumber)
+	}
+
+	if found, err := st.HasSectorNo(store, params.SectorNumber); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to check sector %v: %v", params.SectorNumber, err)
+	} else if found {
+		rt.Abortf(exitcode.ErrIllegalArgument, "sector %v already committed", params.SectorNumber)
+	}
+
+	// Sector numbers are never re-used, even once a sector has expired, been terminated or its pre-commit withdrawn.
+	if allocated, err := st.AllocatedSectors.IsSet(uint64(params.SectorNumber)); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to check allocated sector number %v: %v", params.SectorNumber, err)
+	} else if allocated {
+		rt.Abortf(exitcode.ErrIllegalArgument, "sector number %v already allocated", params.SectorNumber)
+	}
+
+	// Check expiry is exactly *the epoch before* the start of a proving period.
+	expiryMod := (params.Expiration + 1) % WPoStProvingPeriod
+	if expiryMod != st.Info.ProvingPeriodBoundary {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid expiration %d, must be on proving period boundary %d mod %d",
+			params.Expiration, st.Info.ProvingPeriodBoundary, WPoStProvingPeriod)
+	}
+
+	if params.ReplaceCapacity {
+		if len(params.DealIDs) == 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "cannot replace sector %v without committing deals", params.ReplaceSector)
+		}
+		replaced, found, err := st.GetSector(store, params.ReplaceSector)
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to load sector %v: %v", params.ReplaceSector, err)
+		} else if !found {
+			rt.Abortf(exitcode.ErrNotFound, "no such sector %v to replace", params.ReplaceSector)
+		}
+		if err = checkReplaceable(rt, st, store, replaced, params); err != nil {
+			rt.Abortf(exitcode.ErrIllegalArgument, "cannot replace sector: %v", err)
+		}
+	}
+}
+
 type ProveCommitSectorParams struct {
 	SectorNumber abi.SectorNumber
 	Proof        []byte
@@ -407,6 +943,7 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 	store := adt.AsStore(rt)
 	var st State
 	rt.State().Readonly(&st)
+	requireNotSlashed(rt, &st)
 
 	sectorNo := params.SectorNumber
 	precommit, found, err := st.GetPrecommittedSector(store, sectorNo)
@@ -453,8 +990,8 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 	AssertNoError(ret.Into(&dealWeights))
 
 	// Request power for activated sector.
-	// Return initial pledge requirement.
-	var initialPledge abi.TokenAmount
+	// Return initial pledge requirement and projected reward.
+	var pledge power.SectorPledge
 	ret, code = rt.Send(
 		builtin.StoragePowerActorAddr,
 		builtin.MethodsPower.OnSectorProveCommit,
@@ -469,9 +1006,11 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 		big.Zero(),
 	)
 	builtin.RequireSuccess(rt, code, "failed to notify power actor")
-	AssertNoError(ret.Into(&initialPledge))
+	AssertNoError(ret.Into(&pledge))
+	initialPledge := pledge.InitialPledge
 
 	// Add sector and pledge lock-up to miner state
+	var pledgeToLock abi.TokenAmount
 	newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
 		newlyVestedFund, err := st.UnlockVestedFunds(store, rt.CurrEpoch())
 		if err != nil {
@@ -484,46 +1023,233 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 		// Verify locked funds are are at least the sum of sector initial pledges.
 		verifyPledgeMeetsInitialRequirements(rt, &st)
 
+		// A replacement takes over the pledge of the sector it replaces, so need only lock up any shortfall.
+		sectorPledge := initialPledge
+		pledgeToLock = initialPledge
+		if precommit.Info.ReplaceCapacity {
+			sectorPledge, pledgeToLock = replaceCapacitySector(rt, &st, store, &precommit.Info, initialPledge)
+		}
+
 		// Lock up initial pledge for new sector.
 		availableBalance := st.GetAvailableBalance(rt.CurrentBalance())
-		if availableBalance.LessThan(initialPledge) {
-			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for initial pledge requirement %s, available: %s", initialPledge, availableBalance)
+		if availableBalance.LessThan(pledgeToLock) {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for initial pledge requirement %s, available: %s", pledgeToLock, availableBalance)
 		}
-		if err = st.AddLockedFunds(store, rt.CurrEpoch(), initialPledge, &PledgeVestingSpec); err != nil {
+		if err = st.AddLockedFunds(store, rt.CurrEpoch(), pledgeToLock, &PledgeVestingSpec); err != nil {
 			rt.Abortf(exitcode.ErrIllegalState, "failed to add pledge: %v", err)
 		}
 		st.AssertBalanceInvariants(rt.CurrentBalance())
 
-		newSectorInfo := &SectorOnChainInfo{
-			Info:               precommit.Info,
-			ActivationEpoch:    rt.CurrEpoch(),
-			DealWeight:         dealWeights.DealWeight,
-			VerifiedDealWeight: dealWeights.VerifiedDealWeight,
+		activateSector(rt, &st, store, precommit, dealWeights.DealWeight, dealWeights.VerifiedDealWeight, sectorPledge, pledge.ExpectedEpochReward)
+		return newlyVestedFund
+	}).(abi.TokenAmount)
+
+	notifyPledgeChanged(rt, big.Sub(pledgeToLock, newlyVestedAmount))
+
+	rt.EmitEvent(&SectorProven{SectorNumber: sectorNo, Expiration: precommit.Info.Expiration})
+	return nil
+}
+
+type ProveCommitSectorsParams struct {
+	Sectors []ProveCommitSectorParams
+}
+
+// Proves a batch of pre-committed sectors, verifying their seal proofs together.
+// Each sector succeeds or fails on its own: a sector that is not pre-committed (or appears twice), is outside
+// its proving window, has an invalid proof, or has deals that cannot be activated is skipped and remains pre-committed.
+// Aborts if no sector in the batch can be proven, or if the available balance can't cover the initial pledge
+// of those that can.
+// Returns the numbers of the sectors proven.
+func (a Actor) ProveCommitSectors(rt Runtime, params *ProveCommitSectorsParams) *abi.BitField {
+	rt.ValidateImmediateCallerAcceptAny()
+	if len(params.Sectors) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors to prove")
+	}
+
+	store := adt.AsStore(rt)
+	var st State
+	rt.State().Readonly(&st)
+	requireNotSlashed(rt, &st)
+
+	// Find the sectors that may be proven now.
+	var candidates []*SectorPreCommitOnChainInfo
+	var onChainInfos []*abi.OnChainSealVerifyInfo
+	var cdcInputs []market.ComputeDataCommitmentParams
+	seen := make(map[abi.SectorNumber]bool, len(params.Sectors))
+	for _, sector := range params.Sectors {
+		if seen[sector.SectorNumber] {
+			continue
 		}
+		seen[sector.SectorNumber] = true
 
-		if err = st.PutSector(store, newSectorInfo); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to prove commit: %v", err)
+		precommit, found, err := st.GetPrecommittedSector(store, sector.SectorNumber)
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to get precommitted sector %v: %v", sector.SectorNumber, err)
+		} else if !found {
+			continue
 		}
 
-		if err = st.DeletePrecommittedSector(store, sectorNo); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to delete precommit for sector %v: %v", sectorNo, err)
+		msd, ok := MaxSealDuration[precommit.Info.RegisteredProof]
+		if !ok {
+			rt.Abortf(exitcode.ErrIllegalState, "no max seal duration for proof type: %d", precommit.Info.RegisteredProof)
+		}
+		if rt.CurrEpoch() > precommit.PreCommitEpoch+msd {
+			continue
 		}
 
-		if err = st.AddSectorExpirations(store, precommit.Info.Expiration, uint64(sectorNo)); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to add new sector %v expiration: %v", sectorNo, err)
+		onChainInfo := &abi.OnChainSealVerifyInfo{
+			SealedCID:        precommit.Info.SealedCID,
+			InteractiveEpoch: precommit.PreCommitEpoch + PreCommitChallengeDelay,
+			SealRandEpoch:    precommit.Info.SealRandEpoch,
+			Proof:            sector.Proof,
+			DealIDs:          precommit.Info.DealIDs,
+			SectorNumber:     precommit.Info.SectorNumber,
+			RegisteredProof:  precommit.Info.RegisteredProof,
+		}
+		if _, err = checkSealEpochs(rt, onChainInfo); err != nil {
+			continue
 		}
+		candidates = append(candidates, precommit)
+		onChainInfos = append(onChainInfos, onChainInfo)
+		cdcInputs = append(cdcInputs, market.ComputeDataCommitmentParams{
+			DealIDs:    precommit.Info.DealIDs,
+			SectorType: precommit.Info.RegisteredProof,
+		})
+	}
 
-		// Add to new sectors, a staging ground before scheduling to a deadline at end of proving period.
-		if err = st.AddNewSectors(sectorNo); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to add new sector number %v: %v", sectorNo, err)
+	// Assemble seal verification info for each sector whose unsealed CID can be computed.
+	var precommits []*SectorPreCommitOnChainInfo
+	var svInfos []abi.SealVerifyInfo
+	if len(candidates) > 0 {
+		commDs := requestUnsealedSectorCIDs(rt, cdcInputs)
+		for i, precommit := range candidates {
+			if commDs[i].CommD == nil {
+				continue
+			}
+			precommits = append(precommits, precommit)
+			svInfos = append(svInfos, *makeSealVerifyInfo(rt, onChainInfos[i], *commDs[i].CommD))
 		}
+	}
 
+	valid, err := rt.Syscalls().BatchVerifySeals(svInfos)
+	if err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to verify seals: %s", err)
+	}
+	AssertMsg(len(valid) == len(svInfos), "expected %d seal verification results, got %d", len(svInfos), len(valid))
+
+	var sealed []*SectorPreCommitOnChainInfo
+	var sectorDeals []market.SectorDeals
+	for i, precommit := range precommits {
+		if valid[i] {
+			sealed = append(sealed, precommit)
+			sectorDeals = append(sectorDeals, market.SectorDeals{
+				DealIDs:      precommit.Info.DealIDs,
+				SectorExpiry: precommit.Info.Expiration,
+			})
+		}
+	}
+	if len(sealed) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no valid proofs in batch of %d sectors", len(params.Sectors))
+	}
+
+	// Check (and activate) storage deals associated to the sectors, and get the deal weight of each.
+	var dealWeights market.BatchVerifyDealsOnSectorProveCommitReturn
+	ret, code := rt.Send(
+		builtin.StorageMarketActorAddr,
+		builtin.MethodsMarket.BatchVerifyDealsOnSectorProveCommit,
+		&market.BatchVerifyDealsOnSectorProveCommitParams{Sectors: sectorDeals},
+		abi.NewTokenAmount(0),
+	)
+	builtin.RequireSuccess(rt, code, "failed to verify deals and get deal weights")
+	AssertNoError(ret.Into(&dealWeights))
+	AssertMsg(len(dealWeights.Sectors) == len(sealed), "expected %d deal weights, got %d", len(sealed), len(dealWeights.Sectors))
+
+	var proven []*SectorPreCommitOnChainInfo
+	var provenWeights []market.SectorDealWeights
+	var powerWeights []power.SectorStorageWeightDesc
+	for i, precommit := range sealed {
+		weights := dealWeights.Sectors[i]
+		if !weights.Valid {
+			continue
+		}
+		proven = append(proven, precommit)
+		provenWeights = append(provenWeights, weights)
+		powerWeights = append(powerWeights, power.SectorStorageWeightDesc{
+			SectorSize:         st.Info.SectorSize,
+			DealWeight:         weights.DealWeight,
+			VerifiedDealWeight: weights.VerifiedDealWeight,
+			Duration:           precommit.Info.Expiration - rt.CurrEpoch(),
+		})
+	}
+	if len(proven) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors with valid deals in batch of %d sectors", len(params.Sectors))
+	}
+
+	// Request power for the activated sectors.
+	// Return initial pledge requirements.
+	var pledges power.BatchOnSectorProveCommitReturn
+	ret, code = rt.Send(
+		builtin.StoragePowerActorAddr,
+		builtin.MethodsPower.BatchOnSectorProveCommit,
+		&power.BatchOnSectorProveCommitParams{Weights: powerWeights},
+		big.Zero(),
+	)
+	builtin.RequireSuccess(rt, code, "failed to notify power actor")
+	AssertNoError(ret.Into(&pledges))
+	AssertMsg(len(pledges.Sectors) == len(proven), "expected %d initial pledges, got %d", len(proven), len(pledges.Sectors))
+
+	// Add sectors and pledge lock-up to miner state
+	totalPledge := big.Zero()
+	newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
+		newlyVestedFund, err := st.UnlockVestedFunds(store, rt.CurrEpoch())
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to vest new funds: %s", err)
+		}
+
+		// Unlock deposits for successful proofs, make them available for lock-up as initial pledge.
+		for _, precommit := range proven {
+			st.AddPreCommitDeposit(precommit.PreCommitDeposit.Neg())
+		}
+
+		// Verify locked funds are are at least the sum of sector initial pledges.
+		verifyPledgeMeetsInitialRequirements(rt, &st)
+
+		// Replacements take over the pledge of the sectors they replace, so need only lock up any shortfall.
+		sectorPledges := make([]abi.TokenAmount, len(proven))
+		for i, precommit := range proven {
+			pledgeToLock := pledges.Sectors[i].InitialPledge
+			sectorPledges[i] = pledgeToLock
+			if precommit.Info.ReplaceCapacity {
+				sectorPledges[i], pledgeToLock = replaceCapacitySector(rt, &st, store, &precommit.Info, pledgeToLock)
+			}
+			totalPledge = big.Add(totalPledge, pledgeToLock)
+		}
+
+		// Lock up initial pledge for new sectors.
+		availableBalance := st.GetAvailableBalance(rt.CurrentBalance())
+		if availableBalance.LessThan(totalPledge) {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for initial pledge requirement %s, available: %s", totalPledge, availableBalance)
+		}
+		if err = st.AddLockedFunds(store, rt.CurrEpoch(), totalPledge, &PledgeVestingSpec); err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to add pledge: %v", err)
+		}
+		st.AssertBalanceInvariants(rt.CurrentBalance())
+
+		for i, precommit := range proven {
+			activateSector(rt, &st, store, precommit, provenWeights[i].DealWeight, provenWeights[i].VerifiedDealWeight, sectorPledges[i],
+				pledges.Sectors[i].ExpectedEpochReward)
+		}
 		return newlyVestedFund
 	}).(abi.TokenAmount)
 
-	notifyPledgeChanged(rt, big.Sub(initialPledge, newlyVestedAmount))
+	notifyPledgeChanged(rt, big.Sub(totalPledge, newlyVestedAmount))
 
-	return nil
+	provenNos := abi.NewBitField()
+	for _, precommit := range proven {
+		provenNos.Set(uint64(precommit.Info.SectorNumber))
+		rt.EmitEvent(&SectorProven{SectorNumber: precommit.Info.SectorNumber, Expiration: precommit.Info.Expiration})
+	}
+	return provenNos
 }
 
 type CheckSectorProvenParams struct {
@@ -546,6 +1272,47 @@ func (a Actor) CheckSectorProven(rt Runtime, params *CheckSectorProvenParams) *a
 	return nil
 }
 
+type GetSectorStatusParams struct {
+	SectorNumber abi.SectorNumber
+}
+
+// Returns the status of a pre-committed or proven sector, and the deadline and partition at which it is due.
+func (a Actor) GetSectorStatus(rt Runtime, params *GetSectorStatusParams) *SectorStatus {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.State().Readonly(&st)
+
+	status, found, err := st.GetSectorStatus(adt.AsStore(rt), params.SectorNumber)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector %v status", params.SectorNumber)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no such sector %v", params.SectorNumber)
+	}
+	return status
+}
+
+type GetDeadlineSummaryReturn struct {
+	Deadline   DeadlineInfo
+	Partitions []PartitionSummary // The partitions due at the current deadline.
+}
+
+// Returns the current deadline, and a summary of each partition due at it.
+func (a Actor) GetDeadlineSummary(rt Runtime, _ *adt.EmptyValue) *GetDeadlineSummaryReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.State().Readonly(&st)
+	store := adt.AsStore(rt)
+
+	deadline, _ := st.DeadlineInfo(rt.CurrEpoch())
+	dl, err := st.LoadDeadline(store, deadline.Index)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", deadline.Index)
+	partitions, err := dl.PartitionSummaries(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to summarize partitions at deadline %d", deadline.Index)
+	return &GetDeadlineSummaryReturn{
+		Deadline:   *deadline,
+		Partitions: partitions,
+	}
+}
+
 /////////////////////////
 // Sector Modification //
 /////////////////////////
@@ -555,66 +1322,166 @@ type ExtendSectorExpirationParams struct {
 	NewExpiration abi.ChainEpoch
 }
 
+// Extends the expiration of a single sector. See ExtendSectorExpirations.
 func (a Actor) ExtendSectorExpiration(rt Runtime, params *ExtendSectorExpirationParams) *adt.EmptyValue {
+	sectorNos := abi.NewBitField()
+	sectorNos.Set(uint64(params.SectorNumber))
+	extendSectorExpirations(rt, []ExpirationExtension{{Sectors: sectorNos, NewExpiration: params.NewExpiration}})
+	return nil
+}
+
+type ExtendSectorExpirationsParams struct {
+	Extensions []ExpirationExtension
+}
+
+type ExpirationExtension struct {
+	Sectors       *abi.BitField
+	NewExpiration abi.ChainEpoch
+}
+
+// Extends the expiration of many sectors, grouped by their new expiration epoch.
+// Each new expiration must be on the proving period boundary, and no earlier than the sector's current expiration.
+// Faulty sectors cannot be extended.
+// The power and initial pledge of all the sectors are updated with a single call to the power actor, and any
+// increase in initial pledge is locked from the miner's available balance.
+func (a Actor) ExtendSectorExpirations(rt Runtime, params *ExtendSectorExpirationsParams) *adt.EmptyValue {
+	extendSectorExpirations(rt, params.Extensions)
+	return nil
+}
+
+func extendSectorExpirations(rt Runtime, extensions []ExpirationExtension) {
 	var st State
 	rt.State().Readonly(&st)
 	rt.ValidateImmediateCallerIs(st.Info.Worker)
+	requireNotSlashed(rt, &st)
+	if len(extensions) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors to extend")
+	}
 
 	store := adt.AsStore(rt)
-	sectorNo := params.SectorNumber
-	sector, found, err := st.GetSector(store, sectorNo)
-	if err != nil {
-		rt.Abortf(exitcode.ErrIllegalState, "failed to load sector %v: %v", sectorNo, err)
-	} else if !found {
-		rt.Abortf(exitcode.ErrNotFound, "no such sector %v", sectorNo)
+	var prevSectors, newSectors []*SectorOnChainInfo
+	seen := make(map[uint64]bool)
+	for _, ext := range extensions {
+		if ext.NewExpiration <= rt.CurrEpoch() {
+			rt.Abortf(exitcode.ErrIllegalArgument, "sector expiration %v must be after now (%v)", ext.NewExpiration, rt.CurrEpoch())
+		}
+		if (ext.NewExpiration+1)%WPoStProvingPeriod != st.Info.ProvingPeriodBoundary {
+			rt.Abortf(exitcode.ErrIllegalArgument, "invalid expiration %d, must be on proving period boundary %d mod %d",
+				ext.NewExpiration, st.Info.ProvingPeriodBoundary, WPoStProvingPeriod)
+		}
+
+		err := ext.Sectors.ForEach(func(sectorNo uint64) error {
+			if seen[sectorNo] {
+				rt.Abortf(exitcode.ErrIllegalArgument, "sector %v extended more than once", sectorNo)
+			}
+			seen[sectorNo] = true
+
+			sector, found, err := st.GetSector(store, abi.SectorNumber(sectorNo))
+			if err != nil {
+				return err
+			} else if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no such sector %v", sectorNo)
+			}
+			if ext.NewExpiration < sector.Info.Expiration {
+				rt.Abortf(exitcode.ErrIllegalArgument, "cannot reduce sector %v expiration %v to %v", sectorNo,
+					sector.Info.Expiration, ext.NewExpiration)
+			}
+
+			updated := *sector
+			updated.Info.Expiration = ext.NewExpiration
+			prevSectors = append(prevSectors, sector)
+			newSectors = append(newSectors, &updated)
+			return nil
+		})
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors to extend")
+	}
+	if len(newSectors) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no sectors to extend")
 	}
 
-	oldExpiration := sector.Info.Expiration
-	storageWeightDescPrev := AsStorageWeightDesc(st.Info.SectorSize, sector)
-	extensionLength := params.NewExpiration - oldExpiration
-	if extensionLength < 0 {
-		rt.Abortf(exitcode.ErrIllegalArgument, "cannot reduce sector expiration")
+	faulty, err := st.FindFaultySectors(store, prevSectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to find faulty sectors")
+	if empty, err := faulty.IsEmpty(); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to check faulty sectors: %v", err)
+	} else if !empty {
+		rt.Abortf(exitcode.ErrIllegalArgument, "cannot extend faulty sectors %v", faulty)
 	}
 
-	storageWeightDescNew := *storageWeightDescPrev
-	storageWeightDescNew.Duration = storageWeightDescPrev.Duration + extensionLength
+	modifications := make([]power.OnSectorModifyWeightDescParams, len(newSectors))
+	for i, updated := range newSectors {
+		modifications[i] = power.OnSectorModifyWeightDescParams{
+			PrevWeight: *AsStorageWeightDesc(st.Info.SectorSize, prevSectors[i]),
+			NewWeight:  *AsStorageWeightDesc(st.Info.SectorSize, updated),
+		}
+	}
 
-	_, code := rt.Send(
+	// Replace the power of the sectors, and get the initial pledge requirement at their new durations.
+	var pledges power.BatchOnSectorModifyWeightDescReturn
+	ret, code := rt.Send(
 		builtin.StoragePowerActorAddr,
-		builtin.MethodsPower.OnSectorModifyWeightDesc,
-		&power.OnSectorModifyWeightDescParams{
-			PrevWeight: *storageWeightDescPrev,
-			NewWeight:  storageWeightDescNew,
-		},
-		abi.NewTokenAmount(0),
+		builtin.MethodsPower.BatchOnSectorModifyWeightDesc,
+		&power.BatchOnSectorModifyWeightDescParams{Modifications: modifications},
+		big.Zero(),
 	)
-	builtin.RequireSuccess(rt, code, "failed to modify sector weight")
+	builtin.RequireSuccess(rt, code, "failed to modify sector weights")
+	AssertNoError(ret.Into(&pledges))
+	AssertMsg(len(pledges.Sectors) == len(newSectors), "expected %d initial pledges, got %d", len(newSectors), len(pledges.Sectors))
+
+	// Store the new expirations, and lock up any additional pledge they require.
+	totalPledge := big.Zero()
+	newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
+		// No sector can have become faulty since checked above, so all are rescheduled.
+		_, err := st.RescheduleSectorExpirations(store, prevSectors, newSectors)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to reschedule sector expirations")
+
+		newlyVestedFund, err := st.UnlockVestedFunds(store, rt.CurrEpoch())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to vest new funds")
+
+		// The projected rewards are replaced with those at the sectors' new durations, while pledge only increases.
+		for i, sector := range newSectors {
+			if pledge := pledges.Sectors[i].InitialPledge; pledge.GreaterThan(sector.InitialPledge) {
+				totalPledge = big.Add(totalPledge, big.Sub(pledge, sector.InitialPledge))
+				sector.InitialPledge = pledge
+			}
+			setExpectedRewards(sector, pledges.Sectors[i].ExpectedEpochReward)
+		}
 
-	// Store new sector expiry.
-	rt.State().Transaction(&st, func() interface{} {
-		sector.Info.Expiration = params.NewExpiration
-		if err = st.PutSector(store, sector); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to update sector %v, %v", sectorNo, err)
+		availableBalance := st.GetAvailableBalance(rt.CurrentBalance())
+		if availableBalance.LessThan(totalPledge) {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for initial pledge requirement %s, available: %s", totalPledge, availableBalance)
 		}
-		return nil
-	})
+		if err = st.AddLockedFunds(store, rt.CurrEpoch(), totalPledge, &PledgeVestingSpec); err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to add pledge: %v", err)
+		}
+		st.AssertBalanceInvariants(rt.CurrentBalance())
 
-	return nil
+		for _, sector := range newSectors {
+			if err = st.PutSector(store, sector); err != nil {
+				rt.Abortf(exitcode.ErrIllegalState, "failed to update sector %v, %v", sector.Info.SectorNumber, err)
+			}
+		}
+		return newlyVestedFund
+	}).(abi.TokenAmount)
+
+	notifyPledgeChanged(rt, big.Sub(totalPledge, newlyVestedAmount))
 }
 
 type TerminateSectorsParams struct {
 	Sectors *abi.BitField
 }
 
-func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *adt.EmptyValue {
+// Terminates sectors before their scheduled expiry, charging the early termination fee for each.
+// Returns the total termination fee charged.
+func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *abi.TokenAmount {
 	var st State
 	rt.State().Readonly(&st)
 	rt.ValidateImmediateCallerIs(st.Info.Worker)
+	requireNotSlashed(rt, &st)
 
 	// Note: this cannot terminate pre-committed but un-proven sectors.
-	// They must be allowed to expire (and deposit burnt).
-	terminateSectors(rt, params.Sectors, power.SectorTerminationManual)
-	return nil
+	// They must be withdrawn with WithdrawPreCommits, or allowed to expire (and deposit burnt).
+	fee := terminateSectors(rt, params.Sectors, power.SectorTerminationManual)
+	return &fee
 }
 
 ////////////
@@ -626,13 +1493,14 @@ type DeclareFaultsParams struct {
 }
 
 type FaultDeclaration struct {
-	Deadline uint64 // In range [0..WPoStPeriodDeadlines)
-	Sectors  *abi.BitField
+	Deadline  uint64 // In range [0..WPoStPeriodDeadlines)
+	Partition uint64 // Partition index within the deadline
+	Sectors   *abi.BitField
 }
 
 func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *adt.EmptyValue {
-	if uint64(len(params.Faults)) > WPoStPeriodDeadlines {
-		rt.Abortf(exitcode.ErrIllegalArgument, "too many declarations %d, max %d", len(params.Faults), WPoStPeriodDeadlines)
+	if uint64(len(params.Faults)) > PartitionsMax {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many declarations %d, max %d", len(params.Faults), PartitionsMax)
 	}
 
 	currEpoch := rt.CurrEpoch()
@@ -644,40 +1512,28 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *adt.Empty
 
 	rt.State().Transaction(&st, func() interface{} {
 		rt.ValidateImmediateCallerIs(st.Info.Worker)
+		requireNotSlashed(rt, &st)
 
 		// The proving period start may be negative for low epochs, but all the arithmetic should work out
 		// correctly in order to declare faults for an upcoming deadline or the next period.
 		deadline, _ := st.DeadlineInfo(currEpoch)
-		deadlines, err := st.LoadDeadlines(adt.AsStore(rt))
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
 
 		// Traverse earlier submissions and enact detected faults.
 		// This is necessary to prevent the miner "declaring" a fault for a PoSt already missed.
-		detectedFaultSectors, penalty = checkMissingPoStFaults(rt, &st, store, deadlines, deadline.PeriodStart, deadline.Index, currEpoch)
+		detectedFaultSectors, penalty = checkMissingPoStFaults(rt, &st, store, deadline.PeriodStart, deadline.Index, currEpoch)
 
-		var decaredSectors []*abi.BitField
 		for _, decl := range params.Faults {
-			err = validateFRDeclaration(deadlines, deadline, decl.Deadline, decl.Sectors)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid fault declaration")
-			decaredSectors = append(decaredSectors, decl.Sectors)
-		}
-
-		allDeclared, err := abi.BitFieldUnion(decaredSectors...)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union faults")
-
-		// Split declarations into declarations of new faults, and retraction of declared recoveries.
-		recoveries, err := bitfield.IntersectBitField(st.Recoveries, allDeclared)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect sectors with recoveries")
+			dl, partition := loadFRDeclarationPartition(rt, &st, store, deadline, decl.Deadline, decl.Partition, decl.Sectors)
 
-		newFaults, err := bitfield.SubtractBitField(allDeclared, recoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract recoveries from sectors")
+			// Split declarations into declarations of new faults, and retraction of declared recoveries.
+			recoveries, err := bitfield.IntersectBitField(partition.Recoveries, decl.Sectors)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect sectors with recoveries")
 
-		empty, err := newFaults.IsEmpty()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check if bitfield was empty")
+			newFaults, err := bitfield.SubtractBitField(decl.Sectors, recoveries)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract recoveries from sectors")
 
-		if !empty {
 			// Check new fault are really new.
-			contains, err := abi.BitFieldContainsAny(st.Faults, newFaults)
+			contains, err := abi.BitFieldContainsAny(partition.Faults, newFaults)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect existing faults")
 			if contains {
 				// This could happen if attempting to declare a fault for a deadline that's already passed,
@@ -688,28 +1544,24 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *adt.Empty
 				rt.Abortf(exitcode.ErrIllegalArgument, "attempted to re-declare fault")
 			}
 
-			// Add new faults to state and charge fee.
-			err = st.AddFaults(store, newFaults, deadline.PeriodStart)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add faults")
+			// Remove faulty recoveries
+			err = partition.RemoveRecoveries(recoveries)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove recoveries")
 
-			// Load info for sectors.
-			declaredFaultSectors, err = st.LoadSectorInfos(store, newFaults)
+			// Add new faults to state.
+			newFaultSectors, err := st.LoadSectorInfos(store, newFaults)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault sectors")
+			err = partition.AddFaults(store, st.Info.SectorSize, deadline.PeriodStart, newFaultSectors...)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add faults")
+			declaredFaultSectors = append(declaredFaultSectors, newFaultSectors...)
 
-			// Unlock penalty for declared faults.
-			declaredPenalty, err := unlockPenalty(&st, store, currEpoch, declaredFaultSectors, pledgePenaltyForSectorDeclaredFault)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge fault fee")
-			penalty = big.Add(penalty, declaredPenalty)
+			saveFRDeclarationPartition(rt, &st, store, decl.Deadline, dl, decl.Partition, partition)
 		}
 
-		// Remove faulty recoveries
-		empty, err = recoveries.IsEmpty()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check if bitfield was empty")
-
-		if !empty {
-			err = st.RemoveRecoveries(recoveries)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove recoveries")
-		}
+		// Unlock penalty for declared faults.
+		declaredPenalty, err := unlockPenalty(&st, store, currEpoch, PenaltyDeclaredFault, declaredFaultSectors, pledgePenaltyForSectorDeclaredFault)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge fault fee")
+		penalty = big.Add(penalty, declaredPenalty)
 		return nil
 	})
 
@@ -725,47 +1577,43 @@ type DeclareFaultsRecoveredParams struct {
 }
 
 type RecoveryDeclaration struct {
-	Deadline uint64 // In range [0..WPoStPeriodDeadlines)
-	Sectors  *abi.BitField
+	Deadline  uint64 // In range [0..WPoStPeriodDeadlines)
+	Partition uint64 // Partition index within the deadline
+	Sectors   *abi.BitField
 }
 
 func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecoveredParams) *adt.EmptyValue {
-	if uint64(len(params.Recoveries)) > WPoStPeriodDeadlines {
-		rt.Abortf(exitcode.ErrIllegalArgument, "too many declarations %d, max %d", len(params.Recoveries), WPoStPeriodDeadlines)
+	if uint64(len(params.Recoveries)) > PartitionsMax {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many declarations %d, max %d", len(params.Recoveries), PartitionsMax)
 	}
 
 	currEpoch := rt.CurrEpoch()
+	store := adt.AsStore(rt)
 	var st State
 	rt.State().Transaction(&st, func() interface{} {
 		rt.ValidateImmediateCallerIs(st.Info.Worker)
+		requireNotSlashed(rt, &st)
 
 		deadline, _ := st.DeadlineInfo(currEpoch)
-		deadlines, err := st.LoadDeadlines(adt.AsStore(rt))
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
-
-		var declaredSectors []*abi.BitField
 		for _, decl := range params.Recoveries {
-			err = validateFRDeclaration(deadlines, deadline, decl.Deadline, decl.Sectors)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid recovery declaration")
-			declaredSectors = append(declaredSectors, decl.Sectors)
-		}
+			dl, partition := loadFRDeclarationPartition(rt, &st, store, deadline, decl.Deadline, decl.Partition, decl.Sectors)
 
-		allRecoveries, err := abi.BitFieldUnion(declaredSectors...)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union recoveries")
+			contains, err := abi.BitFieldContainsAll(partition.Faults, decl.Sectors)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check recoveries are faulty")
+			if !contains {
+				rt.Abortf(exitcode.ErrIllegalArgument, "declared recoveries not currently faulty")
+			}
+			contains, err = abi.BitFieldContainsAny(partition.Recoveries, decl.Sectors)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect new recoveries")
+			if contains {
+				rt.Abortf(exitcode.ErrIllegalArgument, "sector already declared recovered")
+			}
 
-		contains, err := abi.BitFieldContainsAll(st.Faults, allRecoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check recoveries are faulty")
-		if !contains {
-			rt.Abortf(exitcode.ErrIllegalArgument, "declared recoveries not currently faulty")
-		}
-		contains, err = abi.BitFieldContainsAny(st.Recoveries, allRecoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to intersect new recoveries")
-		if contains {
-			rt.Abortf(exitcode.ErrIllegalArgument, "sector already declared recovered")
-		}
+			err = partition.AddRecoveries(decl.Sectors)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid recoveries")
 
-		err = st.AddRecoveries(allRecoveries)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid recoveries")
+			saveFRDeclarationPartition(rt, &st, store, decl.Deadline, dl, decl.Partition, partition)
+		}
 		return nil
 	})
 
@@ -778,27 +1626,45 @@ func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecovered
 ///////////////////////
 
 // Locks up some amount of a the miner's unlocked balance (including any received alongside the invoking message).
+// Any fee debt is first repaid from the unlocked balance, and the amount locked reduced by the amount repaid.
+// Funds locked by the reward actor are block rewards, which vest on their own schedule and are drawn on for
+// penalties before pledge collateral. Because locked rewards back penalties just as pledge does, they are reported
+// to the power actor as pledge, keeping the network's total pledge equal to the sum of miners' locked funds.
 func (a Actor) AddLockedFund(rt Runtime, amountToLock *abi.TokenAmount) *adt.EmptyValue {
+	if amountToLock.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "cannot lock negative amount %v", *amountToLock)
+	}
+
 	store := adt.AsStore(rt)
 	var st State
+	var debtRepaid, amountLocked abi.TokenAmount
 	newlyVested := rt.State().Transaction(&st, func() interface{} {
 		rt.ValidateImmediateCallerIs(st.Info.Worker, st.Info.Owner, builtin.RewardActorAddr)
+		requireNotSlashed(rt, &st)
 
 		newlyVestedFund, err := st.UnlockVestedFunds(store, rt.CurrEpoch())
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to vest funds")
 
-		availableBalance := st.GetAvailableBalance(rt.CurrentBalance())
-		if availableBalance.LessThan(*amountToLock) {
-			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds to lock, available: %v, requested: %v", availableBalance, *amountToLock)
+		debtRepaid = st.RepayDebt(rt.CurrentBalance())
+		amountLocked = big.Max(big.Sub(*amountToLock, debtRepaid), big.Zero())
+
+		availableBalance := big.Sub(st.GetAvailableBalance(rt.CurrentBalance()), debtRepaid)
+		if availableBalance.LessThan(amountLocked) {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds to lock, available: %v, requested: %v", availableBalance, amountLocked)
 		}
 
-		if err = st.AddLockedFunds(store, rt.CurrEpoch(), *amountToLock, &PledgeVestingSpec); err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to lock pledge: %v", err)
+		if rt.Message().Caller() == builtin.RewardActorAddr {
+			err = st.AddLockedRewards(store, rt.CurrEpoch(), amountLocked, &RewardVestingSpec)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock rewards")
+		} else {
+			err = st.AddLockedFunds(store, rt.CurrEpoch(), amountLocked, &PledgeVestingSpec)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock pledge")
 		}
 		return newlyVestedFund
 	}).(abi.TokenAmount)
 
-	notifyPledgeChanged(rt, big.Sub(*amountToLock, newlyVested))
+	burnFunds(rt, debtRepaid)
+	notifyPledgeChanged(rt, big.Sub(amountLocked, newlyVested))
 	return nil
 }
 
@@ -808,9 +1674,11 @@ type ReportConsensusFaultParams struct {
 	BlockHeaderExtra []byte
 }
 
+// Slashes the miner for a consensus fault: its power is removed, its deals terminated, and its entire balance
+// forfeit, with a share rewarded to the reporter and the remainder burnt.
+// The fault is recorded, and any later report against the miner (including of the same fault) is rejected.
 func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultParams) *adt.EmptyValue {
 	// Note: only the first reporter of any fault is rewarded.
-	// Subsequent invocations fail because the target miner has been removed.
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
 	reporter := rt.Message().Caller()
 
@@ -818,6 +1686,9 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalArgument, "fault not verified: %s", err)
 	}
+	if fault.Target != rt.Message().Receiver() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "fault by %v reported to miner %v", fault.Target, rt.Message().Receiver())
+	}
 
 	// Elapsed since the fault (i.e. since the higher of the two blocks)
 	faultAge := rt.CurrEpoch() - fault.Epoch
@@ -827,25 +1698,41 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 
 	var st State
 	rt.State().Readonly(&st)
+	if st.ConsensusFault != nil {
+		rt.Abortf(exitcode.ErrForbidden, "miner already slashed for consensus fault at epoch %v", st.ConsensusFault.FaultEpoch)
+	}
+
+	// Terminate the deals of all sectors, slashing the miner's deal collateral.
+	requestTerminateAllDeals(rt, &st)
 
 	// Notify power actor with lock-up total being removed.
+	totalLocked := st.TotalLockedFunds()
 	_, code := rt.Send(
 		builtin.StoragePowerActorAddr,
 		builtin.MethodsPower.OnConsensusFault,
-		&st.LockedFunds,
+		&totalLocked,
 		abi.NewTokenAmount(0),
 	)
 	builtin.RequireSuccess(rt, code, "failed to notify power actor on consensus fault")
 
-	// TODO: terminate deals with market actor, https://github.com/filecoin-project/specs-actors/issues/279
-
 	// Reward reporter with a share of the miner's current balance.
 	slasherReward := rewardForConsensusSlashReport(faultAge, rt.CurrentBalance())
 	_, code = rt.Send(reporter, builtin.MethodSend, nil, slasherReward)
 	builtin.RequireSuccess(rt, code, "failed to reward reporter")
 
-	// Delete the actor and burn all remaining funds
-	rt.DeleteActor(builtin.BurntFundsActorAddr)
+	// Record the fault, discarding the rest of the miner's state, and forfeit all remaining funds.
+	forfeit := rt.CurrentBalance()
+	rt.State().Transaction(&st, func() interface{} {
+		err := st.RecordConsensusFault(adt.AsStore(rt), &ConsensusFaultRecord{
+			FaultEpoch:  fault.Epoch,
+			ReportEpoch: rt.CurrEpoch(),
+			Reporter:    reporter,
+		}, forfeit)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record consensus fault")
+		return nil
+	})
+
+	burnFunds(rt, forfeit)
 	return nil
 }
 
@@ -859,15 +1746,21 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *adt.E
 		rt.Abortf(exitcode.ErrIllegalArgument, "negative fund requested for withdrawal: %s", params.AmountRequested)
 	}
 
+	var debtRepaid abi.TokenAmount
 	newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
 		rt.ValidateImmediateCallerIs(st.Info.Owner)
+		requireNotSlashed(rt, &st)
 		newlyVestedFund, err := st.UnlockVestedFunds(adt.AsStore(rt), rt.CurrEpoch())
 		if err != nil {
 			rt.Abortf(exitcode.ErrIllegalState, "failed to vest fund: %v", err)
 		}
+		debtRepaid = st.RepayDebt(rt.CurrentBalance())
+		requireNoFeeDebt(rt, &st)
 		return newlyVestedFund
 	}).(abi.TokenAmount)
 
+	burnFunds(rt, debtRepaid)
+
 	currBalance := rt.CurrentBalance()
 	amountWithdrawn := big.Min(st.GetAvailableBalance(currBalance), params.AmountRequested)
 	Assert(amountWithdrawn.LessThanEqual(currBalance))
@@ -889,6 +1782,13 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *adt.E
 func (a Actor) OnDeferredCronEvent(rt Runtime, payload *CronEventPayload) *adt.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.StoragePowerActorAddr)
 
+	// A miner slashed for a consensus fault has no power or collateral left to manage, and stops re-enrolling.
+	var st State
+	rt.State().Readonly(&st)
+	if st.ConsensusFault != nil {
+		return nil
+	}
+
 	switch payload.EventType {
 	case CronEventProvingPeriod:
 		handleProvingPeriod(rt)
@@ -912,13 +1812,16 @@ func handleProvingPeriod(rt Runtime) {
 	var deadline *DeadlineInfo
 	var fullPeriod bool
 	{
-		// Vest locked funds.
+		// Vest locked funds, and repay any fee debt from them.
+		var debtRepaid abi.TokenAmount
 		newlyVestedAmount := rt.State().Transaction(&st, func() interface{} {
 			newlyVestedFund, err := st.UnlockVestedFunds(store, currEpoch)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to vest funds")
+			debtRepaid = st.RepayDebt(rt.CurrentBalance())
 			return newlyVestedFund
 		}).(abi.TokenAmount)
 
+		burnFunds(rt, debtRepaid)
 		notifyPledgeChanged(rt, newlyVestedAmount.Neg())
 	}
 
@@ -930,9 +1833,7 @@ func handleProvingPeriod(rt Runtime) {
 			deadline, fullPeriod = st.DeadlineInfo(currEpoch)
 			AssertMsg(currEpoch == deadline.PeriodEnd(), "proving period cron at epoch %d, period ends at %d", currEpoch, deadline.PeriodEnd())
 			if fullPeriod { // Skip checking faults on the first, incomplete period.
-				deadlines, err := st.LoadDeadlines(store)
-				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
-				detectedFaultSectors, penalty = checkMissingPoStFaults(rt, &st, store, deadlines, deadline.PeriodStart, WPoStPeriodDeadlines, currEpoch)
+				detectedFaultSectors, penalty = checkMissingPoStFaults(rt, &st, store, deadline.PeriodStart, WPoStPeriodDeadlines, currEpoch)
 			}
 			return nil
 		})
@@ -943,27 +1844,32 @@ func handleProvingPeriod(rt Runtime) {
 	}
 
 	{
-		// Expire sectors that are due.
-		var expiredSectors *abi.BitField
-		var err error
+		// Assign new sectors to deadlines.
+		// This precedes expiration so that new sectors expiring now are expired along with the others.
 		rt.State().Transaction(&st, func() interface{} {
-			expiredSectors, err = popSectorExpirations(&st, store, currEpoch)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load expired sectors")
+			newSectors, err := st.LoadSectorInfos(store, st.NewSectors)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load new sectors")
+
+			if len(newSectors) > 0 {
+				err = st.AssignNewSectors(store, newSectors)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to assign new sectors to deadlines")
+
+				err = st.ClearNewSectors()
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to clear new sectors")
+			}
 			return nil
 		})
-
-		// Terminate expired sectors (sends messages to power and market actors).
-		terminateSectors(rt, expiredSectors, power.SectorTerminationExpired)
 	}
 
 	{
-		// Terminate sectors with faults that are too old, and pay fees for ongoing faults.
-		var expiredFaults, ongoingFaults *abi.BitField
+		// Expire sectors that are due, terminate sectors with faults that are too old, and pay fees for ongoing faults.
+		var expiredSectors, expiredFaults *abi.BitField
 		var ongoingFaultPenalty abi.TokenAmount
-		var err error
 		rt.State().Transaction(&st, func() interface{} {
-			expiredFaults, ongoingFaults, err = popExpiredFaults(&st, store, currEpoch-FaultMaxAge)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault epochs")
+			var ongoingFaults *abi.BitField
+			var err error
+			expiredSectors, expiredFaults, ongoingFaults, err = findExpirations(&st, store, currEpoch, currEpoch-FaultMaxAge)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to find expired sectors")
 
 			// Load info for ongoing faults.
 			// TODO: this is potentially super expensive for a large miner with ongoing faults
@@ -971,38 +1877,35 @@ func handleProvingPeriod(rt Runtime) {
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault sectors")
 
 			// Unlock penalty for ongoing faults.
-			ongoingFaultPenalty, err = unlockPenalty(&st, store, currEpoch, ongoingFaultInfos, pledgePenaltyForSectorDeclaredFault)
+			ongoingFaultPenalty, err = unlockPenalty(&st, store, currEpoch, PenaltyDeclaredFault, ongoingFaultInfos, pledgePenaltyForSectorDeclaredFault)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge fault fee")
 			return nil
 		})
 
+		// Terminate expired sectors (sends messages to power and market actors).
+		terminateSectors(rt, expiredSectors, power.SectorTerminationExpired)
 		terminateSectors(rt, expiredFaults, power.SectorTerminationFaulty)
 		burnFundsAndNotifyPledgeChange(rt, ongoingFaultPenalty)
 	}
 
 	{
-		// Establish new proving sets and clear proofs.
+		// Clear proofs for the next period, and discard the snapshots of those that may no longer be disputed.
 		rt.State().Transaction(&st, func() interface{} {
-			deadlines, err := st.LoadDeadlines(store)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
-
-			// Assign new sectors to deadlines.
-			newSectors, err := st.NewSectors.All(NewSectorsPerPeriodMax)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to expand new sectors")
-
-			if len(newSectors) > 0 {
-				assignmentSeed := rt.GetRandomness(crypto.DomainSeparationTag_WindowedPoStDeadlineAssignment, currEpoch-1, nil)
-				err = AssignNewSectors(deadlines, newSectors, assignmentSeed)
-				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to assign new sectors to deadlines")
-
-				// Store updated deadline state.
-				err = st.SaveDeadlines(store, deadlines)
-				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store new deadlines")
+			err := st.PruneExpiredPoStSnapshots(store, currEpoch)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to prune PoSt snapshots")
+
+			for dlIdx := uint64(0); dlIdx < WPoStPeriodDeadlines; dlIdx++ {
+				dl, err := st.LoadDeadline(store, dlIdx)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", dlIdx)
+
+				empty, err := dl.PostSubmissions.IsEmpty()
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check PoSt submissions")
+				if !empty {
+					dl.ClearPoStSubmissions()
+					err = st.SaveDeadline(store, dlIdx, dl)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d", dlIdx)
+				}
 			}
-
-			// Reset PoSt submissions for next period.
-			err = st.ClearPoStSubmissions()
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to clear PoSt submissions")
 			return nil
 		})
 	}
@@ -1014,149 +1917,132 @@ func handleProvingPeriod(rt Runtime) {
 	})
 }
 
-// Detects faults from missing PoSt submissions that did not arrive.
-func checkMissingPoStFaults(rt Runtime, st *State, store adt.Store, deadlines *Deadlines, periodStart abi.ChainEpoch, beforeDeadline uint64, currEpoch abi.ChainEpoch) ([]*SectorOnChainInfo, abi.TokenAmount) {
-	detectedFaults, failedRecoveries, err := computeFaultsFromMissingPoSts(st, deadlines, beforeDeadline)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compute detected faults")
-
-	err = st.AddFaults(store, detectedFaults, periodStart)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record new faults")
-
-	err = st.RemoveRecoveries(failedRecoveries)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record failed recoveries")
-
-	// Load info for sectors.
-	// TODO: this is potentially super expensive for a large miner failing to submit proofs.
-	detectedFaultSectors, err := st.LoadSectorInfos(store, detectedFaults)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault sectors")
-	failedRecoverySectors, err := st.LoadSectorInfos(store, failedRecoveries)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load failed recovery sectors")
-
-	// Unlock sector penalty for all undeclared faults.
-	penalty, err := unlockPenalty(st, store, currEpoch, append(detectedFaultSectors, failedRecoverySectors...), pledgePenaltyForSectorUndeclaredFault)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge sector penalty")
-	return detectedFaultSectors, penalty
-}
-
-// Computes the sectors that were expected to be present in partitions of a PoSt submission but were not.
-func computeFaultsFromMissingPoSts(st *State, deadlines *Deadlines, beforeDeadline uint64) (detectedFaults, failedRecoveries *abi.BitField, err error) {
-	// TODO: Iterating this bitfield and keeping track of what partitions we're expecting could remove the
-	// need to expand this into a potentially-giant map. But it's tricksy.
-	submissions, err := st.PostSubmissions.AllMap(PartitionsMax)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to expand submissions: %w", err)
-	}
-
-	deadlineFirstPartition := uint64(0)
+// Detects faults from missing PoSt submissions for partitions due at deadlines before some deadline.
+func checkMissingPoStFaults(rt Runtime, st *State, store adt.Store, periodStart abi.ChainEpoch, beforeDeadline uint64, currEpoch abi.ChainEpoch) ([]*SectorOnChainInfo, abi.TokenAmount) {
+	var detectedFaultSectors, failedRecoverySectors []*SectorOnChainInfo
 	var fGroups, rGroups []*abi.BitField
 	for dlIdx := uint64(0); dlIdx < beforeDeadline; dlIdx++ {
-		partitionCount, dlSectorCount, err := DeadlineCount(deadlines, dlIdx)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to count deadline %d partitions: %w", dlIdx, err)
-		}
-		deadlineSectors := deadlines.Due[dlIdx]
-
-		for i := uint64(0); i < partitionCount; i++ {
-			if !submissions[deadlineFirstPartition+i] {
-				// No PoSt received in prior period.
-				firstSector := i * WPoStPartitionSectors
-				sectorCount := WPoStPartitionSectors
-				if i == partitionCount-1 {
-					sectorCount = dlSectorCount % WPoStPartitionSectors
-				}
+		dl, err := st.LoadDeadline(store, dlIdx)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", dlIdx)
+
+		var changedIdxs []uint64
+		var changed []*Partition
+		err = dl.ForEachPartition(store, func(partIdx uint64, partition *Partition) error {
+			submitted, err := dl.PostSubmissions.IsSet(partIdx)
+			if err != nil || submitted {
+				return err
+			}
 
-				partitionSectors, err := deadlineSectors.Slice(firstSector, sectorCount)
-				if err != nil {
-					return nil, nil, fmt.Errorf("failed to slice deadline %d partition %d sectors %d..%d: %w",
-						dlIdx, i, firstSector, firstSector+partitionCount, err)
-				}
+			// No PoSt received in prior period.
+			// Record newly-faulty sectors.
+			newFaults, err := bitfield.SubtractBitField(partition.Sectors, partition.Faults)
+			if err != nil {
+				return err
+			}
+			newFaultSectors, err := st.LoadSectorInfos(store, newFaults)
+			if err != nil {
+				return err
+			}
 
-				// Record newly-faulty sectors.
-				newFaults, err := bitfield.SubtractBitField(partitionSectors, st.Faults)
-				fGroups = append(fGroups, newFaults)
+			// Record failed recoveries.
+			// By construction, these are already faulty and thus not in newFaults.
+			failedRecoveries := partition.Recoveries
+			failedRecoveryCount, err := failedRecoveries.Count()
+			if err != nil {
+				return err
+			}
+			if len(newFaultSectors) == 0 && failedRecoveryCount == 0 {
+				return nil
+			}
+			failedRecoveryInfos, err := st.LoadSectorInfos(store, failedRecoveries)
+			if err != nil {
+				return err
+			}
 
-				// Record failed recoveries.
-				// By construction, these are already faulty and thus not in newFaults.
-				failedRecovery, err := bitfield.IntersectBitField(partitionSectors, st.Recoveries)
-				rGroups = append(rGroups, failedRecovery)
+			if err = partition.RemoveRecoveries(failedRecoveries); err != nil {
+				return err
+			}
+			if err = partition.AddFaults(store, st.Info.SectorSize, periodStart, newFaultSectors...); err != nil {
+				return err
 			}
-		}
 
-		deadlineFirstPartition += partitionCount
-	}
-	detectedFaults, err = abi.BitFieldUnion(fGroups...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to union detected fault groups: %w", err)
+			fGroups = append(fGroups, newFaults)
+			rGroups = append(rGroups, failedRecoveries)
+			detectedFaultSectors = append(detectedFaultSectors, newFaultSectors...)
+			failedRecoverySectors = append(failedRecoverySectors, failedRecoveryInfos...)
+			changedIdxs = append(changedIdxs, partIdx)
+			changed = append(changed, partition)
+			return nil
+		})
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to detect faults at deadline %d", dlIdx)
+
+		if len(changed) == 0 {
+			continue
+		}
+		for i, partIdx := range changedIdxs {
+			err = dl.SavePartition(store, partIdx, changed[i])
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store partition %d at deadline %d", partIdx, dlIdx)
+		}
+		err = st.SaveDeadline(store, dlIdx, dl)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d", dlIdx)
 	}
-	failedRecoveries, err = abi.BitFieldUnion(rGroups...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to union failed recovery groups: %w", err)
+
+	if len(fGroups) > 0 {
+		detectedFaults, err := abi.BitFieldUnion(fGroups...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union detected fault groups")
+		failedRecoveries, err := abi.BitFieldUnion(rGroups...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to union failed recovery groups")
+		rt.EmitEvent(&FaultDetected{Faults: detectedFaults, FailedRecoveries: failedRecoveries})
 	}
-	return
+
+	// Unlock sector penalty for all undeclared faults.
+	penalty, err := unlockPenalty(st, store, currEpoch, PenaltyUndeclaredFault, append(detectedFaultSectors, failedRecoverySectors...), pledgePenaltyForSectorUndeclaredFault)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge sector penalty")
+	return detectedFaultSectors, penalty
 }
 
-// Removes and returns sector numbers that expire at or before an epoch.
-func popSectorExpirations(st *State, store adt.Store, epoch abi.ChainEpoch) (*abi.BitField, error) {
-	var expiredEpochs []abi.ChainEpoch
-	var expiredSectors []*abi.BitField
-	errDone := fmt.Errorf("done")
-	err := st.ForEachSectorExpiration(store, func(expiry abi.ChainEpoch, sectors *abi.BitField) error {
-		if expiry > epoch {
-			return errDone
+// Finds, across all partitions, the sectors that expire at or before an epoch, and the faulty sectors detected
+// faulty at or before a latest termination epoch.
+// Returns the expired sectors, the expired faults (excluding any expired sectors), and the other, ongoing faults.
+func findExpirations(st *State, store adt.Store, expiry, latestTermination abi.ChainEpoch) (expired, expiredFaults, ongoingFaults *abi.BitField, err error) {
+	var expiredSets, expiredFaultSets, faultSets []*abi.BitField
+	err = st.ForEachPartition(store, func(_, _ uint64, partition *Partition) error {
+		partitionExpired, err := partition.ExpiringSectors(store, expiry)
+		if err != nil {
+			return err
+		}
+		partitionExpiredFaults, err := partition.FaultsDetectedBy(store, latestTermination)
+		if err != nil {
+			return err
 		}
-		expiredSectors = append(expiredSectors, sectors)
-		expiredEpochs = append(expiredEpochs, expiry)
+		expiredSets = append(expiredSets, partitionExpired)
+		expiredFaultSets = append(expiredFaultSets, partitionExpiredFaults)
+		faultSets = append(faultSets, partition.Faults)
 		return nil
 	})
-	if err != nil && err != errDone {
-		return nil, err
-	}
-	err = st.ClearSectorExpirations(store, expiredEpochs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to clear sector expirations %s: %w", expiredEpochs, err)
+		return nil, nil, nil, err
 	}
 
-	allExpiries, err := abi.BitFieldUnion(expiredSectors...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to union expired sectors: %w", err)
-	}
-	return allExpiries, err
-}
-
-// Removes and returns sector numbers that were faulty at or before an epoch, and returns the sector
-// numbers for other ongoing faults.
-func popExpiredFaults(st *State, store adt.Store, latestTermination abi.ChainEpoch) (*abi.BitField, *abi.BitField, error) {
-	var expiredEpochs []abi.ChainEpoch
-	var expiredFaults []*abi.BitField
-	var ongoingFaults []*abi.BitField
-	errDone := fmt.Errorf("done")
-	err := st.ForEachFaultEpoch(store, func(faultStart abi.ChainEpoch, faults *abi.BitField) error {
-		if faultStart <= latestTermination {
-			expiredFaults = append(expiredFaults, faults)
-			expiredEpochs = append(expiredEpochs, faultStart)
-		} else {
-			ongoingFaults = append(ongoingFaults, faults)
-		}
-		return nil
-	})
-	if err != nil && err != errDone {
-		return nil, nil, nil
+	if expired, err = abi.BitFieldUnion(expiredSets...); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to union expired sectors: %w", err)
 	}
-	err = st.ClearFaultEpochs(store, expiredEpochs...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to clear fault epochs %s: %w", expiredEpochs, err)
+	if expiredFaults, err = abi.BitFieldUnion(expiredFaultSets...); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to union expired faults: %w", err)
 	}
-
-	allExpiries, err := abi.BitFieldUnion(expiredFaults...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to union expired faults: %w", err)
+	if expiredFaults, err = bitfield.SubtractBitField(expiredFaults, expired); err != nil {
+		return nil, nil, nil, err
 	}
-	allOngoing, err := abi.BitFieldUnion(ongoingFaults...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to union ongoing faults: %w", err)
+	if ongoingFaults, err = abi.BitFieldUnion(faultSets...); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to union ongoing faults: %w", err)
 	}
-
-	return allExpiries, allOngoing, err
+	if ongoingFaults, err = bitfield.SubtractBitField(ongoingFaults, expired); err != nil {
+		return nil, nil, nil, err
+	}
+	if ongoingFaults, err = bitfield.SubtractBitField(ongoingFaults, expiredFaults); err != nil {
+		return nil, nil, nil, err
+	}
+	return expired, expiredFaults, ongoingFaults, nil
 }
 
 func checkPrecommitExpiry(rt Runtime, sectors *abi.BitField) {
@@ -1166,6 +2052,7 @@ func checkPrecommitExpiry(rt Runtime, sectors *abi.BitField) {
 	// initialize here to add together for all sectors and minimize calls across actors
 	depositToBurn := abi.NewTokenAmount(0)
 	rt.State().Transaction(&st, func() interface{} {
+		var expiredSectors []uint64
 		err := sectors.ForEach(func(i uint64) error {
 			sectorNo := abi.SectorNumber(i)
 			sector, found, err := st.GetPrecommittedSector(store, sectorNo)
@@ -1184,10 +2071,14 @@ func checkPrecommitExpiry(rt Runtime, sectors *abi.BitField) {
 			}
 			// increment deposit to burn
 			depositToBurn = big.Add(depositToBurn, sector.PreCommitDeposit)
+			expiredSectors = append(expiredSectors, i)
 			return nil
 		})
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check precommit expiries")
 
+		err = st.RecordPenalty(store, rt.CurrEpoch(), PenaltyPreCommitExpiry, bitfield.NewFromSet(expiredSectors), depositToBurn)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record expired deposit penalty")
+
 		st.PreCommitDeposits = big.Sub(st.PreCommitDeposits, depositToBurn)
 		Assert(st.PreCommitDeposits.GreaterThanEqual(big.Zero()))
 		return nil
@@ -1198,13 +2089,14 @@ func checkPrecommitExpiry(rt Runtime, sectors *abi.BitField) {
 }
 
 // TODO: red flag that this method is potentially super expensive
-func terminateSectors(rt Runtime, sectorNos *abi.BitField, terminationType power.SectorTermination) {
+// Returns the termination fee charged, which is zero for expired sectors.
+func terminateSectors(rt Runtime, sectorNos *abi.BitField, terminationType power.SectorTermination) abi.TokenAmount {
 	empty, err := sectorNos.IsEmpty()
 	if err != nil {
 		rt.Abortf(exitcode.ErrIllegalState, "failed to count sectors")
 	}
 	if empty {
-		return
+		return big.Zero()
 	}
 
 	store := adt.AsStore(rt)
@@ -1213,21 +2105,10 @@ func terminateSectors(rt Runtime, sectorNos *abi.BitField, terminationType power
 	var dealIDs []abi.DealID
 	var allSectors []*SectorOnChainInfo
 	var faultySectors []*SectorOnChainInfo
-	var penalty abi.TokenAmount
+	fee := big.Zero()
+	penalty := big.Zero()
 
 	rt.State().Transaction(&st, func() interface{} {
-		maxAllowedFaults, err := st.GetMaxAllowedFaults(store)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault max")
-
-		// Narrow faults to just the set that are expiring, before expanding to a map.
-		faults, err := bitfield.IntersectBitField(sectorNos, st.Faults)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load faults")
-
-		faultsMap, err := faults.AllMap(maxAllowedFaults)
-		if err != nil {
-			rt.Abortf(exitcode.ErrIllegalState, "failed to expand faults")
-		}
-
 		err = sectorNos.ForEach(func(sectorNo uint64) error {
 			sector, found, err := st.GetSector(store, abi.SectorNumber(sectorNo))
 			if err != nil {
@@ -1239,28 +2120,31 @@ func terminateSectors(rt Runtime, sectorNos *abi.BitField, terminationType power
 
 			dealIDs = append(dealIDs, sector.Info.DealIDs...)
 			allSectors = append(allSectors, sector)
-
-			_, fault := faultsMap[sectorNo]
-			if fault {
-				faultySectors = append(faultySectors, sector)
-			}
 			return nil
 		})
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector metadata")
 
-		deadlines, err := st.LoadDeadlines(store)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
-
-		err = removeTerminatedSectors(&st, store, deadlines, sectorNos)
+		faults, err := removeTerminatedSectors(&st, store, allSectors)
 		if err != nil {
 			rt.Abortf(exitcode.ErrIllegalState, "failed to delete sectors: %v", err)
 		}
 
-		err = st.SaveDeadlines(store, deadlines)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store new deadlines")
+		faultsMap, err := faults.AllMap(uint64(len(allSectors)))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to expand faults")
+		for _, sector := range allSectors {
+			if faultsMap[uint64(sector.Info.SectorNumber)] {
+				faultySectors = append(faultySectors, sector)
+			}
+		}
 
 		if terminationType != power.SectorTerminationExpired {
-			penalty, err = unlockPenalty(&st, store, rt.CurrEpoch(), allSectors, pledgePenaltyForSectorTermination)
+			currEpoch := rt.CurrEpoch()
+			terminationFee := func(sector *SectorOnChainInfo) abi.TokenAmount {
+				return pledgePenaltyForSectorTermination(sector, currEpoch)
+			}
+			fee = totalPenalty(allSectors, terminationFee)
+			penalty, err = unlockPenalty(&st, store, currEpoch, PenaltyTermination, allSectors, terminationFee)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to charge termination fee")
 		}
 		return nil
 	})
@@ -1272,28 +2156,22 @@ func terminateSectors(rt Runtime, sectorNos *abi.BitField, terminationType power
 	requestTerminatePower(rt, terminationType, st.Info.SectorSize, allSectors)
 
 	burnFundsAndNotifyPledgeChange(rt, penalty)
+	return fee
 }
 
-// Removes a group sectors from the sector set and its number from all sector collections in state.
-func removeTerminatedSectors(st *State, store adt.Store, deadlines *Deadlines, sectors *abi.BitField) error {
-	err := st.DeleteSectors(store, sectors)
-	if err != nil {
-		return err
-	}
-	err = st.RemoveNewSectors(sectors)
+// Removes a group of sectors from the sector set, from new sectors, and from the partitions holding them.
+// Returns the sector numbers that were faulty.
+func removeTerminatedSectors(st *State, store adt.Store, sectors []*SectorOnChainInfo) (*abi.BitField, error) {
+	sectorNos := sectorNumbers(sectors)
+	err := st.DeleteSectors(store, sectorNos)
 	if err != nil {
-		return err
-	}
-	err = deadlines.RemoveFromAllDeadlines(sectors)
-	if err != nil {
-		return err
+		return nil, err
 	}
-	err = st.RemoveFaults(store, sectors)
+	err = st.RemoveNewSectors(sectorNos)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = st.RemoveRecoveries(sectors)
-	return err
+	return st.RemoveSectorsFromDeadlines(store, sectors)
 }
 
 func enrollCronEvent(rt Runtime, eventEpoch abi.ChainEpoch, callbackPayload *CronEventPayload) {
@@ -1403,7 +2281,8 @@ func requestTerminatePower(rt Runtime, terminationType power.SectorTermination,
 	builtin.RequireSuccess(rt, code, "failed to terminate sector power type %v, sectors %v", terminationType, sectors)
 }
 
-func verifyWindowedPost(rt Runtime, challengeEpoch abi.ChainEpoch, sectors []*SectorOnChainInfo, proofs []abi.PoStProof) {
+// Verifies a Window PoSt over a set of challenged sectors, returning an error if it is invalid.
+func verifyWindowedPost(rt Runtime, challengeEpoch abi.ChainEpoch, sectors []abi.SectorInfo, proofs []abi.PoStProof) error {
 	minerActorID, err := addr.IDFromAddress(rt.Message().Receiver())
 	AssertNoError(err) // Runtime always provides ID-addresses
 
@@ -1413,38 +2292,70 @@ func verifyWindowedPost(rt Runtime, challengeEpoch abi.ChainEpoch, sectors []*Se
 	AssertNoError(err)
 	postRandomness := rt.GetRandomness(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, challengeEpoch, addrBuf.Bytes())
 
-	sectorProofInfo := make([]abi.SectorInfo, len(sectors))
-	for i, s := range sectors {
-		sectorProofInfo[i] = s.AsSectorInfo()
-	}
-
 	// Get public inputs
 	pvInfo := abi.WindowPoStVerifyInfo{
 		Randomness:        abi.PoStRandomness(postRandomness),
 		Proofs:            proofs,
-		ChallengedSectors: sectorProofInfo,
+		ChallengedSectors: sectors,
 		Prover:            abi.ActorID(minerActorID),
 	}
 
 	// Verify the PoSt Proof
 	if err = rt.Syscalls().VerifyPoSt(pvInfo); err != nil {
-		rt.Abortf(exitcode.ErrIllegalArgument, "invalid PoSt %+v: %s", pvInfo, err)
+		return xerrors.Errorf("invalid PoSt %+v: %w", pvInfo, err)
+	}
+	return nil
+}
+
+func asSectorInfos(sectors []*SectorOnChainInfo) []abi.SectorInfo {
+	sectorProofInfo := make([]abi.SectorInfo, len(sectors))
+	for i, s := range sectors {
+		sectorProofInfo[i] = s.AsSectorInfo()
 	}
+	return sectorProofInfo
 }
 
 func verifySeal(rt Runtime, onChainInfo *abi.OnChainSealVerifyInfo) {
+	svInfo, code, err := getSealVerifyInfo(rt, onChainInfo)
+	if err != nil {
+		rt.Abortf(code, "%s", err)
+	}
+	if err = rt.Syscalls().VerifySeal(*svInfo); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "invalid seal %+v: %s", svInfo, err)
+	}
+}
+
+// Checks that a seal proof may be verified at the current epoch, and assembles the information with which to verify it.
+// If not, returns an error and the exit code with which to abort.
+func getSealVerifyInfo(rt Runtime, onChainInfo *abi.OnChainSealVerifyInfo) (*abi.SealVerifyInfo, exitcode.ExitCode, error) {
+	if code, err := checkSealEpochs(rt, onChainInfo); err != nil {
+		return nil, code, err
+	}
+
+	commD, code := requestUnsealedSectorCID(rt, onChainInfo.RegisteredProof, onChainInfo.DealIDs)
+	if !code.IsSuccess() {
+		return nil, code, xerrors.Errorf("failed request for unsealed sector CID for deals %v", onChainInfo.DealIDs)
+	}
+	return makeSealVerifyInfo(rt, onChainInfo, commD), exitcode.Ok, nil
+}
+
+// Checks that a sector may be proven at the current epoch, with its interactive challenge available and
+// seal randomness recent enough.
+func checkSealEpochs(rt Runtime, onChainInfo *abi.OnChainSealVerifyInfo) (exitcode.ExitCode, error) {
 	if rt.CurrEpoch() <= onChainInfo.InteractiveEpoch {
-		rt.Abortf(exitcode.ErrForbidden, "too early to prove sector")
+		return exitcode.ErrForbidden, xerrors.Errorf("too early to prove sector")
 	}
 
 	// Check randomness.
 	sealRandEarliest := rt.CurrEpoch() - ChainFinalityish - MaxSealDuration[onChainInfo.RegisteredProof]
 	if onChainInfo.SealRandEpoch < sealRandEarliest {
-		rt.Abortf(exitcode.ErrIllegalArgument, "seal epoch %v too old, expected >= %v", onChainInfo.SealRandEpoch, sealRandEarliest)
+		return exitcode.ErrIllegalArgument, xerrors.Errorf("seal epoch %v too old, expected >= %v", onChainInfo.SealRandEpoch, sealRandEarliest)
 	}
+	return exitcode.Ok, nil
+}
 
-	commD := requestUnsealedSectorCID(rt, onChainInfo.RegisteredProof, onChainInfo.DealIDs)
-
+// Assembles the information to verify a sector's seal, given the sector's unsealed CID.
+func makeSealVerifyInfo(rt Runtime, onChainInfo *abi.OnChainSealVerifyInfo, commD cid.Cid) *abi.SealVerifyInfo {
 	minerActorID, err := addr.IDFromAddress(rt.Message().Receiver())
 	AssertNoError(err) // Runtime always provides ID-addresses
 
@@ -1455,7 +2366,7 @@ func verifySeal(rt Runtime, onChainInfo *abi.OnChainSealVerifyInfo) {
 	svInfoRandomness := rt.GetRandomness(crypto.DomainSeparationTag_SealRandomness, onChainInfo.SealRandEpoch, buf.Bytes())
 	svInfoInteractiveRandomness := rt.GetRandomness(crypto.DomainSeparationTag_InteractiveSealChallengeSeed, onChainInfo.InteractiveEpoch, buf.Bytes())
 
-	svInfo := abi.SealVerifyInfo{
+	return &abi.SealVerifyInfo{
 		SectorID: abi.SectorID{
 			Miner:  abi.ActorID(minerActorID),
 			Number: onChainInfo.SectorNumber,
@@ -1465,13 +2376,27 @@ func verifySeal(rt Runtime, onChainInfo *abi.OnChainSealVerifyInfo) {
 		InteractiveRandomness: abi.InteractiveSealRandomness(svInfoInteractiveRandomness),
 		UnsealedCID:           commD,
 	}
-	if err = rt.Syscalls().VerifySeal(svInfo); err != nil {
-		rt.Abortf(exitcode.ErrIllegalState, "invalid seal %+v: %s", svInfo, err)
-	}
+}
+
+// Requests the storage market actor compute the unsealed sector CIDs of a batch of sectors from their deals,
+// with a single message. Returns the result for each sector, in order.
+func requestUnsealedSectorCIDs(rt Runtime, inputs []market.ComputeDataCommitmentParams) []market.SectorDataCommitment {
+	var commDs market.BatchComputeDataCommitmentReturn
+	ret, code := rt.Send(
+		builtin.StorageMarketActorAddr,
+		builtin.MethodsMarket.BatchComputeDataCommitment,
+		&market.BatchComputeDataCommitmentParams{Inputs: inputs},
+		abi.NewTokenAmount(0),
+	)
+	builtin.RequireSuccess(rt, code, "failed to compute unsealed sector CIDs")
+	AssertNoError(ret.Into(&commDs))
+	AssertMsg(len(commDs.Sectors) == len(inputs), "expected %d unsealed sector CIDs, got %d", len(inputs), len(commDs.Sectors))
+	return commDs.Sectors
 }
 
 // Requests the storage market actor compute the unsealed sector CID from a sector's deals.
-func requestUnsealedSectorCID(rt Runtime, proofType abi.RegisteredProof, dealIDs []abi.DealID) cid.Cid {
+// Returns the exit code of the request, with the CID only if it succeeded.
+func requestUnsealedSectorCID(rt Runtime, proofType abi.RegisteredProof, dealIDs []abi.DealID) (cid.Cid, exitcode.ExitCode) {
 	var unsealedCID cbg.CborCid
 	ret, code := rt.Send(
 		builtin.StorageMarketActorAddr,
@@ -1482,9 +2407,129 @@ func requestUnsealedSectorCID(rt Runtime, proofType abi.RegisteredProof, dealIDs
 		},
 		abi.NewTokenAmount(0),
 	)
-	builtin.RequireSuccess(rt, code, "failed request for unsealed sector CID for deals %v", dealIDs)
+	if !code.IsSuccess() {
+		return cid.Undef, code
+	}
 	AssertNoError(ret.Into(&unsealedCID))
-	return cid.Cid(unsealedCID)
+	return cid.Cid(unsealedCID), code
+}
+
+// Sets the rewards a sector is projected to earn, given the share of the per-epoch block reward its power is
+// projected to earn.
+func setExpectedRewards(sector *SectorOnChainInfo, expectedEpochReward abi.TokenAmount) {
+	sector.ExpectedDayReward = big.Mul(expectedEpochReward, big.NewInt(EpochsInDay))
+	sector.ExpectedStoragePledge = big.Mul(expectedEpochReward, big.NewInt(int64(StoragePledgeProjectionPeriod)))
+}
+
+// Records a newly proven sector in state and removes its pre-commitment.
+func activateSector(rt Runtime, st *State, store adt.Store, precommit *SectorPreCommitOnChainInfo, dealWeight, verifiedDealWeight abi.DealWeight,
+	initialPledge, expectedEpochReward abi.TokenAmount) {
+	sectorNo := precommit.Info.SectorNumber
+	newSectorInfo := &SectorOnChainInfo{
+		Info:               precommit.Info,
+		ActivationEpoch:    rt.CurrEpoch(),
+		DealWeight:         dealWeight,
+		VerifiedDealWeight: verifiedDealWeight,
+		InitialPledge:      initialPledge,
+	}
+	setExpectedRewards(newSectorInfo, expectedEpochReward)
+
+	if err := st.PutSector(store, newSectorInfo); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to prove commit: %v", err)
+	}
+
+	if err := st.DeletePrecommittedSector(store, sectorNo); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to delete precommit for sector %v: %v", sectorNo, err)
+	}
+
+	// Add to new sectors, a staging ground before scheduling to a deadline at end of proving period.
+	if err := st.AddNewSectors(sectorNo); err != nil {
+		rt.Abortf(exitcode.ErrIllegalState, "failed to add new sector number %v: %v", sectorNo, err)
+	}
+}
+
+// Replaces the committed-capacity sector named by a newly proven sector, if it may still be replaced.
+// The replaced sector is rescheduled to expire, without penalty, at the end of the current proving period,
+// and its pledge moves to the new sector.
+// Returns the new sector's initial pledge, and the amount of it that must be newly locked.
+// If the sector can no longer be replaced, these are both the new sector's own pledge requirement.
+//
+// A replacement never shortens the committed lifetime: checkReplaceable rejects one that expires before the
+// sector it replaces, both at pre-commit and here (the replaced sector's expiration may have been extended since).
+//
+// Both sectors hold power until the end of the current proving period. The replaced sector remains in its
+// partition and must still be proven by Window PoSt until it expires, while the new sector's power is claimed
+// when it is proven. The overlap is bounded by one proving period and is covered by the replaced sector's
+// continued proofs; removing its power early would leave its partition's power out of step with its sectors.
+func replaceCapacitySector(rt Runtime, st *State, store adt.Store, info *SectorPreCommitInfo, initialPledge abi.TokenAmount) (abi.TokenAmount, abi.TokenAmount) {
+	replaced, found, err := st.GetSector(store, info.ReplaceSector)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector %v", info.ReplaceSector)
+	if !found || checkReplaceable(rt, st, store, replaced, info) != nil {
+		return initialPledge, initialPledge
+	}
+
+	deadline, _ := st.DeadlineInfo(rt.CurrEpoch())
+	movedPledge := replaced.InitialPledge
+	updated := *replaced
+	updated.Info.Expiration = deadline.PeriodEnd()
+	updated.InitialPledge = big.Zero()
+	err = st.PutSector(store, &updated)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update replaced sector %v", info.ReplaceSector)
+
+	// A sector not yet assigned to a partition is scheduled when it is assigned.
+	dlIdx, partIdx, assigned, err := st.FindSector(store, info.ReplaceSector)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to find sector %v", info.ReplaceSector)
+	if assigned {
+		dl, err := st.LoadDeadline(store, dlIdx)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", dlIdx)
+		partition, err := dl.LoadPartition(store, partIdx)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partition %d at deadline %d", partIdx, dlIdx)
+
+		_, err = partition.RemoveSectors(store, st.Info.SectorSize, replaced)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to remove sector %v expiration", info.ReplaceSector)
+		err = partition.AddSectors(store, st.Info.SectorSize, &updated)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to reschedule sector %v expiration", info.ReplaceSector)
+
+		err = dl.SavePartition(store, partIdx, partition)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store partition %d at deadline %d", partIdx, dlIdx)
+		err = st.SaveDeadline(store, dlIdx, dl)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d", dlIdx)
+	}
+
+	return big.Max(initialPledge, movedPledge), big.Max(big.Sub(initialPledge, movedPledge), big.Zero())
+}
+
+// Checks that a sector may be replaced by a (pre-committed) sector, returning an error describing why not otherwise.
+// The replaced sector must have no deals, not be faulty, not be due to expire in the current proving period,
+// and not outlive its replacement.
+func checkReplaceable(rt Runtime, st *State, store adt.Store, replaced *SectorOnChainInfo, info *SectorPreCommitInfo) error {
+	if len(replaced.Info.DealIDs) > 0 {
+		return xerrors.Errorf("sector %v is not committed capacity, it has %d deals", info.ReplaceSector, len(replaced.Info.DealIDs))
+	}
+
+	dlIdx, partIdx, assigned, err := st.FindSector(store, info.ReplaceSector)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to find sector %v", info.ReplaceSector)
+	if assigned {
+		dl, err := st.LoadDeadline(store, dlIdx)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", dlIdx)
+		partition, err := dl.LoadPartition(store, partIdx)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partition %d at deadline %d", partIdx, dlIdx)
+		faulty, err := partition.Faults.IsSet(uint64(info.ReplaceSector))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check faults")
+		if faulty {
+			return xerrors.Errorf("sector %v is faulty", info.ReplaceSector)
+		}
+	}
+
+	deadline, _ := st.DeadlineInfo(rt.CurrEpoch())
+	if replaced.Info.Expiration <= deadline.PeriodEnd() {
+		return xerrors.Errorf("sector %v already expires at %v, in the current proving period", info.ReplaceSector, replaced.Info.Expiration)
+	}
+
+	if replaced.Info.Expiration > info.Expiration {
+		return xerrors.Errorf("sector %v expiration %v is after its replacement's %v", info.ReplaceSector, replaced.Info.Expiration, info.Expiration)
+	}
+	return nil
 }
 
 func commitWorkerKeyChange(rt Runtime) *adt.EmptyValue {
@@ -1529,6 +2574,29 @@ func resolveOwnerAddress(rt Runtime, raw addr.Address) addr.Address {
 	return resolved
 }
 
+// Resolves an address to an ID address and verifies that it is address of an account or multisig actor.
+func resolveControlAddress(rt Runtime, raw addr.Address) addr.Address {
+	resolved, ok := rt.ResolveAddress(raw)
+	if !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "unable to resolve address %v", raw)
+	}
+	Assert(resolved.Protocol() == addr.ID)
+
+	controlCode, ok := rt.GetActorCodeCID(resolved)
+	if !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no code for address %v", resolved)
+	}
+	if !builtin.IsPrincipal(controlCode) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "control actor type must be a principal, was %v", controlCode)
+	}
+	return resolved
+}
+
+// The addresses that may submit Window PoSts and commit sectors: the worker itself, and the control addresses.
+func workerAndControlAddresses(info *MinerInfo) []addr.Address {
+	return append([]addr.Address{info.Worker}, info.ControlAddresses...)
+}
+
 // Resolves an address to an ID address and verifies that it is address of an account actor with an associated BLS key.
 // The worker must be BLS since the worker key will be used alongside a BLS-VRF.
 func resolveWorkerAddress(rt Runtime, raw addr.Address) addr.Address {
@@ -1561,6 +2629,22 @@ func resolveWorkerAddress(rt Runtime, raw addr.Address) addr.Address {
 	return resolved
 }
 
+// Aborts if the miner has been slashed for a consensus fault, after which its state retains only the fault record.
+func requireNotSlashed(rt Runtime, st *State) {
+	if st.ConsensusFault != nil {
+		rt.Abortf(exitcode.ErrForbidden, "miner slashed for consensus fault at epoch %v", st.ConsensusFault.FaultEpoch)
+	}
+}
+
+// Aborts if the miner has fee debt that could not be repaid.
+func requireNoFeeDebt(rt Runtime, st *State) {
+	if !st.FeeDebt.IsZero() {
+		rt.Abortf(exitcode.ErrInsufficientFunds, "unpaid fee debt %v", st.FeeDebt)
+	}
+}
+
+// Burns a penalty unlocked from the miner's locked funds and reports its removal from the network's total pledge.
+// The penalty may be drawn from locked rewards as well as pledge collateral; both count as pledge (see AddLockedFund).
 func burnFundsAndNotifyPledgeChange(rt Runtime, amt abi.TokenAmount) {
 	burnFunds(rt, amt)
 	notifyPledgeChanged(rt, amt.Neg())
@@ -1573,6 +2657,7 @@ func burnFunds(rt Runtime, amt abi.TokenAmount) {
 	}
 }
 
+// Reports a change in the miner's total locked funds, including locked rewards, to the power actor.
 func notifyPledgeChanged(rt Runtime, pledgeDelta abi.TokenAmount) {
 	if !pledgeDelta.IsZero() {
 		_, code := rt.Send(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero())
@@ -1605,40 +2690,80 @@ func assignProvingPeriodBoundary(myAddr addr.Address, currEpoch abi.ChainEpoch,
 	return abi.ChainEpoch(ppBoundary), nil
 }
 
-// Checks that a fault or recovery declaration of sectors at a specific deadline is valid and not within
-// the exclusion window for the deadline.
-func validateFRDeclaration(deadlines *Deadlines, deadline *DeadlineInfo, declaredDeadline uint64, declaredSectors *abi.BitField) error {
+// Loads the partition named by a fault or recovery declaration, checking that the declaration is not within the
+// exclusion window for the deadline, and that the declared sectors are all due in the partition.
+func loadFRDeclarationPartition(rt Runtime, st *State, store adt.Store, deadline *DeadlineInfo, declaredDeadline, declaredPartition uint64,
+	declaredSectors *abi.BitField) (*Deadline, *Partition) {
 	if declaredDeadline >= WPoStPeriodDeadlines {
-		return fmt.Errorf("invalid deadline %d, must be < %d", declaredDeadline, WPoStPeriodDeadlines)
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid deadline %d, must be < %d", declaredDeadline, WPoStPeriodDeadlines)
 	}
 
-	// Check that either this declaration is before the fault declaration cutoff for this deadline, or the deadline
-	// has passed (in which case the declaration is for the subsequent proving period)
-	if deadline.FaultCutoffPassed() && !deadline.HasElapsed() {
-		return fmt.Errorf("late fault declaration at %v", deadline)
+	// Check that this declaration is before the fault declaration cutoff for the declared deadline.
+	// A declaration for a deadline that has passed in the current proving period is for the subsequent one.
+	declared := NewDeadlineInfo(deadline.PeriodStart, declaredDeadline, deadline.CurrentEpoch)
+	if declared.HasElapsed() {
+		declared = NewDeadlineInfo(deadline.NextPeriodStart(), declaredDeadline, deadline.CurrentEpoch)
+	}
+	if declared.FaultCutoffPassed() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "late fault declaration for deadline %d at epoch %d, cutoff %d",
+			declaredDeadline, declared.CurrentEpoch, declared.FaultCutoff)
 	}
 
-	// Check that the declared sectors are actually due at the deadline.
-	deadlineSectors := deadlines.Due[declaredDeadline]
-	contains, err := abi.BitFieldContainsAll(deadlineSectors, declaredSectors)
-	if err != nil {
-		return fmt.Errorf("failed to check sectors at deadline: %w", err)
+	dl, err := st.LoadDeadline(store, declaredDeadline)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", declaredDeadline)
+	partitionCount, err := dl.PartitionCount(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to count partitions at deadline %d", declaredDeadline)
+	if declaredPartition >= partitionCount {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid partition %d at deadline %d with %d partitions",
+			declaredPartition, declaredDeadline, partitionCount)
 	}
+	partition, err := dl.LoadPartition(store, declaredPartition)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partition %d at deadline %d", declaredPartition, declaredDeadline)
+
+	// Check that the declared sectors are actually due at the partition.
+	contains, err := abi.BitFieldContainsAll(partition.Sectors, declaredSectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check sectors at partition")
 	if !contains {
-		return fmt.Errorf("sectors not all due at deadline %d", declaredDeadline)
+		rt.Abortf(exitcode.ErrIllegalArgument, "sectors not all due at deadline %d partition %d", declaredDeadline, declaredPartition)
 	}
-	return nil
+	return dl, partition
+}
+
+func saveFRDeclarationPartition(rt Runtime, st *State, store adt.Store, dlIdx uint64, dl *Deadline, partIdx uint64, partition *Partition) {
+	err := dl.SavePartition(store, partIdx, partition)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store partition %d at deadline %d", partIdx, dlIdx)
+	err = st.SaveDeadline(store, dlIdx, dl)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d", dlIdx)
 }
 
-// Computes a fee for a collection of sectors and unlocks it from unvested funds (for burning).
-// The fee computation is a parameter.
-func unlockPenalty(st *State, store adt.Store, currEpoch abi.ChainEpoch, sectors []*SectorOnChainInfo,
+// Computes a fee for a collection of sectors and unlocks it from unvested funds (for burning),
+// recording the amount unlocked in the penalty ledger. The fee computation is a parameter.
+// Any part of the fee exceeding the unvested funds is added to the miner's fee debt.
+func unlockPenalty(st *State, store adt.Store, currEpoch abi.ChainEpoch, cause PenaltyCause, sectors []*SectorOnChainInfo,
 	feeCalc func(info *SectorOnChainInfo) abi.TokenAmount) (abi.TokenAmount, error) {
-	fee := big.Zero()
+	fee := totalPenalty(sectors, feeCalc)
+	sectorNos := make([]uint64, len(sectors))
+	for i, s := range sectors {
+		sectorNos[i] = uint64(s.Info.SectorNumber)
+	}
+	unlocked, err := st.UnlockUnvestedFunds(store, currEpoch, fee)
+	if err != nil {
+		return big.Zero(), err
+	}
+	st.AddFeeDebt(big.Sub(fee, unlocked))
+	if err = st.RecordPenalty(store, currEpoch, cause, bitfield.NewFromSet(sectorNos), unlocked); err != nil {
+		return big.Zero(), fmt.Errorf("failed to record penalty: %w", err)
+	}
+	return unlocked, nil
+}
+
+// Sums the penalty for each of some sectors.
+func totalPenalty(sectors []*SectorOnChainInfo, feeCalc func(info *SectorOnChainInfo) abi.TokenAmount) abi.TokenAmount {
+	total := big.Zero()
 	for _, s := range sectors {
-		fee = big.Add(fee, feeCalc(s))
+		total = big.Add(total, feeCalc(s))
 	}
-	return st.UnlockUnvestedFunds(store, currEpoch, fee)
+	return total
 }
 
 func min64(a, b uint64) uint64 {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/abi/big"
 	tutils "github.com/filecoin-project/specs-actors/support/testing"
 )
 
@@ -36,3 +37,13 @@ func TestAssignProvingPeriodBoundary(t *testing.T) {
 		assert.True(t, boundary < WPoStProvingPeriod)
 	}
 }
+
+func TestPrecommitDepositRefund(t *testing.T) {
+	deposit := abi.NewTokenAmount(1000)
+	msd := abi.ChainEpoch(100)
+
+	assert.Equal(t, deposit, precommitDepositRefund(deposit, 0, msd))
+	assert.Equal(t, abi.NewTokenAmount(750), precommitDepositRefund(deposit, 25, msd))
+	assert.Equal(t, abi.NewTokenAmount(10), precommitDepositRefund(deposit, 99, msd))
+	assert.Equal(t, big.Zero(), precommitDepositRefund(deposit, msd, msd))
+}
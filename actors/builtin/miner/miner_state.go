@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
@@ -15,13 +16,16 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
 	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
 	. "github.com/filecoin-project/specs-actors/actors/util"
 	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
 )
 
 // Balance of Miner Actor should be greater than or equal to
-// the sum of PreCommitDeposits and LockedFunds.
+// the sum of PreCommitDeposits, LockedFunds and LockedRewards.
+// Penalties exceeding the miner's funds are recorded as FeeDebt, which is repaid before the balance may be
+// otherwise used.
 // Excess balance as computed by st.GetAvailableBalance will be
 // withdrawable or usable for pre-commit deposit or pledge lock-up.
 type State struct {
@@ -32,6 +36,9 @@ type State struct {
 	PreCommitDeposits abi.TokenAmount // Total funds locked as PreCommitDeposits
 	LockedFunds       abi.TokenAmount // Total unvested funds locked as pledge collateral
 	VestingFunds      cid.Cid         // Array, AMT[ChainEpoch]TokenAmount
+	LockedRewards     abi.TokenAmount // Total unvested block rewards, drawn on for penalties before pledge collateral
+	VestingRewards    cid.Cid         // Array, AMT[ChainEpoch]TokenAmount
+	FeeDebt           abi.TokenAmount // Penalties not yet paid, to be repaid from vesting funds and rewards
 
 	// Sectors that have been pre-committed but not yet proven.
 	PreCommittedSectors cid.Cid // Map, HAMT[SectorNumber]SectorPreCommitOnChainInfo
@@ -39,35 +46,36 @@ type State struct {
 	// Information for all proven and not-yet-expired sectors.
 	Sectors cid.Cid // Array, AMT[SectorNumber]SectorOnChainInfo (sparse)
 
-	// Sector numbers prove-committed since period start, to be added to Deadlines at next proving period boundary.
-	NewSectors *abi.BitField
+	// Sector numbers pre-committed or otherwise claimed by the miner, which may never be used again.
+	// Invariant: Keys(PreCommittedSectors) ⊆ AllocatedSectors, Keys(Sectors) ⊆ AllocatedSectors
+	AllocatedSectors *abi.BitField
 
-	// Sector numbers indexed by expiry epoch (which are on proving period boundaries).
-	// Invariant: Keys(Sectors) == union(SectorExpirations.Values())
-	SectorExpirations cid.Cid // Array, AMT[ChainEpoch]Bitfield
+	// Sector numbers prove-committed since period start, to be assigned to deadlines at next proving period boundary.
+	// Invariant: NewSectors is disjoint from the sectors of every partition.
+	NewSectors *abi.BitField
 
-	// The sector numbers due for PoSt at each deadline in the current proving period, frozen at period start.
+	// The partitions of sectors due for PoSt at each deadline, with their faults, recoveries and expirations.
 	// New sectors are added and expired ones removed at proving period boundary.
-	// Faults are not subtracted from this in state, but on the fly.
-	Deadlines cid.Cid
-
-	// All currently known faulty sectors, mutated eagerly.
-	// These sectors are exempt from inclusion in PoSt.
-	Faults *abi.BitField
-
-	// Faulty sector numbers indexed by the start epoch of the proving period in which detected.
-	// Used to track fault durations for eventual sector termination.
-	// At most 14 entries, b/c sectors faulty longer expire.
-	// Invariant: Faults == union(FaultEpochs.Values())
-	FaultEpochs cid.Cid // AMT[ChainEpoch]Bitfield
-
-	// Faulty sectors that will recover when next included in a valid PoSt.
-	// Invariant: Recoveries ⊆ Faults.
-	Recoveries *abi.BitField
-
-	// Records successful PoSt submission in the current proving period by partition number.
-	// The presence of a partition number indicates on-time PoSt received.
-	PostSubmissions *abi.BitField
+	// Invariant: Keys(Sectors) == union(NewSectors, Deadlines.Partitions.Sectors)
+	Deadlines cid.Cid // Array, AMT[DeadlineIndex]Deadline (sparse, absent deadlines are empty)
+
+	// The deadline and partition at which each sector in Deadlines is due, so that a sector may be found without
+	// visiting every partition.
+	// Invariant: Keys(SectorLocations) == union(Deadlines.Partitions.Sectors), each at its partition.
+	SectorLocations cid.Cid // Array, AMT[SectorNumber]SectorLocation
+
+	// Penalties burnt from the miner's funds, indexed by the epoch at which they were incurred.
+	PenaltyLedger cid.Cid // Array, AMT[ChainEpoch]PenaltyRecords
+
+	// Window PoSts accepted without verification, retained so that they may be disputed.
+	// Snapshots are discarded at the first proving period end after their dispute window closes, or upon the first
+	// submission for the same deadline in a later proving period.
+	PoStSnapshots cid.Cid // Array, AMT[DeadlineIndex]WindowedPoStSnapshots
+
+	// The consensus fault for which the miner was slashed, if any, retained so that later reports are rejected.
+	// Once set, the miner's collateral has been forfeit and its deals terminated, all other sector and fund state
+	// except the penalty ledger is discarded, and every method that would change the miner's state is rejected.
+	ConsensusFault *ConsensusFaultRecord
 }
 
 type MinerInfo struct {
@@ -76,15 +84,26 @@ type MinerInfo struct {
 	// - This address is also allowed to change the worker address for the miner.
 	Owner addr.Address // Must be an ID-address.
 
+	// A new owner proposed by the current owner, which becomes the owner once it confirms the change.
+	PendingOwnerChange *OwnerChange
+
 	// Worker account for this miner.
 	// The associated pubkey-type address is used to sign blocks and messages on behalf of this miner.
 	Worker addr.Address // Must be an ID-address.
 
 	PendingWorkerKey *WorkerKeyChange
 
+	// Additional addresses, set by the owner, that may submit Window PoSts and pre-commit and prove sectors in place
+	// of the worker, so that the worker key may be dedicated to signing blocks.
+	// Other messages, such as fault declarations and terminations, remain with the worker.
+	ControlAddresses []addr.Address // Must all be ID-addresses.
+
 	// Libp2p identity that should be used when connecting to this miner.
 	PeerId peer.ID
 
+	// Serialized multiaddrs at which this miner may be dialed, so clients need not look them up separately.
+	Multiaddrs [][]byte
+
 	// Amount of space in each sector committed to the network by this miner.
 	SectorSize abi.SectorSize
 
@@ -96,6 +115,10 @@ type MinerInfo struct {
 
 type PeerID peer.ID
 
+type OwnerChange struct {
+	NewOwner addr.Address // Must be an ID address
+}
+
 type WorkerKeyChange struct {
 	NewWorker   addr.Address // Must be an ID address
 	EffectiveAt abi.ChainEpoch
@@ -108,6 +131,9 @@ type SectorPreCommitInfo struct {
 	SealRandEpoch   abi.ChainEpoch
 	DealIDs         []abi.DealID
 	Expiration      abi.ChainEpoch // Sector Expiration
+	// Whether to replace a committed-capacity sector (one with no deals) with this one, which must have deals.
+	ReplaceCapacity bool
+	ReplaceSector   abi.SectorNumber // The committed-capacity sector to replace, if ReplaceCapacity.
 }
 
 type SectorPreCommitOnChainInfo struct {
@@ -116,21 +142,104 @@ type SectorPreCommitOnChainInfo struct {
 	PreCommitEpoch   abi.ChainEpoch
 }
 
+// The reason for which a penalty was burnt from a miner's funds.
+type PenaltyCause int64
+
+const (
+	PenaltyUndeclaredFault    PenaltyCause = iota // Sectors detected faulty by a missing PoSt, or failing to recover
+	PenaltyDeclaredFault                          // Sectors declared faulty, or remaining faulty at a proving period end
+	PenaltyTermination                            // Sectors terminated before their expiration
+	PenaltyConsensusFault                         // A consensus fault, forfeiting the miner's balance
+	PenaltyPreCommitExpiry                        // Pre-committed sectors not proven in time, forfeiting their deposit
+	PenaltyInvalidPoSt                            // Sectors proven by a Window PoSt that was successfully disputed
+	PenaltyPreCommitWithdrawn                     // Pre-committed sectors withdrawn, forfeiting the unrefunded deposit
+)
+
+// A single penalty burnt from a miner's funds.
+type PenaltyRecord struct {
+	Cause   PenaltyCause
+	Sectors *abi.BitField // The sectors penalized, empty for a consensus fault.
+	Amount  abi.TokenAmount
+}
+
+// The penalties incurred at a single epoch, in the order they were incurred.
+type PenaltyRecords struct {
+	Records []PenaltyRecord
+}
+
+// A consensus fault for which a miner was slashed.
+type ConsensusFaultRecord struct {
+	FaultEpoch  abi.ChainEpoch // Epoch of the fault, the higher epoch of the blocks causing it.
+	ReportEpoch abi.ChainEpoch // Epoch at which the fault was reported, and the miner slashed.
+	Reporter    addr.Address
+}
+
+// A Window PoSt accepted without verification, retained for a dispute window after its submission.
+type WindowedPoStSnapshot struct {
+	SubmissionEpoch abi.ChainEpoch
+	ChallengeEpoch  abi.ChainEpoch // Epoch at which the chain was sampled for the challenge.
+	Partitions      []uint64       // The indices of the partitions proven, within the deadline.
+	Proofs          []abi.PoStProof
+	Sectors         []abi.SectorInfo // The sectors challenged, with faulty sectors replaced by a non-faulty stand-in.
+	Proven          *abi.BitField    // The non-faulty sectors (including recoveries) proven by the submission.
+	Disputed        bool             // Whether the proof has been successfully disputed, after which it is discarded.
+}
+
+// The Window PoSts accepted for a deadline, in the order they were submitted.
+type WindowedPoStSnapshots struct {
+	Snapshots []WindowedPoStSnapshot
+}
+
 type SectorOnChainInfo struct {
 	Info               SectorPreCommitInfo
-	ActivationEpoch    abi.ChainEpoch // Epoch at which SectorProveCommit is accepted
-	DealWeight         abi.DealWeight // Integral of active deals over sector lifetime
-	VerifiedDealWeight abi.DealWeight // Integral of active verified deals over sector lifetime
+	ActivationEpoch    abi.ChainEpoch  // Epoch at which SectorProveCommit is accepted
+	DealWeight         abi.DealWeight  // Integral of active deals over sector lifetime
+	VerifiedDealWeight abi.DealWeight  // Integral of active verified deals over sector lifetime
+	InitialPledge      abi.TokenAmount // Pledge collateral committed for the sector, or zero once moved to a replacement
+	// Projected reward the sector earns per day, given the network's power and reward at activation.
+	ExpectedDayReward abi.TokenAmount
+	// Projected reward over StoragePledgeProjectionPeriod at activation, forfeit upon early termination.
+	ExpectedStoragePledge abi.TokenAmount
+}
+
+// The deadline and partition at which a sector is due for Window PoSt.
+type SectorLocation struct {
+	Deadline  uint64
+	Partition uint64
+}
+
+type SectorStatusCode int64
+
+const (
+	SectorStatusPreCommitted SectorStatusCode = iota // Pre-committed, awaiting proof
+	SectorStatusActive                               // Proven, and not faulty
+	SectorStatusFaulty                               // Proven, but faulty and not declared recovering
+	SectorStatusRecovering                           // Faulty, but declared recovering
+)
+
+// The lifecycle status of a sector, and where it is due for Window PoSt.
+type SectorStatus struct {
+	SectorNumber abi.SectorNumber
+	Status       SectorStatusCode
+	Expiration   abi.ChainEpoch // Epoch at which the sector expires (or would, if proven).
+	// Whether the sector is assigned to a deadline and partition. New sectors are assigned at the end of the
+	// proving period in which they are proven, and pre-committed sectors are never assigned.
+	Assigned  bool
+	Deadline  uint64 // Deadline index, if assigned
+	Partition uint64 // Partition index within the deadline, if assigned
 }
 
-func ConstructState(emptyArrayCid, emptyMapCid, emptyDeadlinesCid cid.Cid, ownerAddr, workerAddr addr.Address,
+func ConstructState(emptyArrayCid, emptyMapCid cid.Cid, ownerAddr, workerAddr addr.Address,
 	peerId peer.ID, sectorSize abi.SectorSize, periodBoundary abi.ChainEpoch) *State {
 	return &State{
 		Info: MinerInfo{
 			Owner:                 ownerAddr,
+			PendingOwnerChange:    nil,
 			Worker:                workerAddr,
 			PendingWorkerKey:      nil,
+			ControlAddresses:      nil,
 			PeerId:                peerId,
+			Multiaddrs:            nil,
 			SectorSize:            sectorSize,
 			ProvingPeriodBoundary: periodBoundary,
 		},
@@ -138,19 +247,27 @@ func ConstructState(emptyArrayCid, emptyMapCid, emptyDeadlinesCid cid.Cid, owner
 		PreCommitDeposits: abi.NewTokenAmount(0),
 		LockedFunds:       abi.NewTokenAmount(0),
 		VestingFunds:      emptyArrayCid,
+		LockedRewards:     abi.NewTokenAmount(0),
+		VestingRewards:    emptyArrayCid,
+		FeeDebt:           abi.NewTokenAmount(0),
 
 		PreCommittedSectors: emptyMapCid,
 		Sectors:             emptyArrayCid,
+		AllocatedSectors:    abi.NewBitField(),
 		NewSectors:          abi.NewBitField(),
-		SectorExpirations:   emptyArrayCid,
-		Deadlines:           emptyDeadlinesCid,
-		Faults:              abi.NewBitField(),
-		FaultEpochs:         emptyArrayCid,
-		Recoveries:          abi.NewBitField(),
-		PostSubmissions:     abi.NewBitField(),
+		Deadlines:           emptyArrayCid,
+		SectorLocations:     emptyArrayCid,
+		PenaltyLedger:       emptyArrayCid,
+		PoStSnapshots:       emptyArrayCid,
+		ConsensusFault:      nil,
 	}
 }
 
+// Returns the miner's information, including the addresses at which it may be dialed.
+func (st *State) GetInfo() *MinerInfo {
+	return &st.Info
+}
+
 func (st *State) GetWorker() addr.Address {
 	return st.Info.Worker
 }
@@ -165,29 +282,21 @@ func (st *State) DeadlineInfo(currEpoch abi.ChainEpoch) (*DeadlineInfo, bool) {
 }
 
 func (st *State) GetSectorCount(store adt.Store) (uint64, error) {
-	arr, err := adt.AsArray(store, st.Sectors)
+	sectors, err := AsSectorOnChainInfoArray(store, st.Sectors)
 	if err != nil {
 		return 0, err
 	}
 
-	return arr.Length(), nil
-}
-
-func (st *State) GetMaxAllowedFaults(store adt.Store) (uint64, error) {
-	sectorCount, err := st.GetSectorCount(store)
-	if err != nil {
-		return 0, err
-	}
-	return 2 * sectorCount, nil
+	return sectors.Length(), nil
 }
 
 func (st *State) PutPrecommittedSector(store adt.Store, info *SectorPreCommitOnChainInfo) error {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors)
+	precommitted, err := AsSectorPreCommitOnChainInfoMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return err
 	}
 
-	err = precommitted.Put(SectorKey(info.Info.SectorNumber), info)
+	err = precommitted.Put(info.Info.SectorNumber, info)
 	if err != nil {
 		return errors.Wrapf(err, "failed to store precommitment for %v", info)
 	}
@@ -196,26 +305,25 @@ func (st *State) PutPrecommittedSector(store adt.Store, info *SectorPreCommitOnC
 }
 
 func (st *State) GetPrecommittedSector(store adt.Store, sectorNo abi.SectorNumber) (*SectorPreCommitOnChainInfo, bool, error) {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors)
+	precommitted, err := AsSectorPreCommitOnChainInfoMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return nil, false, err
 	}
 
-	var info SectorPreCommitOnChainInfo
-	found, err := precommitted.Get(SectorKey(sectorNo), &info)
+	info, found, err := precommitted.Get(sectorNo)
 	if err != nil {
 		return nil, false, errors.Wrapf(err, "failed to load precommitment for %v", sectorNo)
 	}
-	return &info, found, nil
+	return info, found, nil
 }
 
 func (st *State) DeletePrecommittedSector(store adt.Store, sectorNo abi.SectorNumber) error {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors)
+	precommitted, err := AsSectorPreCommitOnChainInfoMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return err
 	}
 
-	err = precommitted.Delete(SectorKey(sectorNo))
+	err = precommitted.Delete(sectorNo)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete precommitment for %v", sectorNo)
 	}
@@ -224,26 +332,44 @@ func (st *State) DeletePrecommittedSector(store adt.Store, sectorNo abi.SectorNu
 }
 
 func (st *State) HasSectorNo(store adt.Store, sectorNo abi.SectorNumber) (bool, error) {
-	sectors, err := adt.AsArray(store, st.Sectors)
+	sectors, err := AsSectorOnChainInfoArray(store, st.Sectors)
 	if err != nil {
 		return false, err
 	}
 
-	var info SectorOnChainInfo
-	found, err := sectors.Get(uint64(sectorNo), &info)
+	_, found, err := sectors.Get(sectorNo)
 	if err != nil {
 		return false, xerrors.Errorf("failed to get sector %v: %w", sectorNo, err)
 	}
 	return found, nil
 }
 
+// Records a sector number as allocated, failing if it already is.
+func (st *State) AllocateSectorNumber(sectorNo abi.SectorNumber) error {
+	allocated, err := st.AllocatedSectors.IsSet(uint64(sectorNo))
+	if err != nil {
+		return xerrors.Errorf("failed to check allocated sector number %v: %w", sectorNo, err)
+	}
+	if allocated {
+		return xerrors.Errorf("sector number %v already allocated", sectorNo)
+	}
+	st.AllocatedSectors, err = bitfield.MergeBitFields(st.AllocatedSectors, bitfield.NewFromSet([]uint64{uint64(sectorNo)}))
+	return err
+}
+
+// Records sector numbers as allocated, whether or not they already are.
+func (st *State) MaskSectorNumbers(sectorNos *abi.BitField) (err error) {
+	st.AllocatedSectors, err = bitfield.MergeBitFields(st.AllocatedSectors, sectorNos)
+	return err
+}
+
 func (st *State) PutSector(store adt.Store, sector *SectorOnChainInfo) error {
-	sectors, err := adt.AsArray(store, st.Sectors)
+	sectors, err := AsSectorOnChainInfoArray(store, st.Sectors)
 	if err != nil {
 		return err
 	}
 
-	if err := sectors.Set(uint64(sector.Info.SectorNumber), sector); err != nil {
+	if err := sectors.Set(sector.Info.SectorNumber, sector); err != nil {
 		return errors.Wrapf(err, "failed to put sector %v", sector)
 	}
 	st.Sectors, err = sectors.Root()
@@ -251,26 +377,25 @@ func (st *State) PutSector(store adt.Store, sector *SectorOnChainInfo) error {
 }
 
 func (st *State) GetSector(store adt.Store, sectorNo abi.SectorNumber) (*SectorOnChainInfo, bool, error) {
-	sectors, err := adt.AsArray(store, st.Sectors)
+	sectors, err := AsSectorOnChainInfoArray(store, st.Sectors)
 	if err != nil {
 		return nil, false, err
 	}
 
-	var info SectorOnChainInfo
-	found, err := sectors.Get(uint64(sectorNo), &info)
+	info, found, err := sectors.Get(sectorNo)
 	if err != nil {
 		return nil, false, errors.Wrapf(err, "failed to get sector %v", sectorNo)
 	}
-	return &info, found, nil
+	return info, found, nil
 }
 
 func (st *State) DeleteSectors(store adt.Store, sectorNos *abi.BitField) error {
-	sectors, err := adt.AsArray(store, st.Sectors)
+	sectors, err := AsSectorOnChainInfoArray(store, st.Sectors)
 	if err != nil {
 		return err
 	}
 	err = sectorNos.ForEach(func(sectorNo uint64) error {
-		if err = sectors.Delete(sectorNo); err != nil {
+		if err = sectors.Delete(abi.SectorNumber(sectorNo)); err != nil {
 			return errors.Wrapf(err, "failed to delete sector %v", sectorNos)
 		}
 		return nil
@@ -284,13 +409,12 @@ func (st *State) DeleteSectors(store adt.Store, sectorNos *abi.BitField) error {
 }
 
 func (st *State) ForEachSector(store adt.Store, f func(*SectorOnChainInfo)) error {
-	sectors, err := adt.AsArray(store, st.Sectors)
+	sectors, err := AsSectorOnChainInfoArray(store, st.Sectors)
 	if err != nil {
 		return err
 	}
-	var sector SectorOnChainInfo
-	return sectors.ForEach(&sector, func(idx int64) error {
-		f(&sector)
+	return sectors.ForEach(func(_ abi.SectorNumber, sector *SectorOnChainInfo) error {
+		f(sector)
 		return nil
 	})
 }
@@ -328,435 +452,1105 @@ func (st *State) ClearNewSectors() error {
 	return nil
 }
 
-// Gets the sector numbers expiring at some epoch.
-func (st *State) GetSectorExpirations(store adt.Store, expiry abi.ChainEpoch) (*abi.BitField, error) {
-	arr, err := adt.AsArray(store, st.SectorExpirations)
+// Loads sector info for a sequence of sectors.
+func (st *State) LoadSectorInfos(store adt.Store, sectors *abi.BitField) ([]*SectorOnChainInfo, error) {
+	var sectorInfos []*SectorOnChainInfo
+	err := sectors.ForEach(func(i uint64) error {
+		sectorOnChain, found, err := st.GetSector(store, abi.SectorNumber(i))
+		if err != nil {
+			return fmt.Errorf("failed to load sector %d: %v", i, err)
+		} else if !found {
+			return fmt.Errorf("can't find sector %d", i)
+		}
+		sectorInfos = append(sectorInfos, sectorOnChain)
+		return nil
+	})
+	return sectorInfos, err
+}
+
+// Loads sector info for a sequence of sectors, substituting info for a stand-in sector for any that are faulty.
+func (st *State) LoadSectorInfosWithFaultMask(store adt.Store, sectors *abi.BitField, faults *abi.BitField, faultStandIn abi.SectorNumber) ([]*SectorOnChainInfo, error) {
+	sectorOnChain, found, err := st.GetSector(store, faultStandIn)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to load stand-in sector %d: %v", faultStandIn, err)
+	} else if !found {
+		return nil, fmt.Errorf("can't find stand-in sector %d", faultStandIn)
 	}
+	standInInfo := sectorOnChain
 
-	bf := abi.NewBitField()
-	_, err = arr.Get(uint64(expiry), bf)
+	// Expand faults into a map for quick lookups.
+	// The faults bitfield should already be a subset of the sectors bitfield.
+	faultMax, err := sectors.Count()
 	if err != nil {
 		return nil, err
 	}
-
-	return bf, nil
-}
-
-// Iterates sector expiration groups in order.
-// Note that the sectors bitfield provided to the callback is not safe to store.
-func (st *State) ForEachSectorExpiration(store adt.Store, f func(expiry abi.ChainEpoch, sectors *abi.BitField) error) error {
-	arr, err := adt.AsArray(store, st.SectorExpirations)
+	faultSet, err := faults.AllMap(faultMax)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to expand faults: %w", err)
 	}
 
-	bf := abi.NewBitField()
-	return arr.ForEach(bf, func(i int64) error {
-		return f(abi.ChainEpoch(i), bf)
+	// Load the sector infos, masking out fault sectors with a good one.
+	var sectorInfos []*SectorOnChainInfo
+	err = sectors.ForEach(func(i uint64) error {
+		sector := standInInfo
+		faulty := faultSet[i]
+		if !faulty {
+			sectorOnChain, found, err = st.GetSector(store, abi.SectorNumber(i))
+			if err != nil {
+				return fmt.Errorf("failed to load sector %d: %v", i, err)
+			} else if !found {
+				return fmt.Errorf("can't find sector %d", i)
+			}
+			sector = sectorOnChain
+		}
+		sectorInfos = append(sectorInfos, sector)
+		return nil
 	})
+	return sectorInfos, err
 }
 
-// Adds some sector numbers to the set expiring at an epoch.
-// The sector numbers are given as uint64s to avoid pointless conversions.
-func (st *State) AddSectorExpirations(store adt.Store, expiry abi.ChainEpoch, sectors ...uint64) error {
-	arr, err := adt.AsArray(store, st.SectorExpirations)
-	if err != nil {
-		return err
-	}
-
-	bf := abi.NewBitField()
-	_, err = arr.Get(uint64(expiry), bf)
-	if err != nil {
-		return err
-	}
+//
+// PoSt Deadlines and partitions
+//
 
-	bf, err = bitfield.MergeBitFields(bf, bitfield.NewFromSet(sectors))
+// Loads the deadline at an index, which is empty if never stored.
+func (st *State) LoadDeadline(store adt.Store, dlIdx uint64) (*Deadline, error) {
+	AssertMsg(dlIdx < WPoStPeriodDeadlines, "invalid deadline index %d for %d deadlines", dlIdx, WPoStPeriodDeadlines)
+	deadlines, err := AsDeadlineArray(store, st.Deadlines)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	count, err := bf.Count()
+	dl, found, err := deadlines.Get(dlIdx)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to load deadline %d: %w", dlIdx, err)
 	}
-	if count > SectorsMax {
-		return fmt.Errorf("too many sectors at expiration %d, %d, max %d", expiry, count, SectorsMax)
+	if !found {
+		emptyArray, err := adt.MakeEmptyArray(store).Root()
+		if err != nil {
+			return nil, err
+		}
+		dl = ConstructDeadline(emptyArray)
 	}
+	return dl, nil
+}
 
-	if err = arr.Set(uint64(expiry), bf); err != nil {
+func (st *State) SaveDeadline(store adt.Store, dlIdx uint64, dl *Deadline) error {
+	AssertMsg(dlIdx < WPoStPeriodDeadlines, "invalid deadline index %d for %d deadlines", dlIdx, WPoStPeriodDeadlines)
+	deadlines, err := AsDeadlineArray(store, st.Deadlines)
+	if err != nil {
 		return err
 	}
-
-	st.SectorExpirations, err = arr.Root()
+	if err = deadlines.Set(dlIdx, dl); err != nil {
+		return fmt.Errorf("failed to store deadline %d: %w", dlIdx, err)
+	}
+	st.Deadlines, err = deadlines.Root()
 	return err
 }
 
-// Removes some sector numbers from the set expiring at an epoch.
-func (st *State) RemoveSectorExpirations(store adt.Store, expiry abi.ChainEpoch, sectors ...uint64) error {
-	arr, err := adt.AsArray(store, st.SectorExpirations)
+// Iterates the partitions of every deadline, in order of deadline and then partition index.
+func (st *State) ForEachPartition(store adt.Store, cb func(dlIdx, partIdx uint64, partition *Partition) error) error {
+	deadlines, err := AsDeadlineArray(store, st.Deadlines)
 	if err != nil {
 		return err
 	}
+	return deadlines.ForEach(func(dlIdx uint64, dl *Deadline) error {
+		return dl.ForEachPartition(store, func(partIdx uint64, partition *Partition) error {
+			return cb(dlIdx, partIdx, partition)
+		})
+	})
+}
 
-	bf := abi.NewBitField()
-	_, err = arr.Get(uint64(expiry), bf)
+// Finds the deadline and partition at which a sector is due, returning false if it is not due at any
+// (i.e. it is a new sector not yet assigned to a deadline, or doesn't exist).
+func (st *State) FindSector(store adt.Store, sectorNo abi.SectorNumber) (dlIdx, partIdx uint64, found bool, err error) {
+	locations, err := AsSectorLocationArray(store, st.SectorLocations)
 	if err != nil {
-		return err
+		return 0, 0, false, err
 	}
+	loc, found, err := locations.Get(sectorNo)
+	if err != nil || !found {
+		return 0, 0, false, err
+	}
+	return loc.Deadline, loc.Partition, true, nil
+}
 
-	bf, err = bitfield.SubtractBitField(bf, bitfield.NewFromSet(sectors))
+// Records the deadline and partition at which some sectors are due.
+func (st *State) putSectorLocations(store adt.Store, dlIdx, partIdx uint64, sectors []*SectorOnChainInfo) error {
+	locations, err := AsSectorLocationArray(store, st.SectorLocations)
 	if err != nil {
 		return err
 	}
-
-	if err = arr.Set(uint64(expiry), bf); err != nil {
-		return err
+	for _, sector := range sectors {
+		if err = locations.Set(sector.Info.SectorNumber, &SectorLocation{Deadline: dlIdx, Partition: partIdx}); err != nil {
+			return fmt.Errorf("failed to store location of sector %d: %w", sector.Info.SectorNumber, err)
+		}
 	}
-
-	st.SectorExpirations, err = arr.Root()
+	st.SectorLocations, err = locations.Root()
 	return err
 }
 
-// Removes all sector numbers from the set expiring some epochs.
-func (st *State) ClearSectorExpirations(store adt.Store, expirations ...abi.ChainEpoch) error {
-	arr, err := adt.AsArray(store, st.SectorExpirations)
+// Forgets the locations of some sectors, which are no longer due at any deadline.
+func (st *State) deleteSectorLocations(store adt.Store, sectors []*SectorOnChainInfo) error {
+	locations, err := AsSectorLocationArray(store, st.SectorLocations)
 	if err != nil {
 		return err
 	}
-
-	for _, exp := range expirations {
-		err = arr.Delete(uint64(exp))
-		if err != nil {
-			return err
+	for _, sector := range sectors {
+		if err = locations.Delete(sector.Info.SectorNumber); err != nil {
+			return fmt.Errorf("failed to delete location of sector %d: %w", sector.Info.SectorNumber, err)
 		}
 	}
-
-	st.SectorExpirations, err = arr.Root()
+	st.SectorLocations, err = locations.Root()
 	return err
 }
 
-// Adds sectors numbers to faults and fault epochs.
-func (st *State) AddFaults(store adt.Store, sectorNos *abi.BitField, faultEpoch abi.ChainEpoch) (err error) {
-	empty, err := sectorNos.IsEmpty()
+// Returns the status of a pre-committed or proven sector, or false if there is no such sector.
+func (st *State) GetSectorStatus(store adt.Store, sectorNo abi.SectorNumber) (*SectorStatus, bool, error) {
+	sector, found, err := st.GetSector(store, sectorNo)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
-	if empty {
-		return nil
+	if !found {
+		precommit, found, err := st.GetPrecommittedSector(store, sectorNo)
+		if err != nil || !found {
+			return nil, false, err
+		}
+		return &SectorStatus{
+			SectorNumber: sectorNo,
+			Status:       SectorStatusPreCommitted,
+			Expiration:   precommit.Info.Expiration,
+		}, true, nil
+	}
+
+	status := &SectorStatus{
+		SectorNumber: sectorNo,
+		Status:       SectorStatusActive,
+		Expiration:   sector.Info.Expiration,
+	}
+	dlIdx, partIdx, assigned, err := st.FindSector(store, sectorNo)
+	if err != nil {
+		return nil, false, err
+	}
+	if !assigned {
+		return status, true, nil
+	}
+	status.Assigned, status.Deadline, status.Partition = true, dlIdx, partIdx
+
+	dl, err := st.LoadDeadline(store, dlIdx)
+	if err != nil {
+		return nil, false, err
+	}
+	partition, err := dl.LoadPartition(store, partIdx)
+	if err != nil {
+		return nil, false, err
+	}
+	if recovering, err := partition.Recoveries.IsSet(uint64(sectorNo)); err != nil {
+		return nil, false, err
+	} else if recovering {
+		status.Status = SectorStatusRecovering
+	} else if faulty, err := partition.Faults.IsSet(uint64(sectorNo)); err != nil {
+		return nil, false, err
+	} else if faulty {
+		status.Status = SectorStatusFaulty
 	}
+	return status, true, nil
+}
 
-	{
-		st.Faults, err = bitfield.MergeBitFields(st.Faults, sectorNos)
+// Assigns new sectors to partitions by:
+// - filling any non-full partitions, in order of deadline and then partition index
+// - repeatedly adding a new partition to the deadline with the fewest partitions (the earliest, if several)
+func (st *State) AssignNewSectors(store adt.Store, sectors []*SectorOnChainInfo) error {
+	nextNewSector := uint64(0)
+	remaining := func() uint64 { return uint64(len(sectors)) - nextNewSector }
+
+	// Iterate deadlines and fill any non-full partitions. There's no great advantage to filling more- or less-
+	// full ones first, so they're filled in sequence order.
+	// Meanwhile, record the partition count at each deadline.
+	deadlines := make([]*Deadline, WPoStPeriodDeadlines)
+	partitionCounts := make([]uint64, WPoStPeriodDeadlines)
+	for dlIdx := uint64(0); dlIdx < WPoStPeriodDeadlines; dlIdx++ {
+		dl, err := st.LoadDeadline(store, dlIdx)
 		if err != nil {
 			return err
 		}
+		deadlines[dlIdx] = dl
+		if partitionCounts[dlIdx], err = dl.PartitionCount(store); err != nil {
+			return fmt.Errorf("failed to count partitions at deadline %d: %w", dlIdx, err)
+		}
 
-		count, err := st.Faults.Count()
+		var filledIdxs []uint64
+		var filled []*Partition
+		err = dl.ForEachPartition(store, func(partIdx uint64, partition *Partition) error {
+			sectorCount, err := partition.SectorCount()
+			if err != nil || remaining() == 0 || sectorCount == WPoStPartitionSectors {
+				return err
+			}
+			countToAdd := min64(WPoStPartitionSectors-sectorCount, remaining())
+			toAdd := sectors[nextNewSector : nextNewSector+countToAdd]
+			if err = partition.AddSectors(store, st.Info.SectorSize, toAdd...); err != nil {
+				return fmt.Errorf("failed to add %d sectors to partition %d: %w", countToAdd, partIdx, err)
+			}
+			if err = st.putSectorLocations(store, dlIdx, partIdx, toAdd); err != nil {
+				return err
+			}
+			nextNewSector += countToAdd
+			filledIdxs = append(filledIdxs, partIdx)
+			filled = append(filled, partition)
+			return nil
+		})
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to fill partitions at deadline %d: %w", dlIdx, err)
+		}
+		if len(filled) == 0 {
+			continue
 		}
-		if count > SectorsMax {
-			return fmt.Errorf("too many faults %d, max %d", count, SectorsMax)
+		for i, partIdx := range filledIdxs {
+			if err = dl.SavePartition(store, partIdx, filled[i]); err != nil {
+				return err
+			}
+		}
+		if err = st.SaveDeadline(store, dlIdx, dl); err != nil {
+			return err
 		}
 	}
 
-	{
-		epochFaultArr, err := adt.AsArray(store, st.FaultEpochs)
-		if err != nil {
-			return err
+	// While there remain new sectors to assign, add a new partition to the deadline with fewest partitions.
+	emptyArray, err := adt.MakeEmptyArray(store).Root()
+	if err != nil {
+		return err
+	}
+	for remaining() > 0 {
+		targetDeadline := uint64(0)
+		for dlIdx, count := range partitionCounts {
+			if count < partitionCounts[targetDeadline] {
+				targetDeadline = uint64(dlIdx)
+			}
 		}
 
-		bf := abi.NewBitField()
-		_, err = epochFaultArr.Get(uint64(faultEpoch), bf)
-		if err != nil {
-			return err
+		countToAdd := min64(WPoStPartitionSectors, remaining())
+		toAdd := sectors[nextNewSector : nextNewSector+countToAdd]
+		partition := ConstructPartition(emptyArray)
+		if err = partition.AddSectors(store, st.Info.SectorSize, toAdd...); err != nil {
+			return fmt.Errorf("failed to add %d sectors to new partition: %w", countToAdd, err)
 		}
+		nextNewSector += countToAdd
 
-		bf, err = bitfield.MergeBitFields(bf, sectorNos)
-		if err != nil {
+		dl := deadlines[targetDeadline]
+		if err = dl.SavePartition(store, partitionCounts[targetDeadline], partition); err != nil {
 			return err
 		}
-
-		if err = epochFaultArr.Set(uint64(faultEpoch), bf); err != nil {
+		if err = st.putSectorLocations(store, targetDeadline, partitionCounts[targetDeadline], toAdd); err != nil {
 			return err
 		}
-
-		st.FaultEpochs, err = epochFaultArr.Root()
-		if err != nil {
+		partitionCounts[targetDeadline]++
+		if err = st.SaveDeadline(store, targetDeadline, dl); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
-// Removes sector numbers from faults and fault epochs, if present.
-func (st *State) RemoveFaults(store adt.Store, sectorNos *abi.BitField) (err error) {
-	empty, err := sectorNos.IsEmpty()
+// Removes sectors from the partitions at which they are due, returning the sector numbers that were faulty.
+// Sectors not assigned to any deadline are ignored.
+func (st *State) RemoveSectorsFromDeadlines(store adt.Store, sectors []*SectorOnChainInfo) (*abi.BitField, error) {
+	var faultSets []*abi.BitField
+	var removed []*SectorOnChainInfo
+	err := st.updatePartitionsHolding(store, sectors, func(partIdx uint64, partition *Partition, found []*SectorOnChainInfo) error {
+		faulty, err := partition.RemoveSectors(store, st.Info.SectorSize, found...)
+		if err != nil {
+			return fmt.Errorf("failed to remove sectors from partition %d: %w", partIdx, err)
+		}
+		faultSets = append(faultSets, faulty)
+		removed = append(removed, found...)
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if empty {
-		return nil
+	if err = st.deleteSectorLocations(store, removed); err != nil {
+		return nil, err
 	}
+	return abi.BitFieldUnion(faultSets...)
+}
 
-	st.Faults, err = bitfield.SubtractBitField(st.Faults, sectorNos)
-	if err != nil {
-		return err
+// Moves sectors in the expiration queues of the partitions at which they are due, from the expirations of their
+// previous infos to those of their updated infos. prev and updated must describe the same sectors, in the same order.
+// Faulty sectors are left unchanged, and their numbers returned.
+// Sectors not assigned to any deadline are ignored.
+func (st *State) RescheduleSectorExpirations(store adt.Store, prev, updated []*SectorOnChainInfo) (*abi.BitField, error) {
+	if len(prev) != len(updated) {
+		return nil, fmt.Errorf("mismatched sector counts %d and %d", len(prev), len(updated))
 	}
-
-	arr, err := adt.AsArray(store, st.FaultEpochs)
-	if err != nil {
-		return err
+	updatedByNumber := make(map[abi.SectorNumber]*SectorOnChainInfo, len(updated))
+	for _, s := range updated {
+		updatedByNumber[s.Info.SectorNumber] = s
 	}
 
-	changed := map[uint64]*abi.BitField{}
-
-	bf1 := &abi.BitField{}
-	err = arr.ForEach(bf1, func(i int64) error {
-		c1, err := bf1.Count()
+	var faultSets []*abi.BitField
+	err := st.updatePartitionsHolding(store, prev, func(partIdx uint64, partition *Partition, found []*SectorOnChainInfo) error {
+		faulty, err := bitfield.IntersectBitField(partition.Faults, sectorNumbers(found))
 		if err != nil {
 			return err
 		}
-
-		bf2, err := bitfield.SubtractBitField(bf1, sectorNos)
+		faultySet, err := faulty.AllMap(WPoStPartitionSectors)
 		if err != nil {
 			return err
 		}
+		faultSets = append(faultSets, faulty)
 
-		c2, err := bf2.Count()
-		if err != nil {
-			return err
+		var healthyPrev, healthyUpdated []*SectorOnChainInfo
+		for _, sector := range found {
+			if faultySet[uint64(sector.Info.SectorNumber)] {
+				continue
+			}
+			update, ok := updatedByNumber[sector.Info.SectorNumber]
+			if !ok {
+				return fmt.Errorf("no updated info for sector %d", sector.Info.SectorNumber)
+			}
+			healthyPrev = append(healthyPrev, sector)
+			healthyUpdated = append(healthyUpdated, update)
 		}
-
-		if c1 != c2 {
-			changed[uint64(i)] = bf2
+		if err = partition.RescheduleExpirations(store, st.Info.SectorSize, healthyPrev, healthyUpdated); err != nil {
+			return fmt.Errorf("failed to reschedule expirations in partition %d: %w", partIdx, err)
 		}
-
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return abi.BitFieldUnion(faultSets...)
+}
+
+// Invokes a callback with each partition holding any of some sectors, and the sectors it holds, then stores the
+// partitions and their deadlines.
+// Partitions are visited in order of deadline and then partition index. Sectors not assigned to any deadline
+// are ignored.
+func (st *State) updatePartitionsHolding(store adt.Store, sectors []*SectorOnChainInfo,
+	cb func(partIdx uint64, partition *Partition, found []*SectorOnChainInfo) error) error {
+	byDeadline, err := st.groupSectorsByLocation(store, sectors)
 	if err != nil {
 		return err
 	}
 
-	for i, field := range changed {
-		if err = arr.Set(i, field); err != nil {
+	for dlIdx := uint64(0); dlIdx < WPoStPeriodDeadlines; dlIdx++ {
+		byPartition, ok := byDeadline[dlIdx]
+		if !ok {
+			continue
+		}
+		dl, err := st.LoadDeadline(store, dlIdx)
+		if err != nil {
 			return err
 		}
-	}
+		partIdxs := make([]uint64, 0, len(byPartition))
+		for partIdx := range byPartition {
+			partIdxs = append(partIdxs, partIdx)
+		}
+		sort.Slice(partIdxs, func(i, j int) bool { return partIdxs[i] < partIdxs[j] })
 
-	st.FaultEpochs, err = arr.Root()
-	return err
+		for _, partIdx := range partIdxs {
+			partition, err := dl.LoadPartition(store, partIdx)
+			if err != nil {
+				return fmt.Errorf("failed to load partition %d at deadline %d: %w", partIdx, dlIdx, err)
+			}
+			if err = cb(partIdx, partition, byPartition[partIdx]); err != nil {
+				return fmt.Errorf("failed to update sectors at deadline %d: %w", dlIdx, err)
+			}
+			if err = dl.SavePartition(store, partIdx, partition); err != nil {
+				return err
+			}
+		}
+		if err = st.SaveDeadline(store, dlIdx, dl); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Iterates faults by declaration epoch, in order.
-func (st *State) ForEachFaultEpoch(store adt.Store, cb func(epoch abi.ChainEpoch, faults *abi.BitField) error) error {
-	arr, err := adt.AsArray(store, st.FaultEpochs)
+// Returns the numbers of those of some sectors that are faulty in the partitions at which they are due.
+// Sectors not assigned to any deadline are not faulty.
+func (st *State) FindFaultySectors(store adt.Store, sectors []*SectorOnChainInfo) (*abi.BitField, error) {
+	byDeadline, err := st.groupSectorsByLocation(store, sectors)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	bf := abi.NewBitField()
-	return arr.ForEach(bf, func(i int64) error {
-		return cb(abi.ChainEpoch(i), bf)
-	})
+	var faulty []uint64
+	for dlIdx, byPartition := range byDeadline {
+		dl, err := st.LoadDeadline(store, dlIdx)
+		if err != nil {
+			return nil, err
+		}
+		for partIdx, found := range byPartition {
+			partition, err := dl.LoadPartition(store, partIdx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load partition %d at deadline %d: %w", partIdx, dlIdx, err)
+			}
+			for _, sector := range found {
+				isFaulty, err := partition.Faults.IsSet(uint64(sector.Info.SectorNumber))
+				if err != nil {
+					return nil, err
+				}
+				if isFaulty {
+					faulty = append(faulty, uint64(sector.Info.SectorNumber))
+				}
+			}
+		}
+	}
+	return bitfield.NewFromSet(faulty), nil
 }
 
-func (st *State) ClearFaultEpochs(store adt.Store, epochs ...abi.ChainEpoch) error {
-	arr, err := adt.AsArray(store, st.FaultEpochs)
+// Groups sectors by the deadline and then partition at which they are due, omitting those not assigned to any
+// deadline.
+func (st *State) groupSectorsByLocation(store adt.Store, sectors []*SectorOnChainInfo) (map[uint64]map[uint64][]*SectorOnChainInfo, error) {
+	locations, err := AsSectorLocationArray(store, st.SectorLocations)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	for _, exp := range epochs {
-		err = arr.Delete(uint64(exp))
+	byDeadline := make(map[uint64]map[uint64][]*SectorOnChainInfo)
+	for _, s := range sectors {
+		loc, found, err := locations.Get(s.Info.SectorNumber)
 		if err != nil {
-			return nil
+			return nil, fmt.Errorf("failed to load location of sector %d: %w", s.Info.SectorNumber, err)
+		}
+		if !found {
+			continue
+		}
+		if byDeadline[loc.Deadline] == nil {
+			byDeadline[loc.Deadline] = make(map[uint64][]*SectorOnChainInfo)
 		}
+		byDeadline[loc.Deadline][loc.Partition] = append(byDeadline[loc.Deadline][loc.Partition], s)
 	}
-
-	st.FaultEpochs, err = arr.Root()
-	return nil
+	return byDeadline, nil
 }
 
-// Adds sectors to recoveries.
-func (st *State) AddRecoveries(sectorNos *abi.BitField) (err error) {
-	empty, err := sectorNos.IsEmpty()
+//
+// Funds and vesting
+//
+
+// Records a consensus fault, leaving the record and the penalty ledger as a tombstone in place of all other sector
+// and fund state.
+// All pre-committed and proven sectors are discarded, and their deposits forfeit along with all locked funds and
+// rewards. The forfeit funds remain in the miner's balance, to be burnt by the caller, and are recorded in the
+// penalty ledger against the discarded sectors.
+// Fails if a consensus fault has already been recorded.
+func (st *State) RecordConsensusFault(store adt.Store, record *ConsensusFaultRecord, forfeit abi.TokenAmount) error {
+	if st.ConsensusFault != nil {
+		return xerrors.Errorf("consensus fault at epoch %v already recorded", st.ConsensusFault.FaultEpoch)
+	}
+
+	var slashed []uint64
+	if err := st.ForEachSector(store, func(sector *SectorOnChainInfo) {
+		slashed = append(slashed, uint64(sector.Info.SectorNumber))
+	}); err != nil {
+		return err
+	}
+	precommitted, err := AsSectorPreCommitOnChainInfoMap(store, st.PreCommittedSectors)
 	if err != nil {
 		return err
 	}
-	if empty {
+	if err = precommitted.ForEach(func(sectorNo abi.SectorNumber, _ *SectorPreCommitOnChainInfo) error {
+		slashed = append(slashed, uint64(sectorNo))
 		return nil
+	}); err != nil {
+		return err
 	}
-	st.Recoveries, err = bitfield.MergeBitFields(st.Recoveries, sectorNos)
-	if err != nil {
+	if err = st.RecordPenalty(store, record.ReportEpoch, PenaltyConsensusFault, bitfield.NewFromSet(slashed), forfeit); err != nil {
 		return err
 	}
 
-	count, err := st.Recoveries.Count()
+	emptyMap, err := adt.MakeEmptyMap(store).Root()
 	if err != nil {
 		return err
 	}
-	if count > SectorsMax {
-		return fmt.Errorf("too many recoveries %d, max %d", count, SectorsMax)
+	emptyArray, err := adt.MakeEmptyArray(store).Root()
+	if err != nil {
+		return err
 	}
+
+	st.PreCommittedSectors = emptyMap
+	st.PreCommitDeposits = big.Zero()
+	st.VestingFunds = emptyArray
+	st.LockedFunds = big.Zero()
+	st.VestingRewards = emptyArray
+	st.LockedRewards = big.Zero()
+	st.FeeDebt = big.Zero()
+	st.Sectors = emptyArray
+	st.AllocatedSectors = abi.NewBitField()
+	st.NewSectors = abi.NewBitField()
+	st.Deadlines = emptyArray
+	st.SectorLocations = emptyArray
+	st.PoStSnapshots = emptyArray
+	st.ConsensusFault = record
 	return nil
 }
 
-// Removes sectors from recoveries, if present.
-func (st *State) RemoveRecoveries(sectorNos *abi.BitField) (err error) {
-	empty, err := sectorNos.IsEmpty()
+func (st *State) AddPreCommitDeposit(amount abi.TokenAmount) {
+	newTotal := big.Add(st.PreCommitDeposits, amount)
+	AssertMsg(newTotal.GreaterThanEqual(big.Zero()), "negative pre-commit deposit %s after adding %s to prior %s",
+		newTotal, amount, st.PreCommitDeposits)
+	st.PreCommitDeposits = newTotal
+}
+
+// Locks funds as pledge collateral, vesting according to the given schedule.
+func (st *State) AddLockedFunds(store adt.Store, currEpoch abi.ChainEpoch, vestingSum abi.TokenAmount, spec *VestSpec) error {
+	root, err := addVestingFunds(store, st.VestingFunds, currEpoch, vestingSum, spec)
 	if err != nil {
 		return err
 	}
-	if empty {
-		return nil
-	}
-	st.Recoveries, err = bitfield.SubtractBitField(st.Recoveries, sectorNos)
-	return err
+	st.VestingFunds = root
+	st.LockedFunds = big.Add(st.LockedFunds, vestingSum)
+	return nil
 }
 
-// Loads sector info for a sequence of sectors.
-func (st *State) LoadSectorInfos(store adt.Store, sectors *abi.BitField) ([]*SectorOnChainInfo, error) {
-	var sectorInfos []*SectorOnChainInfo
-	err := sectors.ForEach(func(i uint64) error {
-		sectorOnChain, found, err := st.GetSector(store, abi.SectorNumber(i))
-		if err != nil {
-			return fmt.Errorf("failed to load sector %d: %v", i, err)
-		} else if !found {
-			return fmt.Errorf("can't find sector %d", i)
-		}
-		sectorInfos = append(sectorInfos, sectorOnChain)
-		return nil
-	})
-	return sectorInfos, err
+// Locks funds received as block rewards, vesting according to the given schedule.
+// Locked rewards are tracked separately from pledge collateral so that each may vest on its own schedule.
+func (st *State) AddLockedRewards(store adt.Store, currEpoch abi.ChainEpoch, vestingSum abi.TokenAmount, spec *VestSpec) error {
+	root, err := addVestingFunds(store, st.VestingRewards, currEpoch, vestingSum, spec)
+	if err != nil {
+		return err
+	}
+	st.VestingRewards = root
+	st.LockedRewards = big.Add(st.LockedRewards, vestingSum)
+	return nil
 }
 
-// Loads sector info for a sequence of sectors, substituting info for a stand-in sector for any that are faulty.
-func (st *State) LoadSectorInfosWithFaultMask(store adt.Store, sectors *abi.BitField, faults *abi.BitField, faultStandIn abi.SectorNumber) ([]*SectorOnChainInfo, error) {
-	sectorOnChain, found, err := st.GetSector(store, faultStandIn)
+// Returns the total of locked pledge collateral and locked rewards.
+func (st *State) TotalLockedFunds() abi.TokenAmount {
+	return big.Add(st.LockedFunds, st.LockedRewards)
+}
+
+// Unlocks an amount of funds that have *not yet vested*, if possible.
+// Locked rewards are unlocked before pledge collateral, and within each the soonest-vesting entries are unlocked first.
+// Returns the amount actually unlocked.
+func (st *State) UnlockUnvestedFunds(store adt.Store, currEpoch abi.ChainEpoch, target abi.TokenAmount) (abi.TokenAmount, error) {
+	rewardsRoot, rewardsUnlocked, err := unlockUnvestedFunds(store, st.VestingRewards, currEpoch, target)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load stand-in sector %d: %v", faultStandIn, err)
-	} else if !found {
-		return nil, fmt.Errorf("can't find stand-in sector %d", faultStandIn)
+		return big.Zero(), err
 	}
-	standInInfo := sectorOnChain
+	st.VestingRewards = rewardsRoot
+	st.LockedRewards = big.Sub(st.LockedRewards, rewardsUnlocked)
+	Assert(st.LockedRewards.GreaterThanEqual(big.Zero()))
 
-	// Expand faults into a map for quick lookups.
-	// The faults bitfield should already be a subset of the sectors bitfield.
-	faultMax, err := sectors.Count()
+	fundsRoot, fundsUnlocked, err := unlockUnvestedFunds(store, st.VestingFunds, currEpoch, big.Sub(target, rewardsUnlocked))
 	if err != nil {
-		return nil, err
+		return big.Zero(), err
 	}
-	faultSet, err := faults.AllMap(faultMax)
+	st.VestingFunds = fundsRoot
+	st.LockedFunds = big.Sub(st.LockedFunds, fundsUnlocked)
+	Assert(st.LockedFunds.GreaterThanEqual(big.Zero()))
+
+	return big.Add(rewardsUnlocked, fundsUnlocked), nil
+}
+
+// Unlocks all vesting funds and rewards that have vested before the provided epoch.
+// Returns the amount unlocked.
+func (st *State) UnlockVestedFunds(store adt.Store, currEpoch abi.ChainEpoch) (abi.TokenAmount, error) {
+	rewardsRoot, rewardsUnlocked, err := unlockVestedFunds(store, st.VestingRewards, currEpoch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to expand faults: %w", err)
+		return big.Zero(), err
 	}
+	st.VestingRewards = rewardsRoot
+	st.LockedRewards = big.Sub(st.LockedRewards, rewardsUnlocked)
+	Assert(st.LockedRewards.GreaterThanEqual(big.Zero()))
 
-	// Load the sector infos, masking out fault sectors with a good one.
-	var sectorInfos []*SectorOnChainInfo
-	err = sectors.ForEach(func(i uint64) error {
-		sector := standInInfo
-		faulty := faultSet[i]
-		if !faulty {
-			sectorOnChain, found, err = st.GetSector(store, abi.SectorNumber(i))
-			if err != nil {
-				return fmt.Errorf("failed to load sector %d: %v", i, err)
-			} else if !found {
-				return fmt.Errorf("can't find sector %d", i)
-			}
-			sector = sectorOnChain
+	fundsRoot, fundsUnlocked, err := unlockVestedFunds(store, st.VestingFunds, currEpoch)
+	if err != nil {
+		return big.Zero(), err
+	}
+	st.VestingFunds = fundsRoot
+	st.LockedFunds = big.Sub(st.LockedFunds, fundsUnlocked)
+	Assert(st.LockedFunds.GreaterThanEqual(big.Zero()))
+
+	return big.Add(rewardsUnlocked, fundsUnlocked), nil
+}
+
+// Records a penalty that could not be paid from the miner's funds, to be repaid later.
+func (st *State) AddFeeDebt(amount abi.TokenAmount) {
+	AssertMsg(amount.GreaterThanEqual(big.Zero()), "negative fee debt %s", amount)
+	st.FeeDebt = big.Add(st.FeeDebt, amount)
+}
+
+// Repays as much of the fee debt as possible from the available balance.
+// Returns the amount repaid, which the caller must burn.
+func (st *State) RepayDebt(actorBalance abi.TokenAmount) abi.TokenAmount {
+	repaid := big.Min(st.GetAvailableBalance(actorBalance), st.FeeDebt)
+	st.FeeDebt = big.Sub(st.FeeDebt, repaid)
+	return repaid
+}
+
+// Appends a record of a penalty incurred at an epoch to the penalty ledger.
+// Records older than PenaltyLedgerRetention are pruned. A zero penalty is not recorded.
+func (st *State) RecordPenalty(store adt.Store, epoch abi.ChainEpoch, cause PenaltyCause, sectors *abi.BitField, amount abi.TokenAmount) error {
+	if amount.IsZero() {
+		return nil
+	}
+	arr, err := adt.AsArray(store, st.PenaltyLedger)
+	if err != nil {
+		return err
+	}
+
+	var records PenaltyRecords
+	if _, err = arr.Get(uint64(epoch), &records); err != nil {
+		return err
+	}
+	records.Records = append(records.Records, PenaltyRecord{
+		Cause:   cause,
+		Sectors: sectors,
+		Amount:  amount,
+	})
+	if err = arr.Set(uint64(epoch), &records); err != nil {
+		return err
+	}
+
+	// Prune records that have passed the retention period, which are the first in the ledger.
+	var toDelete []uint64
+	var finished = fmt.Errorf("finished")
+	var pruned PenaltyRecords
+	err = arr.ForEach(&pruned, func(i int64) error {
+		if abi.ChainEpoch(i)+PenaltyLedgerRetention > epoch {
+			return finished
 		}
-		sectorInfos = append(sectorInfos, sector)
+		toDelete = append(toDelete, uint64(i))
 		return nil
 	})
-	return sectorInfos, err
-}
+	if err != nil && err != finished {
+		return err
+	}
+	if err = deleteMany(arr, toDelete); err != nil {
+		return err
+	}
 
-// Adds partition numbers to the set of PoSt submissions
-func (st *State) AddPoStSubmissions(partitionNos *abi.BitField) (err error) {
-	st.PostSubmissions, err = bitfield.MergeBitFields(st.PostSubmissions, partitionNos)
+	st.PenaltyLedger, err = arr.Root()
 	return err
 }
 
-// Removes all PoSt submissions
-func (st *State) ClearPoStSubmissions() error {
-	st.PostSubmissions = abi.NewBitField()
+// Iterates the retained penalties incurred in the epoch range [start, end), in order.
+func (st *State) ForEachPenalty(store adt.Store, start, end abi.ChainEpoch, cb func(epoch abi.ChainEpoch, record *PenaltyRecord) error) error {
+	arr, err := adt.AsArray(store, st.PenaltyLedger)
+	if err != nil {
+		return err
+	}
+
+	var records PenaltyRecords
+	var finished = fmt.Errorf("finished")
+	err = arr.ForEach(&records, func(i int64) error {
+		epoch := abi.ChainEpoch(i)
+		if epoch >= end {
+			return finished // stop iterating
+		}
+		if epoch < start {
+			return nil
+		}
+		for j := range records.Records {
+			if err := cb(epoch, &records.Records[j]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err != finished {
+		return err
+	}
 	return nil
 }
 
-func (st *State) LoadDeadlines(store adt.Store) (*Deadlines, error) {
-	var deadlines Deadlines
-	if err := store.Get(store.Context(), st.Deadlines, &deadlines); err != nil {
-		return nil, fmt.Errorf("failed to load deadlines (%s): %w", st.Deadlines, err)
+// Records a Window PoSt accepted without verification at a deadline, so that it may later be disputed.
+// Snapshots for the deadline submitted before the deadline opened (i.e. in an earlier proving period) are discarded.
+// Returns the index of the new snapshot among those for the deadline.
+func (st *State) RecordPoStSnapshot(store adt.Store, deadline *DeadlineInfo, snapshot *WindowedPoStSnapshot) (uint64, error) {
+	arr, err := adt.AsArray(store, st.PoStSnapshots)
+	if err != nil {
+		return 0, err
+	}
+
+	var snapshots WindowedPoStSnapshots
+	if _, err = arr.Get(deadline.Index, &snapshots); err != nil {
+		return 0, err
+	}
+	if len(snapshots.Snapshots) > 0 && snapshots.Snapshots[0].SubmissionEpoch < deadline.Open {
+		snapshots.Snapshots = nil
+	}
+	snapshots.Snapshots = append(snapshots.Snapshots, *snapshot)
+	if err = arr.Set(deadline.Index, &snapshots); err != nil {
+		return 0, err
 	}
 
-	return &deadlines, nil
+	st.PoStSnapshots, err = arr.Root()
+	return uint64(len(snapshots.Snapshots) - 1), err
 }
 
-func (st *State) SaveDeadlines(store adt.Store, deadlines *Deadlines) error {
-	c, err := store.Put(store.Context(), deadlines)
+// Discards the Window PoSt snapshots of every deadline for which the dispute window has closed by an epoch.
+// A deadline's snapshots are all submitted during its challenge window, so are discarded together once the last
+// may no longer be disputed.
+func (st *State) PruneExpiredPoStSnapshots(store adt.Store, currEpoch abi.ChainEpoch) error {
+	arr, err := adt.AsArray(store, st.PoStSnapshots)
 	if err != nil {
 		return err
 	}
-	st.Deadlines = c
-	return nil
+
+	var toDelete []uint64
+	var snapshots WindowedPoStSnapshots
+	err = arr.ForEach(&snapshots, func(dlIdx int64) error {
+		last := snapshots.Snapshots[len(snapshots.Snapshots)-1]
+		if currEpoch >= last.SubmissionEpoch+WPoStDisputeWindow {
+			toDelete = append(toDelete, uint64(dlIdx))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err = deleteMany(arr, toDelete); err != nil {
+		return err
+	}
+
+	st.PoStSnapshots, err = arr.Root()
+	return err
+}
+
+// Loads a Window PoSt snapshot by deadline and index, returning whether it was found.
+func (st *State) GetPoStSnapshot(store adt.Store, deadlineIdx, index uint64) (*WindowedPoStSnapshot, bool, error) {
+	arr, err := adt.AsArray(store, st.PoStSnapshots)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var snapshots WindowedPoStSnapshots
+	found, err := arr.Get(deadlineIdx, &snapshots)
+	if err != nil || !found || index >= uint64(len(snapshots.Snapshots)) {
+		return nil, false, err
+	}
+	return &snapshots.Snapshots[index], true, nil
+}
+
+// Marks a Window PoSt snapshot as disputed, discarding its proofs and sectors.
+// The snapshot remains in place so that the indices of later snapshots for the deadline are unchanged.
+func (st *State) MarkPoStSnapshotDisputed(store adt.Store, deadlineIdx, index uint64) error {
+	arr, err := adt.AsArray(store, st.PoStSnapshots)
+	if err != nil {
+		return err
+	}
+
+	var snapshots WindowedPoStSnapshots
+	found, err := arr.Get(deadlineIdx, &snapshots)
+	if err != nil {
+		return err
+	} else if !found || index >= uint64(len(snapshots.Snapshots)) {
+		return xerrors.Errorf("no PoSt snapshot %d at deadline %d", index, deadlineIdx)
+	}
+	snapshot := &snapshots.Snapshots[index]
+	snapshot.Disputed = true
+	snapshot.Proofs = nil
+	snapshot.Sectors = nil
+	if err = arr.Set(deadlineIdx, &snapshots); err != nil {
+		return err
+	}
+
+	st.PoStSnapshots, err = arr.Root()
+	return err
+}
+
+func (st *State) GetAvailableBalance(actorBalance abi.TokenAmount) abi.TokenAmount {
+	availableBal := big.Sub(big.Sub(actorBalance, st.TotalLockedFunds()), st.PreCommitDeposits)
+	Assert(availableBal.GreaterThanEqual(big.Zero()))
+	return availableBal
+}
+
+func (st *State) AssertBalanceInvariants(balance abi.TokenAmount) {
+	Assert(st.PreCommitDeposits.GreaterThanEqual(big.Zero()))
+	Assert(st.LockedFunds.GreaterThanEqual(big.Zero()))
+	Assert(st.LockedRewards.GreaterThanEqual(big.Zero()))
+	Assert(st.FeeDebt.GreaterThanEqual(big.Zero()))
+	Assert(balance.GreaterThanEqual(big.Add(st.PreCommitDeposits, st.TotalLockedFunds())))
 }
 
 //
-// PoSt Deadlines and partitions
+// Sectors
 //
 
-type Deadlines struct {
-	// A bitfield of sector numbers due at each deadline.
-	// The sectors for each deadline are logically grouped into sequential partitions for proving.
-	Due [WPoStPeriodDeadlines]*abi.BitField
+func (s *SectorOnChainInfo) AsSectorInfo() abi.SectorInfo {
+	return abi.SectorInfo{
+		RegisteredProof: s.Info.RegisteredProof,
+		SectorNumber:    s.Info.SectorNumber,
+		SealedCID:       s.Info.SealedCID,
+	}
 }
 
-func ConstructDeadlines() *Deadlines {
-	d := &Deadlines{Due: [WPoStPeriodDeadlines]*abi.BitField{}}
-	for i := range d.Due {
-		d.Due[i] = abi.NewBitField()
+func AsStorageWeightDesc(sectorSize abi.SectorSize, sectorInfo *SectorOnChainInfo) *power.SectorStorageWeightDesc {
+	return &power.SectorStorageWeightDesc{
+		SectorSize:         sectorSize,
+		DealWeight:         sectorInfo.DealWeight,
+		VerifiedDealWeight: sectorInfo.VerifiedDealWeight,
+		Duration:           sectorInfo.Info.Expiration - sectorInfo.ActivationEpoch,
 	}
-	return d
 }
 
-// Adds sector numbers to a deadline.
-// The sector numbers are given as uint64 to avoid pointless conversions for bitfield use.
-func (d *Deadlines) AddToDeadline(deadline uint64, newSectors ...uint64) (err error) {
-	ns := bitfield.NewFromSet(newSectors)
-	d.Due[deadline], err = bitfield.MergeBitFields(d.Due[deadline], ns)
-	return err
-}
+//
+// Invariants
+//
+
+// Checks the internal consistency of the state, returning any violations found.
+// The balance is that of the miner actor.
+func (st *State) CheckStateInvariants(store adt.Store, balance abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("miner")
+
+	// Funds
+	acc.Require(st.PreCommitDeposits.GreaterThanEqual(big.Zero()), "negative pre-commit deposits %v", st.PreCommitDeposits)
+	acc.Require(st.LockedFunds.GreaterThanEqual(big.Zero()), "negative locked funds %v", st.LockedFunds)
+	acc.Require(st.LockedRewards.GreaterThanEqual(big.Zero()), "negative locked rewards %v", st.LockedRewards)
+	acc.Require(st.FeeDebt.GreaterThanEqual(big.Zero()), "negative fee debt %v", st.FeeDebt)
+	acc.Require(balance.GreaterThanEqual(big.Add(st.PreCommitDeposits, st.TotalLockedFunds())),
+		"balance %v less than pre-commit deposits %v plus locked funds %v and rewards %v", balance, st.PreCommitDeposits,
+		st.LockedFunds, st.LockedRewards)
+
+	checkVestingTable := func(name string, root cid.Cid, locked abi.TokenAmount) {
+		vestingFunds, err := adt.AsArray(store, root)
+		if err != nil {
+			acc.Addf("failed to load vesting %s: %v", name, err)
+			return
+		}
+		totalVesting := big.Zero()
+		var amount abi.TokenAmount
+		err = vestingFunds.ForEach(&amount, func(epoch int64) error {
+			acc.Require(amount.GreaterThanEqual(big.Zero()), "negative vesting %s amount %v at epoch %d", name, amount, epoch)
+			totalVesting = big.Add(totalVesting, amount)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate vesting %s", name)
+		acc.Require(totalVesting.Equals(locked), "vesting %s total %v != locked %s %v", name, totalVesting, name, locked)
+	}
+	checkVestingTable("funds", st.VestingFunds, st.LockedFunds)
+	checkVestingTable("rewards", st.VestingRewards, st.LockedRewards)
+
+	// Sectors
+	allSectors := abi.NewBitField()
+	sectorsByNumber := map[uint64]*SectorOnChainInfo{}
+	err := st.ForEachSector(store, func(sector *SectorOnChainInfo) {
+		allSectors.Set(uint64(sector.Info.SectorNumber))
+		sectorsByNumber[uint64(sector.Info.SectorNumber)] = sector
+	})
+	acc.RequireNoError(err, "failed to iterate sectors")
+	allocated, err := abi.BitFieldContainsAll(st.AllocatedSectors, allSectors)
+	acc.RequireNoError(err, "failed to check allocated sectors")
+	acc.Require(allocated, "sectors not all allocated")
+
+	totalDeposits := big.Zero()
+	if precommitted, err := AsSectorPreCommitOnChainInfoMap(store, st.PreCommittedSectors); err != nil {
+		acc.Addf("failed to load pre-committed sectors: %v", err)
+	} else {
+		err = precommitted.ForEach(func(sectorNo abi.SectorNumber, info *SectorPreCommitOnChainInfo) error {
+			acc.Require(sectorNo == info.Info.SectorNumber, "pre-commit at key %d has sector number %d", sectorNo, info.Info.SectorNumber)
+			proven, err := allSectors.IsSet(uint64(sectorNo))
+			acc.RequireNoError(err, "failed to read sectors")
+			acc.Require(!proven, "sector %d is both pre-committed and proven", sectorNo)
+			allocated, err := st.AllocatedSectors.IsSet(uint64(sectorNo))
+			acc.RequireNoError(err, "failed to read allocated sectors")
+			acc.Require(allocated, "pre-committed sector %d not allocated", sectorNo)
+			totalDeposits = big.Add(totalDeposits, info.PreCommitDeposit)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate pre-committed sectors")
+		acc.Require(totalDeposits.Equals(st.PreCommitDeposits), "pre-commit deposits total %v != recorded total %v", totalDeposits, st.PreCommitDeposits)
+	}
 
-// Removes sector numbers from all deadlines.
-func (d *Deadlines) RemoveFromAllDeadlines(sectorNos *abi.BitField) (err error) {
-	for i := range d.Due {
-		d.Due[i], err = bitfield.SubtractBitField(d.Due[i], sectorNos)
+	// Deadlines
+	// Keys(Sectors) == union(NewSectors, Deadlines.Partitions.Sectors)
+	sectorSets := []*abi.BitField{st.NewSectors}
+	expectedLocations := map[abi.SectorNumber]SectorLocation{}
+	for dlIdx := uint64(0); dlIdx < WPoStPeriodDeadlines; dlIdx++ {
+		dl, err := st.LoadDeadline(store, dlIdx)
 		if err != nil {
+			acc.Addf("failed to load deadline %d: %v", dlIdx, err)
+			continue
+		}
+		partitionCount, err := dl.PartitionCount(store)
+		acc.RequireNoError(err, "failed to count partitions at deadline %d", dlIdx)
+		err = dl.PostSubmissions.ForEach(func(partIdx uint64) error {
+			acc.Require(partIdx < partitionCount, "deadline %d has submission for partition %d of %d", dlIdx, partIdx, partitionCount)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate submissions at deadline %d", dlIdx)
+
+		err = dl.ForEachPartition(store, func(partIdx uint64, partition *Partition) error {
+			sectorSets = append(sectorSets, partition.Sectors)
+			err := partition.Sectors.ForEach(func(sectorNo uint64) error {
+				expectedLocations[abi.SectorNumber(sectorNo)] = SectorLocation{Deadline: dlIdx, Partition: partIdx}
+				return nil
+			})
+			acc.RequireNoError(err, "failed to iterate sectors at deadline %d partition %d", dlIdx, partIdx)
+			checkPartitionInvariants(acc, store, fmt.Sprintf("deadline %d partition %d", dlIdx, partIdx), partition,
+				st.Info.SectorSize, sectorsByNumber)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate partitions at deadline %d", dlIdx)
+	}
+	requireDisjoint(acc, "new sectors and partitions", sectorSets...)
+	requireUnionEqual(acc, "sectors", allSectors, "new sectors and partitions", sectorSets...)
+
+	// Keys(SectorLocations) == union(Deadlines.Partitions.Sectors), each at its partition.
+	if locations, err := AsSectorLocationArray(store, st.SectorLocations); err != nil {
+		acc.Addf("failed to load sector locations: %v", err)
+	} else {
+		locationCount := 0
+		err = locations.ForEach(func(sectorNo abi.SectorNumber, loc *SectorLocation) error {
+			locationCount++
+			expected, found := expectedLocations[sectorNo]
+			acc.Require(found, "sector %d located at deadline %d partition %d is not in any partition", sectorNo,
+				loc.Deadline, loc.Partition)
+			acc.Require(!found || expected == *loc, "sector %d located at deadline %d partition %d, held by deadline %d partition %d",
+				sectorNo, loc.Deadline, loc.Partition, expected.Deadline, expected.Partition)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate sector locations")
+		acc.Require(locationCount == len(expectedLocations), "%d sector locations for %d partitioned sectors", locationCount,
+			len(expectedLocations))
+	}
+
+	return acc.Violations()
+}
+
+// Records any violations of the internal consistency of a partition, with respect to the sectors it contains.
+func checkPartitionInvariants(acc *builtin.InvariantAccumulator, store adt.Store, name string, partition *Partition,
+	sectorSize abi.SectorSize, sectorsByNumber map[uint64]*SectorOnChainInfo) {
+	sectorCount, err := partition.SectorCount()
+	acc.RequireNoError(err, "failed to count %s sectors", name)
+	acc.Require(sectorCount <= WPoStPartitionSectors, "%s has %d sectors, max %d", name, sectorCount, WPoStPartitionSectors)
+
+	// Sectors == union(Expirations.Values()), each at its expiration epoch.
+	var sectors []*SectorOnChainInfo
+	err = partition.Sectors.ForEach(func(sectorNo uint64) error {
+		if sector, found := sectorsByNumber[sectorNo]; found {
+			sectors = append(sectors, sector)
+		}
+		return nil
+	})
+	acc.RequireNoError(err, "failed to iterate %s sectors", name)
+	acc.Require(uint64(len(sectors)) == sectorCount, "%s has sectors missing from the sector set", name)
+
+	var expirationSets []*abi.BitField
+	if expirations, err := adt.AsArray(store, partition.Expirations); err != nil {
+		acc.Addf("failed to load %s expirations: %v", name, err)
+	} else {
+		bf := abi.NewBitField()
+		err = expirations.ForEach(bf, func(epoch int64) error {
+			cpy, err := bitfield.MergeBitFields(abi.NewBitField(), bf) // bf is not safe to store
+			if err != nil {
+				return err
+			}
+			expirationSets = append(expirationSets, cpy)
+			return cpy.ForEach(func(sectorNo uint64) error {
+				if sector, found := sectorsByNumber[sectorNo]; found {
+					acc.Require(sector.Info.Expiration == abi.ChainEpoch(epoch), "%s sector %d expires at %d, queued at %d",
+						name, sectorNo, sector.Info.Expiration, epoch)
+				}
+				return nil
+			})
+		})
+		acc.RequireNoError(err, "failed to iterate %s expirations", name)
+	}
+	requireDisjoint(acc, name+" expiration sets", expirationSets...)
+	requireUnionEqual(acc, name+" sectors", partition.Sectors, "expirations", expirationSets...)
+
+	// Faults == union(FaultEpochs.Values())
+	var faultSets []*abi.BitField
+	if faultEpochs, err := adt.AsArray(store, partition.FaultEpochs); err != nil {
+		acc.Addf("failed to load %s fault epochs: %v", name, err)
+	} else {
+		bf := abi.NewBitField()
+		err = faultEpochs.ForEach(bf, func(epoch int64) error {
+			cpy, err := bitfield.MergeBitFields(abi.NewBitField(), bf)
+			faultSets = append(faultSets, cpy)
 			return err
+		})
+		acc.RequireNoError(err, "failed to iterate %s fault epochs", name)
+	}
+	requireDisjoint(acc, name+" fault epochs", faultSets...)
+	requireUnionEqual(acc, name+" faults", partition.Faults, "fault epochs", faultSets...)
+
+	requireSubset(acc, name+" faults", partition.Faults, "sectors", partition.Sectors)
+	requireSubset(acc, name+" recoveries", partition.Recoveries, "faults", partition.Faults)
+
+	// Power totals
+	var faultySectors []*SectorOnChainInfo
+	for _, sector := range sectors {
+		faulty, err := partition.Faults.IsSet(uint64(sector.Info.SectorNumber))
+		acc.RequireNoError(err, "failed to read %s faults", name)
+		if faulty {
+			faultySectors = append(faultySectors, sector)
 		}
 	}
-	return nil
+	totalPower := PowerForSectors(sectorSize, sectors)
+	acc.Require(partition.TotalPower.Equals(totalPower), "%s total power %v != sector power %v", name, partition.TotalPower, totalPower)
+	faultyPower := PowerForSectors(sectorSize, faultySectors)
+	acc.Require(partition.FaultyPower.Equals(faultyPower), "%s faulty power %v != faulty sector power %v", name, partition.FaultyPower, faultyPower)
+}
+
+// Records a violation if a is not a subset of b.
+func requireSubset(acc *builtin.InvariantAccumulator, aName string, a *abi.BitField, bName string, b *abi.BitField) {
+	diff, err := bitfield.SubtractBitField(a, b)
+	if err != nil {
+		acc.Addf("failed to subtract %s from %s: %v", bName, aName, err)
+		return
+	}
+	empty, err := diff.IsEmpty()
+	acc.RequireNoError(err, "failed to check %s subset of %s", aName, bName)
+	acc.Require(empty, "%s is not a subset of %s", aName, bName)
+}
+
+// Records a violation if the union of some bitfields is not equal to a.
+func requireUnionEqual(acc *builtin.InvariantAccumulator, aName string, a *abi.BitField, partsName string, parts ...*abi.BitField) {
+	union, err := abi.BitFieldUnion(parts...)
+	if err != nil {
+		acc.Addf("failed to union %s: %v", partsName, err)
+		return
+	}
+	requireSubset(acc, aName, a, partsName, union)
+	requireSubset(acc, partsName, union, aName, a)
+}
+
+// Records a violation if any number is present in more than one of some bitfields.
+func requireDisjoint(acc *builtin.InvariantAccumulator, name string, sets ...*abi.BitField) {
+	total := uint64(0)
+	for _, s := range sets {
+		count, err := s.Count()
+		if err != nil {
+			acc.Addf("failed to count %s: %v", name, err)
+			return
+		}
+		total += count
+	}
+	union, err := abi.BitFieldUnion(sets...)
+	if err != nil {
+		acc.Addf("failed to union %s: %v", name, err)
+		return
+	}
+	unionCount, err := union.Count()
+	acc.RequireNoError(err, "failed to count %s", name)
+	acc.Require(unionCount == total, "%s are not disjoint", name)
 }
 
 //
-// Funds and vesting
+// Misc helpers
 //
 
-func (st *State) AddPreCommitDeposit(amount abi.TokenAmount) {
-	newTotal := big.Add(st.PreCommitDeposits, amount)
-	AssertMsg(newTotal.GreaterThanEqual(big.Zero()), "negative pre-commit deposit %s after adding %s to prior %s",
-		newTotal, amount, st.PreCommitDeposits)
-	st.PreCommitDeposits = newTotal
+func deleteMany(arr *adt.Array, keys []uint64) error {
+	// If AMT exposed a batch delete we could save some writes here.
+	for _, i := range keys {
+		err := arr.Delete(i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (st *State) AddLockedFunds(store adt.Store, currEpoch abi.ChainEpoch, vestingSum abi.TokenAmount, spec *VestSpec) error {
+// Adds a sum to a vesting table, to vest according to a schedule beginning at the current epoch.
+// Returns the new root of the table.
+func addVestingFunds(store adt.Store, root cid.Cid, currEpoch abi.ChainEpoch, vestingSum abi.TokenAmount, spec *VestSpec) (cid.Cid, error) {
 	AssertMsg(vestingSum.GreaterThanEqual(big.Zero()), "negative vesting sum %s", vestingSum)
-	vestingFunds, err := adt.AsArray(store, st.VestingFunds)
+	vestingFunds, err := adt.AsArray(store, root)
 	if err != nil {
-		return err
+		return cid.Undef, err
 	}
 
 	// Nothing unlocks here, this is just the start of the clock.
@@ -784,32 +1578,25 @@ func (st *State) AddLockedFunds(store adt.Store, currEpoch abi.ChainEpoch, vesti
 		lockedFundEntry := big.Zero()
 		_, err = vestingFunds.Get(key, &lockedFundEntry)
 		if err != nil {
-			return err
+			return cid.Undef, err
 		}
 
 		lockedFundEntry = big.Add(lockedFundEntry, vestThisTime)
 		err = vestingFunds.Set(key, &lockedFundEntry)
 		if err != nil {
-			return err
+			return cid.Undef, err
 		}
 	}
 
-	st.VestingFunds, err = vestingFunds.Root()
-	if err != nil {
-		return err
-	}
-	st.LockedFunds = big.Add(st.LockedFunds, vestingSum)
-
-	return nil
+	return vestingFunds.Root()
 }
 
-// Unlocks an amount of funds that have *not yet vested*, if possible.
-// The soonest-vesting entries are unlocked first.
-// Returns the amount actually unlocked.
-func (st *State) UnlockUnvestedFunds(store adt.Store, currEpoch abi.ChainEpoch, target abi.TokenAmount) (abi.TokenAmount, error) {
-	vestingFunds, err := adt.AsArray(store, st.VestingFunds)
+// Unlocks up to a target amount of not-yet-vested funds from a vesting table, soonest-vesting first.
+// Returns the new root of the table and the amount unlocked.
+func unlockUnvestedFunds(store adt.Store, root cid.Cid, currEpoch abi.ChainEpoch, target abi.TokenAmount) (cid.Cid, abi.TokenAmount, error) {
+	vestingFunds, err := adt.AsArray(store, root)
 	if err != nil {
-		return abi.TokenAmount{}, err
+		return cid.Undef, big.Zero(), err
 	}
 
 	amountUnlocked := big.Zero()
@@ -841,30 +1628,27 @@ func (st *State) UnlockUnvestedFunds(store adt.Store, currEpoch abi.ChainEpoch,
 	})
 
 	if err != nil && err != finished {
-		return big.Zero(), err
+		return cid.Undef, big.Zero(), err
 	}
 
 	err = deleteMany(vestingFunds, toDelete)
 	if err != nil {
-		return big.Zero(), errors.Wrapf(err, "failed to delete locked fund during slash: %v", err)
+		return cid.Undef, big.Zero(), errors.Wrapf(err, "failed to delete locked fund during slash: %v", err)
 	}
 
-	st.LockedFunds = big.Sub(st.LockedFunds, amountUnlocked)
-	Assert(st.LockedFunds.GreaterThanEqual(big.Zero()))
-	st.VestingFunds, err = vestingFunds.Root()
+	newRoot, err := vestingFunds.Root()
 	if err != nil {
-		return big.Zero(), err
+		return cid.Undef, big.Zero(), err
 	}
-
-	return amountUnlocked, nil
+	return newRoot, amountUnlocked, nil
 }
 
-// Unlocks all vesting funds that have vested before the provided epoch.
-// Returns the amount unlocked.
-func (st *State) UnlockVestedFunds(store adt.Store, currEpoch abi.ChainEpoch) (abi.TokenAmount, error) {
-	vestingFunds, err := adt.AsArray(store, st.VestingFunds)
+// Unlocks all funds in a vesting table that have vested before the provided epoch.
+// Returns the new root of the table and the amount unlocked.
+func unlockVestedFunds(store adt.Store, root cid.Cid, currEpoch abi.ChainEpoch) (cid.Cid, abi.TokenAmount, error) {
+	vestingFunds, err := adt.AsArray(store, root)
 	if err != nil {
-		return abi.TokenAmount{}, err
+		return cid.Undef, big.Zero(), err
 	}
 
 	amountUnlocked := big.Zero()
@@ -885,69 +1669,19 @@ func (st *State) UnlockVestedFunds(store adt.Store, currEpoch abi.ChainEpoch) (a
 	})
 
 	if err != nil && err != finished {
-		return big.Zero(), err
+		return cid.Undef, big.Zero(), err
 	}
 
 	err = deleteMany(vestingFunds, toDelete)
 	if err != nil {
-		return big.Zero(), errors.Wrapf(err, "failed to delete locked fund during vest: %v", err)
+		return cid.Undef, big.Zero(), errors.Wrapf(err, "failed to delete locked fund during vest: %v", err)
 	}
 
-	st.LockedFunds = big.Sub(st.LockedFunds, amountUnlocked)
-	Assert(st.LockedFunds.GreaterThanEqual(big.Zero()))
-	st.VestingFunds, err = vestingFunds.Root()
+	newRoot, err := vestingFunds.Root()
 	if err != nil {
-		return big.Zero(), err
-	}
-
-	return amountUnlocked, nil
-}
-
-func (st *State) GetAvailableBalance(actorBalance abi.TokenAmount) abi.TokenAmount {
-	availableBal := big.Sub(big.Sub(actorBalance, st.LockedFunds), st.PreCommitDeposits)
-	Assert(availableBal.GreaterThanEqual(big.Zero()))
-	return availableBal
-}
-
-func (st *State) AssertBalanceInvariants(balance abi.TokenAmount) {
-	Assert(st.PreCommitDeposits.GreaterThanEqual(big.Zero()))
-	Assert(st.LockedFunds.GreaterThanEqual(big.Zero()))
-	Assert(balance.GreaterThanEqual(big.Add(st.PreCommitDeposits, st.LockedFunds)))
-}
-
-//
-// Sectors
-//
-
-func (s *SectorOnChainInfo) AsSectorInfo() abi.SectorInfo {
-	return abi.SectorInfo{
-		RegisteredProof: s.Info.RegisteredProof,
-		SectorNumber:    s.Info.SectorNumber,
-		SealedCID:       s.Info.SealedCID,
+		return cid.Undef, big.Zero(), err
 	}
-}
-
-func AsStorageWeightDesc(sectorSize abi.SectorSize, sectorInfo *SectorOnChainInfo) *power.SectorStorageWeightDesc {
-	return &power.SectorStorageWeightDesc{
-		SectorSize: sectorSize,
-		DealWeight: sectorInfo.DealWeight,
-		Duration:   sectorInfo.Info.Expiration - sectorInfo.ActivationEpoch,
-	}
-}
-
-//
-// Misc helpers
-//
-
-func deleteMany(arr *adt.Array, keys []uint64) error {
-	// If AMT exposed a batch delete we could save some writes here.
-	for _, i := range keys {
-		err := arr.Delete(i)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return newRoot, amountUnlocked, nil
 }
 
 // Rounds e to the nearest exact multiple of the quantization unit, rounding up.
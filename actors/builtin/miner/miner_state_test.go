@@ -117,6 +117,39 @@ func TestSectorsStore(t *testing.T) {
 	})
 }
 
+func TestDiffSectors(t *testing.T) {
+	harness := constructStateHarness(t, abi.ChainEpoch(0))
+	for i := int64(0); i < 20; i++ {
+		harness.putSector(newSectorOnChainInfo(abi.SectorNumber(i), tutils.MakeCID(fmt.Sprintf("%d", i)), big.NewInt(i), abi.ChainEpoch(i)))
+	}
+	before := *harness.s
+
+	modified := newSectorOnChainInfo(3, tutils.MakeCID("modified"), big.NewInt(3), abi.ChainEpoch(3))
+	added := newSectorOnChainInfo(100, tutils.MakeCID("100"), big.NewInt(100), abi.ChainEpoch(100))
+	harness.putSector(modified)
+	harness.putSector(added)
+	harness.deleteSectors(7)
+
+	changes, err := miner.DiffSectors(harness.store, &before, harness.s)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, adt.ChangeModify, changes[0].Type)
+	assert.Equal(t, abi.SectorNumber(3), changes[0].Index)
+	assert.Equal(t, tutils.MakeCID("3"), changes[0].Before.Info.SealedCID)
+	assert.Equal(t, modified, changes[0].After)
+	assert.Equal(t, adt.ChangeRemove, changes[1].Type)
+	assert.Equal(t, abi.SectorNumber(7), changes[1].Index)
+	assert.Nil(t, changes[1].After)
+	assert.Equal(t, adt.ChangeAdd, changes[2].Type)
+	assert.Equal(t, abi.SectorNumber(100), changes[2].Index)
+	assert.Nil(t, changes[2].Before)
+	assert.Equal(t, added, changes[2].After)
+
+	changes, err = miner.DiffSectors(harness.store, harness.s, harness.s)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
 func TestNewSectorsBitField(t *testing.T) {
 	t.Run("Add new sectors happy path", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
@@ -167,234 +200,284 @@ func TestNewSectorsBitField(t *testing.T) {
 	})
 }
 
-func TestSectorExpirationStore(t *testing.T) {
-	exp1 := abi.ChainEpoch(10)
-	exp2 := abi.ChainEpoch(20)
-
-	sectorExpirations := make(map[abi.ChainEpoch][]uint64)
-	sectorExpirations[exp1] = []uint64{1, 2, 3, 4, 5}
-	sectorExpirations[exp2] = []uint64{6, 7, 8, 9, 10}
-
-	t.Run("Round trip add get sector expirations", func(t *testing.T) {
-		harness := constructStateHarness(t, abi.ChainEpoch(0))
-
-		harness.addSectorExpiration(exp1, sectorExpirations[exp1]...)
-		harness.addSectorExpiration(exp2, sectorExpirations[exp2]...)
-
-		assert.Equal(t, sectorExpirations[exp1], harness.getSectorExpirations(exp1))
-		assert.Equal(t, sectorExpirations[exp2], harness.getSectorExpirations(exp2))
-
-		// return nothing if there are no sectors at the epoch
-		assert.Empty(t, harness.getSectorExpirations(abi.ChainEpoch(0)))
-
-		// remove the first sector from expiration set 1
-		harness.removeSectorExpiration(exp1, sectorExpirations[exp1][0])
-		assert.Equal(t, sectorExpirations[exp1][1:], harness.getSectorExpirations(exp1))
-		assert.Equal(t, sectorExpirations[exp2], harness.getSectorExpirations(exp2)) // No change
-
-		// remove all sectors from expiration set 2
-		harness.removeSectorExpiration(exp2, sectorExpirations[exp2]...)
-		assert.Empty(t, harness.getSectorExpirations(exp2))
-	})
+func TestAllocatedSectorNumbers(t *testing.T) {
+	harness := constructStateHarness(t, abi.ChainEpoch(0))
+	require.NoError(t, harness.s.AllocateSectorNumber(5))
+	assert.Error(t, harness.s.AllocateSectorNumber(5))
+
+	// Masking ignores numbers already allocated.
+	require.NoError(t, harness.s.MaskSectorNumbers(bitfield.NewFromSet([]uint64{3, 4, 5, 6})))
+	assertBfEqual(t, bitfield.NewFromSet([]uint64{3, 4, 5, 6}), harness.s.AllocatedSectors)
+	assert.Error(t, harness.s.AllocateSectorNumber(3))
+	require.NoError(t, harness.s.AllocateSectorNumber(7))
+}
+
+func TestAssignNewSectors(t *testing.T) {
+	// Returns sectors with consecutive numbers from `first`, all expiring at epoch 100.
+	makeSectors := func(first, count uint64) []*miner.SectorOnChainInfo {
+		sectors := make([]*miner.SectorOnChainInfo, count)
+		for i := range sectors {
+			sectors[i] = testSector(100, abi.SectorNumber(first+uint64(i)), 0)
+		}
+		return sectors
+	}
 
-	t.Run("Iteration by expiration", func(t *testing.T) {
+	t.Run("fills partitions, then adds partitions to the emptiest deadlines", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		harness.assignNewSectors(makeSectors(0, partSize+10)...)
+		assert.Equal(t, []uint64{partSize}, harness.deadlinePartitionSectorCounts(0))
+		assert.Equal(t, []uint64{10}, harness.deadlinePartitionSectorCounts(1))
+		assert.Equal(t, []uint64(nil), harness.deadlinePartitionSectorCounts(2))
+
+		// The partial partition is filled first.
+		harness.assignNewSectors(makeSectors(partSize+10, partSize)...)
+		assert.Equal(t, []uint64{partSize}, harness.deadlinePartitionSectorCounts(0))
+		assert.Equal(t, []uint64{partSize}, harness.deadlinePartitionSectorCounts(1))
+		assert.Equal(t, []uint64{10}, harness.deadlinePartitionSectorCounts(2))
+
+		dlIdx, partIdx, found, err := harness.s.FindSector(harness.store, abi.SectorNumber(partSize+5))
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, uint64(1), dlIdx)
+		assert.Equal(t, uint64(0), partIdx)
 
-		harness.addSectorExpiration(exp1, sectorExpirations[exp1]...)
-		harness.addSectorExpiration(exp2, sectorExpirations[exp2]...)
-
-		exp1Hit, exp2Hit := false, false
-		err := harness.s.ForEachSectorExpiration(harness.store, func(expiry abi.ChainEpoch, sectors *abi.BitField) error {
-			if expiry == exp1 {
-				sectorSlice, err := sectors.All(miner.SectorsMax)
-				assert.NoError(t, err)
-				assert.Equal(t, sectorExpirations[expiry], sectorSlice)
-				exp1Hit = true
-			} else if expiry == exp2 {
-				sectorSlice, err := sectors.All(miner.SectorsMax)
-				assert.NoError(t, err)
-				assert.Equal(t, sectorExpirations[expiry], sectorSlice)
-				exp2Hit = true
-			} else {
-				t.Fatalf("unexpected expiry value: %v in sector expirations", expiry)
-			}
-			return nil
-		})
-		assert.NoError(t, err)
-		assert.True(t, exp1Hit)
-		assert.True(t, exp2Hit)
+		_, _, found, err = harness.s.FindSector(harness.store, abi.SectorNumber(3*partSize))
+		require.NoError(t, err)
+		assert.False(t, found)
 	})
 
-	t.Run("Adding sectors at expiry merges with existing", func(t *testing.T) {
+	t.Run("removes sectors from their partitions", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		sectors := makeSectors(0, partSize+10)
+		harness.assignNewSectors(sectors...)
 
-		mergedSectors := []uint64{21, 22, 23, 24, 25}
-		harness.addSectorExpiration(exp1, sectorExpirations[exp1]...)
-		harness.addSectorExpiration(exp1, mergedSectors...)
-
-		merged := harness.getSectorExpirations(exp1)
-		assert.Equal(t, append(sectorExpirations[exp1], mergedSectors...), merged)
-	})
-
-	t.Run("clear sectors by expirations", func(t *testing.T) {
-		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		// Fault one of the sectors to be removed.
+		dl, err := harness.s.LoadDeadline(harness.store, 1)
+		require.NoError(t, err)
+		partition, err := dl.LoadPartition(harness.store, 0)
+		require.NoError(t, err)
+		require.NoError(t, partition.AddFaults(harness.store, SectorSize, 0, sectors[partSize]))
+		require.NoError(t, dl.SavePartition(harness.store, 0, partition))
+		require.NoError(t, harness.s.SaveDeadline(harness.store, 1, dl))
 
-		harness.addSectorExpiration(exp1, sectorExpirations[exp1]...)
-		harness.addSectorExpiration(exp2, sectorExpirations[exp2]...)
+		faulty, err := harness.s.RemoveSectorsFromDeadlines(harness.store, []*miner.SectorOnChainInfo{sectors[0], sectors[partSize], sectors[partSize+1]})
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{partSize}), faulty)
+		assert.Equal(t, []uint64{partSize - 1}, harness.deadlinePartitionSectorCounts(0))
+		assert.Equal(t, []uint64{8}, harness.deadlinePartitionSectorCounts(1))
 
-		// ensure clearing works
-		harness.clearSectorExpiration(exp1, exp2)
-		empty1 := harness.getSectorExpirations(exp1)
-		assert.Empty(t, empty1)
+		// Removed sectors are no longer located, while the others remain at their partitions.
+		_, _, found, err := harness.s.FindSector(harness.store, sectors[partSize].Info.SectorNumber)
+		require.NoError(t, err)
+		assert.False(t, found)
+		dlIdx, partIdx, found, err := harness.s.FindSector(harness.store, sectors[partSize+2].Info.SectorNumber)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, uint64(1), dlIdx)
+		assert.Equal(t, uint64(0), partIdx)
 
-		empty2 := harness.getSectorExpirations(exp2)
-		assert.Empty(t, empty2)
+		// Unassigned sectors are ignored.
+		faulty, err = harness.s.RemoveSectorsFromDeadlines(harness.store, makeSectors(3*partSize, 1))
+		require.NoError(t, err)
+		assertEmptyBitfield(t, faulty)
 	})
 }
 
-func TestFaultStore(t *testing.T) {
-	fault1 := abi.ChainEpoch(10)
-	fault2 := abi.ChainEpoch(20)
+func TestSectorStatus(t *testing.T) {
+	harness := constructStateHarness(t, abi.ChainEpoch(0))
+	harness.putPreCommit(newSectorPreCommitOnChainInfo(1, tutils.MakeCID("1"), abi.NewTokenAmount(1), 0))
+	sectors := []*miner.SectorOnChainInfo{testSector(100, 2, 0), testSector(200, 3, 0), testSector(300, 4, 0)}
+	for _, sector := range sectors {
+		harness.putSector(sector)
+	}
+	harness.assignNewSectors(sectors[1:]...)
 
-	sectorFaults := make(map[abi.ChainEpoch][]uint64)
-	faultSet1 := []uint64{1, 2, 3, 4, 5}
-	faultSet2 := []uint64{6, 7, 8, 9, 10, 11}
-	sectorFaults[fault1] = faultSet1
-	sectorFaults[fault2] = faultSet2
+	// Fault both assigned sectors, and declare one recovering.
+	dl, err := harness.s.LoadDeadline(harness.store, 0)
+	require.NoError(t, err)
+	partition, err := dl.LoadPartition(harness.store, 0)
+	require.NoError(t, err)
+	require.NoError(t, partition.AddFaults(harness.store, SectorSize, 0, sectors[1:]...))
+	require.NoError(t, partition.AddRecoveries(bitfield.NewFromSet([]uint64{4})))
+	require.NoError(t, dl.SavePartition(harness.store, 0, partition))
+	require.NoError(t, dl.AddPoStSubmissions(bitfield.NewFromSet([]uint64{0})))
+	require.NoError(t, harness.s.SaveDeadline(harness.store, 0, dl))
+
+	expected := map[abi.SectorNumber]miner.SectorStatus{
+		1: {SectorNumber: 1, Status: miner.SectorStatusPreCommitted, Expiration: newSectorPreCommitInfo(1, tutils.MakeCID("1")).Expiration},
+		2: {SectorNumber: 2, Status: miner.SectorStatusActive, Expiration: 100},
+		3: {SectorNumber: 3, Status: miner.SectorStatusFaulty, Expiration: 200, Assigned: true},
+		4: {SectorNumber: 4, Status: miner.SectorStatusRecovering, Expiration: 300, Assigned: true},
+	}
+	for sectorNo, want := range expected {
+		status, found, err := harness.s.GetSectorStatus(harness.store, sectorNo)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, want, *status)
+	}
+	_, found, err := harness.s.GetSectorStatus(harness.store, 5)
+	require.NoError(t, err)
+	assert.False(t, found)
 
-	t.Run("Round trip add remove", func(t *testing.T) {
+	summaries, err := dl.PartitionSummaries(harness.store)
+	require.NoError(t, err)
+	assert.Equal(t, []miner.PartitionSummary{{
+		Index:         0,
+		SectorCount:   2,
+		FaultCount:    2,
+		RecoveryCount: 1,
+		Proven:        true,
+		TotalPower:    miner.PowerForSectors(SectorSize, sectors[1:]),
+		FaultyPower:   miner.PowerForSectors(SectorSize, sectors[1:]),
+	}}, summaries)
+}
+
+func TestPenaltyLedger(t *testing.T) {
+	t.Run("Record and iterate by epoch range", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
 
-		harness.addFaults(fault1, faultSet1...)
-		harness.addFaults(fault2, faultSet2...)
-
-		fault1Hit, fault2Hit := false, false
-		err := harness.s.ForEachFaultEpoch(harness.store, func(epoch abi.ChainEpoch, faults *abi.BitField) error {
-			if epoch == fault1 {
-				sectors, err := faults.All(uint64(len(faultSet1)))
-				require.NoError(t, err)
-				assert.Equal(t, faultSet1, sectors)
-				fault1Hit = true
-			} else if epoch == fault2 {
-				sectors, err := faults.All(uint64(len(faultSet2)))
-				require.NoError(t, err)
-				assert.Equal(t, faultSet2, sectors)
-				fault2Hit = true
-			} else {
-				t.Fatalf("unexpected fault epoch: %v", epoch)
-			}
+		harness.recordPenalty(10, miner.PenaltyDeclaredFault, abi.NewTokenAmount(1), 1, 2)
+		harness.recordPenalty(10, miner.PenaltyTermination, abi.NewTokenAmount(2), 3)
+		harness.recordPenalty(20, miner.PenaltyUndeclaredFault, abi.NewTokenAmount(3), 4)
+		harness.recordPenalty(30, miner.PenaltyConsensusFault, abi.NewTokenAmount(4))
+
+		var epochs []abi.ChainEpoch
+		var causes []miner.PenaltyCause
+		err := harness.s.ForEachPenalty(harness.store, 10, 30, func(epoch abi.ChainEpoch, record *miner.PenaltyRecord) error {
+			epochs = append(epochs, epoch)
+			causes = append(causes, record.Cause)
 			return nil
 		})
 		require.NoError(t, err)
-		assert.True(t, fault1Hit)
-		assert.True(t, fault2Hit)
-
-		// remove the faults
-		harness.removeFaults(faultSet1[1:]...)
-		harness.removeFaults(faultSet2[2:]...)
-
-		fault1Hit, fault2Hit = false, false
-		err = harness.s.ForEachFaultEpoch(harness.store, func(epoch abi.ChainEpoch, faults *abi.BitField) error {
-			if epoch == fault1 {
-				sectors, err := faults.All(uint64(len(faultSet1)))
-				require.NoError(t, err)
-				assert.Equal(t, faultSet1[:1], sectors, "expected: %v, actual: %v", faultSet1[:1], sectors)
-				fault1Hit = true
-			} else if epoch == fault2 {
-				sectors, err := faults.All(uint64(len(faultSet2)))
-				require.NoError(t, err)
-				assert.Equal(t, faultSet2[:2], sectors, "expected: %v, actual: %v", faultSet2[:2], sectors)
-				fault2Hit = true
-
-			} else {
-				t.Fatalf("unexpected fault epoch: %v", epoch)
-			}
+		assert.Equal(t, []abi.ChainEpoch{10, 10, 20}, epochs)
+		assert.Equal(t, []miner.PenaltyCause{miner.PenaltyDeclaredFault, miner.PenaltyTermination, miner.PenaltyUndeclaredFault}, causes)
+
+		total := big.Zero()
+		err = harness.s.ForEachPenalty(harness.store, 11, 100, func(epoch abi.ChainEpoch, record *miner.PenaltyRecord) error {
+			total = big.Add(total, record.Amount)
 			return nil
 		})
 		require.NoError(t, err)
-		assert.True(t, fault1Hit)
-		assert.True(t, fault2Hit)
+		assert.Equal(t, abi.NewTokenAmount(7), total)
 	})
 
-	t.Run("Clear all", func(t *testing.T) {
+	t.Run("Records penalized sectors", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		harness.recordPenalty(10, miner.PenaltyPreCommitExpiry, abi.NewTokenAmount(1), 5, 6)
 
-		harness.addFaults(fault1, faultSet1...)
-		harness.addFaults(fault2, faultSet2...)
-
-		// now clear all the faults
-		err := harness.s.ClearFaultEpochs(harness.store, fault1, fault2)
-		require.NoError(t, err)
-
-		err = harness.s.ForEachFaultEpoch(harness.store, func(epoch abi.ChainEpoch, faults *abi.BitField) error {
-			t.Fatalf("unexpected fault epoch: %v", epoch)
+		err := harness.s.ForEachPenalty(harness.store, 0, 100, func(epoch abi.ChainEpoch, record *miner.PenaltyRecord) error {
+			sectors, err := record.Sectors.All(miner.SectorsMax)
+			require.NoError(t, err)
+			assert.Equal(t, []uint64{5, 6}, sectors)
 			return nil
 		})
 		require.NoError(t, err)
 	})
-}
 
-func TestRecoveriesBitfield(t *testing.T) {
-	t.Run("Add new recoveries happy path", func(t *testing.T) {
+	t.Run("Prunes records past the retention period", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		harness.recordPenalty(10, miner.PenaltyDeclaredFault, abi.NewTokenAmount(1), 1)
+		harness.recordPenalty(20, miner.PenaltyDeclaredFault, abi.NewTokenAmount(2), 1)
+		harness.recordPenalty(10+miner.PenaltyLedgerRetention, miner.PenaltyTermination, abi.NewTokenAmount(3), 1)
 
-		// set of sectors, the larger numbers here are not significant
-		sectorNos := []uint64{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000}
-		harness.addRecoveries(sectorNos...)
-		assert.Equal(t, uint64(len(sectorNos)), harness.getRecoveriesCount())
+		var epochs []abi.ChainEpoch
+		err := harness.s.ForEachPenalty(harness.store, 0, 20+miner.PenaltyLedgerRetention, func(epoch abi.ChainEpoch, record *miner.PenaltyRecord) error {
+			epochs = append(epochs, epoch)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []abi.ChainEpoch{20, 10 + miner.PenaltyLedgerRetention}, epochs)
 	})
 
-	t.Run("Add new recoveries excludes duplicates", func(t *testing.T) {
+	t.Run("Zero penalty is not recorded", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
-
-		sectorNos := []uint64{1, 1, 2, 2, 3, 4, 5}
-		harness.addRecoveries(sectorNos...)
-		assert.Equal(t, uint64(5), harness.getRecoveriesCount())
+		emptyLedger := harness.s.PenaltyLedger
+		harness.recordPenalty(10, miner.PenaltyDeclaredFault, big.Zero(), 1)
+		assert.Equal(t, emptyLedger, harness.s.PenaltyLedger)
 	})
+}
 
-	t.Run("Remove recoveries happy path", func(t *testing.T) {
-		harness := constructStateHarness(t, abi.ChainEpoch(0))
+func TestPoStSnapshots(t *testing.T) {
+	record := func(t *testing.T, harness *stateHarness, deadline *miner.DeadlineInfo, epoch abi.ChainEpoch) uint64 {
+		idx, err := harness.s.RecordPoStSnapshot(harness.store, deadline, &miner.WindowedPoStSnapshot{
+			SubmissionEpoch: epoch,
+			ChallengeEpoch:  deadline.Challenge,
+			Proven:          bitfield.NewFromSet([]uint64{uint64(epoch)}),
+		})
+		require.NoError(t, err)
+		return idx
+	}
 
-		sectorNos := []uint64{1, 2, 3, 4, 5}
-		harness.addRecoveries(sectorNos...)
-		assert.Equal(t, uint64(len(sectorNos)), harness.getRecoveriesCount())
+	t.Run("Record, get and mark disputed", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		deadline, _ := harness.s.DeadlineInfo(miner.WPoStChallengeWindow)
+		assert.Equal(t, uint64(0), record(t, harness, deadline, deadline.Open))
+		assert.Equal(t, uint64(1), record(t, harness, deadline, deadline.Open+1))
 
-		harness.removeRecoveries(1, 3, 5)
-		assert.Equal(t, uint64(2), harness.getRecoveriesCount())
+		snapshot, found, err := harness.s.GetPoStSnapshot(harness.store, deadline.Index, 1)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, deadline.Open+1, snapshot.SubmissionEpoch)
+		assert.False(t, snapshot.Disputed)
 
-		recoveries, err := harness.s.Recoveries.All(uint64(len(sectorNos)))
-		assert.NoError(t, err)
-		assert.Equal(t, []uint64{2, 4}, recoveries)
-	})
-}
+		_, found, err = harness.s.GetPoStSnapshot(harness.store, deadline.Index, 2)
+		require.NoError(t, err)
+		assert.False(t, found)
+		_, found, err = harness.s.GetPoStSnapshot(harness.store, deadline.Index+1, 0)
+		require.NoError(t, err)
+		assert.False(t, found)
 
-func TestPostSubmissionsBitfield(t *testing.T) {
-	t.Run("Add new submission happy path", func(t *testing.T) {
-		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		require.NoError(t, harness.s.MarkPoStSnapshotDisputed(harness.store, deadline.Index, 0))
+		snapshot, found, err = harness.s.GetPoStSnapshot(harness.store, deadline.Index, 0)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.True(t, snapshot.Disputed)
 
-		// set of sectors, the larger numbers here are not significant
-		partitionNos := []uint64{10, 20, 30, 40}
-		harness.addPoStSubmissions(partitionNos...)
-		assert.Equal(t, uint64(len(partitionNos)), harness.getPoStSubmissionsCount())
+		// Later snapshots keep their index.
+		snapshot, found, err = harness.s.GetPoStSnapshot(harness.store, deadline.Index, 1)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, deadline.Open+1, snapshot.SubmissionEpoch)
 	})
 
-	t.Run("Add new submission excludes duplicates", func(t *testing.T) {
+	t.Run("Snapshots from earlier periods are discarded", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		deadline, _ := harness.s.DeadlineInfo(miner.WPoStChallengeWindow)
+		record(t, harness, deadline, deadline.Open)
+		record(t, harness, deadline, deadline.Open+1)
+
+		next, _ := harness.s.DeadlineInfo(deadline.Open + miner.WPoStProvingPeriod)
+		require.Equal(t, deadline.Index, next.Index)
+		assert.Equal(t, uint64(0), record(t, harness, next, next.Open))
 
-		sectorNos := []uint64{1, 1, 2, 2, 3, 4, 5}
-		harness.addPoStSubmissions(sectorNos...)
-		assert.Equal(t, uint64(5), harness.getPoStSubmissionsCount())
+		_, found, err := harness.s.GetPoStSnapshot(harness.store, deadline.Index, 1)
+		require.NoError(t, err)
+		assert.False(t, found)
 	})
 
-	t.Run("Clear submission happy path", func(t *testing.T) {
+	t.Run("Snapshots are pruned once the last for a deadline may not be disputed", func(t *testing.T) {
 		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		deadline, _ := harness.s.DeadlineInfo(miner.WPoStChallengeWindow)
+		record(t, harness, deadline, deadline.Open)
+		record(t, harness, deadline, deadline.Open+1)
+		later, _ := harness.s.DeadlineInfo(2 * miner.WPoStChallengeWindow)
+		record(t, harness, later, later.Open)
+
+		// The window for the deadline's second snapshot is still open.
+		require.NoError(t, harness.s.PruneExpiredPoStSnapshots(harness.store, deadline.Open+miner.WPoStDisputeWindow))
+		_, found, err := harness.s.GetPoStSnapshot(harness.store, deadline.Index, 0)
+		require.NoError(t, err)
+		assert.True(t, found)
 
-		sectorNos := []uint64{1, 2, 3, 4, 5}
-		harness.addPoStSubmissions(sectorNos...)
-		assert.Equal(t, uint64(len(sectorNos)), harness.getPoStSubmissionsCount())
-
-		harness.clearPoStSubmissions()
-		assert.Equal(t, uint64(0), harness.getPoStSubmissionsCount())
+		require.NoError(t, harness.s.PruneExpiredPoStSnapshots(harness.store, deadline.Open+1+miner.WPoStDisputeWindow))
+		for _, idx := range []uint64{0, 1} {
+			_, found, err = harness.s.GetPoStSnapshot(harness.store, deadline.Index, idx)
+			require.NoError(t, err)
+			assert.False(t, found)
+		}
+		_, found, err = harness.s.GetPoStSnapshot(harness.store, later.Index, 0)
+		require.NoError(t, err)
+		assert.True(t, found)
 	})
 }
 
@@ -440,6 +523,71 @@ func TestVestingFundsStore(t *testing.T) {
 	assert.Equal(t, abi.NewTokenAmount(51), vested)
 }
 
+func TestVestingRewards(t *testing.T) {
+	pledgeSpec := &miner.VestSpec{
+		InitialDelay: 10,
+		VestPeriod:   10,
+		StepDuration: 1,
+		Quantization: 1,
+	}
+	rewardSpec := &miner.VestSpec{
+		InitialDelay: 0,
+		VestPeriod:   1,
+		StepDuration: 1,
+		Quantization: 1,
+	}
+	vestStart := abi.ChainEpoch(10)
+
+	t.Run("rewards are locked and vest separately from pledge", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		harness.addLockedFunds(vestStart, abi.NewTokenAmount(100), pledgeSpec)
+		harness.addLockedRewards(vestStart, abi.NewTokenAmount(30), rewardSpec)
+		assert.Equal(t, abi.NewTokenAmount(100), harness.s.LockedFunds)
+		assert.Equal(t, abi.NewTokenAmount(30), harness.s.LockedRewards)
+		assert.Equal(t, abi.NewTokenAmount(130), harness.s.TotalLockedFunds())
+		assert.Equal(t, abi.NewTokenAmount(70), harness.s.GetAvailableBalance(abi.NewTokenAmount(200)))
+
+		// Rewards vest on their own, shorter schedule.
+		vested := harness.unlockVestedFunds(vestStart + 2)
+		assert.Equal(t, abi.NewTokenAmount(30), vested)
+		assert.True(t, harness.s.LockedRewards.IsZero())
+		assert.Equal(t, abi.NewTokenAmount(100), harness.s.LockedFunds)
+	})
+
+	t.Run("penalties draw on rewards before pledge", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		harness.addLockedFunds(vestStart, abi.NewTokenAmount(100), pledgeSpec)
+		harness.addLockedRewards(vestStart, abi.NewTokenAmount(30), rewardSpec)
+
+		unlocked := harness.unlockUnvestedFunds(vestStart, abi.NewTokenAmount(20))
+		assert.Equal(t, abi.NewTokenAmount(20), unlocked)
+		assert.Equal(t, abi.NewTokenAmount(10), harness.s.LockedRewards)
+		assert.Equal(t, abi.NewTokenAmount(100), harness.s.LockedFunds)
+
+		unlocked = harness.unlockUnvestedFunds(vestStart, abi.NewTokenAmount(50))
+		assert.Equal(t, abi.NewTokenAmount(50), unlocked)
+		assert.True(t, harness.s.LockedRewards.IsZero())
+		assert.Equal(t, abi.NewTokenAmount(60), harness.s.LockedFunds)
+	})
+}
+
+func TestFeeDebtRepayment(t *testing.T) {
+	t.Run("Repaid from available balance", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		harness.s.AddFeeDebt(abi.NewTokenAmount(100))
+		harness.s.AddPreCommitDeposit(abi.NewTokenAmount(20))
+
+		// Only the balance in excess of the pre-commit deposit is available for repayment.
+		repaid := harness.s.RepayDebt(abi.NewTokenAmount(80))
+		assert.Equal(t, abi.NewTokenAmount(60), repaid)
+		assert.Equal(t, abi.NewTokenAmount(40), harness.s.FeeDebt)
+
+		repaid = harness.s.RepayDebt(abi.NewTokenAmount(1000))
+		assert.Equal(t, abi.NewTokenAmount(40), repaid)
+		assert.True(t, harness.s.FeeDebt.IsZero())
+	})
+}
+
 type stateHarness struct {
 	t testing.TB
 
@@ -456,6 +604,11 @@ func (h *stateHarness) addLockedFunds(epoch abi.ChainEpoch, sum abi.TokenAmount,
 	require.NoError(h.t, err)
 }
 
+func (h *stateHarness) addLockedRewards(epoch abi.ChainEpoch, sum abi.TokenAmount, spec *miner.VestSpec) {
+	err := h.s.AddLockedRewards(h.store, epoch, sum, spec)
+	require.NoError(h.t, err)
+}
+
 func (h *stateHarness) unlockUnvestedFunds(epoch abi.ChainEpoch, target abi.TokenAmount) abi.TokenAmount {
 	amount, err := h.s.UnlockUnvestedFunds(h.store, epoch, target)
 	require.NoError(h.t, err)
@@ -469,94 +622,37 @@ func (h *stateHarness) unlockVestedFunds(epoch abi.ChainEpoch) abi.TokenAmount {
 }
 
 //
-// PostSubmissions Bitfield
+// Penalty Ledger
 //
 
-func (h *stateHarness) addPoStSubmissions(partitionNos ...uint64) {
-	err := h.s.AddPoStSubmissions(bitfield.NewFromSet(partitionNos))
+func (h *stateHarness) recordPenalty(epoch abi.ChainEpoch, cause miner.PenaltyCause, amount abi.TokenAmount, sectorNos ...uint64) {
+	err := h.s.RecordPenalty(h.store, epoch, cause, bitfield.NewFromSet(sectorNos), amount)
 	require.NoError(h.t, err)
 }
 
-func (h *stateHarness) clearPoStSubmissions() {
-	err := h.s.ClearPoStSubmissions()
-	require.NoError(h.t, err)
-}
-
-func (h *stateHarness) getPoStSubmissionsCount() uint64 {
-	count, err := h.s.PostSubmissions.Count()
-	require.NoError(h.t, err)
-	return count
-}
-
 //
-// Recoveries Bitfield
-//
-
-func (h *stateHarness) addRecoveries(sectorNos ...uint64) {
-	bf := bitfield.NewFromSet(sectorNos)
-	err := h.s.AddRecoveries(bf)
-	require.NoError(h.t, err)
-}
-
-func (h *stateHarness) removeRecoveries(sectorNos ...uint64) {
-	bf := bitfield.NewFromSet(sectorNos)
-	err := h.s.RemoveRecoveries(bf)
-	require.NoError(h.t, err)
-}
-
-func (h *stateHarness) getRecoveriesCount() uint64 {
-	count, err := h.s.Recoveries.Count()
-	require.NoError(h.t, err)
-	return count
-}
-
-//
-// Faults Store
-//
-
-func (h *stateHarness) addFaults(epoch abi.ChainEpoch, sectorNos ...uint64) {
-	bf := bitfield.NewFromSet(sectorNos)
-	err := h.s.AddFaults(h.store, bf, epoch)
-	require.NoError(h.t, err)
-}
-
-func (h *stateHarness) removeFaults(sectorNos ...uint64) {
-	bf := bitfield.NewFromSet(sectorNos)
-	err := h.s.RemoveFaults(h.store, bf)
-	require.NoError(h.t, err)
-}
-
-//
-// Sector Expiration Store
+// PostSubmissions Bitfield
 //
 
-func (h *stateHarness) getSectorExpirations(expiry abi.ChainEpoch) []uint64 {
-	bf, err := h.s.GetSectorExpirations(h.store, expiry)
-	require.NoError(h.t, err)
-	sectors, err := bf.All(miner.SectorsMax)
-	require.NoError(h.t, err)
-	return sectors
-}
-
-func (h *stateHarness) addSectorExpiration(expiry abi.ChainEpoch, sectors ...uint64) {
-	err := h.s.AddSectorExpirations(h.store, expiry, sectors...)
+func (h *stateHarness) assignNewSectors(sectors ...*miner.SectorOnChainInfo) {
+	err := h.s.AssignNewSectors(h.store, sectors)
 	require.NoError(h.t, err)
 }
 
-func (h *stateHarness) removeSectorExpiration(expiry abi.ChainEpoch, sectors ...uint64) {
-	err := h.s.RemoveSectorExpirations(h.store, expiry, sectors...)
+// Returns the number of sectors in each partition at a deadline.
+func (h *stateHarness) deadlinePartitionSectorCounts(dlIdx uint64) []uint64 {
+	dl, err := h.s.LoadDeadline(h.store, dlIdx)
 	require.NoError(h.t, err)
-}
-
-func (h *stateHarness) clearSectorExpiration(excitations ...abi.ChainEpoch) {
-	err := h.s.ClearSectorExpirations(h.store, excitations...)
+	var counts []uint64
+	err = dl.ForEachPartition(h.store, func(_ uint64, partition *miner.Partition) error {
+		count, err := partition.SectorCount()
+		counts = append(counts, count)
+		return err
+	})
 	require.NoError(h.t, err)
+	return counts
 }
 
-//
-// NewSectors BitField Assertions
-//
-
 func (h *stateHarness) addNewSectors(sectorNos ...abi.SectorNumber) {
 	err := h.s.AddNewSectors(sectorNos...)
 	require.NoError(h.t, err)
@@ -647,14 +743,10 @@ func constructStateHarness(t *testing.T, periodBoundary abi.ChainEpoch) *stateHa
 	emptyArray, err := adt.MakeEmptyArray(store).Root()
 	require.NoError(t, err)
 
-	emptyDeadlines := miner.ConstructDeadlines()
-	emptyDeadlinesCid, err := store.Put(context.Background(), emptyDeadlines)
-	require.NoError(t, err)
-
 	// state field init
 	owner := tutils.NewBLSAddr(t, 1)
 	worker := tutils.NewBLSAddr(t, 2)
-	state := miner.ConstructState(emptyArray, emptyMap, emptyDeadlinesCid, owner, worker, "peer", SectorSize, periodBoundary)
+	state := miner.ConstructState(emptyArray, emptyMap, owner, worker, "peer", SectorSize, periodBoundary)
 
 	// assert NewSectors bitfield was constructed correctly (empty)
 	newSectorsCount, err := state.NewSectors.Count()
@@ -687,10 +779,13 @@ func newSectorPreCommitOnChainInfo(sectorNo abi.SectorNumber, sealed cid.Cid, de
 func newSectorOnChainInfo(sectorNo abi.SectorNumber, sealed cid.Cid, weight big.Int, activation abi.ChainEpoch) *miner.SectorOnChainInfo {
 	info := newSectorPreCommitInfo(sectorNo, sealed)
 	return &miner.SectorOnChainInfo{
-		Info:               *info,
-		ActivationEpoch:    activation,
-		DealWeight:         weight,
-		VerifiedDealWeight: weight,
+		Info:                  *info,
+		ActivationEpoch:       activation,
+		DealWeight:            weight,
+		VerifiedDealWeight:    weight,
+		InitialPledge:         big.Zero(),
+		ExpectedDayReward:     big.Zero(),
+		ExpectedStoragePledge: big.Zero(),
 	}
 }
 
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"fmt"
 	"testing"
 
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
+	cid "github.com/ipfs/go-cid"
+	peer "github.com/libp2p/go-libp2p-core/peer"
 	"github.com/minio/blake2b-simd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,7 +23,9 @@ import (
 	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
 	"github.com/filecoin-project/specs-actors/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/actors/crypto"
+	"github.com/filecoin-project/specs-actors/actors/runtime"
 	"github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/support/testing"
 )
@@ -74,20 +79,28 @@ func TestConstruction(t *testing.T) {
 		assert.Equal(t, big.Zero(), st.PreCommitDeposits)
 		assert.Equal(t, big.Zero(), st.LockedFunds)
 		assert.True(t, st.VestingFunds.Defined())
+		assert.Equal(t, big.Zero(), st.LockedRewards)
+		assert.True(t, st.VestingRewards.Defined())
 		assert.True(t, st.PreCommittedSectors.Defined())
 		assertEmptyBitfield(t, st.NewSectors)
-		assert.True(t, st.SectorExpirations.Defined())
 		assert.True(t, st.Deadlines.Defined())
-		assertEmptyBitfield(t, st.Faults)
-		assert.True(t, st.FaultEpochs.Defined())
-		assertEmptyBitfield(t, st.Recoveries)
-		assertEmptyBitfield(t, st.PostSubmissions)
-
-		var deadlines miner.Deadlines
-		assert.True(t, rt.Store().Get(st.Deadlines, &deadlines))
-		for i := uint64(0); i < miner.WPoStPeriodDeadlines; i++ {
-			assertEmptyBitfield(t, deadlines.Due[i])
-		}
+
+		deadlines, err := miner.AsDeadlineArray(adt.AsStore(rt), st.Deadlines)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), deadlines.Length())
+	})
+
+	t.Run("oversized peer ID rejected", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.ExpectValidateCallerAddr(builtin.InitActorAddr)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.Constructor, &miner.ConstructorParams{
+				OwnerAddr:  owner,
+				WorkerAddr: worker,
+				SectorSize: SectorSize,
+				PeerId:     peer.ID(make([]byte, miner.MaxPeerIDLength+1)),
+			})
+		})
 	})
 }
 
@@ -108,16 +121,1021 @@ func TestControlAddresses(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt, miner.WPoStProvingPeriod)
 
-		o, w := actor.controlAddresses(rt)
+		o, w, c := actor.controlAddresses(rt)
 		assert.Equal(t, owner, o)
 		assert.Equal(t, worker, w)
+		assert.Empty(t, c)
+	})
+
+	t.Run("change control addresses", func(t *testing.T) {
+		control1 := tutil.NewIDAddr(t, 501)
+		control2 := tutil.NewIDAddr(t, 502)
+		rt := builder.WithActorType(control1, builtin.AccountActorCodeID).WithActorType(control2, builtin.MultisigActorCodeID).Build(t)
+		actor.constructAndVerify(rt, miner.WPoStProvingPeriod)
+
+		// Only the owner may change control addresses.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			actor.changeControlAddresses(rt, worker, []addr.Address{control1})
+		})
+
+		actor.changeControlAddresses(rt, owner, []addr.Address{control1, control2})
+		_, _, c := actor.controlAddresses(rt)
+		assert.Equal(t, []addr.Address{control1, control2}, c)
+
+		// A control address may pre-commit sectors on behalf of the worker.
+		rt.SetEpoch(1)
+		precommit := makePreCommit(100, rt.GetEpoch()-miner.PreCommitChallengeDelay, 2*miner.WPoStProvingPeriod-1)
+		rt.SetCaller(control2, builtin.MultisigActorCodeID)
+		rt.ExpectValidateCallerAddr(worker, control1, control2)
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.EnrollCronEvent, makePreCommitExpiryCronEventParams(t, rt, precommit),
+			big.Zero(), nil, exitcode.Ok)
+		rt.Call(actor.a.PreCommitSector, precommit)
+		rt.Verify()
+
+		// And withdraw them.
+		rt.SetCaller(control2, builtin.MultisigActorCodeID)
+		rt.ExpectValidateCallerAddr(worker, control1, control2)
+		rt.Call(actor.a.WithdrawPreCommits, &miner.WithdrawPreCommitsParams{Sectors: bitfield.NewFromSet([]uint64{100})})
+		rt.Verify()
+
+		// Other messages remain with the worker.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(control2, builtin.MultisigActorCodeID)
+			rt.ExpectValidateCallerAddr(worker)
+			rt.Call(actor.a.ChangePeerID, &miner.ChangePeerIDParams{NewID: "new"})
+		})
+
+		// Control addresses must be principal actors.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeControlAddresses(rt, owner, []addr.Address{builtin.StoragePowerActorAddr})
+		})
+
+		actor.changeControlAddresses(rt, owner, nil)
+		_, _, c = actor.controlAddresses(rt)
+		assert.Empty(t, c)
+	})
+
+	t.Run("change owner", func(t *testing.T) {
+		newOwner := tutil.NewIDAddr(t, 102)
+		other := tutil.NewIDAddr(t, 103)
+		rt := builder.WithActorType(newOwner, builtin.AccountActorCodeID).WithActorType(other, builtin.AccountActorCodeID).Build(t)
+		actor.constructAndVerify(rt, miner.WPoStProvingPeriod)
+
+		// Only the owner may propose a change.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			actor.changeOwnerAddress(rt, newOwner, newOwner)
+		})
+
+		actor.changeOwnerAddress(rt, owner, newOwner)
+		assert.Equal(t, &miner.OwnerChange{NewOwner: newOwner}, getState(rt).Info.PendingOwnerChange)
+		o, _, _ := actor.controlAddresses(rt)
+		assert.Equal(t, owner, o)
+
+		// The proposed owner must confirm the same address.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeOwnerAddress(rt, newOwner, other)
+		})
+
+		actor.changeOwnerAddress(rt, newOwner, newOwner)
+		st := getState(rt)
+		assert.Equal(t, newOwner, st.Info.Owner)
+		assert.Nil(t, st.Info.PendingOwnerChange)
+
+		// Withdrawals are now made by and paid to the new owner.
+		amount := abi.NewTokenAmount(100)
+		rt.SetBalance(amount)
+		rt.SetCaller(newOwner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(newOwner)
+		rt.ExpectSend(newOwner, builtin.MethodSend, nil, amount, nil, exitcode.Ok)
+		rt.Call(actor.a.WithdrawBalance, &miner.WithdrawBalanceParams{AmountRequested: amount})
+		rt.Verify()
+	})
+
+	t.Run("revoke owner change", func(t *testing.T) {
+		newOwner := tutil.NewIDAddr(t, 102)
+		rt := builder.WithActorType(newOwner, builtin.AccountActorCodeID).Build(t)
+		actor.constructAndVerify(rt, miner.WPoStProvingPeriod)
+
+		actor.changeOwnerAddress(rt, owner, newOwner)
+		actor.changeOwnerAddress(rt, owner, owner)
+		assert.Nil(t, getState(rt).Info.PendingOwnerChange)
+
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			actor.changeOwnerAddress(rt, newOwner, newOwner)
+		})
+	})
+
+	t.Run("change peer ID and multiaddrs", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt, miner.WPoStProvingPeriod)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(worker, builtin.AccountActorCodeID)
+			rt.Call(actor.a.ChangePeerID, &miner.ChangePeerIDParams{NewID: peer.ID(make([]byte, miner.MaxPeerIDLength+1))})
+		})
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(worker, builtin.AccountActorCodeID)
+			rt.Call(actor.a.ChangePeerID, &miner.ChangePeerIDParams{NewID: ""})
+		})
+
+		addrs := [][]byte{[]byte("/ip4/1.2.3.4/tcp/1234"), []byte("/ip6/::1/tcp/1234")}
+		actor.changeMultiaddrs(rt, owner, addrs)
+		assert.Equal(t, addrs, getState(rt).GetInfo().Multiaddrs)
+		actor.changeMultiaddrs(rt, worker, addrs[1:])
+		assert.Equal(t, addrs[1:], getState(rt).GetInfo().Multiaddrs)
+
+		// Anyone may read the peer info.
+		info := actor.getInfo(rt, tutil.NewIDAddr(t, 103))
+		assert.Equal(t, peer.ID("peer"), info.PeerId)
+		assert.Equal(t, addrs[1:], info.Multiaddrs)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeMultiaddrs(rt, worker, [][]byte{{}})
+		})
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeMultiaddrs(rt, worker, [][]byte{make([]byte, miner.MaxMultiaddrData+1)})
+		})
+
+		// Only the owner and worker may change the multiaddrs.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			actor.changeMultiaddrs(rt, tutil.NewIDAddr(t, 103), addrs)
+		})
+	})
+
+	// TODO: test changing worker (with delay)
+}
+
+// Test for sector precommitment and proving.
+func TestCommitments(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("invalid pre-commit rejected", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		st := getState(rt)
+		deadline, _ := st.DeadlineInfo(precommitEpoch)
+
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+
+		// Good commitment.
+		actor.preCommitSector(rt, makePreCommit(100, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+
+		// Duplicate sector ID
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.preCommitSector(rt, makePreCommit(100, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		})
+
+		// Expires at current epoch
+		rt.SetEpoch(deadline.PeriodEnd())
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.preCommitSector(rt, makePreCommit(111, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		})
+
+		// Expires before current epoch
+		rt.SetEpoch(deadline.PeriodEnd() + 1)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.preCommitSector(rt, makePreCommit(112, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		})
+
+		// Expires not on period end
+		rt.SetEpoch(precommitEpoch)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.preCommitSector(rt, makePreCommit(113, challengeEpoch, deadline.PeriodEnd()-1), big.Zero())
+		})
+
+		// TODO: test insufficient funds when the precommit deposit is set above zero
+	})
+
+	t.Run("batch pre-commit", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+		withDeals := makePreCommit(101, challengeEpoch, deadline.PeriodEnd())
+		withDeals.DealIDs = []abi.DealID{1, 2}
+		precommits := []miner.SectorPreCommitInfo{*makePreCommit(100, challengeEpoch, deadline.PeriodEnd()), *withDeals}
+		actor.preCommitSectorBatch(rt, &miner.PreCommitSectorBatchParams{Sectors: precommits})
+
+		st := getState(rt)
+		for _, pc := range precommits {
+			onChain, found, err := st.GetPrecommittedSector(adt.AsStore(rt), pc.SectorNumber)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, pc, onChain.Info)
+			assert.Equal(t, precommitEpoch, onChain.PreCommitEpoch)
+		}
+
+		// Any invalid sector fails the whole batch.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.PreCommitSectorBatch, &miner.PreCommitSectorBatchParams{Sectors: []miner.SectorPreCommitInfo{
+				*makePreCommit(102, challengeEpoch, deadline.PeriodEnd()),
+				*makePreCommit(100, challengeEpoch, deadline.PeriodEnd()),
+			}})
+		})
+		_, found, err := getState(rt).GetPrecommittedSector(adt.AsStore(rt), 102)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		// A sector number repeated within the batch fails the batch.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.PreCommitSectorBatch, &miner.PreCommitSectorBatchParams{Sectors: []miner.SectorPreCommitInfo{
+				*makePreCommit(103, challengeEpoch, deadline.PeriodEnd()),
+				*makePreCommit(103, challengeEpoch, deadline.PeriodEnd()),
+			}})
+		})
+	})
+
+	t.Run("batch prove-commit proves only valid sectors", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+		precommits := []*miner.SectorPreCommitInfo{
+			makePreCommit(100, challengeEpoch, deadline.PeriodEnd()),
+			makePreCommit(101, challengeEpoch, deadline.PeriodEnd()),
+		}
+		for _, pc := range precommits {
+			actor.preCommitSector(rt, pc, big.Zero())
+		}
+
+		// Sector 101's proof is invalid, and sector 102 was never pre-committed.
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		proven := actor.proveCommitSectors(rt, precommits, []bool{true, false}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(100), *makeProveCommit(101), *makeProveCommit(102)},
+		})
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100}), proven)
+		assert.Equal(t, []runtime.Event{&miner.SectorProven{SectorNumber: 100, Expiration: deadline.PeriodEnd()}}, rt.Events())
+
+		st := getState(rt)
+		_, found, err := st.GetSector(adt.AsStore(rt), 100)
+		require.NoError(t, err)
+		assert.True(t, found)
+		_, found, err = st.GetPrecommittedSector(adt.AsStore(rt), 101)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		// A batch with no valid proofs is rejected.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.proveCommitSectors(rt, precommits[1:], []bool{false}, &miner.ProveCommitSectorsParams{
+				Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(101)},
+			})
+		})
 	})
 
-	// TODO: test changing worker (with delay), changing peer id
+	t.Run("committed capacity sector upgrade", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+		expiration := deadline.PeriodEnd() + 3*miner.WPoStProvingPeriod
+
+		// Commit a committed-capacity sector, and assign it to a partition at the end of the period.
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+		oldSector := makePreCommit(100, challengeEpoch, expiration)
+		actor.preCommitSector(rt, oldSector, big.Zero())
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, []*miner.SectorPreCommitInfo{oldSector}, []bool{true}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(100)},
+		})
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCron(rt)
+		rt.SetEpoch(deadline.NextPeriodStart() + 1)
+
+		// Replacements must commit deals, and name a committed-capacity sector that exists.
+		makeUpgrade := func(sectorNo, replaced abi.SectorNumber, dealIDs []abi.DealID) *miner.SectorPreCommitInfo {
+			pc := makePreCommit(sectorNo, rt.GetEpoch()-1, expiration)
+			pc.DealIDs = dealIDs
+			pc.ReplaceCapacity = true
+			pc.ReplaceSector = replaced
+			return pc
+		}
+		rejectPreCommit := func(code exitcode.ExitCode, pc *miner.SectorPreCommitInfo) {
+			rt.ExpectAbort(code, func() {
+				rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+				rt.ExpectValidateCallerAddr(actor.worker)
+				rt.Call(actor.a.PreCommitSector, pc)
+			})
+		}
+		rejectPreCommit(exitcode.ErrIllegalArgument, makeUpgrade(101, 100, nil))
+		rejectPreCommit(exitcode.ErrNotFound, makeUpgrade(101, 99, []abi.DealID{1}))
+
+		// A replacement may not expire before the sector it replaces.
+		early := makeUpgrade(101, 100, []abi.DealID{1})
+		early.Expiration = expiration - miner.WPoStProvingPeriod
+		rejectPreCommit(exitcode.ErrIllegalArgument, early)
+
+		newSector := makeUpgrade(101, 100, []abi.DealID{1})
+		actor.preCommitSector(rt, newSector, big.Zero())
+		rt.SetEpoch(rt.GetEpoch() + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, []*miner.SectorPreCommitInfo{newSector}, []bool{true}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(101)},
+		})
+
+		// The old sector now expires at the end of the current proving period, and the new one in its place.
+		st := getState(rt)
+		upgradeDeadline, _ := st.DeadlineInfo(rt.GetEpoch())
+		replaced, found, err := st.GetSector(adt.AsStore(rt), 100)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, upgradeDeadline.PeriodEnd(), replaced.Info.Expiration)
+		assert.True(t, replaced.InitialPledge.IsZero())
+
+		// The old sector's partition schedules it to expire, while the new one awaits assignment.
+		partition := loadPartitionForSector(t, rt, 100)
+		expiring, err := partition.ExpiringSectors(adt.AsStore(rt), upgradeDeadline.PeriodEnd())
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100}), expiring)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{101}), st.NewSectors)
+
+		// A sector with deals cannot itself be replaced.
+		rejectPreCommit(exitcode.ErrIllegalArgument, makeUpgrade(102, 101, []abi.DealID{2}))
+	})
+
+	t.Run("withdraw pre-commits", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+
+		precommit := makePreCommit(100, challengeEpoch, deadline.PeriodEnd())
+		precommit.DealIDs = []abi.DealID{1, 2}
+		actor.preCommitSector(rt, precommit, big.Zero())
+		actor.preCommitSector(rt, makePreCommit(101, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+
+		// Give sector 100 a deposit.
+		msd := miner.MaxSealDuration[precommit.RegisteredProof]
+		deposit := abi.NewTokenAmount(4000)
+		st := getState(rt)
+		onChain, found, err := st.GetPrecommittedSector(adt.AsStore(rt), 100)
+		require.NoError(t, err)
+		require.True(t, found)
+		onChain.PreCommitDeposit = deposit
+		require.NoError(t, st.PutPrecommittedSector(adt.AsStore(rt), onChain))
+		st.AddPreCommitDeposit(deposit)
+		rt.ReplaceState(st)
+		rt.SetBalance(deposit)
+
+		// A quarter of the way through the maximum seal duration, three quarters of the deposit is refunded
+		// to the available balance and the remainder burnt.
+		elapsed := msd / 4
+		refund := big.Div(big.Mul(deposit, big.NewInt(int64(msd-elapsed))), big.NewInt(int64(msd)))
+		burnt := big.Sub(deposit, refund)
+		require.True(t, refund.GreaterThan(big.Zero()))
+		require.True(t, burnt.GreaterThan(big.Zero()))
+		rt.SetEpoch(precommitEpoch + elapsed)
+		actor.withdrawPreCommits(rt, burnt, 100)
+		assert.Equal(t, []runtime.Event{&miner.PreCommitsWithdrawn{
+			Sectors: bitfield.NewFromSet([]uint64{100}),
+			DealIDs: []abi.DealID{1, 2},
+		}}, rt.Events())
+		st = getState(rt)
+		_, found, err = st.GetPrecommittedSector(adt.AsStore(rt), 100)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.True(t, st.PreCommitDeposits.IsZero())
+		assert.Equal(t, refund, st.GetAvailableBalance(rt.GetBalance()))
+
+		// A sector cannot be withdrawn twice.
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			actor.withdrawPreCommits(rt, big.Zero(), 100)
+		})
+
+		// Nor once its pre-commit has expired.
+		rt.SetEpoch(precommitEpoch + msd + 1)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.withdrawPreCommits(rt, big.Zero(), 101)
+		})
+
+		// At least one sector must be listed.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.a.WithdrawPreCommits, &miner.WithdrawPreCommitsParams{Sectors: abi.NewBitField()})
+		})
+	})
+
+	t.Run("sector numbers are never re-used", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+		rejectPreCommit := func(pc *miner.SectorPreCommitInfo) {
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+				rt.ExpectValidateCallerAddr(actor.worker)
+				rt.Call(actor.a.PreCommitSector, pc)
+			})
+		}
+
+		// A withdrawn pre-commit's number cannot be pre-committed again.
+		actor.preCommitSector(rt, makePreCommit(100, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		actor.withdrawPreCommits(rt, big.Zero(), 100)
+		rejectPreCommit(makePreCommit(100, challengeEpoch, deadline.PeriodEnd()))
+
+		// Only the owner may mask numbers.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.owner)
+			rt.Call(actor.a.MaskSectorNumbers, &miner.MaskSectorNumbersParams{Sectors: bitfield.NewFromSet([]uint64{101})})
+		})
+
+		// Masked numbers cannot be pre-committed, while those around them can.
+		actor.maskSectorNumbers(rt, bitfield.NewFromSet([]uint64{100, 101, 102, 103}))
+		rejectPreCommit(makePreCommit(102, challengeEpoch, deadline.PeriodEnd()))
+		actor.preCommitSector(rt, makePreCommit(104, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100, 101, 102, 103, 104}), getState(rt).AllocatedSectors)
+	})
+
+	// TODO
+	// already proven
+	// commitment expires before proof
+	// commitment proven ok
+}
+
+func TestProvingPeriodCron(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("empty period", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt, periodBoundary)
+
+		rt.SetEpoch(periodBoundary - 1)
+		actor.onProvingPeriodCron(rt) // Checks that the expected re-enrollment is made for next period.
+
+		rt.SetEpoch(periodBoundary + miner.WPoStProvingPeriod - 1)
+		actor.onProvingPeriodCron(rt)
+	})
+}
+
+func TestTerminateSectors(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("termination fee grows with sector age", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+
+		precommit := makePreCommit(100, precommitEpoch-miner.PreCommitChallengeDelay, deadline.PeriodEnd()+100*miner.WPoStProvingPeriod)
+		actor.preCommitSector(rt, precommit, big.Zero())
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, []*miner.SectorPreCommitInfo{precommit}, []bool{true}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(100)},
+		})
+
+		sector, found, err := getState(rt).GetSector(adt.AsStore(rt), 100)
+		require.NoError(t, err)
+		require.True(t, found)
+		dayReward := big.Mul(expectedEpochReward, big.NewInt(miner.EpochsInDay))
+		storagePledge := big.Mul(expectedEpochReward, big.NewInt(int64(miner.StoragePledgeProjectionPeriod)))
+		assert.Equal(t, dayReward, sector.ExpectedDayReward)
+		assert.Equal(t, storagePledge, sector.ExpectedStoragePledge)
+
+		// After ten days, half the reward expected over those days is forfeit in addition to the storage pledge.
+		rt.SetEpoch(sector.ActivationEpoch + 10*miner.EpochsInDay)
+		fee := actor.terminateSectors(rt, sector)
+		assert.Equal(t, big.Add(storagePledge, big.Mul(dayReward, big.NewInt(5))), fee)
+
+		// With no pledge locked, the fee is owed as debt.
+		assert.Equal(t, fee, getState(rt).FeeDebt)
+	})
+}
+
+func TestExtendSectorExpirations(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	// Proves sectors 100-102, and assigns them to the first partition at deadline 0 at the end of the period.
+	// Returns at the start of the next period, with the sectors' expiration.
+	setup := func(t *testing.T) (*mock.Runtime, abi.ChainEpoch) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+		expiration := deadline.PeriodEnd() + 2*miner.WPoStProvingPeriod
+
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+		var precommits []*miner.SectorPreCommitInfo
+		var proofs []miner.ProveCommitSectorParams
+		for _, sectorNo := range []abi.SectorNumber{100, 101, 102} {
+			precommit := makePreCommit(sectorNo, challengeEpoch, expiration)
+			actor.preCommitSector(rt, precommit, big.Zero())
+			precommits = append(precommits, precommit)
+			proofs = append(proofs, *makeProveCommit(sectorNo))
+		}
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, precommits, []bool{true, true, true}, &miner.ProveCommitSectorsParams{Sectors: proofs})
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCron(rt)
+		rt.SetEpoch(deadline.NextPeriodStart())
+		return rt, expiration
+	}
+
+	t.Run("extends sectors in a batch", func(t *testing.T) {
+		rt, expiration := setup(t)
+		rt.SetEpoch(rt.GetEpoch() + 1)
+
+		// Expirations may be neither reduced, nor off the proving period boundary.
+		rejectExtension := func(code exitcode.ExitCode, ext miner.ExpirationExtension) {
+			rt.ExpectAbort(code, func() {
+				rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+				rt.ExpectValidateCallerAddr(actor.worker)
+				rt.Call(actor.a.ExtendSectorExpirations, &miner.ExtendSectorExpirationsParams{
+					Extensions: []miner.ExpirationExtension{ext},
+				})
+			})
+		}
+		rejectExtension(exitcode.ErrIllegalArgument, miner.ExpirationExtension{
+			Sectors: bitfield.NewFromSet([]uint64{100}), NewExpiration: expiration - miner.WPoStProvingPeriod,
+		})
+		rejectExtension(exitcode.ErrIllegalArgument, miner.ExpirationExtension{
+			Sectors: bitfield.NewFromSet([]uint64{100}), NewExpiration: expiration + 1,
+		})
+		rejectExtension(exitcode.ErrNotFound, miner.ExpirationExtension{
+			Sectors: bitfield.NewFromSet([]uint64{99}), NewExpiration: expiration + miner.WPoStProvingPeriod,
+		})
+
+		// Extend two sectors to one epoch and one to another, locking the increase in their pledge and
+		// re-projecting their rewards.
+		rt.SetBalance(abi.NewTokenAmount(1000))
+		newPledge := abi.NewTokenAmount(100)
+		newEpochReward := big.Add(expectedEpochReward, big.NewInt(5))
+		actor.extendSectorExpirations(rt, []miner.ExpirationExtension{{
+			Sectors:       bitfield.NewFromSet([]uint64{100, 102}),
+			NewExpiration: expiration + miner.WPoStProvingPeriod,
+		}, {
+			Sectors:       bitfield.NewFromSet([]uint64{101}),
+			NewExpiration: expiration + 3*miner.WPoStProvingPeriod,
+		}}, newPledge, newEpochReward)
+
+		st := getState(rt)
+		assert.Equal(t, big.Mul(newPledge, big.NewInt(3)), st.LockedFunds)
+		for sectorNo, exp := range map[abi.SectorNumber]abi.ChainEpoch{
+			100: expiration + miner.WPoStProvingPeriod,
+			101: expiration + 3*miner.WPoStProvingPeriod,
+			102: expiration + miner.WPoStProvingPeriod,
+		} {
+			sector, found, err := st.GetSector(adt.AsStore(rt), sectorNo)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, exp, sector.Info.Expiration)
+			assert.Equal(t, newPledge, sector.InitialPledge)
+			assert.Equal(t, big.Mul(newEpochReward, big.NewInt(miner.EpochsInDay)), sector.ExpectedDayReward)
+			assert.Equal(t, big.Mul(newEpochReward, big.NewInt(int64(miner.StoragePledgeProjectionPeriod))), sector.ExpectedStoragePledge)
+		}
+
+		// The partition's expiration queue has moved the sectors to their new epochs.
+		partition := loadPartitionForSector(t, rt, 100)
+		expiring, err := partition.ExpiringSectors(adt.AsStore(rt), expiration)
+		require.NoError(t, err)
+		assertEmptyBitfield(t, expiring)
+		expiring, err = partition.ExpiringSectors(adt.AsStore(rt), expiration+miner.WPoStProvingPeriod)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100, 102}), expiring)
+		expiring, err = partition.ExpiringSectors(adt.AsStore(rt), expiration+3*miner.WPoStProvingPeriod)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100, 101, 102}), expiring)
+	})
+
+	t.Run("faulty sectors cannot be extended", func(t *testing.T) {
+		rt, expiration := setup(t)
+		locked := abi.NewTokenAmount(1000000)
+		rt.SetBalance(locked)
+		actor.addLockedFund(rt, locked, big.Zero(), locked)
+
+		// Prove the sectors at deadline 0, then declare sector 101 faulty once the deadline has passed.
+		actor.submitWindowedPoSt(rt, makeWindowedPoStParams(0, []uint64{0}, abi.NewBitField()), poStExpectations{})
+		rt.SetEpoch(rt.GetEpoch() + miner.WPoStChallengeWindow)
+		sector, found, err := getState(rt).GetSector(adt.AsStore(rt), 101)
+		require.NoError(t, err)
+		require.True(t, found)
+		actor.declareFaults(rt, 0, 0, []*miner.SectorOnChainInfo{sector},
+			big.Mul(expectedEpochReward, big.NewInt(int64(miner.DeclaredFaultProjectionPeriod))))
+
+		// The extension is rejected before any power is modified, since no message to the power actor is expected.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.ExtendSectorExpirations, &miner.ExtendSectorExpirationsParams{
+				Extensions: []miner.ExpirationExtension{{
+					Sectors:       bitfield.NewFromSet([]uint64{100, 101}),
+					NewExpiration: expiration + miner.WPoStProvingPeriod,
+				}},
+			})
+		})
+		for _, sectorNo := range []abi.SectorNumber{100, 101} {
+			sector, found, err := getState(rt).GetSector(adt.AsStore(rt), sectorNo)
+			require.NoError(t, err)
+			require.True(t, found)
+			assert.Equal(t, expiration, sector.Info.Expiration)
+		}
+	})
+}
+
+func TestSectorQueries(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("sector status and deadline summary", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+		expiration := deadline.PeriodEnd() + 2*miner.WPoStProvingPeriod
+
+		precommit := makePreCommit(100, precommitEpoch-miner.PreCommitChallengeDelay, expiration)
+		actor.preCommitSector(rt, precommit, big.Zero())
+		assert.Equal(t, &miner.SectorStatus{
+			SectorNumber: 100,
+			Status:       miner.SectorStatusPreCommitted,
+			Expiration:   expiration,
+		}, actor.getSectorStatus(rt, 100))
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			actor.getSectorStatus(rt, 101)
+		})
+
+		// A proven sector is active, and assigned to a deadline at the end of the period.
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, []*miner.SectorPreCommitInfo{precommit}, []bool{true}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(100)},
+		})
+		assert.Equal(t, &miner.SectorStatus{
+			SectorNumber: 100,
+			Status:       miner.SectorStatusActive,
+			Expiration:   expiration,
+		}, actor.getSectorStatus(rt, 100))
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCron(rt)
+
+		status := actor.getSectorStatus(rt, 100)
+		assert.Equal(t, miner.SectorStatusActive, status.Status)
+		assert.True(t, status.Assigned)
+
+		// The summary of the sector's deadline, once current, shows its partition awaiting proof.
+		rt.SetEpoch(deadline.NextPeriodStart() + abi.ChainEpoch(status.Deadline)*miner.WPoStChallengeWindow)
+		summary := actor.getDeadlineSummary(rt)
+		assert.Equal(t, status.Deadline, summary.Deadline.Index)
+		require.Len(t, summary.Partitions, 1)
+		assert.Equal(t, status.Partition, summary.Partitions[0].Index)
+		assert.Equal(t, uint64(1), summary.Partitions[0].SectorCount)
+		assert.False(t, summary.Partitions[0].Proven)
+	})
+}
+
+func TestReportConsensusFault(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	reporter := tutil.NewIDAddr(t, 1001)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("terminates deals, forfeits funds and rejects later reports", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+		expiration := deadline.PeriodEnd() + 2*miner.WPoStProvingPeriod
+
+		// Prove a sector with deals, and leave another pre-committed.
+		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+		precommit := makePreCommit(100, challengeEpoch, expiration)
+		precommit.DealIDs = []abi.DealID{1, 2}
+		actor.preCommitSector(rt, precommit, big.Zero())
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, []*miner.SectorPreCommitInfo{precommit}, []bool{true}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(100)},
+		})
+		actor.preCommitSector(rt, makePreCommit(101, rt.GetEpoch()-1, expiration), big.Zero())
+
+		balance := abi.NewTokenAmount(1000000)
+		rt.SetBalance(balance)
+		faultEpoch := rt.GetEpoch() - 1
+		rt.SetConsensusFaultVerifier(func(h1, h2, extra []byte) (*runtime.ConsensusFault, error) {
+			return &runtime.ConsensusFault{Target: receiver, Epoch: faultEpoch, Type: runtime.ConsensusFaultDoubleForkMining}, nil
+		})
+
+		// The reporter's share grows from 0.1% of the balance by 1.251% per epoch since the fault.
+		reward := abi.NewTokenAmount(1012)
+		actor.reportConsensusFault(rt, reporter, []abi.DealID{1, 2}, reward, big.Sub(balance, reward))
+
+		st := getState(rt)
+		assert.Equal(t, &miner.ConsensusFaultRecord{
+			FaultEpoch:  faultEpoch,
+			ReportEpoch: rt.GetEpoch(),
+			Reporter:    reporter,
+		}, st.ConsensusFault)
+		_, found, err := st.GetPrecommittedSector(adt.AsStore(rt), 101)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.True(t, st.PreCommitDeposits.IsZero())
+
+		// Only the record remains of the miner's sectors and funds.
+		emptyArray, err := adt.MakeEmptyArray(adt.AsStore(rt)).Root()
+		require.NoError(t, err)
+		for name, root := range map[string]cid.Cid{
+			"sectors":          st.Sectors,
+			"deadlines":        st.Deadlines,
+			"sector locations": st.SectorLocations,
+			"PoSt snapshots":   st.PoStSnapshots,
+			"vesting funds":    st.VestingFunds,
+			"vesting rewards":  st.VestingRewards,
+		} {
+			assert.Equal(t, emptyArray, root, name)
+		}
+		assertEmptyBitfield(t, st.NewSectors)
+		assertEmptyBitfield(t, st.AllocatedSectors)
+		assert.Equal(t, big.Zero(), st.TotalLockedFunds())
+		assert.True(t, st.FeeDebt.IsZero())
+
+		// The forfeit is recorded in the penalty ledger against both the proven and pre-committed sectors.
+		var penalties []miner.PenaltyRecord
+		err = st.ForEachPenalty(adt.AsStore(rt), 0, rt.GetEpoch()+1, func(epoch abi.ChainEpoch, record *miner.PenaltyRecord) error {
+			assert.Equal(t, rt.GetEpoch(), epoch)
+			penalties = append(penalties, *record)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, penalties, 1)
+		assert.Equal(t, miner.PenaltyConsensusFault, penalties[0].Cause)
+		assert.Equal(t, big.Sub(balance, reward), penalties[0].Amount)
+		slashed, err := penalties[0].Sectors.All(miner.SectorsMax)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{100, 101}, slashed)
+
+		// The same fault cannot be reported again.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(reporter, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.Call(actor.a.ReportConsensusFault, &miner.ReportConsensusFaultParams{})
+		})
+
+		// The miner's state may no longer change, and its cron events lapse without re-enrolling.
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.PreCommitSector, makePreCommit(102, rt.GetEpoch()-1, expiration))
+		})
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.DeclareFaults, &miner.DeclareFaultsParams{})
+		})
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.TerminateSectors, &miner.TerminateSectorsParams{Sectors: bitfield.NewFromSet([]uint64{100})})
+		})
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.SetCaller(actor.owner, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.owner)
+			rt.Call(actor.a.WithdrawBalance, &miner.WithdrawBalanceParams{AmountRequested: big.Zero()})
+		})
+		rt.SetEpoch(deadline.PeriodEnd())
+		rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
+		rt.Call(actor.a.OnDeferredCronEvent, &miner.CronEventPayload{EventType: miner.CronEventProvingPeriod})
+		rt.Verify()
+	})
+
+	t.Run("fault must be by the miner", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetEpoch(periodBoundary + 1)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		rt.SetConsensusFaultVerifier(func(h1, h2, extra []byte) (*runtime.ConsensusFault, error) {
+			return &runtime.ConsensusFault{Target: tutil.NewIDAddr(t, 1002), Epoch: periodBoundary}, nil
+		})
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(reporter, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.Call(actor.a.ReportConsensusFault, &miner.ReportConsensusFaultParams{})
+		})
+	})
+}
+
+func TestFeeDebt(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("debt blocks pre-commit and withdrawal until repaid", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+
+		st := getState(rt)
+		st.FeeDebt = abi.NewTokenAmount(100)
+		rt.ReplaceState(st)
+
+		deadline, _ := st.DeadlineInfo(precommitEpoch)
+		precommit := makePreCommit(100, precommitEpoch-miner.PreCommitChallengeDelay, deadline.PeriodEnd()+2*miner.WPoStProvingPeriod)
+		rt.ExpectAbort(exitcode.ErrInsufficientFunds, func() {
+			rt.SetCaller(worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(worker)
+			rt.Call(actor.a.PreCommitSector, precommit)
+		})
+		rt.ExpectAbort(exitcode.ErrInsufficientFunds, func() {
+			rt.SetCaller(owner, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(owner)
+			rt.Call(actor.a.WithdrawBalance, &miner.WithdrawBalanceParams{AmountRequested: abi.NewTokenAmount(1)})
+		})
+
+		// A block reward repays the debt, and only the remainder is locked.
+		reward := abi.NewTokenAmount(150)
+		rt.SetBalance(big.Add(rt.GetBalance(), reward))
+		actor.addLockedFund(rt, reward, abi.NewTokenAmount(100), abi.NewTokenAmount(50))
+
+		st = getState(rt)
+		assert.True(t, st.FeeDebt.IsZero())
+		assert.True(t, st.LockedFunds.IsZero())
+		assert.Equal(t, abi.NewTokenAmount(50), st.LockedRewards)
+
+		actor.preCommitSector(rt, precommit, big.Zero())
+	})
+}
+
+func TestWindowedPoSt(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 100)
+	worker := tutil.NewIDAddr(t, 101)
+	workerKey := tutil.NewBLSAddr(t, 0)
+	receiver := tutil.NewIDAddr(t, 1000)
+	actor := newHarness(t, owner, worker, workerKey)
+	periodBoundary := abi.ChainEpoch(100)
+	builder := mock.NewBuilder(context.Background(), receiver).
+		WithActorType(owner, builtin.AccountActorCodeID).
+		WithActorType(worker, builtin.AccountActorCodeID).
+		WithHasher(fixedHasher(uint64(periodBoundary))).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+
+	t.Run("skipped sectors become faulty", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+
+		// Prove three sectors, assigned to the first partition at deadline 0.
+		expiration := deadline.PeriodEnd() + 2*miner.WPoStProvingPeriod
+		var precommits []*miner.SectorPreCommitInfo
+		var proveCommits []miner.ProveCommitSectorParams
+		for _, sectorNo := range []abi.SectorNumber{100, 101, 102} {
+			precommit := makePreCommit(sectorNo, precommitEpoch-miner.PreCommitChallengeDelay, expiration)
+			actor.preCommitSector(rt, precommit, big.Zero())
+			precommits = append(precommits, precommit)
+			proveCommits = append(proveCommits, *makeProveCommit(sectorNo))
+		}
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, precommits, []bool{true, true, true}, &miner.ProveCommitSectorsParams{Sectors: proveCommits})
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCron(rt)
+		rt.SetEpoch(deadline.NextPeriodStart())
+
+		// Lock some rewards, from which penalties are paid.
+		locked := abi.NewTokenAmount(1000000)
+		rt.SetBalance(locked)
+		actor.addLockedFund(rt, locked, big.Zero(), locked)
+
+		// Mark sector 101 faulty and declared recovering.
+		st := getState(rt)
+		store := adt.AsStore(rt)
+		deadline, _ = st.DeadlineInfo(rt.GetEpoch())
+		require.Equal(t, uint64(0), deadline.Index)
+		sectors := make(map[abi.SectorNumber]*miner.SectorOnChainInfo)
+		for _, sectorNo := range []abi.SectorNumber{100, 101, 102} {
+			dlIdx, partIdx, found, err := st.FindSector(store, sectorNo)
+			require.NoError(t, err)
+			require.True(t, found)
+			require.Equal(t, uint64(0), dlIdx)
+			require.Equal(t, uint64(0), partIdx)
+			sectors[sectorNo], found, err = st.GetSector(store, sectorNo)
+			require.NoError(t, err)
+			require.True(t, found)
+		}
+		dl, err := st.LoadDeadline(store, 0)
+		require.NoError(t, err)
+		partition, err := dl.LoadPartition(store, 0)
+		require.NoError(t, err)
+		require.NoError(t, partition.AddFaults(store, st.Info.SectorSize, deadline.PeriodStart, sectors[101]))
+		require.NoError(t, partition.AddRecoveries(bitfield.NewFromSet([]uint64{101})))
+		require.NoError(t, dl.SavePartition(store, 0, partition))
+		require.NoError(t, st.SaveDeadline(store, 0, dl))
+		rt.ReplaceState(st)
+
+		// Skipped sectors must be in the partitions proven.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.SubmitWindowedPoSt, makeWindowedPoStParams(deadline.Index, []uint64{0}, bitfield.NewFromSet([]uint64{100, 103})))
+		})
+
+		// Skipping the recovering sector retracts its recovery, and the healthy skipped sector becomes faulty,
+		// with its power removed and the declared fault penalty paid from locked funds.
+		penalty := big.Mul(expectedEpochReward, big.NewInt(int64(miner.DeclaredFaultProjectionPeriod)))
+		actor.submitWindowedPoSt(rt, makeWindowedPoStParams(deadline.Index, []uint64{0}, bitfield.NewFromSet([]uint64{101, 102})), poStExpectations{
+			newFaults: []*miner.SectorOnChainInfo{sectors[102]},
+			penalty:   penalty,
+		})
+
+		st = getState(rt)
+		partition = loadPartitionForSector(t, rt, 100)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{101, 102}), partition.Faults)
+		assertEmptyBitfield(t, partition.Recoveries)
+		assert.Equal(t, big.Sub(locked, penalty), st.TotalLockedFunds())
+		assert.True(t, st.FeeDebt.IsZero())
+	})
 }
 
-// Test for sector precommitment and proving.
-func TestCommitments(t *testing.T) {
+func TestFaults(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 100)
 	worker := tutil.NewIDAddr(t, 101)
 	workerKey := tutil.NewBLSAddr(t, 0)
@@ -128,76 +1146,285 @@ func TestCommitments(t *testing.T) {
 		WithActorType(owner, builtin.AccountActorCodeID).
 		WithActorType(worker, builtin.AccountActorCodeID).
 		WithHasher(fixedHasher(uint64(periodBoundary))).
-		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID)
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
+	locked := abi.NewTokenAmount(1000000)
+	declaredPenalty := big.Mul(expectedEpochReward, big.NewInt(int64(miner.DeclaredFaultProjectionPeriod)))
+	undeclaredPenalty := big.Mul(expectedEpochReward, big.NewInt(int64(miner.UndeclaredFaultProjectionPeriod)))
 
-	t.Run("invalid pre-commit rejected", func(t *testing.T) {
+	// Proves sectors 100-102, assigned to the first partition at deadline 0, and locks some rewards from which
+	// penalties are paid. Returns at the start of the first proving period in which the sectors are due.
+	setup := func(t *testing.T) (*mock.Runtime, []*miner.SectorOnChainInfo) {
 		rt := builder.Build(t)
 		precommitEpoch := periodBoundary + 1
 		rt.SetEpoch(precommitEpoch)
 		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
+
+		expiration := deadline.PeriodEnd() + 10*miner.WPoStProvingPeriod
+		var precommits []*miner.SectorPreCommitInfo
+		var proveCommits []miner.ProveCommitSectorParams
+		for _, sectorNo := range []abi.SectorNumber{100, 101, 102} {
+			precommit := makePreCommit(sectorNo, precommitEpoch-miner.PreCommitChallengeDelay, expiration)
+			actor.preCommitSector(rt, precommit, big.Zero())
+			precommits = append(precommits, precommit)
+			proveCommits = append(proveCommits, *makeProveCommit(sectorNo))
+		}
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, precommits, []bool{true, true, true}, &miner.ProveCommitSectorsParams{Sectors: proveCommits})
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCron(rt)
+		rt.SetEpoch(deadline.NextPeriodStart())
+
+		rt.SetBalance(locked)
+		actor.addLockedFund(rt, locked, big.Zero(), locked)
+
 		st := getState(rt)
-		deadline, _ := st.DeadlineInfo(precommitEpoch)
+		var sectors []*miner.SectorOnChainInfo
+		for _, sectorNo := range []abi.SectorNumber{100, 101, 102} {
+			sector, found, err := st.GetSector(adt.AsStore(rt), sectorNo)
+			require.NoError(t, err)
+			require.True(t, found)
+			sectors = append(sectors, sector)
+		}
+		return rt, sectors
+	}
 
-		challengeEpoch := precommitEpoch - miner.PreCommitChallengeDelay
+	t.Run("declared fault loses power until recovered and proven", func(t *testing.T) {
+		rt, sectors := setup(t)
+		sectorSize := getState(rt).Info.SectorSize
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+		require.Equal(t, uint64(0), deadline.Index)
 
-		// Good commitment.
-		actor.preCommitSector(rt, makePreCommit(100, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		// A fault may not be declared for a deadline once its fault cutoff has passed.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.DeclareFaults, &miner.DeclareFaultsParams{Faults: []miner.FaultDeclaration{{
+				Deadline: 0, Partition: 0, Sectors: bitfield.NewFromSet([]uint64{101}),
+			}}})
+		})
+		actor.submitWindowedPoSt(rt, makeWindowedPoStParams(0, []uint64{0}, abi.NewBitField()), poStExpectations{})
 
-		// Duplicate sector ID
+		// Once the deadline has passed, a declaration is for the deadline in the next proving period.
+		rt.SetEpoch(deadline.Close)
+		actor.declareFaults(rt, 0, 0, sectors[1:2], declaredPenalty)
+		partition := loadPartitionForSector(t, rt, 101)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{101}), partition.Faults)
+		assert.True(t, miner.PowerForSectors(sectorSize, sectors).Equals(partition.TotalPower))
+		assert.True(t, miner.PowerForSectors(sectorSize, sectors[1:2]).Equals(partition.FaultyPower))
+
+		// A recovery restores no power until proven, and may be declared only once.
+		actor.declareFaultsRecovered(rt, 0, 0, bitfield.NewFromSet([]uint64{101}))
 		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.preCommitSector(rt, makePreCommit(100, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+			actor.declareFaultsRecovered(rt, 0, 0, bitfield.NewFromSet([]uint64{101}))
 		})
+		partition = loadPartitionForSector(t, rt, 101)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{101}), partition.Recoveries)
+		assert.True(t, miner.PowerForSectors(sectorSize, sectors[1:2]).Equals(partition.FaultyPower))
 
-		// Expires at current epoch
+		// The recovering sector remains faulty through the period end, and pays the ongoing fault penalty.
 		rt.SetEpoch(deadline.PeriodEnd())
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.preCommitSector(rt, makePreCommit(111, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+		actor.onProvingPeriodCronWithFaults(rt, cronExpectations{ongoingPenalty: declaredPenalty})
+
+		// Proving the recovery restores the sector's power.
+		rt.SetEpoch(deadline.NextPeriodStart())
+		actor.submitWindowedPoSt(rt, makeWindowedPoStParams(0, []uint64{0}, abi.NewBitField()), poStExpectations{
+			recovered: sectors[1:2],
 		})
+		partition = loadPartitionForSector(t, rt, 101)
+		assertEmptyBitfield(t, partition.Faults)
+		assertEmptyBitfield(t, partition.Recoveries)
+		assert.True(t, miner.PowerForSectors(sectorSize, sectors).Equals(partition.TotalPower))
+		assert.True(t, partition.FaultyPower.Equals(miner.PowerForSectors(sectorSize, nil)))
+		assert.Equal(t, big.Sub(locked, big.Mul(big.NewInt(2), declaredPenalty)), getState(rt).TotalLockedFunds())
+	})
 
-		// Expires before current epoch
-		rt.SetEpoch(deadline.PeriodEnd() + 1)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.preCommitSector(rt, makePreCommit(112, challengeEpoch, deadline.PeriodEnd()), big.Zero())
+	t.Run("missed PoSt is detected at period end and fails recoveries", func(t *testing.T) {
+		rt, sectors := setup(t)
+		sectorSize := getState(rt).Info.SectorSize
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+
+		// Skip sector 102, then declare it recovered for the next proving period.
+		actor.submitWindowedPoSt(rt, makeWindowedPoStParams(0, []uint64{0}, bitfield.NewFromSet([]uint64{102})), poStExpectations{
+			newFaults: sectors[2:],
+			penalty:   declaredPenalty,
 		})
+		rt.SetEpoch(deadline.Close)
+		actor.declareFaultsRecovered(rt, 0, 0, bitfield.NewFromSet([]uint64{102}))
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCronWithFaults(rt, cronExpectations{ongoingPenalty: declaredPenalty})
 
-		// Expires not on period end
-		rt.SetEpoch(precommitEpoch)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.preCommitSector(rt, makePreCommit(113, challengeEpoch, deadline.PeriodEnd()-1), big.Zero())
+		// No PoSt is submitted in the next period, so the healthy sectors are detected faulty and the recovery
+		// fails, all paying the undeclared fault penalty and then the ongoing fault penalty.
+		rt.SetEpoch(deadline.PeriodEnd() + miner.WPoStProvingPeriod)
+		actor.onProvingPeriodCronWithFaults(rt, cronExpectations{
+			detectedFaults:  sectors[:2],
+			detectedPenalty: big.Mul(big.NewInt(3), undeclaredPenalty),
+			ongoingPenalty:  big.Mul(big.NewInt(3), declaredPenalty),
 		})
+		partition := loadPartitionForSector(t, rt, 100)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100, 101, 102}), partition.Faults)
+		assertEmptyBitfield(t, partition.Recoveries)
+		assert.True(t, miner.PowerForSectors(sectorSize, sectors).Equals(partition.FaultyPower))
+		assert.True(t, partition.TotalPower.Equals(partition.FaultyPower))
 
-		// TODO: test insufficient funds when the precommit deposit is set above zero
+		// Detected faults may not be declared again.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.Call(actor.a.DeclareFaults, &miner.DeclareFaultsParams{Faults: []miner.FaultDeclaration{{
+				Deadline: 0, Partition: 0, Sectors: bitfield.NewFromSet([]uint64{100}),
+			}}})
+		})
 	})
-
-
-	// TODO
-	// already proven
-	// commitment expires before proof
-	// commitment proven ok
 }
 
-func TestProvingPeriodCron(t *testing.T) {
+func TestDisputeWindowedPoSt(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 100)
 	worker := tutil.NewIDAddr(t, 101)
 	workerKey := tutil.NewBLSAddr(t, 0)
+	disputer := tutil.NewIDAddr(t, 102)
 	receiver := tutil.NewIDAddr(t, 1000)
 	actor := newHarness(t, owner, worker, workerKey)
 	periodBoundary := abi.ChainEpoch(100)
+	locked := abi.NewTokenAmount(1000000)
 	builder := mock.NewBuilder(context.Background(), receiver).
 		WithActorType(owner, builtin.AccountActorCodeID).
 		WithActorType(worker, builtin.AccountActorCodeID).
 		WithHasher(fixedHasher(uint64(periodBoundary))).
-		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID)
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithInvariantChecks(&miner.State{})
 
-	t.Run("empty period", func(t *testing.T) {
+	// Proves a sector and assigns it to the first partition at deadline 0, and locks some rewards from which
+	// penalties are paid. Returns at the opening of the deadline.
+	prove := func(t *testing.T) (*mock.Runtime, *miner.SectorOnChainInfo) {
 		rt := builder.Build(t)
-		actor.constructAndVerify(rt, periodBoundary)
+		precommitEpoch := periodBoundary + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt, periodBoundary+miner.WPoStProvingPeriod)
+		deadline, _ := getState(rt).DeadlineInfo(precommitEpoch)
 
-		rt.SetEpoch(periodBoundary - 1)
-		actor.onProvingPeriodCron(rt) // Checks that the expected re-enrollment is made for next period.
+		precommit := makePreCommit(100, precommitEpoch-miner.PreCommitChallengeDelay, deadline.PeriodEnd()+2*miner.WPoStProvingPeriod)
+		actor.preCommitSector(rt, precommit, big.Zero())
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSectors(rt, []*miner.SectorPreCommitInfo{precommit}, []bool{true}, &miner.ProveCommitSectorsParams{
+			Sectors: []miner.ProveCommitSectorParams{*makeProveCommit(100)},
+		})
 
-		rt.SetEpoch(periodBoundary + miner.WPoStProvingPeriod - 1)
+		rt.SetEpoch(deadline.PeriodEnd())
+		actor.onProvingPeriodCron(rt)
+		rt.SetEpoch(deadline.NextPeriodStart())
+		rt.SetBalance(locked)
+		actor.addLockedFund(rt, locked, big.Zero(), locked)
+
+		st := getState(rt)
+		sector, found, err := st.GetSector(adt.AsStore(rt), 100)
+		require.NoError(t, err)
+		require.True(t, found)
+		deadline, _ = st.DeadlineInfo(rt.GetEpoch())
+		require.Equal(t, uint64(0), deadline.Index)
+		return rt, sector
+	}
+
+	// Proves a sector as above, then submits an optimistic PoSt over its partition.
+	setup := func(t *testing.T) (*mock.Runtime, *miner.SectorOnChainInfo) {
+		rt, sector := prove(t)
+		params := makeWindowedPoStParams(0, []uint64{0}, abi.NewBitField())
+		params.Optimistic = true
+		actor.submitWindowedPoSt(rt, params, poStExpectations{})
+
+		// The proof is retained for dispute.
+		snapshot, found, err := getState(rt).GetPoStSnapshot(adt.AsStore(rt), 0, 0)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, []abi.SectorInfo{sector.AsSectorInfo()}, snapshot.Sectors)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{100}), snapshot.Proven)
+		return rt, sector
+	}
+
+	t.Run("proof is verified unless optimistic", func(t *testing.T) {
+		rt, _ := prove(t)
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+		var buf bytes.Buffer
+		require.NoError(t, rt.GetReceiver().MarshalCBOR(&buf))
+
+		rt.SetPoStVerifier(func(vi abi.WindowPoStVerifyInfo) error { return fmt.Errorf("invalid") })
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.worker)
+			rt.ExpectGetRandomness(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, deadline.Challenge, buf.Bytes(), abi.Randomness("postrand"))
+			rt.Call(actor.a.SubmitWindowedPoSt, makeWindowedPoStParams(deadline.Index, []uint64{0}, abi.NewBitField()))
+		})
+
+		// A verified proof is not retained, and cannot be disputed.
+		actor.submitWindowedPoSt(rt, makeWindowedPoStParams(deadline.Index, []uint64{0}, abi.NewBitField()), poStExpectations{})
+		_, found, err := getState(rt).GetPoStSnapshot(adt.AsStore(rt), deadline.Index, 0)
+		require.NoError(t, err)
+		assert.False(t, found)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.SetCaller(disputer, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.Call(actor.a.DisputeWindowedPoSt, &miner.DisputeWindowedPoStParams{Deadline: deadline.Index, PoStIndex: 0})
+		})
+	})
+
+	t.Run("valid proof cannot be disputed", func(t *testing.T) {
+		rt, _ := setup(t)
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+		rt.SetPoStVerifier(func(vi abi.WindowPoStVerifyInfo) error { return nil })
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.disputeWindowedPoSt(rt, disputer, deadline, 0, nil, big.Zero(), big.Zero())
+		})
+	})
+
+	t.Run("invalid proof is disputed", func(t *testing.T) {
+		rt, sector := setup(t)
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+		rt.SetPoStVerifier(func(vi abi.WindowPoStVerifyInfo) error { return fmt.Errorf("invalid") })
+
+		// The sector is penalized as an undeclared fault, with half the penalty rewarded to the disputer.
+		penalty := big.Mul(expectedEpochReward, big.NewInt(int64(miner.UndeclaredFaultProjectionPeriod)))
+		reward := big.Div(penalty, big.NewInt(2))
+		actor.disputeWindowedPoSt(rt, disputer, deadline, 0, []*miner.SectorOnChainInfo{sector}, reward, big.Sub(penalty, reward))
+
+		st := getState(rt)
+		faulty, err := loadPartitionForSector(t, rt, 100).Faults.IsSet(100)
+		require.NoError(t, err)
+		assert.True(t, faulty)
+		snapshot, found, err := st.GetPoStSnapshot(adt.AsStore(rt), deadline.Index, 0)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.True(t, snapshot.Disputed)
+		assert.Equal(t, big.Sub(locked, penalty), st.TotalLockedFunds())
+
+		// A disputed proof cannot be disputed again.
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			actor.disputeWindowedPoSt(rt, disputer, deadline, 0, nil, big.Zero(), big.Zero())
+		})
+	})
+
+	t.Run("dispute window ends", func(t *testing.T) {
+		rt, _ := setup(t)
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+		rt.SetEpoch(rt.GetEpoch() + miner.WPoStDisputeWindow)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.SetCaller(disputer, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.Call(actor.a.DisputeWindowedPoSt, &miner.DisputeWindowedPoStParams{Deadline: deadline.Index, PoStIndex: 0})
+		})
+	})
+
+	t.Run("snapshot is discarded at the period end after the dispute window", func(t *testing.T) {
+		rt, _ := setup(t)
+		deadline, _ := getState(rt).DeadlineInfo(rt.GetEpoch())
+		require.True(t, rt.GetEpoch()+miner.WPoStDisputeWindow <= deadline.PeriodEnd())
+
+		rt.SetEpoch(deadline.PeriodEnd())
 		actor.onProvingPeriodCron(rt)
+		_, found, err := getState(rt).GetPoStSnapshot(adt.AsStore(rt), deadline.Index, 0)
+		require.NoError(t, err)
+		assert.False(t, found)
 	})
 }
 
@@ -233,12 +1460,46 @@ func (h *actorHarness) constructAndVerify(rt *mock.Runtime, nextPPStart abi.Chai
 	rt.Verify()
 }
 
-func (h *actorHarness) controlAddresses(rt *mock.Runtime) (owner, worker addr.Address) {
+func (h *actorHarness) controlAddresses(rt *mock.Runtime) (owner, worker addr.Address, control []addr.Address) {
 	rt.ExpectValidateCallerAny()
 	ret := rt.Call(h.a.ControlAddresses, nil).(*miner.GetControlAddressesReturn)
 	require.NotNil(h.t, ret)
 	rt.Verify()
-	return ret.Owner, ret.Worker
+	return ret.Owner, ret.Worker, ret.ControlAddrs
+}
+
+func (h *actorHarness) changeMultiaddrs(rt *mock.Runtime, caller addr.Address, multiaddrs [][]byte) {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker, h.owner)
+	rt.Call(h.a.ChangeMultiaddrs, &miner.ChangeMultiaddrsParams{NewMultiaddrs: multiaddrs})
+	rt.Verify()
+}
+
+func (h *actorHarness) getInfo(rt *mock.Runtime, caller addr.Address) *miner.MinerInfo {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetInfo, nil).(*miner.MinerInfo)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) changeControlAddresses(rt *mock.Runtime, caller addr.Address, controlAddrs []addr.Address) {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.owner)
+	rt.Call(h.a.ChangeControlAddresses, &miner.ChangeControlAddressesParams{NewControlAddrs: controlAddrs})
+	rt.Verify()
+}
+
+func (h *actorHarness) changeOwnerAddress(rt *mock.Runtime, caller, newOwner addr.Address) {
+	st := getState(rt)
+	validCallers := []addr.Address{st.Info.Owner}
+	if st.Info.PendingOwnerChange != nil {
+		validCallers = append(validCallers, st.Info.PendingOwnerChange.NewOwner)
+	}
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(validCallers...)
+	rt.Call(h.a.ChangeOwnerAddress, &miner.ChangeOwnerAddressParams{NewOwner: newOwner})
+	rt.Verify()
 }
 
 func (h *actorHarness) preCommitSector(rt *mock.Runtime, params *miner.SectorPreCommitInfo, pledgeDelta abi.TokenAmount) {
@@ -248,47 +1509,373 @@ func (h *actorHarness) preCommitSector(rt *mock.Runtime, params *miner.SectorPre
 		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
 	}
 
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.EnrollCronEvent, makePreCommitExpiryCronEventParams(h.t, rt, params),
+		big.Zero(), nil, exitcode.Ok)
+
+	rt.Call(h.a.PreCommitSector, params)
+	rt.Verify()
+}
+
+// Withdraws pre-committed sectors, expecting the part of their deposits not refunded to be burnt.
+func (h *actorHarness) withdrawPreCommits(rt *mock.Runtime, burnt abi.TokenAmount, sectorNos ...uint64) {
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+	if !burnt.IsZero() {
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, burnt, nil, exitcode.Ok)
+	}
+	rt.Call(h.a.WithdrawPreCommits, &miner.WithdrawPreCommitsParams{Sectors: bitfield.NewFromSet(sectorNos)})
+	rt.Verify()
+}
+
+func (h *actorHarness) maskSectorNumbers(rt *mock.Runtime, sectorNos *abi.BitField) {
+	rt.SetCaller(h.owner, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.owner)
+	rt.Call(h.a.MaskSectorNumbers, &miner.MaskSectorNumbersParams{Sectors: sectorNos})
+	rt.Verify()
+}
+
+func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.SectorPreCommitInfo, params *miner.ProveCommitSectorParams) {
+	rt.ExpectValidateCallerAny()
+	commd := cbg.CborCid(tutil.MakeCID("commd"))
+	{
+		cdcParams := market.ComputeDataCommitmentParams{
+			DealIDs:    precommit.DealIDs,
+			SectorType: precommit.RegisteredProof,
+		}
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ComputeDataCommitment, &cdcParams, big.Zero(), &commd, exitcode.Ok)
+	}
+	{
+		var buf bytes.Buffer
+		err := rt.GetReceiver().MarshalCBOR(&buf)
+		require.NoError(h.t, err)
+		rt.ExpectGetRandomness(crypto.DomainSeparationTag_SealRandomness, precommit.SealRandEpoch, buf.Bytes(), abi.Randomness("sealrand"))
+	}
+	rt.Call(h.a.ProveCommitSector, params)
+	rt.Verify()
+}
+
+func (h *actorHarness) preCommitSectorBatch(rt *mock.Runtime, params *miner.PreCommitSectorBatchParams) {
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+
+	var sectorDeals []market.SectorDeals
+	sectorNos := make([]uint64, len(params.Sectors))
+	for i, pc := range params.Sectors {
+		sectorNos[i] = uint64(pc.SectorNumber)
+		if len(pc.DealIDs) > 0 {
+			sectorDeals = append(sectorDeals, market.SectorDeals{DealIDs: pc.DealIDs, SectorExpiry: pc.Expiration})
+		}
+	}
+	if len(sectorDeals) > 0 {
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.VerifyDealsOnSectorPreCommit,
+			&market.VerifyDealsOnSectorPreCommitParams{Sectors: sectorDeals}, big.Zero(), nil, exitcode.Ok)
+	}
+
 	{
 		eventPayload := miner.CronEventPayload{
 			EventType: miner.CronEventPreCommitExpiry,
-			Sectors:   bitfield.NewFromSet([]uint64{uint64(params.SectorNumber)}),
+			Sectors:   bitfield.NewFromSet(sectorNos),
 		}
 		buf := bytes.Buffer{}
 		err := eventPayload.MarshalCBOR(&buf)
 		require.NoError(h.t, err)
 		cronParams := power.EnrollCronEventParams{
-			EventEpoch: rt.GetEpoch() + miner.MaxSealDuration[params.RegisteredProof] + 1,
+			EventEpoch: rt.GetEpoch() + miner.MaxSealDuration[params.Sectors[0].RegisteredProof] + 1,
 			Payload:    buf.Bytes(),
 		}
 		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.EnrollCronEvent, &cronParams, big.Zero(), nil, exitcode.Ok)
 	}
 
-	rt.Call(h.a.PreCommitSector, params)
+	rt.Call(h.a.PreCommitSectorBatch, params)
 	rt.Verify()
 }
 
-func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.SectorPreCommitInfo, params *miner.ProveCommitSectorParams) {
+// Proves a batch of sectors, for which the seal verifier reports the proofs of the pre-commits `precommits` as
+// `valid`. The deals of all sectors with valid proofs are expected to be valid too.
+func (h *actorHarness) proveCommitSectors(rt *mock.Runtime, precommits []*miner.SectorPreCommitInfo, valid []bool,
+	params *miner.ProveCommitSectorsParams) *abi.BitField {
 	rt.ExpectValidateCallerAny()
-	commd := cbg.CborCid(tutil.MakeCID("commd"))
-	{
-		cdcParams := market.ComputeDataCommitmentParams{
+	var buf bytes.Buffer
+	require.NoError(h.t, rt.GetReceiver().MarshalCBOR(&buf))
+	st := getState(rt)
+
+	var sectorDeals []market.SectorDeals
+	var dealWeights []market.SectorDealWeights
+	var pledges []power.SectorPledge
+	var powerWeights []power.SectorStorageWeightDesc
+	var cdcInputs []market.ComputeDataCommitmentParams
+	var commDs []market.SectorDataCommitment
+	for _, precommit := range precommits {
+		commd := tutil.MakeCID("commd")
+		cdcInputs = append(cdcInputs, market.ComputeDataCommitmentParams{
 			DealIDs:    precommit.DealIDs,
 			SectorType: precommit.RegisteredProof,
+		})
+		commDs = append(commDs, market.SectorDataCommitment{CommD: &commd})
+	}
+	rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.BatchComputeDataCommitment,
+		&market.BatchComputeDataCommitmentParams{Inputs: cdcInputs}, big.Zero(),
+		&market.BatchComputeDataCommitmentReturn{Sectors: commDs}, exitcode.Ok)
+	for i, precommit := range precommits {
+		pc, found, err := st.GetPrecommittedSector(adt.AsStore(rt), precommit.SectorNumber)
+		require.NoError(h.t, err)
+		require.True(h.t, found)
+		rt.ExpectGetRandomness(crypto.DomainSeparationTag_SealRandomness, precommit.SealRandEpoch, buf.Bytes(), abi.Randomness("sealrand"))
+		rt.ExpectGetRandomness(crypto.DomainSeparationTag_InteractiveSealChallengeSeed, pc.PreCommitEpoch+miner.PreCommitChallengeDelay, buf.Bytes(), abi.Randomness("interactive"))
+
+		if valid[i] {
+			sectorDeals = append(sectorDeals, market.SectorDeals{DealIDs: precommit.DealIDs, SectorExpiry: precommit.Expiration})
+			dealWeights = append(dealWeights, market.SectorDealWeights{Valid: true, DealWeight: big.Zero(), VerifiedDealWeight: big.Zero()})
+			powerWeights = append(powerWeights, power.SectorStorageWeightDesc{
+				SectorSize:         st.Info.SectorSize,
+				DealWeight:         big.Zero(),
+				VerifiedDealWeight: big.Zero(),
+				Duration:           precommit.Expiration - rt.GetEpoch(),
+			})
+			pledges = append(pledges, power.SectorPledge{InitialPledge: big.Zero(), ExpectedEpochReward: expectedEpochReward})
 		}
-		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ComputeDataCommitment, &cdcParams, big.Zero(), &commd, exitcode.Ok)
 	}
-	{
+	rt.SetBatchSealVerifier(func(vis []abi.SealVerifyInfo) ([]bool, error) {
+		require.Len(h.t, vis, len(valid))
+		return valid, nil
+	})
+	if len(sectorDeals) > 0 {
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.BatchVerifyDealsOnSectorProveCommit,
+			&market.BatchVerifyDealsOnSectorProveCommitParams{Sectors: sectorDeals}, big.Zero(),
+			&market.BatchVerifyDealsOnSectorProveCommitReturn{Sectors: dealWeights}, exitcode.Ok)
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.BatchOnSectorProveCommit,
+			&power.BatchOnSectorProveCommitParams{Weights: powerWeights}, big.Zero(),
+			&power.BatchOnSectorProveCommitReturn{Sectors: pledges}, exitcode.Ok)
+	}
+
+	ret := rt.Call(h.a.ProveCommitSectors, params).(*abi.BitField)
+	rt.Verify()
+	return ret
+}
+
+// Expected effects of a Window PoSt submission.
+type poStExpectations struct {
+	newFaults []*miner.SectorOnChainInfo // Sectors newly faulty, whose power is removed
+	recovered []*miner.SectorOnChainInfo // Sectors recovered, whose power is restored
+	penalty   abi.TokenAmount            // Penalty burnt, if any
+}
+
+// Submits a Window PoSt at the current deadline. Unless accepted optimistically, the proof is verified as valid.
+func (h *actorHarness) submitWindowedPoSt(rt *mock.Runtime, params *miner.SubmitWindowedPoStParams, expect poStExpectations) {
+	st := getState(rt)
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+	if !params.Optimistic {
 		var buf bytes.Buffer
-		err := rt.GetReceiver().MarshalCBOR(&buf)
+		require.NoError(h.t, rt.GetReceiver().MarshalCBOR(&buf))
+		deadline, _ := st.DeadlineInfo(rt.GetEpoch())
+		rt.ExpectGetRandomness(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, deadline.Challenge, buf.Bytes(), abi.Randomness("postrand"))
+		rt.SetPoStVerifier(func(vi abi.WindowPoStVerifyInfo) error { return nil })
+	}
+	if len(expect.newFaults) > 0 {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnFaultBegin, &power.OnFaultBeginParams{
+			Weights: asStorageWeightDescs(st.Info.SectorSize, expect.newFaults),
+		}, big.Zero(), nil, exitcode.Ok)
+	}
+	expectPenalty(rt, expect.penalty)
+	if len(expect.recovered) > 0 {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnFaultEnd, &power.OnFaultEndParams{
+			Weights: asStorageWeightDescs(st.Info.SectorSize, expect.recovered),
+		}, big.Zero(), nil, exitcode.Ok)
+	}
+	rt.Call(h.a.SubmitWindowedPoSt, params)
+	rt.Verify()
+}
+
+// Declares faults at a partition, expecting the power of the newly faulty sectors to be removed and the declared
+// fault penalty burnt.
+func (h *actorHarness) declareFaults(rt *mock.Runtime, deadline, partition uint64, faults []*miner.SectorOnChainInfo,
+	penalty abi.TokenAmount) {
+	st := getState(rt)
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+	if len(faults) > 0 {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnFaultBegin, &power.OnFaultBeginParams{
+			Weights: asStorageWeightDescs(st.Info.SectorSize, faults),
+		}, big.Zero(), nil, exitcode.Ok)
+	}
+	expectPenalty(rt, penalty)
+	sectorNos := make([]uint64, len(faults))
+	for i, s := range faults {
+		sectorNos[i] = uint64(s.Info.SectorNumber)
+	}
+	rt.Call(h.a.DeclareFaults, &miner.DeclareFaultsParams{Faults: []miner.FaultDeclaration{{
+		Deadline:  deadline,
+		Partition: partition,
+		Sectors:   bitfield.NewFromSet(sectorNos),
+	}}})
+	rt.Verify()
+}
+
+// Declares faulty sectors at a partition recovered. Their power is not restored until proven.
+func (h *actorHarness) declareFaultsRecovered(rt *mock.Runtime, deadline, partition uint64, sectorNos *abi.BitField) {
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+	rt.Call(h.a.DeclareFaultsRecovered, &miner.DeclareFaultsRecoveredParams{Recoveries: []miner.RecoveryDeclaration{{
+		Deadline:  deadline,
+		Partition: partition,
+		Sectors:   sectorNos,
+	}}})
+	rt.Verify()
+}
+
+// Disputes a Window PoSt, expecting the power of any sectors that become faulty to be removed, and the penalty
+// for them to be paid as a reward to the disputer and the remainder burnt.
+func (h *actorHarness) disputeWindowedPoSt(rt *mock.Runtime, disputer addr.Address, deadline *miner.DeadlineInfo, postIndex uint64,
+	faults []*miner.SectorOnChainInfo, reward, burnt abi.TokenAmount) {
+	st := getState(rt)
+	rt.SetCaller(disputer, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	var buf bytes.Buffer
+	require.NoError(h.t, rt.GetReceiver().MarshalCBOR(&buf))
+	rt.ExpectGetRandomness(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, deadline.Challenge, buf.Bytes(), abi.Randomness("postrand"))
+	if len(faults) > 0 {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnFaultBegin, &power.OnFaultBeginParams{
+			Weights: asStorageWeightDescs(st.Info.SectorSize, faults),
+		}, big.Zero(), nil, exitcode.Ok)
+	}
+	if !reward.IsZero() {
+		rt.ExpectSend(disputer, builtin.MethodSend, nil, reward, nil, exitcode.Ok)
+	}
+	if !burnt.IsZero() {
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, burnt, nil, exitcode.Ok)
+	}
+	if penalty := big.Add(reward, burnt); !penalty.IsZero() {
+		pledgeDelta := penalty.Neg()
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
+	}
+	rt.Call(h.a.DisputeWindowedPoSt, &miner.DisputeWindowedPoStParams{Deadline: deadline.Index, PoStIndex: postIndex})
+	rt.Verify()
+}
+
+// Terminates sectors that have neither deals nor locked pledge, returning the termination fee.
+func (h *actorHarness) terminateSectors(rt *mock.Runtime, sectors ...*miner.SectorOnChainInfo) abi.TokenAmount {
+	st := getState(rt)
+	sectorNos := make([]uint64, len(sectors))
+	weights := make([]power.SectorStorageWeightDesc, len(sectors))
+	for i, s := range sectors {
+		sectorNos[i] = uint64(s.Info.SectorNumber)
+		weights[i] = *miner.AsStorageWeightDesc(st.Info.SectorSize, s)
+	}
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnSectorTerminate, &power.OnSectorTerminateParams{
+		TerminationType: power.SectorTerminationManual,
+		Weights:         weights,
+	}, big.Zero(), nil, exitcode.Ok)
+	fee := rt.Call(h.a.TerminateSectors, &miner.TerminateSectorsParams{Sectors: bitfield.NewFromSet(sectorNos)}).(*abi.TokenAmount)
+	rt.Verify()
+	return *fee
+}
+
+// Extends sector expirations, with the power actor requiring a new initial pledge for every sector.
+func (h *actorHarness) extendSectorExpirations(rt *mock.Runtime, extensions []miner.ExpirationExtension,
+	newPledge, newEpochReward abi.TokenAmount) {
+	st := getState(rt)
+	var modifications []power.OnSectorModifyWeightDescParams
+	var pledges []power.SectorPledge
+	pledgeDelta := big.Zero()
+	for _, ext := range extensions {
+		err := ext.Sectors.ForEach(func(sectorNo uint64) error {
+			sector, found, err := st.GetSector(adt.AsStore(rt), abi.SectorNumber(sectorNo))
+			require.NoError(h.t, err)
+			require.True(h.t, found)
+			updated := *sector
+			updated.Info.Expiration = ext.NewExpiration
+			modifications = append(modifications, power.OnSectorModifyWeightDescParams{
+				PrevWeight: *miner.AsStorageWeightDesc(st.Info.SectorSize, sector),
+				NewWeight:  *miner.AsStorageWeightDesc(st.Info.SectorSize, &updated),
+			})
+			pledges = append(pledges, power.SectorPledge{InitialPledge: newPledge, ExpectedEpochReward: newEpochReward})
+			if newPledge.GreaterThan(sector.InitialPledge) {
+				pledgeDelta = big.Add(pledgeDelta, big.Sub(newPledge, sector.InitialPledge))
+			}
+			return nil
+		})
 		require.NoError(h.t, err)
-		rt.ExpectGetRandomness(crypto.DomainSeparationTag_SealRandomness, precommit.SealRandEpoch, buf.Bytes(), abi.Randomness("sealrand"))
 	}
-	rt.Call(h.a.ProveCommitSector, params)
+
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker)
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.BatchOnSectorModifyWeightDesc,
+		&power.BatchOnSectorModifyWeightDescParams{Modifications: modifications}, big.Zero(),
+		&power.BatchOnSectorModifyWeightDescReturn{Sectors: pledges}, exitcode.Ok)
+	if !pledgeDelta.IsZero() {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
+	}
+	rt.Call(h.a.ExtendSectorExpirations, &miner.ExtendSectorExpirationsParams{Extensions: extensions})
+	rt.Verify()
+}
+
+func (h *actorHarness) getSectorStatus(rt *mock.Runtime, sectorNo abi.SectorNumber) *miner.SectorStatus {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetSectorStatus, &miner.GetSectorStatusParams{SectorNumber: sectorNo}).(*miner.SectorStatus)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) getDeadlineSummary(rt *mock.Runtime) *miner.GetDeadlineSummaryReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetDeadlineSummary, nil).(*miner.GetDeadlineSummaryReturn)
+	rt.Verify()
+	return ret
+}
+
+// Reports a consensus fault by a miner with no locked funds.
+func (h *actorHarness) reportConsensusFault(rt *mock.Runtime, reporter addr.Address, dealIDs []abi.DealID, reward, burnt abi.TokenAmount) {
+	rt.SetCaller(reporter, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	if len(dealIDs) > 0 {
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.OnMinerSectorsTerminate,
+			&market.OnMinerSectorsTerminateParams{DealIDs: dealIDs}, big.Zero(), nil, exitcode.Ok)
+	}
+	lockedFunds := big.Zero()
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnConsensusFault, &lockedFunds, big.Zero(), nil, exitcode.Ok)
+	rt.ExpectSend(reporter, builtin.MethodSend, nil, reward, nil, exitcode.Ok)
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, burnt, nil, exitcode.Ok)
+	rt.Call(h.a.ReportConsensusFault, &miner.ReportConsensusFaultParams{})
+	rt.Verify()
+}
+
+// Locks funds as the reward actor does when paying a block reward.
+func (h *actorHarness) addLockedFund(rt *mock.Runtime, amount, expectDebtRepaid, expectLocked abi.TokenAmount) {
+	rt.SetCaller(builtin.RewardActorAddr, builtin.RewardActorCodeID)
+	rt.ExpectValidateCallerAddr(h.worker, h.owner, builtin.RewardActorAddr)
+	if !expectDebtRepaid.IsZero() {
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectDebtRepaid, nil, exitcode.Ok)
+	}
+	if !expectLocked.IsZero() {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &expectLocked, big.Zero(), nil, exitcode.Ok)
+	}
+	rt.Call(h.a.AddLockedFund, &amount)
 	rt.Verify()
 }
 
 func (h *actorHarness) onProvingPeriodCron(rt *mock.Runtime) {
+	h.onProvingPeriodCronWithFaults(rt, cronExpectations{})
+}
+
+// Expected effects of the proving period cron event on faulty sectors.
+type cronExpectations struct {
+	detectedFaults  []*miner.SectorOnChainInfo // Sectors detected faulty for a missed PoSt, whose power is removed
+	detectedPenalty abi.TokenAmount            // Penalty burnt for detected faults, if any
+	ongoingPenalty  abi.TokenAmount            // Penalty burnt for ongoing faults, if any
+}
+
+func (h *actorHarness) onProvingPeriodCronWithFaults(rt *mock.Runtime, expect cronExpectations) {
 	rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
+	if len(expect.detectedFaults) > 0 {
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.OnFaultBegin, &power.OnFaultBeginParams{
+			Weights: asStorageWeightDescs(getState(rt).Info.SectorSize, expect.detectedFaults),
+		}, big.Zero(), nil, exitcode.Ok)
+	}
+	expectPenalty(rt, expect.detectedPenalty)
+	expectPenalty(rt, expect.ongoingPenalty)
 	// Re-enrollment for next period.
 	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.EnrollCronEvent,
 		makeProvingPeriodCronEventParams(h.t, rt.GetEpoch()+miner.WPoStProvingPeriod), big.Zero(), nil, exitcode.Ok)
@@ -299,12 +1886,39 @@ func (h *actorHarness) onProvingPeriodCron(rt *mock.Runtime) {
 	rt.Verify()
 }
 
+// Expects a penalty, if non-zero, to be burnt and the power actor notified of the reduction in pledge.
+func expectPenalty(rt *mock.Runtime, penalty abi.TokenAmount) {
+	if penalty.Nil() || penalty.IsZero() {
+		return
+	}
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, penalty, nil, exitcode.Ok)
+	pledgeDelta := penalty.Neg()
+	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
+}
+
+// The projected per-epoch reward of each sector proven through the harness.
+var expectedEpochReward = abi.NewTokenAmount(10)
+
 func getState(rt *mock.Runtime) *miner.State {
 	var st miner.State
 	rt.GetState(&st)
 	return &st
 }
 
+// Loads the partition to which a sector is assigned, failing if it is not assigned.
+func loadPartitionForSector(t testing.TB, rt *mock.Runtime, sectorNo abi.SectorNumber) *miner.Partition {
+	st := getState(rt)
+	store := adt.AsStore(rt)
+	dlIdx, partIdx, found, err := st.FindSector(store, sectorNo)
+	require.NoError(t, err)
+	require.True(t, found, "sector %d not assigned", sectorNo)
+	dl, err := st.LoadDeadline(store, dlIdx)
+	require.NoError(t, err)
+	partition, err := dl.LoadPartition(store, partIdx)
+	require.NoError(t, err)
+	return partition
+}
+
 func makeProvingPeriodCronEventParams(t testing.TB, epoch abi.ChainEpoch) *power.EnrollCronEventParams {
 	eventPayload := miner.CronEventPayload{EventType: miner.CronEventProvingPeriod}
 	buf := bytes.Buffer{}
@@ -316,6 +1930,20 @@ func makeProvingPeriodCronEventParams(t testing.TB, epoch abi.ChainEpoch) *power
 	}
 }
 
+// The cron event a pre-commit at the current epoch enrolls to check its expiry.
+func makePreCommitExpiryCronEventParams(t testing.TB, rt *mock.Runtime, precommit *miner.SectorPreCommitInfo) *power.EnrollCronEventParams {
+	eventPayload := miner.CronEventPayload{
+		EventType: miner.CronEventPreCommitExpiry,
+		Sectors:   bitfield.NewFromSet([]uint64{uint64(precommit.SectorNumber)}),
+	}
+	buf := bytes.Buffer{}
+	require.NoError(t, eventPayload.MarshalCBOR(&buf))
+	return &power.EnrollCronEventParams{
+		EventEpoch: rt.GetEpoch() + miner.MaxSealDuration[precommit.RegisteredProof] + 1,
+		Payload:    buf.Bytes(),
+	}
+}
+
 func makePreCommit(sectorNo abi.SectorNumber, challenge, expiration abi.ChainEpoch) *miner.SectorPreCommitInfo {
 	return &miner.SectorPreCommitInfo{
 		RegisteredProof: abi.RegisteredProof_StackedDRG2KiBSeal,
@@ -327,6 +1955,27 @@ func makePreCommit(sectorNo abi.SectorNumber, challenge, expiration abi.ChainEpo
 	}
 }
 
+func makeWindowedPoStParams(deadline uint64, partitions []uint64, skipped *abi.BitField) *miner.SubmitWindowedPoStParams {
+	proofs := make([]abi.PoStProof, len(partitions))
+	for i := range partitions {
+		proofs[i] = abi.PoStProof{RegisteredProof: abi.RegisteredProof_StackedDRG2KiBWindowPoSt, ProofBytes: []byte("proof")}
+	}
+	return &miner.SubmitWindowedPoStParams{
+		Deadline:   deadline,
+		Partitions: partitions,
+		Proofs:     proofs,
+		Skipped:    *skipped,
+	}
+}
+
+func asStorageWeightDescs(sectorSize abi.SectorSize, sectors []*miner.SectorOnChainInfo) []power.SectorStorageWeightDesc {
+	weights := make([]power.SectorStorageWeightDesc, len(sectors))
+	for i, s := range sectors {
+		weights[i] = *miner.AsStorageWeightDesc(sectorSize, s)
+	}
+	return weights
+}
+
 func makeProveCommit(sectorNo abi.SectorNumber) *miner.ProveCommitSectorParams {
 	return &miner.ProveCommitSectorParams{
 		SectorNumber: sectorNo,
@@ -0,0 +1,502 @@
+package miner
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-bitfield"
+	cid "github.com/ipfs/go-cid"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
+	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// A group of sectors proven together by a single Window PoSt at some deadline.
+// The partition holds the faults, recoveries and expirations of its own sectors, so that proving and fault handling
+// need only load the partitions involved.
+// A partition holds at most WPoStPartitionSectors sectors. Its index within the deadline is never re-used, even
+// once all its sectors have gone.
+type Partition struct {
+	// Sector numbers in this partition, including faulty ones.
+	Sectors *abi.BitField
+
+	// Faulty sectors in this partition, mutated eagerly.
+	// These sectors are exempt from inclusion in PoSt.
+	// Invariant: Faults ⊆ Sectors
+	Faults *abi.BitField
+
+	// Faulty sectors that will recover when next included in a valid PoSt.
+	// Invariant: Recoveries ⊆ Faults
+	Recoveries *abi.BitField
+
+	// Sector numbers indexed by expiry epoch.
+	// Invariant: Sectors == union(Expirations.Values())
+	Expirations cid.Cid // Array, AMT[ChainEpoch]BitField
+
+	// Faulty sector numbers indexed by the start epoch of the proving period in which detected.
+	// Used to track fault durations for eventual sector termination.
+	// Invariant: Faults == union(FaultEpochs.Values())
+	FaultEpochs cid.Cid // Array, AMT[ChainEpoch]BitField
+
+	// Power of all sectors in this partition, including faulty ones.
+	TotalPower PowerPair
+
+	// Power of the faulty sectors in this partition.
+	FaultyPower PowerPair
+}
+
+// Raw byte and quality-adjusted power of a collection of sectors.
+type PowerPair struct {
+	Raw abi.StoragePower
+	QA  abi.StoragePower
+}
+
+func ConstructPartition(emptyArrayCid cid.Cid) *Partition {
+	return &Partition{
+		Sectors:     abi.NewBitField(),
+		Faults:      abi.NewBitField(),
+		Recoveries:  abi.NewBitField(),
+		Expirations: emptyArrayCid,
+		FaultEpochs: emptyArrayCid,
+		TotalPower:  NewPowerPairZero(),
+		FaultyPower: NewPowerPairZero(),
+	}
+}
+
+// Counts the sectors in the partition, including faulty ones.
+func (p *Partition) SectorCount() (uint64, error) {
+	return p.Sectors.Count()
+}
+
+// Adds sectors to the partition, scheduling each to expire at its expiration epoch.
+// The sectors must not already be in the partition.
+func (p *Partition) AddSectors(store adt.Store, sectorSize abi.SectorSize, sectors ...*SectorOnChainInfo) error {
+	sectorNos := sectorNumbers(sectors)
+	contains, err := abi.BitFieldContainsAny(p.Sectors, sectorNos)
+	if err != nil {
+		return err
+	}
+	if contains {
+		return fmt.Errorf("sectors %v already in partition", sectorNos)
+	}
+
+	p.Sectors, err = bitfield.MergeBitFields(p.Sectors, sectorNos)
+	if err != nil {
+		return err
+	}
+	count, err := p.Sectors.Count()
+	if err != nil {
+		return err
+	}
+	if count > WPoStPartitionSectors {
+		return fmt.Errorf("too many sectors in partition %d, max %d", count, WPoStPartitionSectors)
+	}
+
+	for _, sector := range sectors {
+		p.Expirations, err = addToBitfieldQueue(store, p.Expirations, sector.Info.Expiration, uint64(sector.Info.SectorNumber))
+		if err != nil {
+			return fmt.Errorf("failed to add sector %d expiration: %w", sector.Info.SectorNumber, err)
+		}
+	}
+
+	p.TotalPower = p.TotalPower.Add(PowerForSectors(sectorSize, sectors))
+	return nil
+}
+
+// Marks sectors faulty from a fault epoch (the start of the proving period in which they were found faulty).
+// The sectors must be in the partition, and not already faulty.
+func (p *Partition) AddFaults(store adt.Store, sectorSize abi.SectorSize, faultEpoch abi.ChainEpoch, sectors ...*SectorOnChainInfo) error {
+	if len(sectors) == 0 {
+		return nil
+	}
+	sectorNos := sectorNumbers(sectors)
+	if err := p.requireSectors(sectorNos); err != nil {
+		return err
+	}
+	contains, err := abi.BitFieldContainsAny(p.Faults, sectorNos)
+	if err != nil {
+		return err
+	}
+	if contains {
+		return fmt.Errorf("sectors %v already faulty", sectorNos)
+	}
+
+	if p.Faults, err = bitfield.MergeBitFields(p.Faults, sectorNos); err != nil {
+		return err
+	}
+	if p.FaultEpochs, err = addToBitfieldQueue(store, p.FaultEpochs, faultEpoch, sectorNosSlice(sectors)...); err != nil {
+		return err
+	}
+	p.FaultyPower = p.FaultyPower.Add(PowerForSectors(sectorSize, sectors))
+	return nil
+}
+
+// Declares some faulty sectors as recovering.
+// The sectors must be faulty, and not already recovering.
+func (p *Partition) AddRecoveries(sectorNos *abi.BitField) (err error) {
+	contains, err := abi.BitFieldContainsAll(p.Faults, sectorNos)
+	if err != nil {
+		return err
+	}
+	if !contains {
+		return fmt.Errorf("recoveries %v not all faulty", sectorNos)
+	}
+	contains, err = abi.BitFieldContainsAny(p.Recoveries, sectorNos)
+	if err != nil {
+		return err
+	}
+	if contains {
+		return fmt.Errorf("recoveries %v already declared", sectorNos)
+	}
+	p.Recoveries, err = bitfield.MergeBitFields(p.Recoveries, sectorNos)
+	return err
+}
+
+// Retracts the recovery of some sectors, if declared. The sectors remain faulty.
+func (p *Partition) RemoveRecoveries(sectorNos *abi.BitField) (err error) {
+	p.Recoveries, err = bitfield.SubtractBitField(p.Recoveries, sectorNos)
+	return err
+}
+
+// Restores faulty sectors to health, removing any declared recovery for them.
+// The sectors must be faulty.
+func (p *Partition) RecoverFaults(store adt.Store, sectorSize abi.SectorSize, sectors ...*SectorOnChainInfo) error {
+	if len(sectors) == 0 {
+		return nil
+	}
+	sectorNos := sectorNumbers(sectors)
+	contains, err := abi.BitFieldContainsAll(p.Faults, sectorNos)
+	if err != nil {
+		return err
+	}
+	if !contains {
+		return fmt.Errorf("sectors %v not all faulty", sectorNos)
+	}
+
+	if p.Faults, err = bitfield.SubtractBitField(p.Faults, sectorNos); err != nil {
+		return err
+	}
+	if p.Recoveries, err = bitfield.SubtractBitField(p.Recoveries, sectorNos); err != nil {
+		return err
+	}
+	if p.FaultEpochs, err = removeFromAllBitfieldQueue(store, p.FaultEpochs, sectorNos); err != nil {
+		return err
+	}
+	p.FaultyPower = p.FaultyPower.Sub(PowerForSectors(sectorSize, sectors))
+	return nil
+}
+
+// Removes sectors from the partition entirely, whether faulty or not, along with their scheduled expirations.
+// The sectors must be in the partition.
+// Returns the sector numbers that were faulty.
+func (p *Partition) RemoveSectors(store adt.Store, sectorSize abi.SectorSize, sectors ...*SectorOnChainInfo) (*abi.BitField, error) {
+	sectorNos := sectorNumbers(sectors)
+	if err := p.requireSectors(sectorNos); err != nil {
+		return nil, err
+	}
+	faulty, err := bitfield.IntersectBitField(p.Faults, sectorNos)
+	if err != nil {
+		return nil, err
+	}
+	faultySet, err := faulty.AllMap(WPoStPartitionSectors)
+	if err != nil {
+		return nil, err
+	}
+
+	var faultySectors []*SectorOnChainInfo
+	for _, sector := range sectors {
+		p.Expirations, err = removeFromBitfieldQueue(store, p.Expirations, sector.Info.Expiration, uint64(sector.Info.SectorNumber))
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove sector %d expiration: %w", sector.Info.SectorNumber, err)
+		}
+		if faultySet[uint64(sector.Info.SectorNumber)] {
+			faultySectors = append(faultySectors, sector)
+		}
+	}
+	if p.FaultEpochs, err = removeFromAllBitfieldQueue(store, p.FaultEpochs, faulty); err != nil {
+		return nil, err
+	}
+
+	if p.Sectors, err = bitfield.SubtractBitField(p.Sectors, sectorNos); err != nil {
+		return nil, err
+	}
+	if p.Faults, err = bitfield.SubtractBitField(p.Faults, sectorNos); err != nil {
+		return nil, err
+	}
+	if p.Recoveries, err = bitfield.SubtractBitField(p.Recoveries, sectorNos); err != nil {
+		return nil, err
+	}
+	p.TotalPower = p.TotalPower.Sub(PowerForSectors(sectorSize, sectors))
+	p.FaultyPower = p.FaultyPower.Sub(PowerForSectors(sectorSize, faultySectors))
+	return faulty, nil
+}
+
+// Moves sectors from their previous expiration epochs to those of their updated infos, and updates the partition's
+// power to match. prev and updated must describe the same sectors, in the same order.
+// The sectors must be in the partition, and not faulty.
+func (p *Partition) RescheduleExpirations(store adt.Store, sectorSize abi.SectorSize, prev, updated []*SectorOnChainInfo) error {
+	if len(prev) != len(updated) {
+		return fmt.Errorf("mismatched sector counts %d and %d", len(prev), len(updated))
+	}
+	if len(prev) == 0 {
+		return nil
+	}
+	sectorNos := sectorNumbers(prev)
+	if err := p.requireSectors(sectorNos); err != nil {
+		return err
+	}
+	contains, err := abi.BitFieldContainsAny(p.Faults, sectorNos)
+	if err != nil {
+		return err
+	}
+	if contains {
+		return fmt.Errorf("sectors %v include faults", sectorNos)
+	}
+
+	for i, sector := range prev {
+		if updated[i].Info.SectorNumber != sector.Info.SectorNumber {
+			return fmt.Errorf("mismatched sector numbers %d and %d", sector.Info.SectorNumber, updated[i].Info.SectorNumber)
+		}
+	}
+
+	// Update the queue once per epoch, rather than once per sector.
+	prevEpochs, prevByEpoch := groupByExpiration(prev)
+	for _, epoch := range prevEpochs {
+		if p.Expirations, err = removeFromBitfieldQueue(store, p.Expirations, epoch, prevByEpoch[epoch]...); err != nil {
+			return fmt.Errorf("failed to remove sectors %v expiring at %d: %w", prevByEpoch[epoch], epoch, err)
+		}
+	}
+	updatedEpochs, updatedByEpoch := groupByExpiration(updated)
+	for _, epoch := range updatedEpochs {
+		if p.Expirations, err = addToBitfieldQueue(store, p.Expirations, epoch, updatedByEpoch[epoch]...); err != nil {
+			return fmt.Errorf("failed to add sectors %v expiring at %d: %w", updatedByEpoch[epoch], epoch, err)
+		}
+	}
+
+	p.TotalPower = p.TotalPower.Sub(PowerForSectors(sectorSize, prev)).Add(PowerForSectors(sectorSize, updated))
+	return nil
+}
+
+// Returns the sectors scheduled to expire at or before an epoch.
+func (p *Partition) ExpiringSectors(store adt.Store, until abi.ChainEpoch) (*abi.BitField, error) {
+	return bitfieldQueueUntil(store, p.Expirations, until)
+}
+
+// Returns the faulty sectors that were detected faulty in a proving period starting at or before an epoch.
+func (p *Partition) FaultsDetectedBy(store adt.Store, until abi.ChainEpoch) (*abi.BitField, error) {
+	return bitfieldQueueUntil(store, p.FaultEpochs, until)
+}
+
+// Returns the sectors expected to be proven by a PoSt for the partition, i.e. the non-faulty and recovering
+// sectors, and the faulty sectors to be skipped.
+func (p *Partition) ProvingSectors() (active, faults *abi.BitField, err error) {
+	faults, err = bitfield.SubtractBitField(p.Faults, p.Recoveries)
+	if err != nil {
+		return nil, nil, err
+	}
+	active, err = bitfield.SubtractBitField(p.Sectors, faults)
+	if err != nil {
+		return nil, nil, err
+	}
+	return active, faults, nil
+}
+
+func (p *Partition) requireSectors(sectorNos *abi.BitField) error {
+	contains, err := abi.BitFieldContainsAll(p.Sectors, sectorNos)
+	if err != nil {
+		return err
+	}
+	if !contains {
+		return fmt.Errorf("sectors %v not all in partition", sectorNos)
+	}
+	return nil
+}
+
+//
+// Power
+//
+
+func NewPowerPairZero() PowerPair {
+	return PowerPair{Raw: big.Zero(), QA: big.Zero()}
+}
+
+func (pp PowerPair) Add(other PowerPair) PowerPair {
+	return PowerPair{Raw: big.Add(pp.Raw, other.Raw), QA: big.Add(pp.QA, other.QA)}
+}
+
+func (pp PowerPair) Sub(other PowerPair) PowerPair {
+	return PowerPair{Raw: big.Sub(pp.Raw, other.Raw), QA: big.Sub(pp.QA, other.QA)}
+}
+
+func (pp PowerPair) Equals(other PowerPair) bool {
+	return pp.Raw.Equals(other.Raw) && pp.QA.Equals(other.QA)
+}
+
+// Computes the power of a sector, as credited by the power actor.
+func PowerForSector(sectorSize abi.SectorSize, sector *SectorOnChainInfo) PowerPair {
+	return PowerPair{
+		Raw: big.NewIntUnsigned(uint64(sectorSize)),
+		QA:  power.QAPowerForWeight(AsStorageWeightDesc(sectorSize, sector)),
+	}
+}
+
+// Sums the power of some sectors.
+func PowerForSectors(sectorSize abi.SectorSize, sectors []*SectorOnChainInfo) PowerPair {
+	total := NewPowerPairZero()
+	for _, s := range sectors {
+		total = total.Add(PowerForSector(sectorSize, s))
+	}
+	return total
+}
+
+// Groups the numbers of some sectors by their expiration epochs, returning the epochs in increasing order.
+func groupByExpiration(sectors []*SectorOnChainInfo) ([]abi.ChainEpoch, map[abi.ChainEpoch][]uint64) {
+	byEpoch := make(map[abi.ChainEpoch][]uint64)
+	var epochs []abi.ChainEpoch
+	for _, sector := range sectors {
+		epoch := sector.Info.Expiration
+		if _, ok := byEpoch[epoch]; !ok {
+			epochs = append(epochs, epoch)
+		}
+		byEpoch[epoch] = append(byEpoch[epoch], uint64(sector.Info.SectorNumber))
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+	return epochs, byEpoch
+}
+
+//
+// Bitfield queues
+//
+
+// Adds sector numbers to the set at an epoch in an AMT of bitfields, returning the new root.
+func addToBitfieldQueue(store adt.Store, root cid.Cid, epoch abi.ChainEpoch, sectorNos ...uint64) (cid.Cid, error) {
+	arr, err := adt.AsArray(store, root)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	bf := abi.NewBitField()
+	if _, err = arr.Get(uint64(epoch), bf); err != nil {
+		return cid.Undef, err
+	}
+	bf, err = bitfield.MergeBitFields(bf, bitfield.NewFromSet(sectorNos))
+	if err != nil {
+		return cid.Undef, err
+	}
+	if err = arr.Set(uint64(epoch), bf); err != nil {
+		return cid.Undef, err
+	}
+	return arr.Root()
+}
+
+// Removes sector numbers from the set at an epoch in an AMT of bitfields, deleting the set if it becomes empty.
+// Returns the new root.
+func removeFromBitfieldQueue(store adt.Store, root cid.Cid, epoch abi.ChainEpoch, sectorNos ...uint64) (cid.Cid, error) {
+	arr, err := adt.AsArray(store, root)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	bf := abi.NewBitField()
+	found, err := arr.Get(uint64(epoch), bf)
+	if err != nil || !found {
+		return root, err
+	}
+	bf, err = bitfield.SubtractBitField(bf, bitfield.NewFromSet(sectorNos))
+	if err != nil {
+		return cid.Undef, err
+	}
+	if err = setOrDeleteBitfield(arr, uint64(epoch), bf); err != nil {
+		return cid.Undef, err
+	}
+	return arr.Root()
+}
+
+// Removes sector numbers from every set in an AMT of bitfields, deleting sets that become empty.
+// Returns the new root.
+func removeFromAllBitfieldQueue(store adt.Store, root cid.Cid, sectorNos *abi.BitField) (cid.Cid, error) {
+	empty, err := sectorNos.IsEmpty()
+	if err != nil || empty {
+		return root, err
+	}
+	arr, err := adt.AsArray(store, root)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	var changedEpochs []uint64
+	var changedSets []*abi.BitField
+	bf := abi.NewBitField()
+	err = arr.ForEach(bf, func(i int64) error {
+		overlaps, err := abi.BitFieldContainsAny(bf, sectorNos)
+		if err != nil || !overlaps {
+			return err
+		}
+		remaining, err := bitfield.SubtractBitField(bf, sectorNos)
+		if err != nil {
+			return err
+		}
+		changedEpochs = append(changedEpochs, uint64(i))
+		changedSets = append(changedSets, remaining)
+		return nil
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	for i, epoch := range changedEpochs {
+		if err = setOrDeleteBitfield(arr, epoch, changedSets[i]); err != nil {
+			return cid.Undef, err
+		}
+	}
+	return arr.Root()
+}
+
+// Returns the union of the sets at or before an epoch in an AMT of bitfields.
+func bitfieldQueueUntil(store adt.Store, root cid.Cid, until abi.ChainEpoch) (*abi.BitField, error) {
+	arr, err := adt.AsArray(store, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []*abi.BitField
+	bf := abi.NewBitField()
+	errDone := fmt.Errorf("done")
+	err = arr.ForEach(bf, func(i int64) error {
+		if abi.ChainEpoch(i) > until {
+			return errDone
+		}
+		cpy, err := bitfield.MergeBitFields(abi.NewBitField(), bf) // bf is not safe to store
+		sets = append(sets, cpy)
+		return err
+	})
+	if err != nil && err != errDone {
+		return nil, err
+	}
+	return abi.BitFieldUnion(sets...)
+}
+
+func setOrDeleteBitfield(arr *adt.Array, i uint64, bf *abi.BitField) error {
+	empty, err := bf.IsEmpty()
+	if err != nil {
+		return err
+	}
+	if empty {
+		return arr.Delete(i)
+	}
+	return arr.Set(i, bf)
+}
+
+func sectorNumbers(sectors []*SectorOnChainInfo) *abi.BitField {
+	return bitfield.NewFromSet(sectorNosSlice(sectors))
+}
+
+func sectorNosSlice(sectors []*SectorOnChainInfo) []uint64 {
+	sectorNos := make([]uint64, len(sectors))
+	for i, s := range sectors {
+		sectorNos[i] = uint64(s.Info.SectorNumber)
+	}
+	return sectorNos
+}
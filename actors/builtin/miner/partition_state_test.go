@@ -0,0 +1,157 @@
+package miner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/abi/big"
+	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/support/ipld"
+	tutils "github.com/filecoin-project/specs-actors/support/testing"
+)
+
+func TestPartitions(t *testing.T) {
+	sectors := []*miner.SectorOnChainInfo{
+		testSector(5, 1, 0),
+		testSector(5, 2, 0),
+		testSector(9, 3, 0),
+		testSector(9, 4, 0),
+	}
+
+	setup := func(t *testing.T) (adt.Store, *miner.Partition) {
+		store := ipld.NewADTStore(context.Background())
+		emptyArray, err := adt.MakeEmptyArray(store).Root()
+		require.NoError(t, err)
+		partition := miner.ConstructPartition(emptyArray)
+		require.NoError(t, partition.AddSectors(store, SectorSize, sectors...))
+		return store, partition
+	}
+
+	t.Run("adds sectors with expirations and power", func(t *testing.T) {
+		store, partition := setup(t)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{1, 2, 3, 4}), partition.Sectors)
+		assertEmptyBitfield(t, partition.Faults)
+		assert.Equal(t, miner.PowerForSectors(SectorSize, sectors), partition.TotalPower)
+		assert.Equal(t, miner.NewPowerPairZero(), partition.FaultyPower)
+
+		expiring, err := partition.ExpiringSectors(store, 4)
+		require.NoError(t, err)
+		assertEmptyBitfield(t, expiring)
+		expiring, err = partition.ExpiringSectors(store, 5)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{1, 2}), expiring)
+		expiring, err = partition.ExpiringSectors(store, 9)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{1, 2, 3, 4}), expiring)
+
+		// A sector cannot be added twice.
+		assert.Error(t, partition.AddSectors(store, SectorSize, sectors[0]))
+	})
+
+	t.Run("faults, recoveries and recovery", func(t *testing.T) {
+		store, partition := setup(t)
+		require.NoError(t, partition.AddFaults(store, SectorSize, 10, sectors[1]))
+		require.NoError(t, partition.AddFaults(store, SectorSize, 20, sectors[2], sectors[3]))
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2, 3, 4}), partition.Faults)
+		assert.Equal(t, miner.PowerForSectors(SectorSize, sectors[1:]), partition.FaultyPower)
+
+		detected, err := partition.FaultsDetectedBy(store, 10)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2}), detected)
+
+		// Faults must be of healthy sectors in the partition.
+		assert.Error(t, partition.AddFaults(store, SectorSize, 20, sectors[1]))
+		assert.Error(t, partition.AddFaults(store, SectorSize, 20, testSector(9, 5, 0)))
+
+		// Recoveries must be of faulty sectors.
+		assert.Error(t, partition.AddRecoveries(bitfield.NewFromSet([]uint64{1})))
+		require.NoError(t, partition.AddRecoveries(bitfield.NewFromSet([]uint64{2, 3})))
+		assert.Error(t, partition.AddRecoveries(bitfield.NewFromSet([]uint64{3})))
+
+		active, faults, err := partition.ProvingSectors()
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{1, 2, 3}), active)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{4}), faults)
+
+		require.NoError(t, partition.RecoverFaults(store, SectorSize, sectors[1]))
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{3, 4}), partition.Faults)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{3}), partition.Recoveries)
+		assert.Equal(t, miner.PowerForSectors(SectorSize, sectors[2:]), partition.FaultyPower)
+		detected, err = partition.FaultsDetectedBy(store, 20)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{3, 4}), detected)
+
+		require.NoError(t, partition.RemoveRecoveries(bitfield.NewFromSet([]uint64{3})))
+		assertEmptyBitfield(t, partition.Recoveries)
+	})
+
+	t.Run("removes sectors", func(t *testing.T) {
+		store, partition := setup(t)
+		require.NoError(t, partition.AddFaults(store, SectorSize, 10, sectors[1], sectors[2]))
+		require.NoError(t, partition.AddRecoveries(bitfield.NewFromSet([]uint64{3})))
+
+		faulty, err := partition.RemoveSectors(store, SectorSize, sectors[0], sectors[2])
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{3}), faulty)
+
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2, 4}), partition.Sectors)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2}), partition.Faults)
+		assertEmptyBitfield(t, partition.Recoveries)
+		assert.Equal(t, miner.PowerForSectors(SectorSize, []*miner.SectorOnChainInfo{sectors[1], sectors[3]}), partition.TotalPower)
+		assert.Equal(t, miner.PowerForSector(SectorSize, sectors[1]), partition.FaultyPower)
+
+		expiring, err := partition.ExpiringSectors(store, 9)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2, 4}), expiring)
+		detected, err := partition.FaultsDetectedBy(store, 10)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2}), detected)
+
+		// Removed sectors are no longer in the partition.
+		_, err = partition.RemoveSectors(store, SectorSize, sectors[0])
+		assert.Error(t, err)
+	})
+
+	t.Run("reschedules expirations", func(t *testing.T) {
+		store, partition := setup(t)
+		updated := []*miner.SectorOnChainInfo{testSector(12, 1, 0), testSector(9, 2, 0)}
+		require.NoError(t, partition.RescheduleExpirations(store, SectorSize, sectors[:2], updated))
+
+		expiring, err := partition.ExpiringSectors(store, 9)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{2, 3, 4}), expiring)
+		expiring, err = partition.ExpiringSectors(store, 12)
+		require.NoError(t, err)
+		assertBfEqual(t, bitfield.NewFromSet([]uint64{1, 2, 3, 4}), expiring)
+		assert.Equal(t, miner.PowerForSectors(SectorSize, append(updated, sectors[2:]...)), partition.TotalPower)
+
+		// Faulty sectors cannot be rescheduled.
+		require.NoError(t, partition.AddFaults(store, SectorSize, 10, sectors[2]))
+		assert.Error(t, partition.RescheduleExpirations(store, SectorSize, sectors[2:3], []*miner.SectorOnChainInfo{testSector(12, 3, 0)}))
+	})
+}
+
+func TestPowerForSectors(t *testing.T) {
+	sector := testSector(10, 1, 0)
+	sector.DealWeight = big.Mul(big.NewIntUnsigned(uint64(SectorSize)), big.NewInt(10))
+	pwr := miner.PowerForSector(SectorSize, sector)
+	assert.Equal(t, big.NewIntUnsigned(uint64(SectorSize)), pwr.Raw)
+	assert.True(t, pwr.QA.GreaterThan(pwr.Raw))
+
+	total := miner.PowerForSectors(SectorSize, []*miner.SectorOnChainInfo{sector, testSector(10, 2, 0)})
+	assert.Equal(t, big.Mul(big.NewInt(2), pwr.Raw), total.Raw)
+	assert.True(t, total.Sub(pwr).Equals(miner.PowerForSector(SectorSize, testSector(10, 2, 0))))
+}
+
+// Creates a sector without deals, activated at some epoch and expiring at another.
+func testSector(expiration abi.ChainEpoch, sectorNo abi.SectorNumber, activation abi.ChainEpoch) *miner.SectorOnChainInfo {
+	sector := newSectorOnChainInfo(sectorNo, tutils.MakeCID("commr"), big.Zero(), activation)
+	sector.Info.Expiration = expiration
+	return sector
+}
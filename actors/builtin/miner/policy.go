@@ -12,6 +12,7 @@ import (
 const EpochDurationSeconds = 25
 const SecondsInYear = 31556925
 const SecondsInDay = 86400
+const EpochsInDay = SecondsInDay / EpochDurationSeconds
 
 // The period over which all a miner's active sectors will be challenged.
 const WPoStProvingPeriod = abi.ChainEpoch(SecondsInDay / EpochDurationSeconds)
@@ -29,6 +30,9 @@ const WPoStPartitionSectors = uint64(2350)
 // This bounds the size of a list/set of sector numbers that might be instantiated to process a submission.
 const WPoStMessagePartitionsMax = 100_000 / WPoStPartitionSectors
 
+// The period after a Window PoSt submission during which it may be disputed.
+const WPoStDisputeWindow = 2 * ChainFinalityish // PARAM_FINISH
+
 func init() {
 	// Check that the challenge windows divide the proving period evenly.
 	if WPoStProvingPeriod%WPoStChallengeWindow != 0 {
@@ -37,6 +41,10 @@ func init() {
 	if abi.ChainEpoch(WPoStPeriodDeadlines)*WPoStChallengeWindow != WPoStProvingPeriod {
 		panic(fmt.Sprintf("incompatible proving period %d and challenge window %d", WPoStProvingPeriod, WPoStChallengeWindow))
 	}
+	// Check that a submission's dispute window ends before the next submission for the same deadline discards it.
+	if WPoStDisputeWindow > WPoStProvingPeriod-WPoStChallengeWindow {
+		panic(fmt.Sprintf("dispute window %d exceeds the interval between deadlines %d", WPoStDisputeWindow, WPoStProvingPeriod-WPoStChallengeWindow))
+	}
 }
 
 // The maximum number of sectors that a miner can have simultaneously active.
@@ -53,6 +61,10 @@ const NewSectorsPerPeriodMax = 128 << 10
 // An approximation to chain state finality (should include message propagation time as well).
 const ChainFinalityish = abi.ChainEpoch(500) // PARAM_FINISH
 
+// The period for which penalty records are retained in a miner's penalty ledger.
+// Older records are pruned as new ones are recorded, bounding the size of the ledger.
+const PenaltyLedgerRetention = abi.ChainEpoch(90 * SecondsInDay / EpochDurationSeconds) // 90 days, PARAM_FINISH
+
 // Maximum duration to allow for the sealing process for seal algorithms.
 // Dependent on algorithm and sector size
 var MaxSealDuration = map[abi.RegisteredProof]abi.ChainEpoch{
@@ -62,6 +74,18 @@ var MaxSealDuration = map[abi.RegisteredProof]abi.ChainEpoch{
 	abi.RegisteredProof_StackedDRG512MiBSeal: abi.ChainEpoch(10000),
 }
 
+// The part of a pre-commit deposit refunded when the pre-commit is withdrawn some time after pre-commitment.
+// The refund shrinks linearly from the whole deposit to nothing over the sector's maximum seal duration.
+func precommitDepositRefund(deposit abi.TokenAmount, elapsed, maxSealDuration abi.ChainEpoch) abi.TokenAmount {
+	if elapsed >= maxSealDuration {
+		return big.Zero()
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return big.Div(big.Mul(deposit, big.NewInt(int64(maxSealDuration-elapsed))), big.NewInt(int64(maxSealDuration)))
+}
+
 // Number of epochs between publishing the precommit and when the challenge for interactive PoRep is drawn
 // used to ensure it is not predictable by miner.
 const PreCommitChallengeDelay = abi.ChainEpoch(10)
@@ -80,6 +104,15 @@ const FaultDeclarationCutoff = WPoStChallengeLookback // PARAM_FINISH
 // The maximum age of a fault before the sector is terminated.
 const FaultMaxAge = WPoStProvingPeriod*14 - 1
 
+// The maximum length of a miner's peer ID, in bytes.
+const MaxPeerIDLength = 128 // PARAM_FINISH
+
+// The maximum total length of a miner's multiaddrs, in bytes.
+const MaxMultiaddrData = 1024 // PARAM_FINISH
+
+// The maximum number of control addresses a miner may have, in addition to its worker.
+const ControlAddressesMax = 10 // PARAM_FINISH
+
 // Staging period for a miner worker key change.
 const WorkerKeyChangeDelay = 2 * ElectionLookback // PARAM_FINISH
 
@@ -94,19 +127,56 @@ type BigFrac struct {
 	denominator big.Int
 }
 
+// The period of projected reward that a sector pledges as storage pledge at activation, forfeit upon early termination.
+const StoragePledgeProjectionPeriod = abi.ChainEpoch(20 * EpochsInDay) // PARAM_FINISH
+
+// The maximum sector age counted towards the termination fee, which bounds the fee.
+const TerminationFeeAgeCap = abi.ChainEpoch(140 * EpochsInDay) // PARAM_FINISH
+
+// Share of the reward a sector was expected to earn over its age that is forfeit upon early termination.
+var terminationRewardShare = BigFrac{
+	// PARAM_FINISH
+	numerator:   big.NewInt(1),
+	denominator: big.NewInt(2),
+}
+
 // Penalty to locked pledge collateral for the termination of a sector before scheduled expiry.
-func pledgePenaltyForSectorTermination(sector *SectorOnChainInfo) abi.TokenAmount {
-	return big.Zero() // PARAM_FINISH
+// The fee is the sector's storage pledge plus a share of the reward it was expected to earn over its age, with the age
+// capped at TerminationFeeAgeCap.
+func pledgePenaltyForSectorTermination(sector *SectorOnChainInfo, currEpoch abi.ChainEpoch) abi.TokenAmount {
+	age := currEpoch - sector.ActivationEpoch
+	if age > TerminationFeeAgeCap {
+		age = TerminationFeeAgeCap
+	}
+	if age < 0 {
+		age = 0
+	}
+	ageReward := projectedReward(sector, age)
+	forfeitReward := big.Div(big.Mul(ageReward, terminationRewardShare.numerator), terminationRewardShare.denominator)
+	return big.Add(sector.ExpectedStoragePledge, forfeitReward)
 }
 
+// The period of projected reward that a sector forfeits for a declared or on-going fault.
+const DeclaredFaultProjectionPeriod = abi.ChainEpoch(2 * EpochsInDay) // PARAM_FINISH
+
+// The period of projected reward that a sector forfeits for an undeclared fault.
+const UndeclaredFaultProjectionPeriod = abi.ChainEpoch(5 * EpochsInDay) // PARAM_FINISH
+
 // Penalty to locked pledge collateral for a "skipped" sector or missing PoSt fault.
+// The penalty is the reward the sector was expected to earn over UndeclaredFaultProjectionPeriod.
 func pledgePenaltyForSectorUndeclaredFault(sector *SectorOnChainInfo) abi.TokenAmount {
-	return big.Zero() // PARAM_FINISH
+	return projectedReward(sector, UndeclaredFaultProjectionPeriod)
 }
 
 // Penalty to locked pledge collateral for a declared or on-going sector fault.
+// The penalty is the reward the sector was expected to earn over DeclaredFaultProjectionPeriod.
 func pledgePenaltyForSectorDeclaredFault(sector *SectorOnChainInfo) abi.TokenAmount {
-	return big.Zero() // PARAM_FINISH
+	return projectedReward(sector, DeclaredFaultProjectionPeriod)
+}
+
+// The reward a sector was expected to earn over some period, given its expected reward per day.
+func projectedReward(sector *SectorOnChainInfo, period abi.ChainEpoch) abi.TokenAmount {
+	return big.Div(big.Mul(sector.ExpectedDayReward, big.NewInt(int64(period))), big.NewInt(EpochsInDay))
 }
 
 var consensusFaultReporterInitialShare = BigFrac{
@@ -120,6 +190,17 @@ var consensusFaultReporterShareGrowthRate = BigFrac{
 	denominator: big.NewInt(100000),
 }
 
+// Share of the penalty for a successfully disputed Window PoSt that is paid to the disputer.
+var windowedPoStDisputerShare = BigFrac{
+	// PARAM_FINISH
+	numerator:   big.NewInt(1),
+	denominator: big.NewInt(2),
+}
+
+func rewardForDisputedWindowedPoSt(penalty abi.TokenAmount) abi.TokenAmount {
+	return big.Div(big.Mul(penalty, windowedPoStDisputerShare.numerator), windowedPoStDisputerShare.denominator)
+}
+
 // Specification for a linear vesting schedule.
 type VestSpec struct {
 	InitialDelay abi.ChainEpoch // Delay before any amount starts vesting.
@@ -135,6 +216,14 @@ var PledgeVestingSpec = VestSpec{
 	Quantization: SecondsInDay / EpochDurationSeconds,                     // 1 day, PARAM_FINISH
 }
 
+// Vesting schedule for block rewards, which vest sooner than pledge collateral.
+var RewardVestingSpec = VestSpec{
+	InitialDelay: abi.ChainEpoch(20 * SecondsInDay / EpochDurationSeconds),  // 20 days, PARAM_FINISH
+	VestPeriod:   abi.ChainEpoch(180 * SecondsInDay / EpochDurationSeconds), // 180 days, PARAM_FINISH
+	StepDuration: abi.ChainEpoch(SecondsInDay / EpochDurationSeconds),       // 1 day, PARAM_FINISH
+	Quantization: SecondsInDay / EpochDurationSeconds,                       // 1 day, PARAM_FINISH
+}
+
 func rewardForConsensusSlashReport(elapsedEpoch abi.ChainEpoch, collateral abi.TokenAmount) abi.TokenAmount {
 	// PARAM_FINISH
 	// var growthRate = SLASHER_SHARE_GROWTH_RATE_NUM / SLASHER_SHARE_GROWTH_RATE_DENOM
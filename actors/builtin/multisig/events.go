@@ -0,0 +1,19 @@
+package multisig
+
+import (
+	addr "github.com/filecoin-project/go-address"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
+)
+
+// Emitted when a transaction receives its final approval and is sent.
+type TxnExecuted struct {
+	ID       TxnID
+	To       addr.Address
+	Value    abi.TokenAmount
+	Method   abi.MethodNum
+	ExitCode exitcode.ExitCode // The exit code of the transaction's message, which may have failed.
+}
+
+func (e *TxnExecuted) EventType() string { return "TxnExecuted" }
@@ -354,8 +354,14 @@ func (a Actor) approveTransaction(rt vmr.Runtime, txnID TxnID, proposalHash []by
 			vmr.CBORBytes(txn.Params),
 			txn.Value,
 		)
-		// The exit code is explicitly ignored. It's ok for the subcall to fail.
-		_ = code
+		// It's ok for the subcall to fail. Its exit code is recorded in the event, but otherwise ignored.
+		rt.EmitEvent(&TxnExecuted{
+			ID:       txnID,
+			To:       txn.To,
+			Value:    txn.Value,
+			Method:   txn.Method,
+			ExitCode: code,
+		})
 
 		// This could be rearranged to happen inside the first state transaction, before the send().
 		rt.State().Transaction(&st, func() interface{} {
@@ -8,6 +8,7 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
 	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
 )
 
@@ -61,13 +62,12 @@ func (st *State) assertAvailable(currBalance abi.TokenAmount, amountToSpend abi.
 }
 
 func (as *State) getPendingTransaction(s adt.Store, txnID TxnID) (Transaction, error) {
-	hm, err := adt.AsMap(s, as.PendingTxns)
+	pending, err := AsTransactionMap(s, as.PendingTxns)
 	if err != nil {
 		return Transaction{}, err
 	}
 
-	var out Transaction
-	found, err := hm.Get(txnID, &out)
+	out, found, err := pending.Get(txnID)
 	if err != nil {
 		return Transaction{}, errors.Wrapf(err, "failed to read transaction")
 	}
@@ -75,11 +75,11 @@ func (as *State) getPendingTransaction(s adt.Store, txnID TxnID) (Transaction, e
 		return Transaction{}, errors.Errorf("failed to find transaction %v in HAMT %s", txnID, as.PendingTxns)
 	}
 
-	return out, nil
+	return *out, nil
 }
 
-func (st *State) mutatePendingTransactions(s adt.Store, f func(pt *adt.Map) error) error {
-	hm, err := adt.AsMap(s, st.PendingTxns)
+func (st *State) mutatePendingTransactions(s adt.Store, f func(pt *TransactionMap) error) error {
+	hm, err := AsTransactionMap(s, st.PendingTxns)
 	if err != nil {
 		return xerrors.Errorf("Failed to load pending txns map: %w", err)
 	}
@@ -98,7 +98,7 @@ func (st *State) mutatePendingTransactions(s adt.Store, f func(pt *adt.Map) erro
 }
 
 func (as *State) putPendingTransaction(s adt.Store, txnID TxnID, txn Transaction) error {
-	return as.mutatePendingTransactions(s, func(hm *adt.Map) error {
+	return as.mutatePendingTransactions(s, func(hm *TransactionMap) error {
 		if err := hm.Put(txnID, &txn); err != nil {
 			return errors.Wrapf(err, "failed to write transaction")
 		}
@@ -107,10 +107,44 @@ func (as *State) putPendingTransaction(s adt.Store, txnID TxnID, txn Transaction
 }
 
 func (as *State) deletePendingTransaction(s adt.Store, txnID TxnID) error {
-	return as.mutatePendingTransactions(s, func(hm *adt.Map) error {
+	return as.mutatePendingTransactions(s, func(hm *TransactionMap) error {
 		if err := hm.Delete(txnID); err != nil {
 			return errors.Wrapf(err, "failed to delete transaction")
 		}
 		return nil
 	})
 }
+
+// Checks the internal consistency of the state, returning any violations found.
+// The balance is that of the multisig actor.
+func (st *State) CheckStateInvariants(store adt.Store, balance abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("multisig")
+
+	acc.Require(len(st.Signers) > 0, "no signers")
+	acc.Require(st.NumApprovalsThreshold > 0, "non-positive approvals threshold %d", st.NumApprovalsThreshold)
+	acc.Require(st.NumApprovalsThreshold <= int64(len(st.Signers)), "approvals threshold %d exceeds %d signers",
+		st.NumApprovalsThreshold, len(st.Signers))
+	seen := make(map[address.Address]bool, len(st.Signers))
+	for _, s := range st.Signers {
+		acc.Require(!seen[s], "duplicate signer %v", s)
+		seen[s] = true
+	}
+
+	acc.Require(st.InitialBalance.GreaterThanEqual(big.Zero()), "negative initial balance %v", st.InitialBalance)
+	acc.Require(st.UnlockDuration >= 0, "negative unlock duration %d", st.UnlockDuration)
+	acc.Require(balance.GreaterThanEqual(big.Zero()), "negative balance %v", balance)
+
+	if pending, err := AsTransactionMap(store, st.PendingTxns); err != nil {
+		acc.Addf("failed to load pending transactions: %v", err)
+	} else {
+		err = pending.ForEach(func(id TxnID, txn *Transaction) error {
+			acc.Require(id < st.NextTxnID, "pending transaction %d not less than next ID %d", id, st.NextTxnID)
+			acc.Require(txn.Value.GreaterThanEqual(big.Zero()), "pending transaction %d has negative value %v", id, txn.Value)
+			acc.Require(len(txn.Approved) > 0, "pending transaction %d has no approvals", id)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate pending transactions")
+	}
+
+	return acc.Violations()
+}
@@ -360,6 +360,7 @@ func TestApprove(t *testing.T) {
 			Params:   fakeParams,
 			Approved: []addr.Address{anne},
 		})
+		assert.Empty(t, rt.Events())
 
 		rt.SetBalance(sendValue)
 		rt.SetCaller(bob, builtin.AccountActorCodeID)
@@ -379,6 +380,13 @@ func TestApprove(t *testing.T) {
 
 		// Transaction should be removed from actor state after send
 		actor.assertTransactions(rt)
+		assert.Equal(t, []runtime.Event{&multisig.TxnExecuted{
+			ID:       multisig.TxnID(txnID),
+			To:       chuck,
+			Value:    sendValue,
+			Method:   fakeMethod,
+			ExitCode: exitcode.Ok,
+		}}, rt.Events())
 	})
 
 	t.Run("fail approval with bad proposal hash", func(t *testing.T) {
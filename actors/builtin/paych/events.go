@@ -0,0 +1,14 @@
+package paych
+
+import (
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+)
+
+// Emitted when a voucher is redeemed against a lane.
+type VoucherRedeemed struct {
+	Lane   uint64
+	Nonce  uint64
+	Amount abi.TokenAmount // The total redeemed from the lane by the voucher.
+}
+
+func (e *VoucherRedeemed) EventType() string { return "VoucherRedeemed" }
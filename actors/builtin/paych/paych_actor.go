@@ -258,6 +258,8 @@ func (pca Actor) UpdateChannelState(rt vmr.Runtime, params *UpdateChannelStatePa
 		}
 		return nil
 	})
+
+	rt.EmitEvent(&VoucherRedeemed{Lane: sv.Lane, Nonce: sv.Nonce, Amount: sv.Amount})
 	return nil
 }
 
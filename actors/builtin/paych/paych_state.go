@@ -5,6 +5,8 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
+	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
 )
 
 // A given payment channel actor is established by From
@@ -52,3 +54,24 @@ func ConstructState(from addr.Address, to addr.Address) *State {
 		LaneStates:      []*LaneState{},
 	}
 }
+
+// Checks the internal consistency of the state, returning any violations found.
+// The balance is that of the payment channel actor, which must cover the amount to be sent.
+// The store is unused, since the payment channel state has no linked structures.
+func (st *State) CheckStateInvariants(_ adt.Store, balance abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("paych")
+
+	acc.Require(st.From.Protocol() == addr.ID, "from address %v is not an ID address", st.From)
+	acc.Require(st.To.Protocol() == addr.ID, "to address %v is not an ID address", st.To)
+	acc.Require(st.ToSend.GreaterThanEqual(big.Zero()), "negative amount to send %v", st.ToSend)
+	acc.Require(st.ToSend.LessThanEqual(balance), "amount to send %v exceeds balance %v", st.ToSend, balance)
+
+	for i, lane := range st.LaneStates {
+		acc.Require(lane.Redeemed.GreaterThanEqual(big.Zero()), "lane %d has negative redeemed amount %v", lane.ID, lane.Redeemed)
+		if i > 0 {
+			acc.Require(st.LaneStates[i-1].ID < lane.ID, "lanes out of order: %d before %d", st.LaneStates[i-1].ID, lane.ID)
+		}
+	}
+
+	return acc.Violations()
+}
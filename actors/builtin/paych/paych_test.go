@@ -277,6 +277,11 @@ func TestActor_UpdateChannelStateRedeem(t *testing.T) {
 		assert.Equal(t, expToSend, st2.ToSend)
 		assert.Equal(t, ucp.Sv.Amount, lUpdated.Redeemed)
 		assert.Equal(t, ucp.Sv.Nonce, lUpdated.Nonce)
+		assert.Equal(t, []runtime.Event{&VoucherRedeemed{
+			Lane:   ucp.Sv.Lane,
+			Nonce:  ucp.Sv.Nonce,
+			Amount: ucp.Sv.Amount,
+		}}, rt.Events())
 	})
 }
 
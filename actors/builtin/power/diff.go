@@ -0,0 +1,16 @@
+package power
+
+import (
+	xerrors "golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// Computes the miner power claims added, modified and removed between two versions of the power actor's state.
+func DiffClaims(store adt.Store, before, after *State) ([]ClaimMapChange, error) {
+	changes, err := DiffClaimMap(store, before.Claims, after.Claims)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to diff claims: %w", err)
+	}
+	return changes, nil
+}
@@ -43,6 +43,15 @@ func QAPowerForWeight(weight *SectorStorageWeightDesc) abi.StoragePower {
 	return big.Rsh(big.Mul(big.NewInt(int64(weight.SectorSize)), qual), SectorQualityPrecision)
 }
 
+// The share of the per-epoch block reward that a sector of some power is projected to earn, given the network's
+// total power and per-epoch reward.
+func ExpectedEpochRewardForPower(qapower abi.StoragePower, totqapower abi.StoragePower, perEpochReward abi.TokenAmount) abi.TokenAmount {
+	if totqapower.LessThanEqual(big.Zero()) {
+		return perEpochReward
+	}
+	return big.Div(big.Mul(qapower, perEpochReward), totqapower)
+}
+
 func InitialPledgeForWeight(qapower abi.StoragePower, totqapower abi.StoragePower, circSupply abi.TokenAmount, totalPledge abi.TokenAmount, perEpochReward abi.TokenAmount) abi.TokenAmount {
 	// Details here are still subject to change.
 	// PARAM_FINISH
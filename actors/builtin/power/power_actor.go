@@ -44,6 +44,8 @@ func (a Actor) Exports() []interface{} {
 		10:                        a.OnEpochTickEnd,
 		11:                        a.UpdatePledgeTotal,
 		12:                        a.OnConsensusFault,
+		13:                        a.BatchOnSectorProveCommit,
+		14:                        a.BatchOnSectorModifyWeightDesc,
 	}
 }
 
@@ -151,7 +153,7 @@ func (a Actor) DeleteMiner(rt Runtime, params *DeleteMinerParams) *adt.EmptyValu
 		rt.Abortf(exitcode.ErrIllegalArgument, "failed to resolve address %v", params.Miner)
 	}
 
-	ownerAddr, workerAddr := builtin.RequestMinerControlAddrs(rt, nominal)
+	ownerAddr, workerAddr, _ := builtin.RequestMinerControlAddrs(rt, nominal)
 	rt.ValidateImmediateCallerIs(ownerAddr, workerAddr)
 
 	var st State
@@ -186,10 +188,10 @@ type OnSectorProveCommitParams struct {
 	Weight SectorStorageWeightDesc
 }
 
-// Returns the initial pledge collateral requirement.
-func (a Actor) OnSectorProveCommit(rt Runtime, params *OnSectorProveCommitParams) *abi.TokenAmount {
+// Returns the initial pledge collateral requirement, and the sector's projected reward.
+func (a Actor) OnSectorProveCommit(rt Runtime, params *OnSectorProveCommitParams) *SectorPledge {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
-	initialPledge := a.computeInitialPledge(rt, &params.Weight)
+	pledge := a.computeSectorPledges(rt, []SectorStorageWeightDesc{params.Weight})[0]
 	var st State
 	rt.State().Transaction(&st, func() interface{} {
 		rbpower := big.NewIntUnsigned(uint64(params.Weight.SectorSize))
@@ -203,7 +205,39 @@ func (a Actor) OnSectorProveCommit(rt Runtime, params *OnSectorProveCommitParams
 		return nil
 	})
 
-	return &initialPledge
+	return &pledge
+}
+
+type BatchOnSectorProveCommitParams struct {
+	Weights []SectorStorageWeightDesc
+}
+
+type BatchOnSectorProveCommitReturn struct {
+	Sectors []SectorPledge // One for each weight, in order.
+}
+
+type SectorPledge struct {
+	InitialPledge       abi.TokenAmount
+	ExpectedEpochReward abi.TokenAmount // Share of the per-epoch block reward the sector's power is projected to earn
+}
+
+// Adds power for a batch of proven sectors.
+// Returns the initial pledge collateral requirement and projected reward of each sector, all computed with respect
+// to the network totals prior to the batch.
+func (a Actor) BatchOnSectorProveCommit(rt Runtime, params *BatchOnSectorProveCommitParams) *BatchOnSectorProveCommitReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	pledges := a.computeSectorPledges(rt, params.Weights)
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		rbpower, qapower := powersForWeights(params.Weights)
+		err := st.AddToClaim(adt.AsStore(rt), rt.Message().Caller(), rbpower, qapower)
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "Failed to add power for sectors: %v", err)
+		}
+		return nil
+	})
+
+	return &BatchOnSectorProveCommitReturn{Sectors: pledges}
 }
 
 type OnSectorTerminateParams struct {
@@ -274,7 +308,7 @@ type OnSectorModifyWeightDescParams struct {
 // Returns new initial pledge, now committed in place of the old.
 func (a Actor) OnSectorModifyWeightDesc(rt Runtime, params *OnSectorModifyWeightDescParams) *abi.TokenAmount {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
-	newInitialPledge := a.computeInitialPledge(rt, &params.NewWeight)
+	newInitialPledge := a.computeSectorPledges(rt, []SectorStorageWeightDesc{params.NewWeight})[0].InitialPledge
 
 	var st State
 	rt.State().Transaction(&st, func() interface{} {
@@ -296,6 +330,41 @@ func (a Actor) OnSectorModifyWeightDesc(rt Runtime, params *OnSectorModifyWeight
 	return &newInitialPledge
 }
 
+type BatchOnSectorModifyWeightDescParams struct {
+	Modifications []OnSectorModifyWeightDescParams
+}
+
+type BatchOnSectorModifyWeightDescReturn struct {
+	Sectors []SectorPledge // One for each modification's new weight, in order.
+}
+
+// Replaces the power of a batch of sectors with that of their new weights, in one update to the miner's claim.
+// Returns the initial pledge requirement and projected reward for each new weight, all computed with respect
+// to the network totals prior to the batch.
+func (a Actor) BatchOnSectorModifyWeightDesc(rt Runtime, params *BatchOnSectorModifyWeightDescParams) *BatchOnSectorModifyWeightDescReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	prevWeights := make([]SectorStorageWeightDesc, len(params.Modifications))
+	newWeights := make([]SectorStorageWeightDesc, len(params.Modifications))
+	for i, mod := range params.Modifications {
+		prevWeights[i] = mod.PrevWeight
+		newWeights[i] = mod.NewWeight
+	}
+	pledges := a.computeSectorPledges(rt, newWeights)
+
+	var st State
+	rt.State().Transaction(&st, func() interface{} {
+		prevRB, prevQA := powersForWeights(prevWeights)
+		newRB, newQA := powersForWeights(newWeights)
+		err := st.AddToClaim(adt.AsStore(rt), rt.Message().Caller(), big.Sub(newRB, prevRB), big.Sub(newQA, prevQA))
+		if err != nil {
+			rt.Abortf(exitcode.ErrIllegalState, "failed to modify claimed power for sectors: %v", err)
+		}
+		return nil
+	})
+
+	return &BatchOnSectorModifyWeightDescReturn{Sectors: pledges}
+}
+
 type EnrollCronEventParams struct {
 	EventEpoch abi.ChainEpoch
 	Payload    []byte
@@ -386,7 +455,7 @@ func (a Actor) OnConsensusFault(rt Runtime, pledgeAmount *abi.TokenAmount) *adt.
 // Method utility functions
 ////////////////////////////////////////////////////////////////////////////////
 
-func (a Actor) computeInitialPledge(rt Runtime, desc *SectorStorageWeightDesc) abi.TokenAmount {
+func (a Actor) computeSectorPledges(rt Runtime, descs []SectorStorageWeightDesc) []SectorPledge {
 	var st State
 	rt.State().Readonly(&st)
 
@@ -397,10 +466,15 @@ func (a Actor) computeInitialPledge(rt Runtime, desc *SectorStorageWeightDesc) a
 		rt.Abortf(exitcode.SysErrInternal, "failed to unmarshal epoch reward value: %s", err)
 	}
 
-	qapower := QAPowerForWeight(desc)
-	initialPledge := InitialPledgeForWeight(qapower, st.TotalQualityAdjPower, rt.TotalFilCircSupply(), st.TotalPledgeCollateral, epochReward)
-
-	return initialPledge
+	pledges := make([]SectorPledge, len(descs))
+	for i := range descs {
+		qapower := QAPowerForWeight(&descs[i])
+		pledges[i] = SectorPledge{
+			InitialPledge:       InitialPledgeForWeight(qapower, st.TotalQualityAdjPower, rt.TotalFilCircSupply(), st.TotalPledgeCollateral, epochReward),
+			ExpectedEpochReward: ExpectedEpochRewardForPower(qapower, st.TotalQualityAdjPower, epochReward),
+		}
+	}
+	return pledges
 }
 
 func (a Actor) processDeferredCronEvents(rt Runtime) error {
@@ -10,6 +10,7 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
 	. "github.com/filecoin-project/specs-actors/actors/util"
 	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
 )
@@ -17,7 +18,7 @@ import (
 type State struct {
 	TotalRawBytePower     abi.StoragePower
 	TotalQualityAdjPower  abi.StoragePower
-	TotalPledgeCollateral abi.TokenAmount
+	TotalPledgeCollateral abi.TokenAmount // Sum of miners' locked funds, including locked block rewards
 	MinerCount            int64
 
 	// A queue of events to be triggered by cron, indexed by epoch.
@@ -46,8 +47,6 @@ type CronEvent struct {
 	CallbackPayload []byte
 }
 
-type AddrKey = adt.AddrKey
-
 func ConstructState(emptyMapCid cid.Cid) *State {
 	return &State{
 		TotalRawBytePower:        abi.NewStoragePower(0),
@@ -88,14 +87,13 @@ func (st *State) minerNominalPowerMeetsConsensusMinimum(s adt.Store, miner addr.
 		return true, nil
 	}
 
-	m, err := adt.AsMap(s, st.Claims)
+	claims, err := AsClaimMap(s, st.Claims)
 	if err != nil {
 		return false, err
 	}
 
 	var minerSizes []abi.StoragePower
-	var claimed Claim
-	if err = m.ForEach(&claimed, func(k string) error {
+	if err = claims.ForEach(func(_ addr.Address, claimed *Claim) error {
 		nominalPower := claimed.QualityAdjPower
 		minerSizes = append(minerSizes, nominalPower)
 		return nil
@@ -211,36 +209,32 @@ func (st *State) clearCronEvents(store adt.Store, epoch abi.ChainEpoch) error {
 }
 
 func (st *State) getClaim(s adt.Store, a addr.Address) (*Claim, bool, error) {
-	hm, err := adt.AsMap(s, st.Claims)
+	claims, err := AsClaimMap(s, st.Claims)
 	if err != nil {
 		return nil, false, err
 	}
 
-	var out Claim
-	found, err := hm.Get(AddrKey(a), &out)
+	claim, found, err := claims.Get(a)
 	if err != nil {
 		return nil, false, errors.Wrapf(err, "failed to get claim for address %v from store %s", a, st.Claims)
 	}
-	if !found {
-		return nil, false, nil
-	}
-	return &out, true, nil
+	return claim, found, nil
 }
 
 func (st *State) setClaim(s adt.Store, a addr.Address, claim *Claim) error {
 	Assert(claim.RawBytePower.GreaterThanEqual(big.Zero()))
 	Assert(claim.QualityAdjPower.GreaterThanEqual(big.Zero()))
 
-	hm, err := adt.AsMap(s, st.Claims)
+	claims, err := AsClaimMap(s, st.Claims)
 	if err != nil {
 		return err
 	}
 
-	if err = hm.Put(AddrKey(a), claim); err != nil {
+	if err = claims.Put(a, claim); err != nil {
 		return errors.Wrapf(err, "failed to put claim with address %s power %v in store %s", a, claim, st.Claims)
 	}
 
-	st.Claims, err = hm.Root()
+	st.Claims, err = claims.Root()
 	if err != nil {
 		return err
 	}
@@ -248,15 +242,15 @@ func (st *State) setClaim(s adt.Store, a addr.Address, claim *Claim) error {
 }
 
 func (st *State) deleteClaim(s adt.Store, a addr.Address) error {
-	hm, err := adt.AsMap(s, st.Claims)
+	claims, err := AsClaimMap(s, st.Claims)
 	if err != nil {
 		return err
 	}
 
-	if err = hm.Delete(AddrKey(a)); err != nil {
+	if err = claims.Delete(a); err != nil {
 		return errors.Wrapf(err, "failed to delete claim at address %s from store %s", a, st.Claims)
 	}
-	st.Claims, err = hm.Root()
+	st.Claims, err = claims.Root()
 	if err != nil {
 		return err
 	}
@@ -283,3 +277,46 @@ func init() {
 		panic("incorrect chain epoch encoding")
 	}
 }
+
+// Checks the internal consistency of the state, returning any violations found.
+// The power actor's balance is not constrained.
+func (st *State) CheckStateInvariants(store adt.Store, _ abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("power")
+
+	acc.Require(st.TotalRawBytePower.GreaterThanEqual(big.Zero()), "negative total raw byte power %v", st.TotalRawBytePower)
+	acc.Require(st.TotalQualityAdjPower.GreaterThanEqual(big.Zero()), "negative total quality adjusted power %v", st.TotalQualityAdjPower)
+	acc.Require(st.TotalPledgeCollateral.GreaterThanEqual(big.Zero()), "negative total pledge collateral %v", st.TotalPledgeCollateral)
+
+	// Only miners meeting the consensus minimum power contribute to the totals.
+	if claims, err := AsClaimMap(store, st.Claims); err != nil {
+		acc.Addf("failed to load claims: %v", err)
+	} else {
+		claimCount := int64(0)
+		minersMeetingMin := int64(0)
+		rawPower := big.Zero()
+		qaPower := big.Zero()
+		err = claims.ForEach(func(a addr.Address, claim *Claim) error {
+			acc.Require(a.Protocol() == addr.ID, "claim key %v is not an ID address", a)
+			acc.Require(claim.RawBytePower.GreaterThanEqual(big.Zero()), "negative raw byte power %v claimed by %v", claim.RawBytePower, a)
+			acc.Require(claim.QualityAdjPower.GreaterThanEqual(big.Zero()), "negative quality adjusted power %v claimed by %v", claim.QualityAdjPower, a)
+
+			claimCount++
+			if claim.QualityAdjPower.GreaterThanEqual(ConsensusMinerMinPower) {
+				minersMeetingMin++
+				rawPower = big.Add(rawPower, claim.RawBytePower)
+				qaPower = big.Add(qaPower, claim.QualityAdjPower)
+			}
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate claims")
+
+		acc.Require(claimCount == st.MinerCount, "miner count %d != number of claims %d", st.MinerCount, claimCount)
+		acc.Require(minersMeetingMin == st.NumMinersMeetingMinPower, "miners meeting min power %d != number of claims meeting min power %d",
+			st.NumMinersMeetingMinPower, minersMeetingMin)
+		acc.Require(rawPower.Equals(st.TotalRawBytePower), "total raw byte power %v != claimed raw byte power %v", st.TotalRawBytePower, rawPower)
+		acc.Require(qaPower.Equals(st.TotalQualityAdjPower), "total quality adjusted power %v != claimed quality adjusted power %v",
+			st.TotalQualityAdjPower, qaPower)
+	}
+
+	return acc.Violations()
+}
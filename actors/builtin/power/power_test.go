@@ -17,6 +17,7 @@ import (
 	initact "github.com/filecoin-project/specs-actors/actors/builtin/init"
 	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
 	vmr "github.com/filecoin-project/specs-actors/actors/runtime"
+	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
 	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
 	mock "github.com/filecoin-project/specs-actors/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/support/testing"
@@ -39,7 +40,9 @@ func TestConstruction(t *testing.T) {
 
 	unused := tutil.NewIDAddr(t, 999)
 
-	builder := mock.NewBuilder(context.Background(), builtin.StoragePowerActorAddr).WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+	builder := mock.NewBuilder(context.Background(), builtin.StoragePowerActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID).
+		WithInvariantChecks(&power.State{})
 
 	t.Run("simple construction", func(t *testing.T) {
 		rt := builder.Build(t)
@@ -75,6 +78,28 @@ func TestConstruction(t *testing.T) {
 		verifyEmptyMap(t, rt, st.CronEventQueue)
 	})
 
+	t.Run("create miner gas is bounded", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		actor.createMiner(rt, owner1, worker1, miner1, unused, "miner1", abi.SectorSize(int64(32)))
+		rt.Verify()
+
+		used := rt.GasUsed()
+		assert.Greater(t, used, int64(0))
+		assert.Contains(t, rt.GasCharges(), mock.GasCharge{
+			Name:   "OnMethodInvocation",
+			Amount: vmr.DefaultPricelist().OnMethodInvocation(big.Zero(), builtin.MethodsInit.Exec),
+		})
+
+		// The same call aborts if the limit is lower than the gas it requires.
+		rt.SetGasLimit(used - 1)
+		rt.ExpectAbort(exitcode.SysErrOutOfGas, func() {
+			actor.createMiner(rt, owner2, worker2, miner2, unused, "miner2", abi.SectorSize(int64(32)))
+		})
+		rt.Reset()
+	})
+
 	t.Run("ensure cronevents scheduled in null rounds are executed on next block", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -125,6 +150,127 @@ func TestConstruction(t *testing.T) {
 	})
 }
 
+func TestBatchOnSectorProveCommit(t *testing.T) {
+	actor := spActorHarness{power.Actor{}, t}
+	owner := tutil.NewIDAddr(t, 101)
+	miner1 := tutil.NewIDAddr(t, 103)
+	miner2 := tutil.NewIDAddr(t, 106)
+	unused := tutil.NewIDAddr(t, 999)
+	sectorSize := abi.SectorSize(32 << 30)
+
+	builder := mock.NewBuilder(context.Background(), builtin.StoragePowerActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID).
+		WithInvariantChecks(&power.State{})
+
+	t.Run("adds power for all sectors, with pledges computed from prior totals", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetCirculatingSupply(abi.NewTokenAmount(1 << 30))
+		actor.constructAndVerify(rt)
+		actor.createMiner(rt, owner, owner, miner1, unused, "miner1", sectorSize)
+		actor.createMiner(rt, owner, owner, miner2, unused, "miner2", sectorSize)
+
+		// Give the network some power, with respect to which pledges are computed.
+		plain := power.SectorStorageWeightDesc{SectorSize: sectorSize, Duration: 100, DealWeight: big.Zero(), VerifiedDealWeight: big.Zero()}
+		actor.addPower(rt, miner1, plain)
+		priorQAPower := power.QAPowerForWeight(&plain)
+
+		verified := power.SectorStorageWeightDesc{SectorSize: sectorSize, Duration: 100, DealWeight: big.Zero(),
+			VerifiedDealWeight: big.NewInt(int64(sectorSize) * 100)}
+		epochReward := abi.NewTokenAmount(1000)
+		pledges := actor.batchOnSectorProveCommit(rt, miner2, epochReward, plain, verified)
+
+		require.Len(t, pledges, 2)
+		for i, weight := range []power.SectorStorageWeightDesc{plain, verified} {
+			qaPower := power.QAPowerForWeight(&weight)
+			assert.Equal(t, power.InitialPledgeForWeight(qaPower, priorQAPower, rt.TotalFilCircSupply(), big.Zero(), epochReward), pledges[i].InitialPledge)
+			assert.Equal(t, power.ExpectedEpochRewardForPower(qaPower, priorQAPower, epochReward), pledges[i].ExpectedEpochReward)
+		}
+		assert.True(t, pledges[1].InitialPledge.GreaterThan(pledges[0].InitialPledge))
+
+		claim := actor.getClaim(rt, miner2)
+		assert.Equal(t, big.NewInt(2*int64(sectorSize)), claim.RawBytePower)
+		assert.Equal(t, big.Add(power.QAPowerForWeight(&plain), power.QAPowerForWeight(&verified)), claim.QualityAdjPower)
+
+		var st power.State
+		rt.GetState(&st)
+		assert.Equal(t, big.NewInt(3*int64(sectorSize)), st.TotalRawBytePower)
+		assert.Equal(t, big.Add(priorQAPower, claim.QualityAdjPower), st.TotalQualityAdjPower)
+	})
+
+	t.Run("rejects a caller that is not a miner", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(owner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.Call(actor.BatchOnSectorProveCommit, &power.BatchOnSectorProveCommitParams{})
+		})
+	})
+}
+
+func TestBatchOnSectorModifyWeightDesc(t *testing.T) {
+	actor := spActorHarness{power.Actor{}, t}
+	owner := tutil.NewIDAddr(t, 101)
+	miner1 := tutil.NewIDAddr(t, 103)
+	unused := tutil.NewIDAddr(t, 999)
+	sectorSize := abi.SectorSize(32 << 30)
+
+	builder := mock.NewBuilder(context.Background(), builtin.StoragePowerActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID).
+		WithInvariantChecks(&power.State{})
+
+	t.Run("replaces power of all sectors, with pledges computed for their new weights", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetCirculatingSupply(abi.NewTokenAmount(1 << 30))
+		actor.constructAndVerify(rt)
+		actor.createMiner(rt, owner, owner, miner1, unused, "miner1", sectorSize)
+
+		plain := power.SectorStorageWeightDesc{SectorSize: sectorSize, Duration: 100, DealWeight: big.Zero(), VerifiedDealWeight: big.Zero()}
+		verified := power.SectorStorageWeightDesc{SectorSize: sectorSize, Duration: 100, DealWeight: big.Zero(),
+			VerifiedDealWeight: big.NewInt(int64(sectorSize) * 100)}
+		actor.addPower(rt, miner1, plain, verified)
+		priorQAPower := big.Add(power.QAPowerForWeight(&plain), power.QAPowerForWeight(&verified))
+
+		// Extend both sectors. The verified deal weight then spans only half the verified sector's duration.
+		extendedPlain := plain
+		extendedPlain.Duration = 200
+		extendedVerified := verified
+		extendedVerified.Duration = 200
+		epochReward := abi.NewTokenAmount(1000)
+		pledges := actor.batchOnSectorModifyWeightDesc(rt, miner1, epochReward,
+			power.OnSectorModifyWeightDescParams{PrevWeight: plain, NewWeight: extendedPlain},
+			power.OnSectorModifyWeightDescParams{PrevWeight: verified, NewWeight: extendedVerified})
+
+		require.Len(t, pledges, 2)
+		for i, weight := range []power.SectorStorageWeightDesc{extendedPlain, extendedVerified} {
+			qaPower := power.QAPowerForWeight(&weight)
+			assert.Equal(t, power.InitialPledgeForWeight(qaPower, priorQAPower, rt.TotalFilCircSupply(), big.Zero(), epochReward), pledges[i].InitialPledge)
+			assert.Equal(t, power.ExpectedEpochRewardForPower(qaPower, priorQAPower, epochReward), pledges[i].ExpectedEpochReward)
+		}
+
+		expectedQAPower := big.Add(power.QAPowerForWeight(&extendedPlain), power.QAPowerForWeight(&extendedVerified))
+		assert.True(t, expectedQAPower.LessThan(priorQAPower))
+		claim := actor.getClaim(rt, miner1)
+		assert.Equal(t, big.NewInt(2*int64(sectorSize)), claim.RawBytePower)
+		assert.Equal(t, expectedQAPower, claim.QualityAdjPower)
+
+		var st power.State
+		rt.GetState(&st)
+		assert.Equal(t, big.NewInt(2*int64(sectorSize)), st.TotalRawBytePower)
+		assert.Equal(t, expectedQAPower, st.TotalQualityAdjPower)
+	})
+
+	t.Run("rejects a caller that is not a miner", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(owner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.Call(actor.BatchOnSectorModifyWeightDesc, &power.BatchOnSectorModifyWeightDescParams{})
+		})
+	})
+}
+
 //
 // Misc. Utility Functions
 //
@@ -208,3 +354,43 @@ func (h *spActorHarness) initCreateMinerBytes(owner, worker addr.Address, peer p
 
 	return buf.Bytes()
 }
+
+// Adds power to a miner's claim, as if sectors were recovered from faults, without computing pledges.
+func (h *spActorHarness) addPower(rt *mock.Runtime, miner addr.Address, weights ...power.SectorStorageWeightDesc) {
+	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.Call(h.OnFaultEnd, &power.OnFaultEndParams{Weights: weights})
+	rt.Verify()
+}
+
+func (h *spActorHarness) batchOnSectorProveCommit(rt *mock.Runtime, miner addr.Address, epochReward abi.TokenAmount,
+	weights ...power.SectorStorageWeightDesc) []power.SectorPledge {
+	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.LastPerEpochReward, nil, big.Zero(), &epochReward, exitcode.Ok)
+	ret := rt.Call(h.BatchOnSectorProveCommit, &power.BatchOnSectorProveCommitParams{Weights: weights}).(*power.BatchOnSectorProveCommitReturn)
+	rt.Verify()
+	return ret.Sectors
+}
+
+func (h *spActorHarness) batchOnSectorModifyWeightDesc(rt *mock.Runtime, miner addr.Address, epochReward abi.TokenAmount,
+	modifications ...power.OnSectorModifyWeightDescParams) []power.SectorPledge {
+	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.LastPerEpochReward, nil, big.Zero(), &epochReward, exitcode.Ok)
+	params := &power.BatchOnSectorModifyWeightDescParams{Modifications: modifications}
+	ret := rt.Call(h.BatchOnSectorModifyWeightDesc, params).(*power.BatchOnSectorModifyWeightDescReturn)
+	rt.Verify()
+	return ret.Sectors
+}
+
+func (h *spActorHarness) getClaim(rt *mock.Runtime, miner addr.Address) *power.Claim {
+	var st power.State
+	rt.GetState(&st)
+	claims, err := power.AsClaimMap(adt.AsStore(rt), st.Claims)
+	require.NoError(h.t, err)
+	claim, found, err := claims.Get(miner)
+	require.NoError(h.t, err)
+	require.True(h.t, found)
+	return claim
+}
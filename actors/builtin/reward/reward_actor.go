@@ -60,6 +60,8 @@ type AwardBlockRewardParams struct {
 //
 // The reward is reduced before the residual is credited to the block producer, by:
 // - a penalty amount, provided as a parameter, which is burnt,
+//
+// The residual is locked in the miner actor as vesting rewards. If the miner fails to accept it, the reward is burnt.
 func (a Actor) AwardBlockReward(rt vmr.Runtime, params *AwardBlockRewardParams) *adt.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.SystemActorAddr)
 	AssertMsg(rt.CurrentBalance().GreaterThanEqual(params.GasReward),
@@ -93,6 +95,10 @@ func (a Actor) AwardBlockReward(rt vmr.Runtime, params *AwardBlockRewardParams)
 	}).(abi.TokenAmount)
 
 	_, code := rt.Send(minerAddr, builtin.MethodsMiner.AddLockedFund, &rewardPayable, rewardPayable)
+	if !code.IsSuccess() {
+		// The reward could not be delivered, so burn it along with the penalty.
+		penalty = big.Add(penalty, rewardPayable)
+	}
 
 	// Burn the penalty amount.
 	_, code = rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, penalty)
@@ -10,6 +10,7 @@ import (
 	"github.com/filecoin-project/specs-actors/actors/abi/big"
 	"github.com/filecoin-project/specs-actors/actors/builtin"
 	"github.com/filecoin-project/specs-actors/actors/builtin/reward"
+	"github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
 	"github.com/filecoin-project/specs-actors/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/support/testing"
 )
@@ -51,6 +52,45 @@ func TestAwardBlockReward(t *testing.T) {
 		})
 		rt.Verify()
 	})
+
+	t.Run("locks reward in miner and burns penalty", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		miner := tutil.NewIDAddr(t, 1000)
+		rt.SetBalance(abi.NewTokenAmount(100))
+
+		payable := abi.NewTokenAmount(7)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectSend(miner, builtin.MethodsMiner.AddLockedFund, &payable, payable, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, abi.NewTokenAmount(3), nil, exitcode.Ok)
+		rt.Call(actor.AwardBlockReward, &reward.AwardBlockRewardParams{
+			Miner:       miner,
+			Penalty:     abi.NewTokenAmount(3),
+			GasReward:   abi.NewTokenAmount(10),
+			TicketCount: 1,
+		})
+		rt.Verify()
+	})
+
+	t.Run("burns reward that the miner fails to accept", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		miner := tutil.NewIDAddr(t, 1000)
+		// The balance is exactly the gas reward, all of which remains to be burnt after the failed send.
+		rt.SetBalance(abi.NewTokenAmount(10))
+
+		payable := abi.NewTokenAmount(7)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectSend(miner, builtin.MethodsMiner.AddLockedFund, &payable, payable, nil, exitcode.ErrForbidden)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, abi.NewTokenAmount(10), nil, exitcode.Ok)
+		rt.Call(actor.AwardBlockReward, &reward.AwardBlockRewardParams{
+			Miner:       miner,
+			Penalty:     abi.NewTokenAmount(3),
+			GasReward:   abi.NewTokenAmount(10),
+			TicketCount: 1,
+		})
+		rt.Verify()
+	})
 }
 
 type rewardHarness struct {
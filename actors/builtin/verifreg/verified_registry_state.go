@@ -7,13 +7,13 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
 	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
 )
 
 // DataCap is an integer number of bytes.
 // We can introduce policy changes and replace this in the future.
 type DataCap = abi.StoragePower
-type AddrKey = adt.AddrKey
 
 type State struct {
 	// Root key holder multisig.
@@ -40,12 +40,12 @@ func ConstructState(emptyMapCid cid.Cid, rootKeyAddress addr.Address) *State {
 }
 
 func (st *State) PutVerifier(store adt.Store, verifierAddr addr.Address, verifierCap DataCap) error {
-	verifiers, err := adt.AsMap(store, st.Verifiers)
+	verifiers, err := AsDataCapMap(store, st.Verifiers)
 	if err != nil {
 		return err
 	}
 
-	if err := verifiers.Put(AddrKey(verifierAddr), &verifierCap); err != nil {
+	if err := verifiers.Put(verifierAddr, &verifierCap); err != nil {
 		return errors.Wrapf(err, "failed to put verifier %v with a cap of %v", verifierAddr, verifierCap)
 	}
 	st.Verifiers, err = verifiers.Root()
@@ -56,26 +56,25 @@ func (st *State) PutVerifier(store adt.Store, verifierAddr addr.Address, verifie
 }
 
 func (st *State) GetVerifier(store adt.Store, address addr.Address) (*DataCap, bool, error) {
-	verifiers, err := adt.AsMap(store, st.Verifiers)
+	verifiers, err := AsDataCapMap(store, st.Verifiers)
 	if err != nil {
 		return nil, false, err
 	}
 
-	var allowance DataCap
-	found, err := verifiers.Get(AddrKey(address), &allowance)
+	allowance, found, err := verifiers.Get(address)
 	if err != nil {
 		return nil, false, errors.Wrapf(err, "failed to load verifier for address %v", address)
 	}
-	return &allowance, found, nil
+	return allowance, found, nil
 }
 
 func (st *State) DeleteVerifier(store adt.Store, address addr.Address) error {
-	verifiers, err := adt.AsMap(store, st.Verifiers)
+	verifiers, err := AsDataCapMap(store, st.Verifiers)
 	if err != nil {
 		return err
 	}
 
-	if err := verifiers.Delete(AddrKey(address)); err != nil {
+	if err := verifiers.Delete(address); err != nil {
 		return errors.Wrapf(err, "failed to delete verifier for address %v", address)
 	}
 	st.Verifiers, err = verifiers.Root()
@@ -86,12 +85,12 @@ func (st *State) DeleteVerifier(store adt.Store, address addr.Address) error {
 }
 
 func (st *State) PutVerifiedClient(store adt.Store, vcAddress addr.Address, vcCap DataCap) error {
-	vc, err := adt.AsMap(store, st.VerifiedClients)
+	vc, err := AsDataCapMap(store, st.VerifiedClients)
 	if err != nil {
 		return err
 	}
 
-	if err := vc.Put(AddrKey(vcAddress), &vcCap); err != nil {
+	if err := vc.Put(vcAddress, &vcCap); err != nil {
 		return err
 	}
 	st.VerifiedClients, err = vc.Root()
@@ -102,26 +101,28 @@ func (st *State) PutVerifiedClient(store adt.Store, vcAddress addr.Address, vcCa
 }
 
 func (st *State) GetVerifiedClient(store adt.Store, vcAddress addr.Address) (DataCap, bool, error) {
-	vc, err := adt.AsMap(store, st.VerifiedClients)
+	vc, err := AsDataCapMap(store, st.VerifiedClients)
 	if err != nil {
 		return big.Zero(), false, err
 	}
 
-	var allowance DataCap
-	found, err := vc.Get(AddrKey(vcAddress), &allowance)
+	allowance, found, err := vc.Get(vcAddress)
 	if err != nil {
 		return big.Zero(), false, errors.Wrapf(err, "failed to load verified client for address %v", vcAddress)
 	}
-	return allowance, found, nil
+	if !found {
+		return big.Zero(), false, nil
+	}
+	return *allowance, true, nil
 }
 
 func (st *State) DeleteVerifiedClient(store adt.Store, vcAddress addr.Address) error {
-	vc, err := adt.AsMap(store, st.VerifiedClients)
+	vc, err := AsDataCapMap(store, st.VerifiedClients)
 	if err != nil {
 		return err
 	}
 
-	if err := vc.Delete(AddrKey(vcAddress)); err != nil {
+	if err := vc.Delete(vcAddress); err != nil {
 		return errors.Wrapf(err, "failed to delete verified client for address %v", vcAddress)
 	}
 	st.VerifiedClients, err = vc.Root()
@@ -130,3 +131,34 @@ func (st *State) DeleteVerifiedClient(store adt.Store, vcAddress addr.Address) e
 	}
 	return nil
 }
+
+// Checks the internal consistency of the state, returning any violations found.
+// The verified registry actor's balance is not constrained.
+func (st *State) CheckStateInvariants(store adt.Store, _ abi.TokenAmount) []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("verifreg")
+	acc.Require(st.RootKey != addr.Undef, "root key is undefined")
+
+	if verifiers, err := AsDataCapMap(store, st.Verifiers); err != nil {
+		acc.Addf("failed to load verifiers: %v", err)
+	} else {
+		err = verifiers.ForEach(func(a addr.Address, dataCap *DataCap) error {
+			acc.Require(dataCap.GreaterThanEqual(big.Zero()), "verifier %v has negative data cap %v", a, dataCap)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate verifiers")
+	}
+
+	// Clients are removed once their remaining data cap falls below the minimum deal size.
+	if clients, err := AsDataCapMap(store, st.VerifiedClients); err != nil {
+		acc.Addf("failed to load verified clients: %v", err)
+	} else {
+		err = clients.ForEach(func(a addr.Address, dataCap *DataCap) error {
+			acc.Require(dataCap.GreaterThanEqual(MinVerifiedDealSize), "verified client %v has data cap %v below minimum %v",
+				a, dataCap, MinVerifiedDealSize)
+			return nil
+		})
+		acc.RequireNoError(err, "failed to iterate verified clients")
+	}
+
+	return acc.Violations()
+}
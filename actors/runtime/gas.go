@@ -0,0 +1,209 @@
+package runtime
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	crypto "github.com/filecoin-project/specs-actors/actors/crypto"
+)
+
+// Pricelist provides the gas cost of operations performed by the VM on behalf of actors.
+// The prices are a property of the network (and may change with its version), so are provided by the VM.
+type Pricelist interface {
+	// The cost of loading a block of some size from the store.
+	OnIpldGet(dataSize int) int64
+	// The cost of storing a block of some size.
+	OnIpldPut(dataSize int) int64
+
+	// The cost of sending a message to another actor, including transferring value and invoking the method.
+	OnMethodInvocation(value abi.TokenAmount, methodNum abi.MethodNum) int64
+
+	// The cost of emitting an event of some encoded size.
+	OnEmitEvent(dataSize int) int64
+
+	// The costs of syscalls.
+	OnVerifySignature(sigType crypto.SigType, plaintextSize int) int64
+	OnHashing(dataSize int) int64
+	OnComputeUnsealedSectorCid(proof abi.RegisteredProof, pieces []abi.PieceInfo) int64
+	OnVerifySeal(info abi.SealVerifyInfo) int64
+	OnBatchVerifySeals(infos []abi.SealVerifyInfo) int64
+	OnVerifyPost(info abi.WindowPoStVerifyInfo) int64
+	OnVerifyConsensusFault() int64
+
+	// The cost of a single HAMT or AMT operation.
+	// This is in addition to the cost of loading and storing the nodes traversed by the operation.
+	OnHamtOperation() int64
+	OnAmtOperation() int64
+}
+
+// Returns a price list suitable for testing and simulation.
+func DefaultPricelist() Pricelist {
+	return defaultPricelist
+}
+
+var defaultPricelist = &pricelistV0{
+	ipldGetBase:    10,
+	ipldGetPerByte: 1,
+	ipldPutBase:    20,
+	ipldPutPerByte: 2,
+
+	sendBase:          5,
+	sendTransferFunds: 5,
+	sendInvokeMethod:  10,
+
+	emitEventBase:    5,
+	emitEventPerByte: 1,
+
+	verifySignature: map[crypto.SigType]int64{
+		crypto.SigTypeSecp256k1: 2,
+		crypto.SigTypeBLS:       3,
+	},
+	hashingBase:                  5,
+	hashingPerByte:               2,
+	computeUnsealedSectorCidBase: 100,
+	verifySealBase:               2000,
+	batchVerifySealsBase:         500,
+	batchVerifySealsPerSeal:      1500,
+	verifyPostBase:               700,
+	verifyConsensusFault:         10,
+
+	hamtOperation: 10,
+	amtOperation:  10,
+}
+
+type pricelistV0 struct {
+	ipldGetBase    int64
+	ipldGetPerByte int64
+	ipldPutBase    int64
+	ipldPutPerByte int64
+
+	sendBase          int64
+	sendTransferFunds int64
+	sendInvokeMethod  int64
+
+	emitEventBase    int64
+	emitEventPerByte int64
+
+	verifySignature              map[crypto.SigType]int64
+	hashingBase                  int64
+	hashingPerByte               int64
+	computeUnsealedSectorCidBase int64
+	verifySealBase               int64
+	batchVerifySealsBase         int64
+	batchVerifySealsPerSeal      int64
+	verifyPostBase               int64
+	verifyConsensusFault         int64
+
+	hamtOperation int64
+	amtOperation  int64
+}
+
+var _ Pricelist = (*pricelistV0)(nil)
+
+func (pl *pricelistV0) OnIpldGet(dataSize int) int64 {
+	return pl.ipldGetBase + int64(dataSize)*pl.ipldGetPerByte
+}
+
+func (pl *pricelistV0) OnIpldPut(dataSize int) int64 {
+	return pl.ipldPutBase + int64(dataSize)*pl.ipldPutPerByte
+}
+
+func (pl *pricelistV0) OnMethodInvocation(value abi.TokenAmount, methodNum abi.MethodNum) int64 {
+	ret := pl.sendBase
+	if value.GreaterThan(big.Zero()) {
+		ret += pl.sendTransferFunds
+	}
+	if methodNum != 0 { // builtin.MethodSend, which can't be referenced from here
+		ret += pl.sendInvokeMethod
+	}
+	return ret
+}
+
+func (pl *pricelistV0) OnEmitEvent(dataSize int) int64 {
+	return pl.emitEventBase + int64(dataSize)*pl.emitEventPerByte
+}
+
+func (pl *pricelistV0) OnVerifySignature(sigType crypto.SigType, _ int) int64 {
+	return pl.verifySignature[sigType]
+}
+
+func (pl *pricelistV0) OnHashing(dataSize int) int64 {
+	return pl.hashingBase + int64(dataSize)*pl.hashingPerByte
+}
+
+func (pl *pricelistV0) OnComputeUnsealedSectorCid(_ abi.RegisteredProof, _ []abi.PieceInfo) int64 {
+	return pl.computeUnsealedSectorCidBase
+}
+
+func (pl *pricelistV0) OnVerifySeal(_ abi.SealVerifyInfo) int64 {
+	return pl.verifySealBase
+}
+
+func (pl *pricelistV0) OnBatchVerifySeals(infos []abi.SealVerifyInfo) int64 {
+	return pl.batchVerifySealsBase + int64(len(infos))*pl.batchVerifySealsPerSeal
+}
+
+func (pl *pricelistV0) OnVerifyPost(_ abi.WindowPoStVerifyInfo) int64 {
+	return pl.verifyPostBase
+}
+
+func (pl *pricelistV0) OnVerifyConsensusFault() int64 {
+	return pl.verifyConsensusFault
+}
+
+func (pl *pricelistV0) OnHamtOperation() int64 {
+	return pl.hamtOperation
+}
+
+func (pl *pricelistV0) OnAmtOperation() int64 {
+	return pl.amtOperation
+}
+
+// Wraps a syscalls implementation so that each call is charged to a runtime, at the prices
+// given by the runtime's price list.
+// This is intended for use by runtime implementations, rather than by actors.
+func NewGasChargingSyscalls(rt Runtime, inner Syscalls) Syscalls {
+	return &gasChargingSyscalls{rt: rt, inner: inner}
+}
+
+type gasChargingSyscalls struct {
+	rt    Runtime
+	inner Syscalls
+}
+
+func (s *gasChargingSyscalls) VerifySignature(signature crypto.Signature, signer addr.Address, plaintext []byte) error {
+	s.rt.ChargeGas("OnVerifySignature", s.rt.Pricelist().OnVerifySignature(signature.Type, len(plaintext)))
+	return s.inner.VerifySignature(signature, signer, plaintext)
+}
+
+func (s *gasChargingSyscalls) HashBlake2b(data []byte) [32]byte {
+	s.rt.ChargeGas("OnHashing", s.rt.Pricelist().OnHashing(len(data)))
+	return s.inner.HashBlake2b(data)
+}
+
+func (s *gasChargingSyscalls) ComputeUnsealedSectorCID(reg abi.RegisteredProof, pieces []abi.PieceInfo) (cid.Cid, error) {
+	s.rt.ChargeGas("OnComputeUnsealedSectorCid", s.rt.Pricelist().OnComputeUnsealedSectorCid(reg, pieces))
+	return s.inner.ComputeUnsealedSectorCID(reg, pieces)
+}
+
+func (s *gasChargingSyscalls) VerifySeal(vi abi.SealVerifyInfo) error {
+	s.rt.ChargeGas("OnVerifySeal", s.rt.Pricelist().OnVerifySeal(vi))
+	return s.inner.VerifySeal(vi)
+}
+
+func (s *gasChargingSyscalls) BatchVerifySeals(vis []abi.SealVerifyInfo) ([]bool, error) {
+	s.rt.ChargeGas("OnBatchVerifySeals", s.rt.Pricelist().OnBatchVerifySeals(vis))
+	return s.inner.BatchVerifySeals(vis)
+}
+
+func (s *gasChargingSyscalls) VerifyPoSt(vi abi.WindowPoStVerifyInfo) error {
+	s.rt.ChargeGas("OnVerifyPost", s.rt.Pricelist().OnVerifyPost(vi))
+	return s.inner.VerifyPoSt(vi)
+}
+
+func (s *gasChargingSyscalls) VerifyConsensusFault(h1, h2, extra []byte) (*ConsensusFault, error) {
+	s.rt.ChargeGas("OnVerifyConsensusFault", s.rt.Pricelist().OnVerifyConsensusFault())
+	return s.inner.VerifyConsensusFault(h1, h2, extra)
+}
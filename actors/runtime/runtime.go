@@ -78,6 +78,20 @@ type Runtime interface {
 
 	TotalFilCircSupply() abi.TokenAmount
 
+	// Emits a structured event describing an effect of the current message, for consumption off-chain.
+	// The event is CBOR-encoded into the message receipt. Events emitted by a message that does not exit
+	// successfully are discarded, along with its state changes.
+	// Charges gas proportional to the size of the encoded event.
+	EmitEvent(event Event)
+
+	// Charges gas for an operation, aborting with SysErrOutOfGas if the message's gas limit is exceeded.
+	// The runtime charges for storage, sends and syscalls itself; actors may charge for other significant computation.
+	// The name is for diagnostic purposes only.
+	ChargeGas(name string, gas int64)
+
+	// The prices of operations that consume gas.
+	Pricelist() Pricelist
+
 	// Provides a Go context for use by HAMT, etc.
 	// The VM is intended to provide an idealised machine abstraction, with infinite storage etc, so this context
 	// should not be used by actor code directly.
@@ -90,11 +104,20 @@ type Runtime interface {
 // Store defines the storage module exposed to actors.
 type Store interface {
 	// Retrieves and deserializes an object from the store into `o`. Returns whether successful.
+	// Charges gas proportional to the size of the object.
 	Get(c cid.Cid, o CBORUnmarshaler) bool
 	// Serializes and stores an object, returning its CID.
+	// Charges gas proportional to the size of the object.
 	Put(x CBORMarshaler) cid.Cid
 }
 
+// An event emitted by an actor. Each actor defines the types of event it emits.
+type Event interface {
+	CBORMarshaler
+	// A name identifying the type of event, unique among the types emitted by an actor.
+	EventType() string
+}
+
 // Message contains information available to the actor about the executing message.
 type Message interface {
 	// The address of the immediate calling actor. Always an ID-address.
@@ -117,6 +140,9 @@ type Syscalls interface {
 	ComputeUnsealedSectorCID(reg abi.RegisteredProof, pieces []abi.PieceInfo) (cid.Cid, error)
 	// Verifies a sector seal proof.
 	VerifySeal(vi abi.SealVerifyInfo) error
+	// Verifies a batch of sector seal proofs, returning whether each is valid, in order.
+	// An error is returned only if the batch as a whole could not be verified.
+	BatchVerifySeals(vis []abi.SealVerifyInfo) ([]bool, error)
 	// Verifies a proof of spacetime.
 	VerifyPoSt(vi abi.WindowPoStVerifyInfo) error
 	// Verifies that two block headers provide proof of a consensus fault:
@@ -0,0 +1,396 @@
+package adt
+
+import (
+	"bytes"
+	"context"
+	"math/bits"
+
+	amt "github.com/filecoin-project/go-amt-ipld/v2"
+	cid "github.com/ipfs/go-cid"
+	hamt "github.com/ipfs/go-hamt-ipld"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// The kind of change to an entry between two versions of a collection.
+type ChangeType int
+
+const (
+	ChangeAdd ChangeType = iota
+	ChangeModify
+	ChangeRemove
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdd:
+		return "add"
+	case ChangeModify:
+		return "modify"
+	case ChangeRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// A change to an entry of a map, with the serialized values before and after.
+// Before is nil for an added entry, and After is nil for a removed one.
+type MapChange struct {
+	Type   ChangeType
+	Key    string
+	Before *cbg.Deferred
+	After  *cbg.Deferred
+}
+
+// A change to an entry of an array, with the serialized values before and after.
+// Before is nil for an added entry, and After is nil for a removed one.
+type ArrayChange struct {
+	Type   ChangeType
+	Index  uint64
+	Before *cbg.Deferred
+	After  *cbg.Deferred
+}
+
+// Computes the entries added, modified and removed between two versions of a HAMT-based map.
+// Subtrees with equal CIDs in both versions are skipped without being loaded.
+func DiffMaps(s Store, before, after cid.Cid) ([]MapChange, error) {
+	if before.Equals(after) {
+		return nil, nil
+	}
+	b, err := AsMap(s, before)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load map before: %w", err)
+	}
+	a, err := AsMap(s, after)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load map after: %w", err)
+	}
+
+	d := hamtDiffer{ctx: s.Context(), store: s}
+	if err := d.diffNodes(b.root, a.root); err != nil {
+		return nil, err
+	}
+	return d.changes, nil
+}
+
+// Computes the entries added, modified and removed between two versions of an AMT-based array, in index order.
+// Subtrees with equal CIDs in both versions are skipped without being loaded.
+func DiffArrays(s Store, before, after cid.Cid) ([]ArrayChange, error) {
+	if before.Equals(after) {
+		return nil, nil
+	}
+	b, err := AsArray(s, before)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load array before: %w", err)
+	}
+	a, err := AsArray(s, after)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load array after: %w", err)
+	}
+
+	d := amtDiffer{ctx: s.Context(), store: s}
+	if err := d.diffNodes(&b.root.Node, int(b.root.Height), &a.root.Node, int(a.root.Height), 0); err != nil {
+		return nil, err
+	}
+	return d.changes, nil
+}
+
+//
+// HAMT
+//
+
+type hamtDiffer struct {
+	ctx     context.Context
+	store   Store
+	changes []MapChange
+}
+
+// Compares two HAMT nodes at the same depth. The same hash bits index pointers in both nodes.
+func (d *hamtDiffer) diffNodes(before, after *hamt.Node) error {
+	width := before.Bitfield.BitLen()
+	if after.Bitfield.BitLen() > width {
+		width = after.Bitfield.BitLen()
+	}
+	for i := 0; i < width; i++ {
+		b := hamtPointerAt(before, i)
+		a := hamtPointerAt(after, i)
+		if b == nil && a == nil {
+			continue
+		}
+		if b != nil && a != nil && b.Link.Defined() && a.Link.Defined() {
+			if b.Link.Equals(a.Link) {
+				continue
+			}
+			bChild, err := d.loadNode(b.Link)
+			if err != nil {
+				return err
+			}
+			aChild, err := d.loadNode(a.Link)
+			if err != nil {
+				return err
+			}
+			if err := d.diffNodes(bChild, aChild); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// At least one side is a bucket of entries rather than a link, so compare the entries directly.
+		bEntries, err := d.collect(b)
+		if err != nil {
+			return err
+		}
+		aEntries, err := d.collect(a)
+		if err != nil {
+			return err
+		}
+		d.diffEntries(bEntries, aEntries)
+	}
+	return nil
+}
+
+// Compares the entries of two buckets or subtrees, recording changes in the order of keys after then before.
+func (d *hamtDiffer) diffEntries(before, after []*hamt.KV) {
+	beforeByKey := make(map[string]*cbg.Deferred, len(before))
+	for _, kv := range before {
+		beforeByKey[kv.Key] = kv.Value
+	}
+	for _, kv := range after {
+		b, found := beforeByKey[kv.Key]
+		if !found {
+			d.changes = append(d.changes, MapChange{Type: ChangeAdd, Key: kv.Key, After: kv.Value})
+			continue
+		}
+		delete(beforeByKey, kv.Key)
+		if !bytes.Equal(b.Raw, kv.Value.Raw) {
+			d.changes = append(d.changes, MapChange{Type: ChangeModify, Key: kv.Key, Before: b, After: kv.Value})
+		}
+	}
+	for _, kv := range before {
+		if b, found := beforeByKey[kv.Key]; found {
+			d.changes = append(d.changes, MapChange{Type: ChangeRemove, Key: kv.Key, Before: b})
+		}
+	}
+}
+
+// Collects all entries under a pointer, loading subtrees as necessary.
+func (d *hamtDiffer) collect(p *hamt.Pointer) ([]*hamt.KV, error) {
+	if p == nil {
+		return nil, nil
+	}
+	if !p.Link.Defined() {
+		return p.KVs, nil
+	}
+	nd, err := d.loadNode(p.Link)
+	if err != nil {
+		return nil, err
+	}
+	var out []*hamt.KV
+	for _, child := range nd.Pointers {
+		kvs, err := d.collect(child)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, kvs...)
+	}
+	return out, nil
+}
+
+func (d *hamtDiffer) loadNode(c cid.Cid) (*hamt.Node, error) {
+	nd, err := hamt.LoadNode(d.ctx, d.store, c, hamt.UseTreeBitWidth(hamtBitwidth))
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load hamt node %v: %w", c, err)
+	}
+	return nd, nil
+}
+
+// Returns the pointer at a hash index of a node, or nil if there is none.
+func hamtPointerAt(n *hamt.Node, i int) *hamt.Pointer {
+	if n.Bitfield.Bit(i) == 0 {
+		return nil
+	}
+	// Pointers are stored compactly, so the position is the number of set bits below the index.
+	pos := 0
+	for j := 0; j < i; j++ {
+		pos += int(n.Bitfield.Bit(j))
+	}
+	return n.Pointers[pos]
+}
+
+//
+// AMT
+//
+
+// The number of children of each AMT node.
+const amtWidth = 8
+
+type amtDiffer struct {
+	ctx     context.Context
+	store   Store
+	changes []ArrayChange
+}
+
+// Compares two AMT nodes covering indices from offset. A node of lesser height occupies only the first
+// child position of the other, since the AMT grows in height by pushing its root down to the left.
+func (d *amtDiffer) diffNodes(before *amt.Node, bHeight int, after *amt.Node, aHeight int, offset uint64) error {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return d.forEach(after, aHeight, offset, func(i uint64, v *cbg.Deferred) {
+			d.changes = append(d.changes, ArrayChange{Type: ChangeAdd, Index: i, After: v})
+		})
+	case after == nil:
+		return d.forEach(before, bHeight, offset, func(i uint64, v *cbg.Deferred) {
+			d.changes = append(d.changes, ArrayChange{Type: ChangeRemove, Index: i, Before: v})
+		})
+	case bHeight < aHeight:
+		links := amtExpandLinks(after)
+		first, err := d.loadNode(links[0])
+		if err != nil {
+			return err
+		}
+		if err := d.diffNodes(before, bHeight, first, aHeight-1, offset); err != nil {
+			return err
+		}
+		return d.diffLinks(nil, links[1:], aHeight, offset, 1)
+	case bHeight > aHeight:
+		links := amtExpandLinks(before)
+		first, err := d.loadNode(links[0])
+		if err != nil {
+			return err
+		}
+		if err := d.diffNodes(first, bHeight-1, after, aHeight, offset); err != nil {
+			return err
+		}
+		return d.diffLinks(links[1:], nil, bHeight, offset, 1)
+	case bHeight == 0:
+		bValues := amtExpandValues(before)
+		aValues := amtExpandValues(after)
+		for i := 0; i < amtWidth; i++ {
+			idx := offset + uint64(i)
+			b, a := bValues[i], aValues[i]
+			switch {
+			case b == nil && a != nil:
+				d.changes = append(d.changes, ArrayChange{Type: ChangeAdd, Index: idx, After: a})
+			case b != nil && a == nil:
+				d.changes = append(d.changes, ArrayChange{Type: ChangeRemove, Index: idx, Before: b})
+			case b != nil && a != nil && !bytes.Equal(b.Raw, a.Raw):
+				d.changes = append(d.changes, ArrayChange{Type: ChangeModify, Index: idx, Before: b, After: a})
+			}
+		}
+		return nil
+	default:
+		return d.diffLinks(amtExpandLinks(before), amtExpandLinks(after), bHeight, offset, 0)
+	}
+}
+
+// Compares the children of two nodes at some height, starting from child position `start`.
+// Either slice of links may be nil, in which case all its children are treated as absent.
+func (d *amtDiffer) diffLinks(before, after []cid.Cid, height int, offset uint64, start int) error {
+	subCount := amtNodesForHeight(height)
+	for i := 0; i < amtWidth-start; i++ {
+		b, a := cid.Undef, cid.Undef
+		if before != nil {
+			b = before[i]
+		}
+		if after != nil {
+			a = after[i]
+		}
+		if b.Equals(a) {
+			continue
+		}
+		bChild, err := d.loadNode(b)
+		if err != nil {
+			return err
+		}
+		aChild, err := d.loadNode(a)
+		if err != nil {
+			return err
+		}
+		childOffset := offset + uint64(start+i)*subCount
+		if err := d.diffNodes(bChild, height-1, aChild, height-1, childOffset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Calls a function with every value in the subtree of a node.
+func (d *amtDiffer) forEach(n *amt.Node, height int, offset uint64, fn func(uint64, *cbg.Deferred)) error {
+	if height == 0 {
+		for i, v := range amtExpandValues(n) {
+			if v != nil {
+				fn(offset+uint64(i), v)
+			}
+		}
+		return nil
+	}
+	subCount := amtNodesForHeight(height)
+	for i, l := range amtExpandLinks(n) {
+		if !l.Defined() {
+			continue
+		}
+		child, err := d.loadNode(l)
+		if err != nil {
+			return err
+		}
+		if err := d.forEach(child, height-1, offset+uint64(i)*subCount, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Loads a node, or returns nil for an undefined CID.
+func (d *amtDiffer) loadNode(c cid.Cid) (*amt.Node, error) {
+	if !c.Defined() {
+		return nil, nil
+	}
+	var nd amt.Node
+	if err := d.store.Get(d.ctx, c, &nd); err != nil {
+		return nil, xerrors.Errorf("failed to load amt node %v: %w", c, err)
+	}
+	return &nd, nil
+}
+
+// Returns a node's links by child position, with cid.Undef for absent children.
+func amtExpandLinks(n *amt.Node) []cid.Cid {
+	out := make([]cid.Cid, amtWidth)
+	for i, pos := range amtPositions(n) {
+		out[pos] = n.Links[i]
+	}
+	return out
+}
+
+// Returns a leaf node's values by position, with nil for absent values.
+func amtExpandValues(n *amt.Node) []*cbg.Deferred {
+	out := make([]*cbg.Deferred, amtWidth)
+	for i, pos := range amtPositions(n) {
+		out[pos] = n.Values[i]
+	}
+	return out
+}
+
+// Returns the positions of a node's children (or values), in order.
+func amtPositions(n *amt.Node) []int {
+	if len(n.Bmap) == 0 {
+		return nil
+	}
+	positions := make([]int, 0, bits.OnesCount8(n.Bmap[0]))
+	for i := 0; i < amtWidth; i++ {
+		if n.Bmap[0]&(1<<i) != 0 {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// The number of indices covered by each child of a node at some height.
+func amtNodesForHeight(height int) uint64 {
+	out := uint64(1)
+	for i := 0; i < height; i++ {
+		out *= amtWidth
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+package adt_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	cid "github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/actors/runtime"
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/support/ipld"
+)
+
+func TestDiffArrays(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("equal roots have no changes", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		arr := adt.MakeEmptyArray(store)
+		require.NoError(t, arr.Set(3, cborInt(3)))
+		root, err := arr.Root()
+		require.NoError(t, err)
+
+		changes, err := adt.DiffArrays(store, root, root)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("added, modified and removed entries", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		arr := adt.MakeEmptyArray(store)
+		for i := uint64(0); i < 100; i++ {
+			require.NoError(t, arr.Set(i, cborInt(int64(i))))
+		}
+		before, err := arr.Root()
+		require.NoError(t, err)
+
+		require.NoError(t, arr.Set(5, cborInt(500)))
+		require.NoError(t, arr.Delete(70))
+		require.NoError(t, arr.Set(100, cborInt(100)))
+		after, err := arr.Root()
+		require.NoError(t, err)
+
+		changes, err := adt.DiffArrays(store, before, after)
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+		assert.Equal(t, adt.ChangeModify, changes[0].Type)
+		assert.Equal(t, uint64(5), changes[0].Index)
+		assertDeferredInt(t, 5, changes[0].Before)
+		assertDeferredInt(t, 500, changes[0].After)
+		assert.Equal(t, adt.ArrayChange{Type: adt.ChangeRemove, Index: 70, Before: changes[1].Before}, changes[1])
+		assertDeferredInt(t, 70, changes[1].Before)
+		assert.Equal(t, adt.ArrayChange{Type: adt.ChangeAdd, Index: 100, After: changes[2].After}, changes[2])
+		assertDeferredInt(t, 100, changes[2].After)
+	})
+
+	t.Run("arrays of different heights", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		arr := adt.MakeEmptyArray(store)
+		require.NoError(t, arr.Set(1, cborInt(1)))
+		require.NoError(t, arr.Set(2, cborInt(2)))
+		before, err := arr.Root()
+		require.NoError(t, err)
+
+		require.NoError(t, arr.Delete(1))
+		require.NoError(t, arr.Set(1000, cborInt(1000)))
+		after, err := arr.Root()
+		require.NoError(t, err)
+
+		changes, err := adt.DiffArrays(store, before, after)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, adt.ChangeRemove, changes[0].Type)
+		assert.Equal(t, uint64(1), changes[0].Index)
+		assert.Equal(t, adt.ChangeAdd, changes[1].Type)
+		assert.Equal(t, uint64(1000), changes[1].Index)
+
+		// And in reverse.
+		changes, err = adt.DiffArrays(store, after, before)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, adt.ChangeAdd, changes[0].Type)
+		assert.Equal(t, uint64(1), changes[0].Index)
+		assert.Equal(t, adt.ChangeRemove, changes[1].Type)
+		assert.Equal(t, uint64(1000), changes[1].Index)
+	})
+
+	t.Run("unchanged subtrees are not loaded", func(t *testing.T) {
+		store := &countingStore{Store: ipld.NewADTStore(ctx)}
+		arr := adt.MakeEmptyArray(store)
+		for i := uint64(0); i < 1000; i++ {
+			require.NoError(t, arr.Set(i, cborInt(int64(i))))
+		}
+		before, err := arr.Root()
+		require.NoError(t, err)
+		require.NoError(t, arr.Set(999, cborInt(0)))
+		after, err := arr.Root()
+		require.NoError(t, err)
+
+		store.gets = 0
+		changes, err := adt.DiffArrays(store, before, after)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		// Two roots, plus the two versions of each of the three nodes on the path to the changed leaf.
+		assert.Equal(t, 8, store.gets)
+	})
+}
+
+func TestDiffMaps(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("equal roots have no changes", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		root, err := adt.MakeEmptyMap(store).Root()
+		require.NoError(t, err)
+
+		changes, err := adt.DiffMaps(store, root, root)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("added, modified and removed entries", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		m := adt.MakeEmptyMap(store)
+		for i := int64(0); i < 500; i++ {
+			require.NoError(t, m.Put(adt.IntKey(i), cborInt(i)))
+		}
+		before, err := m.Root()
+		require.NoError(t, err)
+
+		require.NoError(t, m.Put(adt.IntKey(5), cborInt(-5)))
+		require.NoError(t, m.Delete(adt.IntKey(70)))
+		require.NoError(t, m.Delete(adt.IntKey(71)))
+		require.NoError(t, m.Put(adt.IntKey(1000), cborInt(1000)))
+		after, err := m.Root()
+		require.NoError(t, err)
+
+		changes, err := adt.DiffMaps(store, before, after)
+		require.NoError(t, err)
+		byKey := make(map[int64]adt.MapChange)
+		for _, c := range changes {
+			k, err := adt.ParseIntKey(c.Key)
+			require.NoError(t, err)
+			byKey[k] = c
+		}
+		require.Len(t, byKey, 4)
+		assert.Equal(t, adt.ChangeModify, byKey[5].Type)
+		assertDeferredInt(t, 5, byKey[5].Before)
+		assertDeferredInt(t, -5, byKey[5].After)
+		assert.Equal(t, adt.ChangeRemove, byKey[70].Type)
+		assert.Nil(t, byKey[70].After)
+		assert.Equal(t, adt.ChangeRemove, byKey[71].Type)
+		assert.Equal(t, adt.ChangeAdd, byKey[1000].Type)
+		assert.Nil(t, byKey[1000].Before)
+		assertDeferredInt(t, 1000, byKey[1000].After)
+	})
+}
+
+func cborInt(i int64) runtime.CBORMarshaler {
+	v := cbg.CborInt(i)
+	return &v
+}
+
+func assertDeferredInt(t *testing.T, expected int64, d *cbg.Deferred) {
+	require.NotNil(t, d)
+	var v cbg.CborInt
+	require.NoError(t, v.UnmarshalCBOR(bytes.NewReader(d.Raw)))
+	assert.Equal(t, cbg.CborInt(expected), v)
+}
+
+// A store that counts the objects it retrieves.
+type countingStore struct {
+	adt.Store
+	gets int
+}
+
+func (s *countingStore) Get(ctx context.Context, c cid.Cid, out interface{}) error {
+	s.gets++
+	return s.Store.Get(ctx, c, out)
+}
@@ -65,6 +65,7 @@ func (m *Map) Root() (cid.Cid, error) {
 
 // Put adds value `v` with key `k` to the hamt store.
 func (m *Map) Put(k Keyer, v runtime.CBORMarshaler) error {
+	chargeHamtOperation(m.store, "OnHamtPut")
 	if err := m.root.Set(m.store.Context(), k.Key(), v); err != nil {
 		return errors.Wrapf(err, "map put failed set in node %v with key %v value %v", m.lastCid, k.Key(), v)
 	}
@@ -73,6 +74,7 @@ func (m *Map) Put(k Keyer, v runtime.CBORMarshaler) error {
 
 // Get puts the value at `k` into `out`.
 func (m *Map) Get(k Keyer, out runtime.CBORUnmarshaler) (bool, error) {
+	chargeHamtOperation(m.store, "OnHamtGet")
 	if err := m.root.Find(m.store.Context(), k.Key(), out); err != nil {
 		if err == hamt.ErrNotFound {
 			return false, nil
@@ -84,6 +86,7 @@ func (m *Map) Get(k Keyer, out runtime.CBORUnmarshaler) (bool, error) {
 
 // Delete removes the value at `k` from the hamt store.
 func (m *Map) Delete(k Keyer) error {
+	chargeHamtOperation(m.store, "OnHamtDelete")
 	if err := m.root.Delete(m.store.Context(), k.Key()); err != nil {
 		return errors.Wrapf(err, "map delete failed in node %v key %v", m.root, k.Key())
 	}
@@ -96,6 +99,7 @@ func (m *Map) Delete(k Keyer) error {
 // Iteration halts if the function returns an error.
 // If the output parameter is nil, deserialization is skipped.
 func (m *Map) ForEach(out runtime.CBORUnmarshaler, fn func(key string) error) error {
+	chargeHamtOperation(m.store, "OnHamtForEach")
 	return m.root.ForEach(m.store.Context(), func(k string, val interface{}) error {
 		if out != nil {
 			// Why doesn't hamt.ForEach() just return the value as bytes?
@@ -70,6 +70,22 @@ func (r rtStore) Put(_ context.Context, v interface{}) (cid.Cid, error) {
 	return r.Store().Put(v.(vmr.CBORMarshaler)), nil
 }
 
+// Charges gas for a HAMT operation if the store is backed by a runtime.
+// The nodes loaded and stored by the operation are charged separately, by the runtime's store.
+func chargeHamtOperation(s Store, name string) {
+	if r, ok := s.(rtStore); ok {
+		r.ChargeGas(name, r.Pricelist().OnHamtOperation())
+	}
+}
+
+// Charges gas for an AMT operation if the store is backed by a runtime.
+// The nodes loaded and stored by the operation are charged separately, by the runtime's store.
+func chargeAmtOperation(s Store, name string) {
+	if r, ok := s.(rtStore); ok {
+		r.ChargeGas(name, r.Pricelist().OnAmtOperation())
+	}
+}
+
 // Keyer defines an interface required to put values in mapping.
 type Keyer interface {
 	Key() string
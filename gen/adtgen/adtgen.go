@@ -0,0 +1,370 @@
+// Package adtgen generates typed wrappers around the ADT collections in actors/util/adt.
+// The wrappers fix the key and value types of a HAMT or AMT, sparing callers from
+// constructing keys, parsing them back out of iteration and unmarshalling values by hand.
+package adtgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"golang.org/x/xerrors"
+)
+
+const adtPkgPath = "github.com/filecoin-project/specs-actors/actors/util/adt"
+
+// Describes a typed wrapper of an AMT.
+type Array struct {
+	// The name of the generated type.
+	Name string
+	// A value of the type by which entries are indexed. Must be of unsigned integer kind.
+	// Defaults to uint64 if nil.
+	Index interface{}
+	// A value of the type stored in the array.
+	Value interface{}
+}
+
+// Describes a typed wrapper of a HAMT.
+type Map struct {
+	// The name of the generated type.
+	Name string
+	// A value of the type by which entries are keyed. Must be an address, or of integer kind.
+	Key interface{}
+	// A value of the type stored in the map.
+	Value interface{}
+}
+
+// Writes a file in package `pkg` containing the wrapper types for some collections, each an Array or Map.
+func WriteCollectionsToFile(fname, pkg string, collections ...interface{}) error {
+	imports := map[string]string{
+		"bytes":                  "",
+		"github.com/ipfs/go-cid": "cid",
+		adtPkgPath:               "adt",
+	}
+	body := new(bytes.Buffer)
+	for _, c := range collections {
+		var err error
+		switch c := c.(type) {
+		case Array:
+			err = writeArray(body, pkg, imports, c)
+		case Map:
+			err = writeMap(body, pkg, imports, c)
+		default:
+			err = xerrors.Errorf("unknown collection %v", c)
+		}
+		if err != nil {
+			return xerrors.Errorf("failed to generate collection: %w", err)
+		}
+	}
+
+	out := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(out, "// Code generated by github.com/filecoin-project/specs-actors/gen/adtgen. DO NOT EDIT.\n\n")
+	_, _ = fmt.Fprintf(out, "package %s\n\nimport (\n", pkg)
+	var paths []string
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if imports[path] == "" {
+			_, _ = fmt.Fprintf(out, "\t%q\n", path)
+		} else {
+			_, _ = fmt.Fprintf(out, "\t%s %q\n", imports[path], path)
+		}
+	}
+	_, _ = fmt.Fprintf(out, ")\n")
+	out.Write(body.Bytes())
+
+	data, err := format.Source(out.Bytes())
+	if err != nil {
+		return xerrors.Errorf("failed to format generated source: %w", err)
+	}
+	return ioutil.WriteFile(fname, data, 0644)
+}
+
+type collectionInfo struct {
+	Name  string
+	Index string // Type expression for array indices.
+	Key   string // Type expression for map keys.
+	Value string // Type expression for values.
+
+	KeyKind string // "address", "int" or "uint".
+	KeyPkg  string // The package qualifier for address keys.
+	Adt     string // The package qualifier for adt, including the dot.
+}
+
+func writeArray(w *bytes.Buffer, pkg string, imports map[string]string, a Array) error {
+	var index interface{} = uint64(0)
+	if a.Index != nil {
+		index = a.Index
+	}
+	indexType := reflect.TypeOf(index)
+	if indexType.Kind() != reflect.Uint64 {
+		return xerrors.Errorf("array %s index type %v is not an unsigned integer", a.Name, indexType)
+	}
+	info := collectionInfo{
+		Name:  a.Name,
+		Index: typeName(pkg, imports, indexType),
+		Value: typeName(pkg, imports, reflect.TypeOf(a.Value)),
+		Adt:   adtQualifier(pkg, imports),
+	}
+	return arrayTemplate.Execute(w, info)
+}
+
+func writeMap(w *bytes.Buffer, pkg string, imports map[string]string, m Map) error {
+	keyType := reflect.TypeOf(m.Key)
+	info := collectionInfo{
+		Name:  m.Name,
+		Key:   typeName(pkg, imports, keyType),
+		Value: typeName(pkg, imports, reflect.TypeOf(m.Value)),
+		Adt:   adtQualifier(pkg, imports),
+	}
+	switch {
+	case keyType.PkgPath() == "github.com/filecoin-project/go-address" && keyType.Name() == "Address":
+		info.KeyKind = "address"
+		info.KeyPkg = imports[keyType.PkgPath()]
+	case keyType.Kind() == reflect.Int64:
+		info.KeyKind = "int"
+	case keyType.Kind() == reflect.Uint64:
+		info.KeyKind = "uint"
+	default:
+		return xerrors.Errorf("map %s key type %v is not an address or integer", m.Name, keyType)
+	}
+	return mapTemplate.Execute(w, info)
+}
+
+// Returns the expression naming a type from package `pkg`, recording any import it requires.
+func typeName(pkg string, imports map[string]string, t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + typeName(pkg, imports, t.Elem())
+	}
+	name := t.String()
+	if t.PkgPath() == "" || strings.HasPrefix(name, pkg+".") {
+		return strings.TrimPrefix(name, pkg+".")
+	}
+	imports[t.PkgPath()] = strings.SplitN(name, ".", 2)[0]
+	return name
+}
+
+func adtQualifier(pkg string, imports map[string]string) string {
+	if pkg == "adt" {
+		delete(imports, adtPkgPath)
+		return ""
+	}
+	return "adt."
+}
+
+// Unmarshals the values before and after a change, within a loop over changes c into out[i].
+const unmarshalChangeTemplate = `
+{{- define "unmarshalChange"}}
+		if c.Before != nil {
+			out[i].Before = new({{.Value}})
+			if err := out[i].Before.UnmarshalCBOR(bytes.NewReader(c.Before.Raw)); err != nil {
+				return nil, err
+			}
+		}
+		if c.After != nil {
+			out[i].After = new({{.Value}})
+			if err := out[i].After.UnmarshalCBOR(bytes.NewReader(c.After.Raw)); err != nil {
+				return nil, err
+			}
+		}
+{{- end}}`
+
+var arrayTemplate = template.Must(template.New("array").Parse(unmarshalChangeTemplate + `
+// {{.Name}} is an AMT of {{.Value}}, indexed by {{.Index}}.
+type {{.Name}} struct {
+	array *{{.Adt}}Array
+}
+
+// Interprets a store as an AMT-based array of {{.Value}} with root ` + "`r`" + `.
+func As{{.Name}}(s {{.Adt}}Store, r cid.Cid) (*{{.Name}}, error) {
+	a, err := {{.Adt}}AsArray(s, r)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Name}}{a}, nil
+}
+
+// Creates a new array of {{.Value}} backed by an empty AMT.
+func MakeEmpty{{.Name}}(s {{.Adt}}Store) *{{.Name}} {
+	return &{{.Name}}{ {{.Adt}}MakeEmptyArray(s)}
+}
+
+// Returns the root CID of the underlying AMT.
+func (a *{{.Name}}) Root() (cid.Cid, error) {
+	return a.array.Root()
+}
+
+// Returns the number of entries in the array.
+func (a *{{.Name}}) Length() uint64 {
+	return a.array.Length()
+}
+
+// Retrieves the value at an index, returning whether it was found.
+func (a *{{.Name}}) Get(i {{.Index}}) (*{{.Value}}, bool, error) {
+	var out {{.Value}}
+	found, err := a.array.Get(uint64(i), &out)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &out, true, nil
+}
+
+// Stores a value at an index.
+func (a *{{.Name}}) Set(i {{.Index}}, value *{{.Value}}) error {
+	return a.array.Set(uint64(i), value)
+}
+
+// Removes the value at an index.
+func (a *{{.Name}}) Delete(i {{.Index}}) error {
+	return a.array.Delete(uint64(i))
+}
+
+// Iterates all entries in index order, calling a function with each index and (a copy of) its value.
+// Iteration halts if the function returns an error.
+func (a *{{.Name}}) ForEach(fn func(i {{.Index}}, value *{{.Value}}) error) error {
+	var value {{.Value}}
+	return a.array.ForEach(&value, func(i int64) error {
+		cpy := value
+		return fn({{.Index}}(i), &cpy)
+	})
+}
+
+// A change to an entry of a {{.Name}} between two versions.
+// Before is nil for an added entry, and After is nil for a removed one.
+type {{.Name}}Change struct {
+	Type   {{.Adt}}ChangeType
+	Index  {{.Index}}
+	Before *{{.Value}}
+	After  *{{.Value}}
+}
+
+// Computes the entries added, modified and removed between two versions of a {{.Name}}, in index order.
+func Diff{{.Name}}(s {{.Adt}}Store, before, after cid.Cid) ([]{{.Name}}Change, error) {
+	changes, err := {{.Adt}}DiffArrays(s, before, after)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]{{.Name}}Change, len(changes))
+	for i, c := range changes {
+		out[i] = {{.Name}}Change{Type: c.Type, Index: {{.Index}}(c.Index)}
+		{{- template "unmarshalChange" .}}
+	}
+	return out, nil
+}
+`))
+
+var mapTemplate = template.Must(template.New("map").Parse(unmarshalChangeTemplate + `
+// {{.Name}} is a HAMT of {{.Value}}, keyed by {{.Key}}.
+type {{.Name}} struct {
+	m *{{.Adt}}Map
+}
+
+// Interprets a store as a HAMT-based map of {{.Value}} with root ` + "`r`" + `.
+func As{{.Name}}(s {{.Adt}}Store, r cid.Cid) (*{{.Name}}, error) {
+	m, err := {{.Adt}}AsMap(s, r)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Name}}{m}, nil
+}
+
+// Creates a new map of {{.Value}} backed by an empty HAMT.
+func MakeEmpty{{.Name}}(s {{.Adt}}Store) *{{.Name}} {
+	return &{{.Name}}{ {{.Adt}}MakeEmptyMap(s)}
+}
+
+// Returns the root CID of the underlying HAMT.
+func (m *{{.Name}}) Root() (cid.Cid, error) {
+	return m.m.Root()
+}
+
+// Retrieves the value for a key, returning whether it was found.
+func (m *{{.Name}}) Get(k {{.Key}}) (*{{.Value}}, bool, error) {
+	var out {{.Value}}
+	found, err := m.m.Get({{template "key" .}}, &out)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &out, true, nil
+}
+
+// Stores a value for a key.
+func (m *{{.Name}}) Put(k {{.Key}}, value *{{.Value}}) error {
+	return m.m.Put({{template "key" .}}, value)
+}
+
+// Removes the value for a key.
+func (m *{{.Name}}) Delete(k {{.Key}}) error {
+	return m.m.Delete({{template "key" .}})
+}
+
+// Iterates all entries, calling a function with each key and (a copy of) its value.
+// Iteration halts if the function returns an error.
+func (m *{{.Name}}) ForEach(fn func(k {{.Key}}, value *{{.Value}}) error) error {
+	var value {{.Value}}
+	return m.m.ForEach(&value, func(key string) error {
+		{{- if eq .KeyKind "address"}}
+		k, err := {{.KeyPkg}}.NewFromBytes([]byte(key))
+		{{- else if eq .KeyKind "int"}}
+		k, err := {{.Adt}}ParseIntKey(key)
+		{{- else}}
+		k, err := {{.Adt}}ParseUIntKey(key)
+		{{- end}}
+		if err != nil {
+			return err
+		}
+		cpy := value
+		return fn({{if eq .KeyKind "address"}}k{{else}}{{.Key}}(k){{end}}, &cpy)
+	})
+}
+
+// A change to an entry of a {{.Name}} between two versions.
+// Before is nil for an added entry, and After is nil for a removed one.
+type {{.Name}}Change struct {
+	Type   {{.Adt}}ChangeType
+	Key    {{.Key}}
+	Before *{{.Value}}
+	After  *{{.Value}}
+}
+
+// Computes the entries added, modified and removed between two versions of a {{.Name}}.
+func Diff{{.Name}}(s {{.Adt}}Store, before, after cid.Cid) ([]{{.Name}}Change, error) {
+	changes, err := {{.Adt}}DiffMaps(s, before, after)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]{{.Name}}Change, len(changes))
+	for i, c := range changes {
+		{{- if eq .KeyKind "address"}}
+		k, err := {{.KeyPkg}}.NewFromBytes([]byte(c.Key))
+		{{- else if eq .KeyKind "int"}}
+		k, err := {{.Adt}}ParseIntKey(c.Key)
+		{{- else}}
+		k, err := {{.Adt}}ParseUIntKey(c.Key)
+		{{- end}}
+		if err != nil {
+			return nil, err
+		}
+		out[i] = {{.Name}}Change{Type: c.Type, Key: {{if eq .KeyKind "address"}}k{{else}}{{.Key}}(k){{end}}}
+		{{- template "unmarshalChange" .}}
+	}
+	return out, nil
+}
+
+{{- define "key" -}}
+{{- if eq .KeyKind "address" -}}
+{{.Adt}}AddrKey(k)
+{{- else if eq .KeyKind "int" -}}
+{{.Adt}}IntKey(int64(k))
+{{- else -}}
+{{.Adt}}UIntKey(uint64(k))
+{{- end -}}
+{{- end -}}
+`))
@@ -0,0 +1,59 @@
+package adtgen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/gen/adtgen"
+)
+
+type value struct{}
+
+func TestWriteCollectionsToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "adtgen")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	fname := filepath.Join(dir, "collections_gen.go")
+
+	t.Run("typed wrappers", func(t *testing.T) {
+		require.NoError(t, adtgen.WriteCollectionsToFile(fname, "adtgen_test",
+			adtgen.Array{Name: "ValueArray", Index: abi.SectorNumber(0), Value: value{}},
+			adtgen.Map{Name: "ValueByAddrMap", Key: addr.Address{}, Value: value{}},
+			adtgen.Map{Name: "ValueByEpochMap", Key: abi.ChainEpoch(0), Value: value{}},
+		))
+		data, err := ioutil.ReadFile(fname)
+		require.NoError(t, err)
+		src := string(data)
+
+		assert.Contains(t, src, `abi "github.com/filecoin-project/specs-actors/actors/abi"`)
+		assert.Contains(t, src, `address "github.com/filecoin-project/go-address"`)
+		assert.Contains(t, src, "func (a *ValueArray) Get(i abi.SectorNumber) (*value, bool, error)")
+		assert.Contains(t, src, "func (m *ValueByAddrMap) Put(k address.Address, value *value) error")
+		assert.Contains(t, src, "m.m.Put(adt.AddrKey(k), value)")
+		assert.Contains(t, src, "m.m.Delete(adt.IntKey(int64(k)))")
+		assert.Contains(t, src, "k, err := adt.ParseIntKey(key)")
+		assert.Contains(t, src, "func DiffValueArray(s adt.Store, before, after cid.Cid) ([]ValueArrayChange, error)")
+		assert.Contains(t, src, "k, err := address.NewFromBytes([]byte(c.Key))")
+	})
+
+	t.Run("unsupported key type", func(t *testing.T) {
+		err := adtgen.WriteCollectionsToFile(fname, "adtgen_test",
+			adtgen.Map{Name: "ValueByNameMap", Key: "", Value: value{}},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("signed array index", func(t *testing.T) {
+		err := adtgen.WriteCollectionsToFile(fname, "adtgen_test",
+			adtgen.Array{Name: "ValueArray", Index: abi.ChainEpoch(0), Value: value{}},
+		)
+		assert.Error(t, err)
+	})
+}
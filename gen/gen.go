@@ -1,6 +1,7 @@
 package main
 
 import (
+	addr "github.com/filecoin-project/go-address"
 	gen "github.com/whyrusleeping/cbor-gen"
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
@@ -16,6 +17,8 @@ import (
 	reward "github.com/filecoin-project/specs-actors/actors/builtin/reward"
 	system "github.com/filecoin-project/specs-actors/actors/builtin/system"
 	verifreg "github.com/filecoin-project/specs-actors/actors/builtin/verifreg"
+	adtgen "github.com/filecoin-project/specs-actors/gen/adtgen"
+	vm "github.com/filecoin-project/specs-actors/support/vm"
 )
 
 func main() {
@@ -97,6 +100,8 @@ func main() {
 		multisig.TxnIDParams{},
 		multisig.ChangeNumApprovalsThresholdParams{},
 		multisig.SwapSignerParams{},
+		// events
+		multisig.TxnExecuted{},
 	); err != nil {
 		panic(err)
 	}
@@ -112,6 +117,8 @@ func main() {
 		paych.SignedVoucher{},
 		paych.ModVerifyParams{},
 		paych.PaymentVerifyParams{},
+		// events
+		paych.VoucherRedeemed{},
 	); err != nil {
 		panic(err)
 	}
@@ -130,11 +137,16 @@ func main() {
 		power.OnSectorProveCommitParams{},
 		power.OnFaultBeginParams{},
 		power.OnFaultEndParams{},
+		power.BatchOnSectorProveCommitParams{},
+		power.BatchOnSectorModifyWeightDescParams{},
 		// method returns
 		power.CreateMinerReturn{},
+		power.BatchOnSectorProveCommitReturn{},
+		power.BatchOnSectorModifyWeightDescReturn{},
 		// other types
 		power.MinerConstructorParams{},
 		power.SectorStorageWeightDesc{},
+		power.SectorPledge{},
 	); err != nil {
 		panic(err)
 	}
@@ -148,7 +160,15 @@ func main() {
 		market.PublishStorageDealsParams{},
 		market.VerifyDealsOnSectorProveCommitParams{},
 		market.VerifyDealsOnSectorProveCommitReturn{},
+		market.SectorDeals{},
+		market.BatchVerifyDealsOnSectorProveCommitParams{},
+		market.SectorDealWeights{},
+		market.BatchVerifyDealsOnSectorProveCommitReturn{},
+		market.VerifyDealsOnSectorPreCommitParams{},
 		market.ComputeDataCommitmentParams{},
+		market.BatchComputeDataCommitmentParams{},
+		market.SectorDataCommitment{},
+		market.BatchComputeDataCommitmentReturn{},
 		market.OnMinerSectorsTerminateParams{},
 		market.HandleExpiredDealsParams{},
 		market.HandleInitTimeoutDealsParams{},
@@ -158,6 +178,8 @@ func main() {
 		market.DealProposal{},
 		market.ClientDealProposal{},
 		market.DealState{},
+		// events
+		market.DealPublished{},
 	); err != nil {
 		panic(err)
 	}
@@ -166,29 +188,58 @@ func main() {
 		// actor state
 		miner.State{},
 		miner.MinerInfo{},
-		miner.Deadlines{},
+		miner.Deadline{},
+		miner.Partition{},
+		miner.PowerPair{},
 		miner.SectorPreCommitOnChainInfo{},
 		miner.SectorPreCommitInfo{},
 		miner.SectorOnChainInfo{},
 		miner.WorkerKeyChange{},
+		miner.OwnerChange{},
+		miner.PenaltyRecord{},
+		miner.PenaltyRecords{},
+		miner.ConsensusFaultRecord{},
+		miner.WindowedPoStSnapshot{},
+		miner.WindowedPoStSnapshots{},
+		miner.SectorLocation{},
 		// method params
 		// miner.ConstructorParams{},
 		miner.SubmitWindowedPoStParams{},
 		miner.TerminateSectorsParams{},
 		miner.ChangePeerIDParams{},
+		miner.ChangeMultiaddrsParams{},
+		miner.WithdrawPreCommitsParams{},
+		miner.MaskSectorNumbersParams{},
 		miner.ProveCommitSectorParams{},
+		miner.ProveCommitSectorsParams{},
+		miner.PreCommitSectorBatchParams{},
+		miner.DisputeWindowedPoStParams{},
 		miner.ChangeWorkerAddressParams{},
+		miner.ChangeOwnerAddressParams{},
+		miner.ChangeControlAddressesParams{},
 		miner.ExtendSectorExpirationParams{},
+		miner.ExtendSectorExpirationsParams{},
 		miner.DeclareFaultsParams{},
 		miner.DeclareFaultsRecoveredParams{},
 		miner.ReportConsensusFaultParams{},
 		miner.GetControlAddressesReturn{},
+		miner.GetSectorStatusParams{},
+		miner.GetDeadlineSummaryReturn{},
 		miner.CheckSectorProvenParams{},
 		miner.WithdrawBalanceParams{},
 		// other types
 		miner.CronEventPayload{},
 		miner.FaultDeclaration{},
 		miner.RecoveryDeclaration{},
+		miner.ExpirationExtension{},
+		miner.SectorStatus{},
+		miner.DeadlineInfo{},
+		miner.PartitionSummary{},
+		// events
+		miner.SectorProven{},
+		miner.FaultDetected{},
+		miner.PoStDisputed{},
+		miner.PreCommitsWithdrawn{},
 	); err != nil {
 		panic(err)
 	}
@@ -206,4 +257,47 @@ func main() {
 		panic(err)
 	}
 
+	// Support
+	if err := gen.WriteTupleEncodersToFile("./support/vm/cbor_gen.go", "vm",
+		vm.Actor{},
+	); err != nil {
+		panic(err)
+	}
+
+	// Typed collections
+	if err := adtgen.WriteCollectionsToFile("./actors/builtin/multisig/collections_gen.go", "multisig",
+		adtgen.Map{Name: "TransactionMap", Key: multisig.TxnID(0), Value: multisig.Transaction{}},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := adtgen.WriteCollectionsToFile("./actors/builtin/power/collections_gen.go", "power",
+		adtgen.Map{Name: "ClaimMap", Key: addr.Address{}, Value: power.Claim{}},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := adtgen.WriteCollectionsToFile("./actors/builtin/market/collections_gen.go", "market",
+		adtgen.Array{Name: "DealProposalArray", Index: abi.DealID(0), Value: market.DealProposal{}},
+		adtgen.Array{Name: "DealStateArray", Index: abi.DealID(0), Value: market.DealState{}},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := adtgen.WriteCollectionsToFile("./actors/builtin/miner/collections_gen.go", "miner",
+		adtgen.Array{Name: "SectorOnChainInfoArray", Index: abi.SectorNumber(0), Value: miner.SectorOnChainInfo{}},
+		adtgen.Array{Name: "DeadlineArray", Value: miner.Deadline{}},
+		adtgen.Array{Name: "PartitionArray", Value: miner.Partition{}},
+		adtgen.Array{Name: "SectorLocationArray", Index: abi.SectorNumber(0), Value: miner.SectorLocation{}},
+		adtgen.Map{Name: "SectorPreCommitOnChainInfoMap", Key: abi.SectorNumber(0), Value: miner.SectorPreCommitOnChainInfo{}},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := adtgen.WriteCollectionsToFile("./actors/builtin/verifreg/collections_gen.go", "verifreg",
+		adtgen.Map{Name: "DataCapMap", Key: addr.Address{}, Value: verifreg.DataCap{}},
+	); err != nil {
+		panic(err)
+	}
+
 }
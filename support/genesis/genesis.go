@@ -0,0 +1,370 @@
+package genesis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
+	cron "github.com/filecoin-project/specs-actors/actors/builtin/cron"
+	init_ "github.com/filecoin-project/specs-actors/actors/builtin/init"
+	miner "github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	multisig "github.com/filecoin-project/specs-actors/actors/builtin/multisig"
+	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
+	runtime "github.com/filecoin-project/specs-actors/actors/runtime"
+	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
+	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
+	vm "github.com/filecoin-project/specs-actors/support/vm"
+)
+
+// Spec declares the initial state of a network.
+type Spec struct {
+	NetworkName string
+
+	// Balance of the reward actor, from which block rewards are paid.
+	RewardBalance abi.TokenAmount
+
+	// Root key holder of the verified registry. Must be the address of one of the accounts or multisigs.
+	VerifiedRegistryRootKey addr.Address
+
+	// Entries for the cron actor. If empty, DefaultCronEntries are used.
+	CronEntries []cron.Entry
+
+	Accounts  []Account
+	Multisigs []Multisig
+	Miners    []Miner
+}
+
+type Account struct {
+	Address addr.Address // A BLS or SECP address.
+	Balance abi.TokenAmount
+}
+
+type Multisig struct {
+	Signers               []addr.Address // Addresses of accounts, which will be created if not otherwise specified.
+	NumApprovalsThreshold int64
+	UnlockDuration        abi.ChainEpoch // Duration over which the balance vests, or zero for no vesting.
+	Balance               abi.TokenAmount
+}
+
+type Miner struct {
+	Owner      addr.Address // Address of an account, which will be created if not otherwise specified.
+	Worker     addr.Address // Address of an account, which will be created if not otherwise specified.
+	SectorSize abi.SectorSize
+	PeerId     string
+	Balance    abi.TokenAmount
+	Sectors    []PreSealedSector
+}
+
+// A sector sealed prior to genesis, which is active (and has power) from the first epoch.
+// Pre-sealed sectors carry no deals.
+type PreSealedSector struct {
+	SectorNumber    abi.SectorNumber
+	RegisteredProof abi.RegisteredProof
+	SealedCID       cid.Cid
+	Expiration      abi.ChainEpoch
+}
+
+// The cron entries of a network that doesn't specify any.
+func DefaultCronEntries() []cron.Entry {
+	return []cron.Entry{{
+		Receiver:  builtin.StoragePowerActorAddr,
+		MethodNum: builtin.MethodsPower.OnEpochTickEnd,
+	}}
+}
+
+// Parses a JSON-encoded spec.
+func LoadSpec(r io.Reader) (*Spec, error) {
+	var spec Spec
+	if err := json.NewDecoder(r).Decode(&spec); err != nil {
+		return nil, errors.Wrap(err, "failed to decode genesis spec")
+	}
+	return &spec, nil
+}
+
+// Builds the genesis state tree for a spec and returns its root.
+func MakeGenesisState(ctx context.Context, store adt.Store, spec *Spec) (cid.Cid, error) {
+	v, err := MakeGenesisVM(ctx, store, spec)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return v.StateRoot()
+}
+
+// Builds the genesis state tree for a spec, returning a VM at epoch zero from which messages may be applied.
+//
+// Each actor is created by invoking its real constructor with a message from the system actor (or, for miners,
+// from the owner via the power actor), so the result matches what the constructors actually do.
+// The total token allocation is initially held by the system actor, and transferred to accounts, multisigs and
+// miners by messages.
+func MakeGenesisVM(ctx context.Context, store adt.Store, spec *Spec) (*vm.VM, error) {
+	b := builder{vm: vm.NewVM(ctx, store)}
+
+	cronEntries := spec.CronEntries
+	if len(cronEntries) == 0 {
+		cronEntries = DefaultCronEntries()
+	}
+
+	allocation := big.Zero()
+	for _, a := range spec.Accounts {
+		allocation = big.Add(allocation, balanceOrZero(a.Balance))
+	}
+	for _, m := range spec.Multisigs {
+		allocation = big.Add(allocation, balanceOrZero(m.Balance))
+	}
+	for _, m := range spec.Miners {
+		allocation = big.Add(allocation, balanceOrZero(m.Balance))
+	}
+
+	// Singletons. The verified registry is constructed later, since its root key may be an account or multisig.
+	if err := b.installSingleton(builtin.SystemActorAddr, builtin.SystemActorCodeID, nil, allocation); err != nil {
+		return nil, err
+	}
+	if err := b.installSingleton(builtin.InitActorAddr, builtin.InitActorCodeID, &init_.ConstructorParams{NetworkName: spec.NetworkName}, big.Zero()); err != nil {
+		return nil, err
+	}
+	if err := b.installSingleton(builtin.RewardActorAddr, builtin.RewardActorCodeID, nil, balanceOrZero(spec.RewardBalance)); err != nil {
+		return nil, err
+	}
+	if err := b.installSingleton(builtin.CronActorAddr, builtin.CronActorCodeID, &cron.ConstructorParams{Entries: cronEntries}, big.Zero()); err != nil {
+		return nil, err
+	}
+	if err := b.installSingleton(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID, nil, big.Zero()); err != nil {
+		return nil, err
+	}
+	if err := b.installSingleton(builtin.StorageMarketActorAddr, builtin.StorageMarketActorCodeID, nil, big.Zero()); err != nil {
+		return nil, err
+	}
+	// The burnt funds actor only receives value, so has no state.
+	if err := b.vm.SetActor(builtin.BurntFundsActorAddr, &vm.Actor{
+		Code:    builtin.AccountActorCodeID,
+		Head:    b.vm.EmptyObject(),
+		Balance: big.Zero(),
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, a := range spec.Accounts {
+		if _, err := b.createAccount(a.Address, balanceOrZero(a.Balance)); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range spec.Multisigs {
+		if err := b.createMultisig(&m); err != nil {
+			return nil, err
+		}
+	}
+
+	rootKey, ok := b.vm.NormalizeAddress(spec.VerifiedRegistryRootKey)
+	if !ok {
+		return nil, fmt.Errorf("verified registry root key %v is not an account or multisig", spec.VerifiedRegistryRootKey)
+	}
+	if err := b.installSingleton(builtin.VerifiedRegistryActorAddr, builtin.VerifiedRegistryActorCodeID, &rootKey, big.Zero()); err != nil {
+		return nil, err
+	}
+
+	for _, m := range spec.Miners {
+		if err := b.createMiner(&m); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.vm, nil
+}
+
+type builder struct {
+	vm *vm.VM
+}
+
+// Installs a singleton actor with empty state and invokes its constructor.
+func (b *builder) installSingleton(a addr.Address, code cid.Cid, params runtime.CBORMarshaler, balance abi.TokenAmount) error {
+	if err := b.vm.SetActor(a, &vm.Actor{
+		Code:    code,
+		Head:    b.vm.EmptyObject(),
+		Balance: balance,
+	}); err != nil {
+		return err
+	}
+	_, exit := b.vm.ApplyMessage(builtin.SystemActorAddr, a, big.Zero(), builtin.MethodConstructor, params)
+	if exit != exitcode.Ok {
+		return fmt.Errorf("failed to construct %s at %v: exit code %v", builtin.ActorNameByCode(code), a, exit)
+	}
+	return nil
+}
+
+// Creates an account actor for a pubkey address, if it doesn't already exist, by sending it a balance.
+// Returns the account's ID address.
+func (b *builder) createAccount(key addr.Address, balance abi.TokenAmount) (addr.Address, error) {
+	if key.Protocol() == addr.ID {
+		if _, found, err := b.vm.GetActor(key); err != nil {
+			return addr.Undef, err
+		} else if !found {
+			return addr.Undef, fmt.Errorf("no actor at %v", key)
+		}
+		return key, nil
+	}
+	if key.Protocol() != addr.BLS && key.Protocol() != addr.SECP256K1 {
+		return addr.Undef, fmt.Errorf("account address %v must be an ID, BLS or SECP address", key)
+	}
+
+	if _, code := b.vm.ApplyMessage(builtin.SystemActorAddr, key, balance, builtin.MethodSend, nil); code != exitcode.Ok {
+		return addr.Undef, fmt.Errorf("failed to create account %v: exit code %v", key, code)
+	}
+	idAddr, ok := b.vm.NormalizeAddress(key)
+	if !ok {
+		return addr.Undef, fmt.Errorf("account %v not created", key)
+	}
+	return idAddr, nil
+}
+
+// Creates a multisig actor through the init actor, transferring its balance in the same message.
+func (b *builder) createMultisig(spec *Multisig) error {
+	signers := make([]addr.Address, len(spec.Signers))
+	for i, s := range spec.Signers {
+		idAddr, err := b.createAccount(s, big.Zero())
+		if err != nil {
+			return errors.Wrapf(err, "failed to create multisig signer")
+		}
+		signers[i] = idAddr
+	}
+
+	ctorParams, err := serialize(&multisig.ConstructorParams{
+		Signers:               signers,
+		NumApprovalsThreshold: spec.NumApprovalsThreshold,
+		UnlockDuration:        spec.UnlockDuration,
+	})
+	if err != nil {
+		return err
+	}
+	_, code := b.vm.ApplyMessage(builtin.SystemActorAddr, builtin.InitActorAddr, balanceOrZero(spec.Balance), builtin.MethodsInit.Exec, &init_.ExecParams{
+		CodeCID:           builtin.MultisigActorCodeID,
+		ConstructorParams: ctorParams,
+	})
+	if code != exitcode.Ok {
+		return fmt.Errorf("failed to create multisig with signers %v: exit code %v", spec.Signers, code)
+	}
+	return nil
+}
+
+// Creates a miner through the power actor, then activates its pre-sealed sectors.
+func (b *builder) createMiner(spec *Miner) error {
+	owner, err := b.createAccount(spec.Owner, big.Zero())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create miner owner")
+	}
+	worker, err := b.createAccount(spec.Worker, big.Zero())
+	if err != nil {
+		return errors.Wrapf(err, "failed to create miner worker")
+	}
+
+	ret, code := b.vm.ApplyMessage(owner, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.CreateMiner, &power.CreateMinerParams{
+		Owner:      owner,
+		Worker:     worker,
+		SectorSize: spec.SectorSize,
+		Peer:       peer.ID(spec.PeerId),
+	})
+	if code != exitcode.Ok {
+		return fmt.Errorf("failed to create miner with owner %v: exit code %v", spec.Owner, code)
+	}
+	minerAddr := ret.(*power.CreateMinerReturn).IDAddress
+
+	if balance := balanceOrZero(spec.Balance); !balance.IsZero() {
+		if _, code := b.vm.ApplyMessage(builtin.SystemActorAddr, minerAddr, balance, builtin.MethodSend, nil); code != exitcode.Ok {
+			return fmt.Errorf("failed to fund miner %v: exit code %v", minerAddr, code)
+		}
+	}
+
+	return b.activatePreSealedSectors(minerAddr, spec.SectorSize, spec.Sectors)
+}
+
+// Writes pre-sealed sectors directly into the miner's state and claims their power.
+// There is no message flow for this: proving a sector at genesis would require randomness and a sealing delay.
+func (b *builder) activatePreSealedSectors(minerAddr addr.Address, sectorSize abi.SectorSize, sectors []PreSealedSector) error {
+	if len(sectors) == 0 {
+		return nil
+	}
+	store := b.vm.Store()
+	currEpoch := b.vm.GetEpoch()
+
+	var minerSt miner.State
+	if err := b.vm.GetState(minerAddr, &minerSt); err != nil {
+		return err
+	}
+	rawPower := big.Zero()
+	qaPower := big.Zero()
+	for _, s := range sectors {
+		if s.Expiration <= currEpoch {
+			return fmt.Errorf("pre-sealed sector %d expiration %d must be after genesis", s.SectorNumber, s.Expiration)
+		}
+		info := &miner.SectorOnChainInfo{
+			Info: miner.SectorPreCommitInfo{
+				RegisteredProof: s.RegisteredProof,
+				SectorNumber:    s.SectorNumber,
+				SealedCID:       s.SealedCID,
+				SealRandEpoch:   currEpoch,
+				DealIDs:         nil,
+				Expiration:      s.Expiration,
+			},
+			ActivationEpoch:       currEpoch,
+			DealWeight:            big.Zero(),
+			VerifiedDealWeight:    big.Zero(),
+			InitialPledge:         big.Zero(),
+			ExpectedDayReward:     big.Zero(),
+			ExpectedStoragePledge: big.Zero(),
+		}
+		if err := minerSt.AllocateSectorNumber(s.SectorNumber); err != nil {
+			return err
+		}
+		if err := minerSt.PutSector(store, info); err != nil {
+			return err
+		}
+		if err := minerSt.AddNewSectors(s.SectorNumber); err != nil {
+			return err
+		}
+
+		rawPower = big.Add(rawPower, big.NewIntUnsigned(uint64(sectorSize)))
+		qaPower = big.Add(qaPower, power.QAPowerForWeight(&power.SectorStorageWeightDesc{
+			SectorSize:         sectorSize,
+			Duration:           s.Expiration - currEpoch,
+			DealWeight:         big.Zero(),
+			VerifiedDealWeight: big.Zero(),
+		}))
+	}
+	if err := b.vm.SetState(minerAddr, &minerSt); err != nil {
+		return err
+	}
+
+	var powerSt power.State
+	if err := b.vm.GetState(builtin.StoragePowerActorAddr, &powerSt); err != nil {
+		return err
+	}
+	if err := powerSt.AddToClaim(store, minerAddr, rawPower, qaPower); err != nil {
+		return err
+	}
+	return b.vm.SetState(builtin.StoragePowerActorAddr, &powerSt)
+}
+
+// Treats an unspecified (nil) balance as zero.
+func balanceOrZero(amount abi.TokenAmount) abi.TokenAmount {
+	if amount.Nil() {
+		return big.Zero()
+	}
+	return amount
+}
+
+func serialize(o runtime.CBORMarshaler) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := o.MarshalCBOR(&buf); err != nil {
+		return nil, errors.Wrapf(err, "failed to serialize %v", o)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,142 @@
+package genesis_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/abi/big"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	"github.com/filecoin-project/specs-actors/actors/builtin/cron"
+	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/actors/builtin/multisig"
+	"github.com/filecoin-project/specs-actors/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/actors/builtin/verifreg"
+	"github.com/filecoin-project/specs-actors/support/genesis"
+	"github.com/filecoin-project/specs-actors/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/support/testing"
+)
+
+func TestMakeGenesis(t *testing.T) {
+	ctx := context.Background()
+	alice := tutil.NewBLSAddr(t, 1)
+	bob := tutil.NewSECP256K1Addr(t, "bob")
+	worker := tutil.NewBLSAddr(t, 2)
+
+	spec := &genesis.Spec{
+		NetworkName:             "testnet",
+		RewardBalance:           abi.NewTokenAmount(1_000_000),
+		VerifiedRegistryRootKey: alice,
+		Accounts: []genesis.Account{
+			{Address: alice, Balance: abi.NewTokenAmount(100)},
+			{Address: bob, Balance: abi.NewTokenAmount(200)},
+		},
+		Multisigs: []genesis.Multisig{{
+			Signers:               []addr.Address{alice, bob},
+			NumApprovalsThreshold: 2,
+			UnlockDuration:        1000,
+			Balance:               abi.NewTokenAmount(300),
+		}},
+		Miners: []genesis.Miner{{
+			Owner:      alice,
+			Worker:     worker,
+			SectorSize: abi.SectorSize(32 << 30),
+			PeerId:     "peer",
+			Balance:    abi.NewTokenAmount(400),
+			Sectors: []genesis.PreSealedSector{
+				{SectorNumber: 1, RegisteredProof: abi.RegisteredProof_StackedDRG32GiBSeal, SealedCID: tutil.MakeCID("1"), Expiration: 10_000},
+				{SectorNumber: 2, RegisteredProof: abi.RegisteredProof_StackedDRG32GiBSeal, SealedCID: tutil.MakeCID("2"), Expiration: 20_000},
+			},
+		}},
+	}
+
+	t.Run("actors constructed from spec", func(t *testing.T) {
+		v, err := genesis.MakeGenesisVM(ctx, ipld.NewADTStore(ctx), spec)
+		require.NoError(t, err)
+
+		// The allocation has been distributed.
+		system, found, err := v.GetActor(builtin.SystemActorAddr)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, big.Zero(), system.Balance)
+		reward, _, err := v.GetActor(builtin.RewardActorAddr)
+		require.NoError(t, err)
+		assert.Equal(t, abi.NewTokenAmount(1_000_000), reward.Balance)
+
+		aliceID, found := v.NormalizeAddress(alice)
+		require.True(t, found)
+		aliceActor, _, err := v.GetActor(aliceID)
+		require.NoError(t, err)
+		assert.Equal(t, builtin.AccountActorCodeID, aliceActor.Code)
+		assert.Equal(t, abi.NewTokenAmount(100), aliceActor.Balance)
+		bobID, found := v.NormalizeAddress(bob)
+		require.True(t, found)
+
+		var cronSt cron.State
+		require.NoError(t, v.GetState(builtin.CronActorAddr, &cronSt))
+		assert.Equal(t, genesis.DefaultCronEntries(), cronSt.Entries)
+
+		var verifregSt verifreg.State
+		require.NoError(t, v.GetState(builtin.VerifiedRegistryActorAddr, &verifregSt))
+		assert.Equal(t, aliceID, verifregSt.RootKey)
+
+		// The multisig and miner are allocated the next IDs after the accounts (and the miner worker).
+		msigAddr := tutil.NewIDAddr(t, builtin.FirstNonSingletonActorId+2)
+		var msigSt multisig.State
+		require.NoError(t, v.GetState(msigAddr, &msigSt))
+		assert.Equal(t, []addr.Address{aliceID, bobID}, msigSt.Signers)
+		assert.Equal(t, abi.NewTokenAmount(300), msigSt.InitialBalance)
+		assert.Equal(t, abi.ChainEpoch(1000), msigSt.UnlockDuration)
+
+		var powerSt power.State
+		require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+		assert.Equal(t, int64(1), powerSt.MinerCount)
+
+		minerAddr := tutil.NewIDAddr(t, builtin.FirstNonSingletonActorId+4)
+		minerActor, found, err := v.GetActor(minerAddr)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, builtin.StorageMinerActorCodeID, minerActor.Code)
+		assert.Equal(t, abi.NewTokenAmount(400), minerActor.Balance)
+
+		var minerSt miner.State
+		require.NoError(t, v.GetState(minerAddr, &minerSt))
+		assert.Equal(t, aliceID, minerSt.Info.Owner)
+		count, err := minerSt.GetSectorCount(v.Store())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), count)
+		sector, found, err := minerSt.GetSector(v.Store(), 2)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, tutil.MakeCID("2"), sector.Info.SealedCID)
+
+		assert.Empty(t, v.CheckStateInvariants())
+	})
+
+	t.Run("spec round trips through JSON to the same state", func(t *testing.T) {
+		expected, err := genesis.MakeGenesisState(ctx, ipld.NewADTStore(ctx), spec)
+		require.NoError(t, err)
+
+		encoded, err := json.Marshal(spec)
+		require.NoError(t, err)
+		loaded, err := genesis.LoadSpec(bytes.NewReader(encoded))
+		require.NoError(t, err)
+
+		actual, err := genesis.MakeGenesisState(ctx, ipld.NewADTStore(ctx), loaded)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("unknown root key", func(t *testing.T) {
+		badSpec := *spec
+		badSpec.VerifiedRegistryRootKey = tutil.NewBLSAddr(t, 99)
+		_, err := genesis.MakeGenesisState(ctx, ipld.NewADTStore(ctx), &badSpec)
+		assert.Error(t, err)
+	})
+}
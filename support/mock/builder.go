@@ -8,6 +8,7 @@ import (
 	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/runtime"
 )
 
 // Build for fluent initialization of a mock runtime.
@@ -34,6 +35,7 @@ func NewBuilder(ctx context.Context, receiver addr.Address) *RuntimeBuilder {
 
 		actorCodeCIDs: make(map[addr.Address]cid.Cid),
 		newActorAddr:  addr.Undef,
+		pricelist:     runtime.DefaultPricelist(),
 
 		t:                        nil, // Initialized at Build()
 		expectValidateCallerAny:  false,
@@ -92,6 +94,16 @@ func (b *RuntimeBuilder) WithVerifiesSig(f VerifyFunc) *RuntimeBuilder {
 	return b
 }
 
+func (b *RuntimeBuilder) WithPricelist(pl runtime.Pricelist) *RuntimeBuilder {
+	b.rt.SetPricelist(pl)
+	return b
+}
+
+func (b *RuntimeBuilder) WithInvariantChecks(stateType StateInvariantChecker) *RuntimeBuilder {
+	b.rt.CheckInvariantsAfterCall(stateType)
+	return b
+}
+
 func (b *RuntimeBuilder) WithHasher(f HasherFunc) *RuntimeBuilder {
 	b.rt.SetHasher(f)
 	return b
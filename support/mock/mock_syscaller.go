@@ -13,10 +13,18 @@ import (
 
 type VerifyFunc func(signature crypto.Signature, signer addr.Address, plaintext []byte) error
 type HasherFunc func(data []byte) [32]byte
+type UnsealedCIDComputeFunc func(reg abi.RegisteredProof, pieces []abi.PieceInfo) (cid.Cid, error)
+type BatchSealVerifyFunc func(vis []abi.SealVerifyInfo) ([]bool, error)
+type PoStVerifyFunc func(vi abi.WindowPoStVerifyInfo) error
+type ConsensusFaultVerifyFunc func(h1, h2, extra []byte) (*runtime.ConsensusFault, error)
 
 type syscaller struct {
 	SignatureVerifier VerifyFunc
 	Hasher            HasherFunc
+	UnsealedCIDs      UnsealedCIDComputeFunc
+	BatchSealVerifier BatchSealVerifyFunc
+	PoStVerifier      PoStVerifyFunc
+	FaultVerifier     ConsensusFaultVerifyFunc
 }
 
 // Interface methods
@@ -35,8 +43,10 @@ func (s *syscaller) HashBlake2b(data []byte) [32]byte {
 }
 
 func (s *syscaller) ComputeUnsealedSectorCID(reg abi.RegisteredProof, pieces []abi.PieceInfo) (cid.Cid, error) {
-	s.PanicOnUnsetFunc("UnsealedSectorCIDComputer")
-	return cid.Undef, nil
+	if s.UnsealedCIDs == nil {
+		s.PanicOnUnsetFunc("UnsealedSectorCIDComputer")
+	}
+	return s.UnsealedCIDs(reg, pieces)
 }
 
 func (s *syscaller) VerifySeal(vi abi.SealVerifyInfo) error {
@@ -44,15 +54,25 @@ func (s *syscaller) VerifySeal(vi abi.SealVerifyInfo) error {
 	return nil
 }
 
+func (s *syscaller) BatchVerifySeals(vis []abi.SealVerifyInfo) ([]bool, error) {
+	if s.BatchSealVerifier == nil {
+		s.PanicOnUnsetFunc("BatchSealVerifier")
+	}
+	return s.BatchSealVerifier(vis)
+}
+
 func (s *syscaller) VerifyPoSt(vi abi.WindowPoStVerifyInfo) error {
-	s.PanicOnUnsetFunc("PoStVerifier")
-	return nil
+	if s.PoStVerifier == nil {
+		s.PanicOnUnsetFunc("PoStVerifier")
+	}
+	return s.PoStVerifier(vi)
 }
 
 func (s *syscaller) VerifyConsensusFault(h1, h2, extra []byte) (*runtime.ConsensusFault, error) {
-
-	s.PanicOnUnsetFunc("ConsensusFaultVerifier")
-	return nil, nil
+	if s.FaultVerifier == nil {
+		s.PanicOnUnsetFunc("ConsensusFaultVerifier")
+	}
+	return s.FaultVerifier(h1, h2, extra)
 }
 
 func (s *syscaller) PanicOnUnsetFunc(unsetFuncName string) {
@@ -14,6 +14,7 @@ import (
 
 	abi "github.com/filecoin-project/specs-actors/actors/abi"
 	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
 	"github.com/filecoin-project/specs-actors/actors/crypto"
 	runtime "github.com/filecoin-project/specs-actors/actors/runtime"
 	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
@@ -36,7 +37,9 @@ type Runtime struct {
 	actorCodeCIDs map[addr.Address]cid.Cid
 	newActorAddr  addr.Address
 
-	syscalls syscaller
+	syscalls   syscaller
+	pricelist  runtime.Pricelist
+	circSupply *abi.TokenAmount // Total circulating supply, if set
 
 	// Actor state
 	state   cid.Cid
@@ -46,6 +49,12 @@ type Runtime struct {
 	inCall        bool
 	store         map[cid.Cid][]byte
 	inTransaction bool
+	gasLimit      int64           // Maximum gas that a single call may consume, or zero for no limit.
+	gasCharges    []GasCharge     // Gas charged during the current (or most recent) call.
+	events        []runtime.Event // Events emitted during the current (or most recent) call.
+
+	// The type of state whose invariants are checked after each call, or nil for no checks.
+	invariantStateType reflect.Type
 
 	// Expectations
 	t                        testing.TB
@@ -86,6 +95,18 @@ func (m *expectedMessage) String() string {
 	return fmt.Sprintf("to: %v method: %v value: %v params: %v sendReturn: %v exitCode: %v", m.to, m.method, m.value, m.params, m.sendReturn, m.exitCode)
 }
 
+// A charge of gas made to the runtime.
+type GasCharge struct {
+	Name   string
+	Amount int64
+}
+
+// A state object that can check its own invariants, such as the state of each builtin actor.
+type StateInvariantChecker interface {
+	runtime.CBORUnmarshaler
+	CheckStateInvariants(store adt.Store, balance abi.TokenAmount) []builtin.InvariantViolation
+}
+
 type expectCreateActor struct {
 	// Expected code CID.
 	codeId cid.Cid
@@ -230,6 +251,7 @@ func (rt *Runtime) Send(toAddr addr.Address, methodNum abi.MethodNum, params run
 		rt.failTestNow("unexpected send to: %v method: %v, value: %v, params: %v", toAddr, methodNum, value, params)
 	}
 	expectedMsg := rt.expectSends[0]
+	rt.ChargeGas("OnMethodInvocation", rt.pricelist.OnMethodInvocation(value, methodNum))
 
 	if !expectedMsg.Equal(toAddr, methodNum, params, value) {
 		rt.failTest("send does not match expectation.\n" +
@@ -242,9 +264,12 @@ func (rt *Runtime) Send(toAddr addr.Address, methodNum abi.MethodNum, params run
 	}
 
 	// pop the expectedMessage from the queue and modify the mockrt balance to reflect the send.
+	// A failed send transfers no value.
 	defer func() {
 		rt.expectSends = rt.expectSends[1:]
-		rt.balance = big.Sub(rt.balance, value)
+		if expectedMsg.exitCode.IsSuccess() {
+			rt.balance = big.Sub(rt.balance, value)
+		}
 	}()
 	return expectedMsg.sendReturn, expectedMsg.exitCode
 }
@@ -296,7 +321,33 @@ func (rt *Runtime) AbortStateMsg(msg string) {
 
 func (rt *Runtime) Syscalls() runtime.Syscalls {
 	rt.requireInCall()
-	return &rt.syscalls
+	return runtime.NewGasChargingSyscalls(rt, &rt.syscalls)
+}
+
+func (rt *Runtime) EmitEvent(event runtime.Event) {
+	rt.requireInCall()
+	var buf bytes.Buffer
+	if err := event.MarshalCBOR(&buf); err != nil {
+		rt.Abortf(exitcode.SysErrSerialization, "failed to marshal %s event: %v", event.EventType(), err)
+	}
+	rt.ChargeGas("OnEmitEvent", rt.pricelist.OnEmitEvent(buf.Len()))
+	rt.events = append(rt.events, event)
+}
+
+func (rt *Runtime) ChargeGas(name string, gas int64) {
+	// requireInCall omitted because it makes using this mock runtime as a store awkward.
+	// Gas is not recorded outside of a call.
+	if !rt.inCall {
+		return
+	}
+	rt.gasCharges = append(rt.gasCharges, GasCharge{name, gas})
+	if rt.gasLimit > 0 && rt.GasUsed() > rt.gasLimit {
+		rt.Abortf(exitcode.SysErrOutOfGas, "gas used %d exceeds limit %d after %s", rt.GasUsed(), rt.gasLimit, name)
+	}
+}
+
+func (rt *Runtime) Pricelist() runtime.Pricelist {
+	return rt.pricelist
 }
 
 func (rt *Runtime) Context() context.Context {
@@ -321,6 +372,7 @@ func (rt *Runtime) Get(c cid.Cid, o runtime.CBORUnmarshaler) bool {
 	// requireInCall omitted because it makes using this mock runtime as a store awkward.
 	data, found := rt.store[c]
 	if found {
+		rt.ChargeGas("OnIpldGet", rt.pricelist.OnIpldGet(len(data)))
 		err := o.UnmarshalCBOR(bytes.NewReader(data))
 		if err != nil {
 			rt.Abortf(exitcode.SysErrSerialization, err.Error())
@@ -337,6 +389,7 @@ func (rt *Runtime) Put(o runtime.CBORMarshaler) cid.Cid {
 		rt.Abortf(exitcode.SysErrSerialization, err.Error())
 	}
 	data := r.Bytes()
+	rt.ChargeGas("OnIpldPut", rt.pricelist.OnIpldPut(len(data)))
 	key, err := cidBuilder.Sum(data)
 	if err != nil {
 		rt.Abortf(exitcode.SysErrSerialization, err.Error())
@@ -430,6 +483,11 @@ func (rt *Runtime) GetState(o runtime.CBORUnmarshaler) {
 	}
 }
 
+// Overwrites the actor's state, such as to set up a scenario that is awkward to reach through method calls.
+func (rt *Runtime) ReplaceState(o runtime.CBORMarshaler) {
+	rt.state = rt.Put(o)
+}
+
 func (rt *Runtime) GetBalance() abi.TokenAmount {
 	return rt.balance
 }
@@ -438,6 +496,26 @@ func (rt *Runtime) GetEpoch() abi.ChainEpoch {
 	return rt.epoch
 }
 
+// Returns the gas charges made during the most recent call, in order.
+func (rt *Runtime) GasCharges() []GasCharge {
+	return rt.gasCharges
+}
+
+// Returns the events emitted during the most recent call, in order.
+// Events emitted by a call that aborted are discarded.
+func (rt *Runtime) Events() []runtime.Event {
+	return rt.events
+}
+
+// Returns the total gas charged during the most recent call.
+func (rt *Runtime) GasUsed() int64 {
+	total := int64(0)
+	for _, c := range rt.gasCharges {
+		total += c.Amount
+	}
+	return total
+}
+
 ///// Mocking facilities /////
 
 func (rt *Runtime) SetCaller(address addr.Address, actorType cid.Cid) {
@@ -450,6 +528,10 @@ func (rt *Runtime) SetBalance(amt abi.TokenAmount) {
 	rt.balance = amt
 }
 
+func (rt *Runtime) SetCirculatingSupply(amt abi.TokenAmount) {
+	rt.circSupply = &amt
+}
+
 func (rt *Runtime) SetReceived(amt abi.TokenAmount) {
 	rt.valueReceived = amt
 }
@@ -458,6 +540,31 @@ func (rt *Runtime) SetEpoch(epoch abi.ChainEpoch) {
 	rt.epoch = epoch
 }
 
+// Configures the runtime to check the invariants of the actor's state after every successful call,
+// failing the test if any are violated.
+// The argument is a pointer to a state object, which serves only to indicate the state type.
+func (rt *Runtime) CheckInvariantsAfterCall(stateType StateInvariantChecker) {
+	rt.invariantStateType = reflect.TypeOf(stateType).Elem()
+}
+
+// Checks the invariants of the actor's current state, returning any violations.
+func (rt *Runtime) CheckStateInvariants(st StateInvariantChecker) []builtin.InvariantViolation {
+	rt.GetState(st)
+	return st.CheckStateInvariants(adt.AsStore(rt), rt.balance)
+}
+
+// Sets the pricelist used to compute gas charges.
+func (rt *Runtime) SetPricelist(pl runtime.Pricelist) {
+	rt.pricelist = pl
+}
+
+// Sets the maximum gas that a subsequent call may consume before aborting with SysErrOutOfGas.
+// A limit of zero removes the limit.
+// This can be used to assert a ceiling on the cost of a method.
+func (rt *Runtime) SetGasLimit(limit int64) {
+	rt.gasLimit = limit
+}
+
 func (rt *Runtime) AddIDAddress(src addr.Address, target addr.Address) {
 	rt.require(target.Protocol() == addr.ID, "target must use ID address protocol")
 	rt.idAddresses[src] = target
@@ -557,8 +664,9 @@ func (rt *Runtime) ExpectAbort(expected exitcode.ExitCode, f func()) {
 		if a.code != expected {
 			rt.failTest("abort expected code %v, got %v %s", expected, a.code, a.msg)
 		}
-		// Roll back state change.
+		// Roll back state change and discard events.
 		rt.state = prevState
+		rt.events = nil
 	}()
 	f()
 }
@@ -598,6 +706,8 @@ func (rt *Runtime) Call(method interface{}, params interface{}) interface{} {
 	// If not expected, the panic will escape and cause the test to fail.
 
 	rt.inCall = true
+	rt.gasCharges = nil
+	rt.events = nil
 	defer func() { rt.inCall = false }()
 	var arg reflect.Value
 	if params != nil {
@@ -606,9 +716,23 @@ func (rt *Runtime) Call(method interface{}, params interface{}) interface{} {
 		arg = reflect.ValueOf(adt.Empty)
 	}
 	ret := meth.Call([]reflect.Value{reflect.ValueOf(rt), arg})
+
+	// Invariants are checked outside the call, so the checks don't consume gas.
+	rt.inCall = false
+	rt.checkInvariantsAfterCall()
 	return ret[0].Interface()
 }
 
+func (rt *Runtime) checkInvariantsAfterCall() {
+	if rt.invariantStateType == nil || !rt.state.Defined() {
+		return
+	}
+	st := reflect.New(rt.invariantStateType).Interface().(StateInvariantChecker)
+	for _, v := range builtin.WithActorAddress(rt.CheckStateInvariants(st), rt.receiver) {
+		rt.failTest("state invariant violated: %v", v)
+	}
+}
+
 func (rt *Runtime) SetVerifier(f VerifyFunc) {
 	rt.syscalls.SignatureVerifier = f
 }
@@ -617,6 +741,22 @@ func (rt *Runtime) SetHasher(f HasherFunc) {
 	rt.syscalls.Hasher = f
 }
 
+func (rt *Runtime) SetUnsealedCIDComputer(f UnsealedCIDComputeFunc) {
+	rt.syscalls.UnsealedCIDs = f
+}
+
+func (rt *Runtime) SetBatchSealVerifier(f BatchSealVerifyFunc) {
+	rt.syscalls.BatchSealVerifier = f
+}
+
+func (rt *Runtime) SetPoStVerifier(f PoStVerifyFunc) {
+	rt.syscalls.PoStVerifier = f
+}
+
+func (rt *Runtime) SetConsensusFaultVerifier(f ConsensusFaultVerifyFunc) {
+	rt.syscalls.FaultVerifier = f
+}
+
 func (rt *Runtime) verifyExportedMethodType(meth reflect.Value) {
 	t := meth.Type()
 	rt.require(t.Kind() == reflect.Func, "%v is not a function", meth)
@@ -651,7 +791,10 @@ func (rt *Runtime) failTestNow(msg string, args ...interface{}) {
 }
 
 func (rt *Runtime) TotalFilCircSupply() abi.TokenAmount {
-	panic("todo crypto econ")
+	if rt.circSupply == nil {
+		panic("no circulating supply set")
+	}
+	return *rt.circSupply
 }
 
 type ReturnWrapper struct {
@@ -0,0 +1,174 @@
+package vm
+
+import (
+	"fmt"
+
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
+	init_ "github.com/filecoin-project/specs-actors/actors/builtin/init"
+	market "github.com/filecoin-project/specs-actors/actors/builtin/market"
+	miner "github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	multisig "github.com/filecoin-project/specs-actors/actors/builtin/multisig"
+	paych "github.com/filecoin-project/specs-actors/actors/builtin/paych"
+	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
+	verifreg "github.com/filecoin-project/specs-actors/actors/builtin/verifreg"
+	runtime "github.com/filecoin-project/specs-actors/actors/runtime"
+	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// The state of an actor that can check its own invariants.
+type invariantChecker interface {
+	runtime.CBORUnmarshaler
+	CheckStateInvariants(store adt.Store, balance abi.TokenAmount) []builtin.InvariantViolation
+}
+
+// Returns a new, empty state object for actors with a code CID whose state can be checked.
+func newCheckableState(code cid.Cid) (invariantChecker, bool) {
+	switch code {
+	case builtin.InitActorCodeID:
+		return &init_.State{}, true
+	case builtin.StoragePowerActorCodeID:
+		return &power.State{}, true
+	case builtin.StorageMarketActorCodeID:
+		return &market.State{}, true
+	case builtin.StorageMinerActorCodeID:
+		return &miner.State{}, true
+	case builtin.MultisigActorCodeID:
+		return &multisig.State{}, true
+	case builtin.PaymentChannelActorCodeID:
+		return &paych.State{}, true
+	case builtin.VerifiedRegistryActorCodeID:
+		return &verifreg.State{}, true
+	}
+	return nil, false
+}
+
+// Checks the invariants of every actor's state, and invariants that span the states of multiple actors.
+// Returns all the violations found.
+func (vm *VM) CheckStateInvariants() []builtin.InvariantViolation {
+	acc := builtin.NewInvariantAccumulator("state tree")
+
+	actors := make(map[addr.Address]*Actor)
+	states := make(map[addr.Address]invariantChecker)
+	var act Actor
+	err := vm.actors.ForEach(&act, func(key string) error {
+		a, err := addr.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		cpy := act
+		actors[a] = &cpy
+
+		st, ok := newCheckableState(act.Code)
+		if !ok || act.Head.Equals(vm.emptyObject) {
+			return nil
+		}
+		if err := vm.store.Get(vm.ctx, act.Head, st); err != nil {
+			acc.Addf("failed to load state of %v: %v", a, err)
+			return nil
+		}
+		states[a] = st
+		acc.AddAll(builtin.WithActorAddress(st.CheckStateInvariants(vm.store, act.Balance), a))
+		return nil
+	})
+	if err != nil {
+		acc.Addf("failed to iterate actors: %v", err)
+		return acc.Violations()
+	}
+
+	if initSt, ok := states[builtin.InitActorAddr].(*init_.State); ok {
+		checkInitAgainstActors(acc.WithContext("init"), initSt, actors)
+	}
+	if powerSt, ok := states[builtin.StoragePowerActorAddr].(*power.State); ok {
+		checkPowerAgainstMiners(acc.WithContext("power"), vm.store, powerSt, actors, states)
+	}
+	if marketSt, ok := states[builtin.StorageMarketActorAddr].(*market.State); ok {
+		checkMarketAgainstMiners(acc.WithContext("market"), vm.store, marketSt, actors)
+	}
+	return acc.Violations()
+}
+
+// Every non-singleton actor must have been allocated its ID by the init actor.
+func checkInitAgainstActors(acc *builtin.InvariantAccumulator, st *init_.State, actors map[addr.Address]*Actor) {
+	for a := range actors {
+		id, err := addr.IDFromAddress(a)
+		if err != nil {
+			acc.Addf("actor address %v is not an ID address: %v", a, err)
+			continue
+		}
+		if id >= builtin.FirstNonSingletonActorId {
+			acc.Require(abi.ActorID(id) < st.NextID, "actor %v has ID not less than next ID %d", a, st.NextID)
+		}
+	}
+}
+
+// Every miner actor has a power claim, unless slashed for a consensus fault, and every claim belongs to a miner actor.
+// A miner's raw byte power claim is the total size of its non-faulty sectors.
+func checkPowerAgainstMiners(acc *builtin.InvariantAccumulator, store adt.Store, st *power.State, actors map[addr.Address]*Actor,
+	states map[addr.Address]invariantChecker) {
+	claims, err := power.AsClaimMap(store, st.Claims)
+	if err != nil {
+		acc.Addf("failed to load claims: %v", err)
+		return
+	}
+	claimed := make(map[addr.Address]*power.Claim)
+	err = claims.ForEach(func(a addr.Address, claim *power.Claim) error {
+		claimed[a] = claim
+		act, found := actors[a]
+		acc.Require(found && act.Code.Equals(builtin.StorageMinerActorCodeID), "claim for %v, which is not a miner actor", a)
+		return nil
+	})
+	acc.RequireNoError(err, "failed to iterate claims")
+
+	for a, act := range actors {
+		if !act.Code.Equals(builtin.StorageMinerActorCodeID) {
+			continue
+		}
+		minerAcc := acc.WithContext(fmt.Sprintf("miner %v", a))
+		minerSt, ok := states[a].(*miner.State)
+		claim, found := claimed[a]
+		if !found {
+			minerAcc.Require(ok && minerSt.ConsensusFault != nil, "no power claim")
+			continue
+		}
+		if !ok {
+			continue
+		}
+		sectorCount, err := minerSt.GetSectorCount(store)
+		if err != nil {
+			minerAcc.Addf("failed to count sectors: %v", err)
+			continue
+		}
+		faultyPower := big.Zero()
+		err = minerSt.ForEachPartition(store, func(_, _ uint64, partition *miner.Partition) error {
+			faultyPower = big.Add(faultyPower, partition.FaultyPower.Raw)
+			return nil
+		})
+		if err != nil {
+			minerAcc.Addf("failed to sum faulty power: %v", err)
+			continue
+		}
+		expected := big.Sub(big.Mul(big.NewIntUnsigned(uint64(minerSt.Info.SectorSize)), big.NewIntUnsigned(sectorCount)), faultyPower)
+		minerAcc.Require(claim.RawBytePower.Equals(expected), "claims raw byte power %v, but has %d sectors of size %d with faulty power %v",
+			claim.RawBytePower, sectorCount, minerSt.Info.SectorSize, faultyPower)
+	}
+}
+
+// Every deal's provider is a miner actor.
+func checkMarketAgainstMiners(acc *builtin.InvariantAccumulator, store adt.Store, st *market.State, actors map[addr.Address]*Actor) {
+	proposals, err := market.AsDealProposalArray(store, st.Proposals)
+	if err != nil {
+		acc.Addf("failed to load proposals: %v", err)
+		return
+	}
+	err = proposals.ForEach(func(id abi.DealID, proposal *market.DealProposal) error {
+		act, found := actors[proposal.Provider]
+		acc.Require(found && act.Code.Equals(builtin.StorageMinerActorCodeID), "deal %d provider %v is not a miner actor", id, proposal.Provider)
+		return nil
+	})
+	acc.RequireNoError(err, "failed to iterate proposals")
+}
@@ -0,0 +1,545 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+	"github.com/minio/blake2b-simd"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
+	init_ "github.com/filecoin-project/specs-actors/actors/builtin/init"
+	crypto "github.com/filecoin-project/specs-actors/actors/crypto"
+	runtime "github.com/filecoin-project/specs-actors/actors/runtime"
+	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
+)
+
+// Context for a top-level invocation sequence, shared by all nested invocations.
+type topLevelContext struct {
+	originatorStableAddress addr.Address // Stable (non-ID) address of the top-level message sender.
+	originatorCallSeq       uint64       // Call sequence number of the top-level message.
+	newActorAddressCount    uint64       // Number of actor addresses created by this message so far.
+	gasUsed                 int64        // Gas charged to this message so far, including by failed sends.
+	events                  []Event      // Events emitted by this message so far, excluding by failed sends.
+}
+
+type internalMessage struct {
+	from   addr.Address // Always an ID address.
+	to     addr.Address // May be any protocol until resolved.
+	value  abi.TokenAmount
+	method abi.MethodNum
+	params runtime.CBORMarshaler
+}
+
+// Context for an individual message invocation, implementing the runtime seen by the receiving actor.
+type invocationContext struct {
+	vm                *VM
+	topLevel          *topLevelContext
+	msg               internalMessage
+	isCallerValidated bool
+	allowSideEffects  bool
+}
+
+var _ runtime.Runtime = (*invocationContext)(nil)
+var _ runtime.StateHandle = (*invocationContext)(nil)
+var _ runtime.Message = (*invocationContext)(nil)
+
+var typeOfRuntimeInterface = reflect.TypeOf((*runtime.Runtime)(nil)).Elem()
+var typeOfCborUnmarshaler = reflect.TypeOf((*runtime.CBORUnmarshaler)(nil)).Elem()
+var typeOfCborMarshaler = reflect.TypeOf((*runtime.CBORMarshaler)(nil)).Elem()
+
+func newInvocationContext(vm *VM, topLevel *topLevelContext, msg internalMessage) *invocationContext {
+	return &invocationContext{
+		vm:                vm,
+		topLevel:          topLevel,
+		msg:               msg,
+		isCallerValidated: false,
+		allowSideEffects:  true,
+	}
+}
+
+// An abort raised by actor code (or by the VM on its behalf) to halt execution of a message.
+type abort struct {
+	code exitcode.ExitCode
+	msg  string
+}
+
+func (a abort) String() string {
+	return fmt.Sprintf("abort(%v): %s", a.code, a.msg)
+}
+
+// Invokes the message, rolling back all state changes made by it (and any messages it sends)
+// if it does not exit successfully.
+func (ic *invocationContext) invokeWithRollback() (ret interface{}, code exitcode.ExitCode) {
+	prior, err := ic.vm.StateRoot()
+	if err != nil {
+		panic(err)
+	}
+	priorEventCount := len(ic.topLevel.events)
+
+	defer func() {
+		if r := recover(); r != nil {
+			a, ok := r.(abort)
+			if !ok {
+				// Not an abort: an assertion failure or other bug, which must not be masked.
+				panic(r)
+			}
+			ret = nil
+			code = a.code
+		}
+		if code != exitcode.Ok {
+			if err := ic.vm.RollbackTo(prior); err != nil {
+				panic(err)
+			}
+			ic.topLevel.events = ic.topLevel.events[:priorEventCount]
+		}
+	}()
+
+	return ic.invoke(), exitcode.Ok
+}
+
+// Transfers value to the receiver then invokes its method, aborting on any failure.
+func (ic *invocationContext) invoke() interface{} {
+	// Resolve the receiver, implicitly creating an account actor for a new pubkey address.
+	to, ok := ic.vm.NormalizeAddress(ic.msg.to)
+	if !ok {
+		to = ic.createImplicitAccountActor(ic.msg.to)
+	}
+	ic.msg.to = to
+
+	if code, err := ic.vm.transfer(ic.msg.from, ic.msg.to, ic.msg.value); err != nil {
+		ic.Abortf(code, "failed to transfer value: %v", err)
+	}
+
+	if ic.msg.method == builtin.MethodSend {
+		return nil
+	}
+
+	toActor := ic.loadActor(ic.msg.to)
+	impl, ok := ic.vm.getActorImpl(toActor.Code)
+	if !ok {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "no implementation for code %v of actor %v", toActor.Code, ic.msg.to)
+	}
+	exports := impl.Exports()
+	if uint64(ic.msg.method) >= uint64(len(exports)) || exports[ic.msg.method] == nil {
+		ic.Abortf(exitcode.SysErrInvalidMethod, "no method %d on actor %v (%s)", ic.msg.method, ic.msg.to,
+			builtin.ActorNameByCode(toActor.Code))
+	}
+
+	meth := reflect.ValueOf(exports[ic.msg.method])
+	ic.checkExportedMethodType(meth)
+	arg := ic.decodeParams(meth.Type().In(1))
+
+	ret := meth.Call([]reflect.Value{reflect.ValueOf(ic), arg})[0].Interface()
+	if !ic.isCallerValidated {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "method %d on actor %v returned without validating caller",
+			ic.msg.method, ic.msg.to)
+	}
+	return ret
+}
+
+// Round-trips the message params through serialization into a new value of the method's parameter type.
+func (ic *invocationContext) decodeParams(paramType reflect.Type) reflect.Value {
+	var buf bytes.Buffer
+	if ic.msg.params != nil {
+		if err := ic.msg.params.MarshalCBOR(&buf); err != nil {
+			ic.Abortf(exitcode.SysErrSerialization, "failed to serialize params: %v", err)
+		}
+	}
+
+	var arg reflect.Value
+	if paramType.Kind() == reflect.Ptr {
+		arg = reflect.New(paramType.Elem())
+	} else {
+		arg = reflect.New(paramType)
+	}
+	if err := arg.Interface().(runtime.CBORUnmarshaler).UnmarshalCBOR(&buf); err != nil {
+		ic.Abortf(exitcode.SysErrInvalidParameters, "failed to deserialize params as %v: %v", paramType, err)
+	}
+	if paramType.Kind() != reflect.Ptr {
+		return arg.Elem()
+	}
+	return arg
+}
+
+func (ic *invocationContext) checkExportedMethodType(meth reflect.Value) {
+	t := meth.Type()
+	ok := t.Kind() == reflect.Func &&
+		t.NumIn() == 2 && t.In(0) == typeOfRuntimeInterface &&
+		(t.In(1).Implements(typeOfCborUnmarshaler) || reflect.PtrTo(t.In(1)).Implements(typeOfCborUnmarshaler)) &&
+		t.NumOut() == 1 && t.Out(0).Implements(typeOfCborMarshaler)
+	if !ok {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "method %d on actor %v has invalid signature %v", ic.msg.method, ic.msg.to, t)
+	}
+}
+
+// Creates an account actor for a pubkey address, as a side effect of sending it a message.
+func (ic *invocationContext) createImplicitAccountActor(target addr.Address) addr.Address {
+	if target.Protocol() != addr.SECP256K1 && target.Protocol() != addr.BLS {
+		ic.Abortf(exitcode.SysErrInvalidReceiver, "cannot create account actor for address %v", target)
+	}
+
+	var initState init_.State
+	initActor := ic.loadActor(builtin.InitActorAddr)
+	if err := ic.vm.store.Get(ic.vm.ctx, initActor.Head, &initState); err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to load init actor state: %v", err)
+	}
+	idAddr, err := initState.MapAddressToNewID(ic.vm.store, target)
+	if err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to map address %v: %v", target, err)
+	}
+	initActor.Head = ic.putOrAbort(&initState)
+	ic.storeActor(builtin.InitActorAddr, initActor)
+
+	ic.storeActor(idAddr, &Actor{
+		Code:       builtin.AccountActorCodeID,
+		Head:       ic.vm.emptyObject,
+		CallSeqNum: 0,
+		Balance:    big.Zero(),
+	})
+
+	// Run the account constructor as the system actor.
+	ctorMsg := internalMessage{
+		from:   builtin.SystemActorAddr,
+		to:     idAddr,
+		value:  big.Zero(),
+		method: builtin.MethodConstructor,
+		params: &target,
+	}
+	if _, code := newInvocationContext(ic.vm, ic.topLevel, ctorMsg).invokeWithRollback(); code != exitcode.Ok {
+		ic.Abortf(code, "failed to construct account actor for %v", target)
+	}
+	return idAddr
+}
+
+///// Implementation of the runtime API /////
+
+func (ic *invocationContext) Message() runtime.Message {
+	return ic
+}
+
+func (ic *invocationContext) CurrEpoch() abi.ChainEpoch {
+	return ic.vm.currentEpoch
+}
+
+func (ic *invocationContext) ValidateImmediateCallerAcceptAny() {
+	ic.isCallerValidated = true
+}
+
+func (ic *invocationContext) ValidateImmediateCallerIs(addrs ...addr.Address) {
+	ic.checkArgument(len(addrs) > 0, "addrs must be non-empty")
+	ic.isCallerValidated = true
+	for _, a := range addrs {
+		if ic.msg.from == a {
+			return
+		}
+	}
+	ic.Abortf(exitcode.SysErrForbidden, "caller address %v forbidden, allowed: %v", ic.msg.from, addrs)
+}
+
+func (ic *invocationContext) ValidateImmediateCallerType(types ...cid.Cid) {
+	ic.checkArgument(len(types) > 0, "types must be non-empty")
+	ic.isCallerValidated = true
+	callerCode := ic.loadActor(ic.msg.from).Code
+	for _, t := range types {
+		if callerCode.Equals(t) {
+			return
+		}
+	}
+	ic.Abortf(exitcode.SysErrForbidden, "caller type %v forbidden, allowed: %v", callerCode, types)
+}
+
+func (ic *invocationContext) CurrentBalance() abi.TokenAmount {
+	return ic.loadActor(ic.msg.to).Balance
+}
+
+func (ic *invocationContext) ResolveAddress(address addr.Address) (addr.Address, bool) {
+	return ic.vm.NormalizeAddress(address)
+}
+
+func (ic *invocationContext) GetActorCodeCID(a addr.Address) (cid.Cid, bool) {
+	act, found, err := ic.vm.GetActor(a)
+	if err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to load actor %v: %v", a, err)
+	}
+	if !found {
+		return cid.Undef, false
+	}
+	return act.Code, true
+}
+
+// Returns a deterministic digest of the arguments, standing in for a random beacon.
+func (ic *invocationContext) GetRandomness(tag crypto.DomainSeparationTag, epoch abi.ChainEpoch, entropy []byte) abi.Randomness {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, int64(tag))
+	_ = binary.Write(&buf, binary.BigEndian, int64(epoch))
+	buf.Write(entropy)
+	digest := blake2b.Sum256(buf.Bytes())
+	return digest[:]
+}
+
+func (ic *invocationContext) State() runtime.StateHandle {
+	return ic
+}
+
+func (ic *invocationContext) Store() runtime.Store {
+	return ic
+}
+
+func (ic *invocationContext) Send(toAddr addr.Address, methodNum abi.MethodNum, params runtime.CBORMarshaler, value abi.TokenAmount) (runtime.SendReturn, exitcode.ExitCode) {
+	if !ic.allowSideEffects {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "side-effect within transaction")
+	}
+
+	msg := internalMessage{
+		from:   ic.msg.to,
+		to:     toAddr,
+		value:  value,
+		method: methodNum,
+		params: params,
+	}
+	ic.ChargeGas("OnMethodInvocation", ic.Pricelist().OnMethodInvocation(value, methodNum))
+	ret, code := newInvocationContext(ic.vm, ic.topLevel, msg).invokeWithRollback()
+	return returnWrapper{ret}, code
+}
+
+func (ic *invocationContext) Abortf(errExitCode exitcode.ExitCode, msg string, args ...interface{}) {
+	panic(abort{errExitCode, fmt.Sprintf(msg, args...)})
+}
+
+func (ic *invocationContext) NewActorAddress() addr.Address {
+	var buf bytes.Buffer
+	if err := ic.topLevel.originatorStableAddress.MarshalCBOR(&buf); err != nil {
+		ic.Abortf(exitcode.SysErrSerialization, "failed to serialize originator address: %v", err)
+	}
+	_ = binary.Write(&buf, binary.BigEndian, ic.topLevel.originatorCallSeq)
+	_ = binary.Write(&buf, binary.BigEndian, ic.topLevel.newActorAddressCount)
+	ic.topLevel.newActorAddressCount++
+
+	actorAddr, err := addr.NewActorAddress(buf.Bytes())
+	if err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to create actor address: %v", err)
+	}
+	return actorAddr
+}
+
+func (ic *invocationContext) CreateActor(codeId cid.Cid, address addr.Address) {
+	if !ic.allowSideEffects {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "side-effect within transaction")
+	}
+	if ic.msg.to != builtin.InitActorAddr {
+		ic.Abortf(exitcode.SysErrForbidden, "actor %v is not permitted to create actors", ic.msg.to)
+	}
+	if !builtin.IsBuiltinActor(codeId) {
+		ic.Abortf(exitcode.SysErrorIllegalArgument, "code %v is not a builtin actor", codeId)
+	}
+	if _, found, err := ic.vm.GetActor(address); err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to load actor %v: %v", address, err)
+	} else if found {
+		ic.Abortf(exitcode.SysErrorIllegalArgument, "actor %v already exists", address)
+	}
+
+	ic.storeActor(address, &Actor{
+		Code:       codeId,
+		Head:       ic.vm.emptyObject,
+		CallSeqNum: 0,
+		Balance:    big.Zero(),
+	})
+}
+
+func (ic *invocationContext) DeleteActor(beneficiary addr.Address) {
+	if !ic.allowSideEffects {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "side-effect within transaction")
+	}
+	beneficiaryID, ok := ic.vm.NormalizeAddress(beneficiary)
+	if !ok {
+		ic.Abortf(exitcode.SysErrorIllegalArgument, "beneficiary %v not found", beneficiary)
+	}
+	if beneficiaryID == ic.msg.to {
+		ic.Abortf(exitcode.SysErrorIllegalArgument, "beneficiary may not be the deleted actor %v", ic.msg.to)
+	}
+
+	balance := ic.loadActor(ic.msg.to).Balance
+	if code, err := ic.vm.transfer(ic.msg.to, beneficiaryID, balance); err != nil {
+		ic.Abortf(code, "failed to transfer balance to beneficiary: %v", err)
+	}
+	if err := ic.vm.deleteActor(ic.msg.to); err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to delete actor: %v", err)
+	}
+}
+
+func (ic *invocationContext) Syscalls() runtime.Syscalls {
+	return runtime.NewGasChargingSyscalls(ic, fakeSyscalls{})
+}
+
+// Events are recorded for the top-level message, attributed to the emitting actor.
+func (ic *invocationContext) EmitEvent(event runtime.Event) {
+	var buf bytes.Buffer
+	if err := event.MarshalCBOR(&buf); err != nil {
+		ic.Abortf(exitcode.SysErrSerialization, "failed to marshal %s event: %v", event.EventType(), err)
+	}
+	ic.ChargeGas("OnEmitEvent", ic.vm.pricelist.OnEmitEvent(buf.Len()))
+	ic.topLevel.events = append(ic.topLevel.events, Event{Emitter: ic.msg.to, Payload: event})
+}
+
+// Gas is accumulated for the top-level message, but there is no gas limit.
+func (ic *invocationContext) ChargeGas(_ string, gas int64) {
+	ic.topLevel.gasUsed += gas
+}
+
+func (ic *invocationContext) Pricelist() runtime.Pricelist {
+	return ic.vm.pricelist
+}
+
+func (ic *invocationContext) TotalFilCircSupply() abi.TokenAmount {
+	return ic.vm.circSupply
+}
+
+func (ic *invocationContext) Context() context.Context {
+	return ic.vm.ctx
+}
+
+func (ic *invocationContext) StartSpan(_ string) runtime.TraceSpan {
+	return traceSpan{}
+}
+
+///// Store implementation /////
+
+func (ic *invocationContext) Get(c cid.Cid, o runtime.CBORUnmarshaler) bool {
+	// Load the raw block first so that its size can be charged.
+	// The underlying store doesn't distinguish a missing block from other failures.
+	var raw cbg.Deferred
+	if err := ic.vm.store.Get(ic.vm.ctx, c, &raw); err != nil {
+		return false
+	}
+	ic.ChargeGas("OnIpldGet", ic.Pricelist().OnIpldGet(len(raw.Raw)))
+	if err := o.UnmarshalCBOR(bytes.NewReader(raw.Raw)); err != nil {
+		ic.Abortf(exitcode.SysErrSerialization, "failed to decode object %v: %v", c, err)
+	}
+	return true
+}
+
+func (ic *invocationContext) Put(o runtime.CBORMarshaler) cid.Cid {
+	return ic.putOrAbort(o)
+}
+
+///// Message implementation /////
+
+func (ic *invocationContext) Caller() addr.Address {
+	return ic.msg.from
+}
+
+func (ic *invocationContext) Receiver() addr.Address {
+	return ic.msg.to
+}
+
+func (ic *invocationContext) ValueReceived() abi.TokenAmount {
+	return ic.msg.value
+}
+
+///// State handle implementation /////
+
+func (ic *invocationContext) Create(obj runtime.CBORMarshaler) {
+	act := ic.loadActor(ic.msg.to)
+	if act.Head != ic.vm.emptyObject {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "state already constructed")
+	}
+	act.Head = ic.putOrAbort(obj)
+	ic.storeActor(ic.msg.to, act)
+}
+
+func (ic *invocationContext) Readonly(obj runtime.CBORUnmarshaler) {
+	act := ic.loadActor(ic.msg.to)
+	if !ic.Get(act.Head, obj) {
+		ic.Abortf(exitcode.SysErrInternal, "failed to load state for actor %v", ic.msg.to)
+	}
+}
+
+func (ic *invocationContext) Transaction(obj runtime.CBORer, f func() interface{}) interface{} {
+	if !ic.allowSideEffects {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "nested transaction")
+	}
+	ic.Readonly(obj)
+
+	ic.allowSideEffects = false
+	ret := f()
+	ic.allowSideEffects = true
+
+	// Reload the actor after the transaction, rather than before, so as not to clobber any changes to its balance.
+	act := ic.loadActor(ic.msg.to)
+	act.Head = ic.putOrAbort(obj)
+	ic.storeActor(ic.msg.to, act)
+	return ret
+}
+
+///// Helpers /////
+
+func (ic *invocationContext) loadActor(a addr.Address) *Actor {
+	act, found, err := ic.vm.GetActor(a)
+	if err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to load actor %v: %v", a, err)
+	}
+	if !found {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "actor %v not found", a)
+	}
+	return act
+}
+
+func (ic *invocationContext) storeActor(a addr.Address, act *Actor) {
+	if err := ic.vm.SetActor(a, act); err != nil {
+		ic.Abortf(exitcode.SysErrInternal, "failed to store actor %v: %v", a, err)
+	}
+}
+
+// Stores an object, charging gas for its serialized size.
+func (ic *invocationContext) putOrAbort(o runtime.CBORMarshaler) cid.Cid {
+	var buf bytes.Buffer
+	if err := o.MarshalCBOR(&buf); err != nil {
+		ic.Abortf(exitcode.SysErrSerialization, "failed to encode object: %v", err)
+	}
+	ic.ChargeGas("OnIpldPut", ic.Pricelist().OnIpldPut(buf.Len()))
+	c, err := ic.vm.store.Put(ic.vm.ctx, runtime.CBORBytes(buf.Bytes()))
+	if err != nil {
+		ic.Abortf(exitcode.SysErrSerialization, "failed to store object: %v", err)
+	}
+	return c
+}
+
+func (ic *invocationContext) checkArgument(predicate bool, msg string, args ...interface{}) {
+	if !predicate {
+		ic.Abortf(exitcode.SysErrorIllegalArgument, msg, args...)
+	}
+}
+
+// Wraps a method's return value as a SendReturn, round-tripping it through serialization on demand.
+type returnWrapper struct {
+	v interface{}
+}
+
+func (r returnWrapper) Into(o runtime.CBORUnmarshaler) error {
+	if r.v == nil {
+		return fmt.Errorf("no return value")
+	}
+	m, ok := r.v.(runtime.CBORMarshaler)
+	if !ok {
+		return fmt.Errorf("return value %v is not CBOR-marshalable", r.v)
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return err
+	}
+	return o.UnmarshalCBOR(&buf)
+}
+
+type traceSpan struct{}
+
+func (t traceSpan) End() {
+	// no-op
+}
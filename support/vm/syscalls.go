@@ -0,0 +1,55 @@
+package vm
+
+import (
+	"fmt"
+
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+	"github.com/minio/blake2b-simd"
+	mh "github.com/multiformats/go-multihash"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	crypto "github.com/filecoin-project/specs-actors/actors/crypto"
+	runtime "github.com/filecoin-project/specs-actors/actors/runtime"
+)
+
+// Syscalls for the test VM, which accept all signatures and proofs.
+type fakeSyscalls struct{}
+
+var _ runtime.Syscalls = fakeSyscalls{}
+
+var unsealedCIDBuilder = cid.V1Builder{Codec: cid.Raw, MhType: mh.IDENTITY}
+
+func (s fakeSyscalls) VerifySignature(_ crypto.Signature, _ addr.Address, _ []byte) error {
+	return nil
+}
+
+func (s fakeSyscalls) HashBlake2b(data []byte) [32]byte {
+	return blake2b.Sum256(data)
+}
+
+// Returns a CID that is a deterministic function of the number of pieces, which is sufficient
+// for the unsealed CID to agree between the market and miner actors.
+func (s fakeSyscalls) ComputeUnsealedSectorCID(_ abi.RegisteredProof, pieces []abi.PieceInfo) (cid.Cid, error) {
+	return unsealedCIDBuilder.Sum([]byte(fmt.Sprintf("unsealed-%d", len(pieces))))
+}
+
+func (s fakeSyscalls) VerifySeal(_ abi.SealVerifyInfo) error {
+	return nil
+}
+
+func (s fakeSyscalls) BatchVerifySeals(vis []abi.SealVerifyInfo) ([]bool, error) {
+	out := make([]bool, len(vis))
+	for i := range out {
+		out[i] = true
+	}
+	return out, nil
+}
+
+func (s fakeSyscalls) VerifyPoSt(_ abi.WindowPoStVerifyInfo) error {
+	return nil
+}
+
+func (s fakeSyscalls) VerifyConsensusFault(_, _, _ []byte) (*runtime.ConsensusFault, error) {
+	return nil, fmt.Errorf("consensus faults are not supported by the test VM")
+}
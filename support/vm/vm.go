@@ -0,0 +1,327 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	abi "github.com/filecoin-project/specs-actors/actors/abi"
+	big "github.com/filecoin-project/specs-actors/actors/abi/big"
+	builtin "github.com/filecoin-project/specs-actors/actors/builtin"
+	account "github.com/filecoin-project/specs-actors/actors/builtin/account"
+	cron "github.com/filecoin-project/specs-actors/actors/builtin/cron"
+	init_ "github.com/filecoin-project/specs-actors/actors/builtin/init"
+	market "github.com/filecoin-project/specs-actors/actors/builtin/market"
+	miner "github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	multisig "github.com/filecoin-project/specs-actors/actors/builtin/multisig"
+	paych "github.com/filecoin-project/specs-actors/actors/builtin/paych"
+	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
+	reward "github.com/filecoin-project/specs-actors/actors/builtin/reward"
+	system "github.com/filecoin-project/specs-actors/actors/builtin/system"
+	verifreg "github.com/filecoin-project/specs-actors/actors/builtin/verifreg"
+	runtime "github.com/filecoin-project/specs-actors/actors/runtime"
+	exitcode "github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
+	adt "github.com/filecoin-project/specs-actors/actors/util/adt"
+)
+
+// VM is a simplified, in-memory message execution framework for testing flows that span multiple actors.
+// It maintains a state tree of actors and dispatches messages to the real builtin actor implementations,
+// so that inter-actor sends need not be scripted by hand as with the mock runtime.
+// The VM is not intended to be used in production: it records gas but imposes no limit, and its syscalls
+// accept all proofs.
+type VM struct {
+	ctx   context.Context
+	store adt.Store
+
+	currentEpoch abi.ChainEpoch
+	circSupply   abi.TokenAmount
+
+	actorImpls  map[cid.Cid]abi.Invokee
+	actors      *adt.Map // HAMT[addr.Address]Actor, the current (uncommitted) state tree
+	emptyObject cid.Cid
+
+	pricelist   runtime.Pricelist
+	lastGasUsed int64   // Gas charged by the most recently applied message.
+	lastEvents  []Event // Events emitted by the most recently applied message.
+}
+
+// An entry in the state tree.
+type Actor struct {
+	Code       cid.Cid // The actor's code CID, identifying its implementation.
+	Head       cid.Cid // The root of the actor's state.
+	CallSeqNum uint64  // The nonce of the next top-level message sent by this actor.
+	Balance    abi.TokenAmount
+}
+
+// An event emitted by an actor during execution of a message.
+type Event struct {
+	Emitter addr.Address // The ID address of the emitting actor.
+	Payload runtime.Event
+}
+
+// The builtin actor implementations, by code CID.
+var builtinActors = map[cid.Cid]abi.Invokee{
+	builtin.SystemActorCodeID:           system.Actor{},
+	builtin.InitActorCodeID:             init_.Actor{},
+	builtin.CronActorCodeID:             cron.Actor{},
+	builtin.AccountActorCodeID:          account.Actor{},
+	builtin.StoragePowerActorCodeID:     power.Actor{},
+	builtin.StorageMinerActorCodeID:     miner.Actor{},
+	builtin.StorageMarketActorCodeID:    market.Actor{},
+	builtin.PaymentChannelActorCodeID:   paych.Actor{},
+	builtin.MultisigActorCodeID:         multisig.Actor{},
+	builtin.RewardActorCodeID:           reward.Actor{},
+	builtin.VerifiedRegistryActorCodeID: verifreg.Actor{},
+}
+
+// The CBOR encoding of an empty array, which is the state of an actor before its constructor has run.
+var emptyObjectBytes = runtime.CBORBytes{0x80}
+
+// Creates a new VM with an empty state tree.
+func NewVM(ctx context.Context, store adt.Store) *VM {
+	emptyObject, err := store.Put(ctx, emptyObjectBytes)
+	if err != nil {
+		panic(err)
+	}
+
+	return &VM{
+		ctx:          ctx,
+		store:        store,
+		currentEpoch: 0,
+		circSupply:   big.Zero(),
+		actorImpls:   builtinActors,
+		actors:       adt.MakeEmptyMap(store),
+		emptyObject:  emptyObject,
+		pricelist:    runtime.DefaultPricelist(),
+		lastGasUsed:  0,
+		lastEvents:   nil,
+	}
+}
+
+// Returns the VM's store.
+func (vm *VM) Store() adt.Store {
+	return vm.store
+}
+
+// The current epoch, as observed by actors.
+func (vm *VM) GetEpoch() abi.ChainEpoch {
+	return vm.currentEpoch
+}
+
+func (vm *VM) SetEpoch(epoch abi.ChainEpoch) {
+	vm.currentEpoch = epoch
+}
+
+// Sets the value returned to actors by TotalFilCircSupply.
+func (vm *VM) SetCirculatingSupply(supply abi.TokenAmount) {
+	vm.circSupply = supply
+}
+
+// The CID of an actor's state prior to construction.
+func (vm *VM) EmptyObject() cid.Cid {
+	return vm.emptyObject
+}
+
+// Sets the prices used to charge gas for subsequent messages.
+func (vm *VM) SetPricelist(pl runtime.Pricelist) {
+	vm.pricelist = pl
+}
+
+// The total gas charged during execution of the most recently applied message, whether or not it succeeded.
+func (vm *VM) LastGasUsed() int64 {
+	return vm.lastGasUsed
+}
+
+// The events emitted during execution of the most recently applied message, in order.
+// A message that does not exit successfully emits no events.
+func (vm *VM) LastEvents() []Event {
+	return vm.lastEvents
+}
+
+// Flushes the state tree and returns its root.
+func (vm *VM) StateRoot() (cid.Cid, error) {
+	return vm.actors.Root()
+}
+
+// Replaces the state tree with one previously returned from StateRoot.
+func (vm *VM) RollbackTo(root cid.Cid) error {
+	actors, err := adt.AsMap(vm.store, root)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load state tree %v", root)
+	}
+	vm.actors = actors
+	return nil
+}
+
+// Loads the actor at an address, which must be an ID address.
+func (vm *VM) GetActor(a addr.Address) (*Actor, bool, error) {
+	var act Actor
+	found, err := vm.actors.Get(adt.AddrKey(a), &act)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to load actor %v", a)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	return &act, true, nil
+}
+
+// Installs an actor in the state tree at an address, which must be an ID address.
+// This is intended for bootstrapping a state tree prior to message execution.
+func (vm *VM) SetActor(a addr.Address, act *Actor) error {
+	if a.Protocol() != addr.ID {
+		return fmt.Errorf("actor address %v must be an ID address", a)
+	}
+	if err := vm.actors.Put(adt.AddrKey(a), act); err != nil {
+		return errors.Wrapf(err, "failed to store actor %v", a)
+	}
+	return nil
+}
+
+func (vm *VM) deleteActor(a addr.Address) error {
+	if err := vm.actors.Delete(adt.AddrKey(a)); err != nil {
+		return errors.Wrapf(err, "failed to delete actor %v", a)
+	}
+	return nil
+}
+
+// Loads the state of the actor at an address into out.
+func (vm *VM) GetState(a addr.Address, out runtime.CBORUnmarshaler) error {
+	act, found, err := vm.GetActor(a)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("actor %v not found", a)
+	}
+	return vm.store.Get(vm.ctx, act.Head, out)
+}
+
+// Stores a new state object for the actor at an address, which must already exist.
+// This is intended for bootstrapping state that is not reachable through message execution.
+func (vm *VM) SetState(a addr.Address, obj runtime.CBORMarshaler) error {
+	act, found, err := vm.GetActor(a)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("actor %v not found", a)
+	}
+	act.Head, err = vm.store.Put(vm.ctx, obj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store state for actor %v", a)
+	}
+	return vm.SetActor(a, act)
+}
+
+// Resolves an address to an ID address via the init actor's address table.
+// ID addresses are returned unchanged.
+func (vm *VM) NormalizeAddress(a addr.Address) (addr.Address, bool) {
+	if a.Protocol() == addr.ID {
+		return a, true
+	}
+
+	var initState init_.State
+	if err := vm.GetState(builtin.InitActorAddr, &initState); err != nil {
+		panic(err)
+	}
+	idAddr, err := initState.ResolveAddress(vm.store, a)
+	if err == init_.ErrAddressNotFound {
+		return addr.Undef, false
+	} else if err != nil {
+		panic(err)
+	}
+	return idAddr, true
+}
+
+// Applies a top-level message from an actor, incrementing its call sequence number.
+// The message is executed as if it were the first message in a block: if it fails, all its state changes
+// except the sequence number increment are rolled back.
+// Returns the value returned by the invoked method (which is not serialized) and the exit code.
+func (vm *VM) ApplyMessage(from, to addr.Address, value abi.TokenAmount, method abi.MethodNum, params runtime.CBORMarshaler) (interface{}, exitcode.ExitCode) {
+	fromID, ok := vm.NormalizeAddress(from)
+	if !ok {
+		return nil, exitcode.SysErrSenderInvalid
+	}
+	fromActor, found, err := vm.GetActor(fromID)
+	if err != nil {
+		panic(err)
+	}
+	if !found {
+		return nil, exitcode.SysErrSenderInvalid
+	}
+
+	callSeq := fromActor.CallSeqNum
+	fromActor.CallSeqNum++
+	if err := vm.SetActor(fromID, fromActor); err != nil {
+		panic(err)
+	}
+
+	topLevel := &topLevelContext{
+		originatorStableAddress: from,
+		originatorCallSeq:       callSeq,
+		newActorAddressCount:    0,
+		gasUsed:                 0,
+		events:                  nil,
+	}
+	msg := internalMessage{
+		from:   fromID,
+		to:     to,
+		value:  value,
+		method: method,
+		params: params,
+	}
+	ic := newInvocationContext(vm, topLevel, msg)
+	ret, code := ic.invokeWithRollback()
+	vm.lastGasUsed = topLevel.gasUsed
+	vm.lastEvents = topLevel.events
+	return ret, code
+}
+
+// Returns the implementation of the actor with a code CID.
+func (vm *VM) getActorImpl(code cid.Cid) (abi.Invokee, bool) {
+	impl, ok := vm.actorImpls[code]
+	return impl, ok
+}
+
+// Transfers value between two actors, which must both exist.
+func (vm *VM) transfer(from, to addr.Address, value abi.TokenAmount) (exitcode.ExitCode, error) {
+	if value.LessThan(big.Zero()) {
+		return exitcode.SysErrForbidden, fmt.Errorf("attempt to transfer negative value %v from %v to %v", value, from, to)
+	}
+
+	fromActor, found, err := vm.GetActor(from)
+	if err != nil {
+		return exitcode.SysErrInternal, err
+	}
+	if !found {
+		return exitcode.SysErrSenderInvalid, fmt.Errorf("sender %v not found", from)
+	}
+	if fromActor.Balance.LessThan(value) {
+		return exitcode.SysErrInsufficientFunds, fmt.Errorf("sender %v balance %v insufficient to transfer %v", from, fromActor.Balance, value)
+	}
+	if from == to {
+		return exitcode.Ok, nil
+	}
+
+	toActor, found, err := vm.GetActor(to)
+	if err != nil {
+		return exitcode.SysErrInternal, err
+	}
+	if !found {
+		return exitcode.SysErrInvalidReceiver, fmt.Errorf("receiver %v not found", to)
+	}
+
+	fromActor.Balance = big.Sub(fromActor.Balance, value)
+	toActor.Balance = big.Add(toActor.Balance, value)
+	if err := vm.SetActor(from, fromActor); err != nil {
+		return exitcode.SysErrInternal, err
+	}
+	if err := vm.SetActor(to, toActor); err != nil {
+		return exitcode.SysErrInternal, err
+	}
+	return exitcode.Ok, nil
+}
@@ -0,0 +1,325 @@
+package vm_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	cid "github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/actors/abi"
+	"github.com/filecoin-project/specs-actors/actors/abi/big"
+	"github.com/filecoin-project/specs-actors/actors/builtin"
+	"github.com/filecoin-project/specs-actors/actors/builtin/account"
+	"github.com/filecoin-project/specs-actors/actors/builtin/cron"
+	initact "github.com/filecoin-project/specs-actors/actors/builtin/init"
+	"github.com/filecoin-project/specs-actors/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/actors/builtin/multisig"
+	"github.com/filecoin-project/specs-actors/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/actors/runtime"
+	"github.com/filecoin-project/specs-actors/actors/runtime/exitcode"
+	"github.com/filecoin-project/specs-actors/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/support/testing"
+	"github.com/filecoin-project/specs-actors/support/vm"
+)
+
+func TestValueTransfer(t *testing.T) {
+	t.Run("send to a new pubkey address creates an account actor", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		value := abi.NewTokenAmount(1_000)
+
+		_, code := v.ApplyMessage(builtin.RewardActorAddr, key, value, builtin.MethodSend, nil)
+		require.Equal(t, exitcode.Ok, code)
+
+		idAddr, found := v.NormalizeAddress(key)
+		require.True(t, found)
+		act := getActor(t, v, idAddr)
+		assert.Equal(t, builtin.AccountActorCodeID, act.Code)
+		assert.Equal(t, value, act.Balance)
+
+		var st account.State
+		require.NoError(t, v.GetState(idAddr, &st))
+		assert.Equal(t, key, st.Address)
+
+		// The account actor can return its key.
+		ret, code := v.ApplyMessage(key, idAddr, big.Zero(), builtin.MethodsAccount.PubkeyAddress, nil)
+		require.Equal(t, exitcode.Ok, code)
+		assert.Equal(t, key, ret)
+		assert.Equal(t, uint64(1), getActor(t, v, idAddr).CallSeqNum)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		idAddr := createAccount(t, v, key, abi.NewTokenAmount(100))
+
+		_, code := v.ApplyMessage(idAddr, builtin.BurntFundsActorAddr, abi.NewTokenAmount(101), builtin.MethodSend, nil)
+		assert.Equal(t, exitcode.SysErrInsufficientFunds, code)
+		assert.Equal(t, abi.NewTokenAmount(100), getActor(t, v, idAddr).Balance)
+	})
+
+	t.Run("send to missing ID address fails", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		_, code := v.ApplyMessage(builtin.RewardActorAddr, tutil.NewIDAddr(t, 9999), abi.NewTokenAmount(1), builtin.MethodSend, nil)
+		assert.Equal(t, exitcode.SysErrInvalidReceiver, code)
+	})
+
+	t.Run("send to invalid method fails", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		_, code := v.ApplyMessage(builtin.RewardActorAddr, builtin.StoragePowerActorAddr, big.Zero(), abi.MethodNum(99), nil)
+		assert.Equal(t, exitcode.SysErrInvalidMethod, code)
+	})
+}
+
+func TestCreateMiner(t *testing.T) {
+	t.Run("miner created through power and init actors", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		owner := createAccount(t, v, key, abi.NewTokenAmount(1_000_000))
+
+		ret, code := v.ApplyMessage(key, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.CreateMiner, &power.CreateMinerParams{
+			Owner:      owner,
+			Worker:     owner,
+			SectorSize: abi.SectorSize(32 << 30),
+			Peer:       "peer",
+		})
+		require.Equal(t, exitcode.Ok, code)
+		minerAddrs := ret.(*power.CreateMinerReturn)
+
+		// The robust address resolves to the new miner.
+		resolved, found := v.NormalizeAddress(minerAddrs.RobustAddress)
+		require.True(t, found)
+		assert.Equal(t, minerAddrs.IDAddress, resolved)
+		assert.Equal(t, builtin.StorageMinerActorCodeID, getActor(t, v, minerAddrs.IDAddress).Code)
+
+		var minerSt miner.State
+		require.NoError(t, v.GetState(minerAddrs.IDAddress, &minerSt))
+		assert.Equal(t, owner, minerSt.Info.Owner)
+		assert.Equal(t, owner, minerSt.Info.Worker)
+
+		// The miner is registered with the power actor, and has enrolled its proving period cron event.
+		var powerSt power.State
+		require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+		assert.Equal(t, int64(1), powerSt.MinerCount)
+		events, err := adt.AsMap(v.Store(), powerSt.CronEventQueue)
+		require.NoError(t, err)
+		keys, err := events.CollectKeys()
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(keys))
+
+		assert.Empty(t, v.CheckStateInvariants())
+	})
+
+	t.Run("failed nested send rolls back all state changes", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		owner := createAccount(t, v, key, abi.NewTokenAmount(1_000_000))
+
+		var initBefore initact.State
+		require.NoError(t, v.GetState(builtin.InitActorAddr, &initBefore))
+
+		// The worker must be an account actor, so the miner constructor aborts after init has allocated an ID.
+		_, code := v.ApplyMessage(key, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.CreateMiner, &power.CreateMinerParams{
+			Owner:      owner,
+			Worker:     builtin.StoragePowerActorAddr,
+			SectorSize: abi.SectorSize(32 << 30),
+			Peer:       "peer",
+		})
+		assert.NotEqual(t, exitcode.Ok, code)
+
+		var initAfter initact.State
+		require.NoError(t, v.GetState(builtin.InitActorAddr, &initAfter))
+		assert.Equal(t, initBefore, initAfter)
+
+		var powerSt power.State
+		require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+		assert.Equal(t, int64(0), powerSt.MinerCount)
+
+		// The sender's sequence number is incremented regardless.
+		assert.Equal(t, uint64(1), getActor(t, v, owner).CallSeqNum)
+		assert.Empty(t, v.CheckStateInvariants())
+	})
+}
+
+func TestCheckStateInvariants(t *testing.T) {
+	t.Run("miner without a power claim", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		owner := createAccount(t, v, key, abi.NewTokenAmount(1_000_000))
+
+		ret, code := v.ApplyMessage(key, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.CreateMiner, &power.CreateMinerParams{
+			Owner:      owner,
+			Worker:     owner,
+			SectorSize: abi.SectorSize(32 << 30),
+			Peer:       "peer",
+		})
+		require.Equal(t, exitcode.Ok, code)
+		minerAddr := ret.(*power.CreateMinerReturn).IDAddress
+
+		// Remove the claim without adjusting the miner count.
+		var powerSt power.State
+		require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+		claims, err := adt.AsMap(v.Store(), powerSt.Claims)
+		require.NoError(t, err)
+		require.NoError(t, claims.Delete(adt.AddrKey(minerAddr)))
+		powerSt.Claims, err = claims.Root()
+		require.NoError(t, err)
+		require.NoError(t, v.SetState(builtin.StoragePowerActorAddr, &powerSt))
+
+		violations := v.CheckStateInvariants()
+		require.Len(t, violations, 2)
+		assert.Equal(t, fmt.Sprintf("power %v", builtin.StoragePowerActorAddr), violations[0].Context)
+		assert.Contains(t, violations[0].Message, "miner count")
+		assert.Equal(t, fmt.Sprintf("miner %v", minerAddr), violations[1].Context)
+		assert.Equal(t, "no power claim", violations[1].Message)
+	})
+}
+
+func TestGasAccounting(t *testing.T) {
+	t.Run("gas is charged for storage, sends and syscalls", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		owner := createAccount(t, v, key, abi.NewTokenAmount(1_000_000))
+
+		_, code := v.ApplyMessage(key, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.CreateMiner, &power.CreateMinerParams{
+			Owner:      owner,
+			Worker:     owner,
+			SectorSize: abi.SectorSize(32 << 30),
+			Peer:       "peer",
+		})
+		require.Equal(t, exitcode.Ok, code)
+		createMinerGas := v.LastGasUsed()
+		assert.Greater(t, createMinerGas, int64(0))
+
+		// A plain value transfer is much cheaper than creating a miner.
+		_, code = v.ApplyMessage(owner, builtin.BurntFundsActorAddr, abi.NewTokenAmount(1), builtin.MethodSend, nil)
+		require.Equal(t, exitcode.Ok, code)
+		assert.Less(t, v.LastGasUsed(), createMinerGas)
+	})
+
+	t.Run("gas is charged for a failed message", func(t *testing.T) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		owner := createAccount(t, v, key, abi.NewTokenAmount(1_000_000))
+
+		_, code := v.ApplyMessage(key, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.CreateMiner, &power.CreateMinerParams{
+			Owner:      owner,
+			Worker:     builtin.StoragePowerActorAddr,
+			SectorSize: abi.SectorSize(32 << 30),
+			Peer:       "peer",
+		})
+		assert.NotEqual(t, exitcode.Ok, code)
+		assert.Greater(t, v.LastGasUsed(), int64(0))
+	})
+}
+
+func TestEvents(t *testing.T) {
+	// Creates a multisig with a single signer, which executes transactions as soon as they're proposed.
+	setup := func(t *testing.T) (*vm.VM, addr.Address, addr.Address) {
+		v := newVMWithSingletons(t)
+		key := tutil.NewBLSAddr(t, 1)
+		signer := createAccount(t, v, key, abi.NewTokenAmount(1_000_000))
+
+		var buf bytes.Buffer
+		require.NoError(t, (&multisig.ConstructorParams{
+			Signers:               []addr.Address{signer},
+			NumApprovalsThreshold: 1,
+		}).MarshalCBOR(&buf))
+		ret, code := v.ApplyMessage(signer, builtin.InitActorAddr, abi.NewTokenAmount(100), builtin.MethodsInit.Exec, &initact.ExecParams{
+			CodeCID:           builtin.MultisigActorCodeID,
+			ConstructorParams: buf.Bytes(),
+		})
+		require.Equal(t, exitcode.Ok, code)
+		return v, signer, ret.(*initact.ExecReturn).IDAddress
+	}
+
+	t.Run("events are recorded for a successful message", func(t *testing.T) {
+		v, signer, msig := setup(t)
+		_, code := v.ApplyMessage(signer, msig, big.Zero(), builtin.MethodsMultisig.Propose, &multisig.ProposeParams{
+			To:     signer,
+			Value:  abi.NewTokenAmount(10),
+			Method: builtin.MethodSend,
+		})
+		require.Equal(t, exitcode.Ok, code)
+
+		require.Len(t, v.LastEvents(), 1)
+		assert.Equal(t, msig, v.LastEvents()[0].Emitter)
+		assert.Equal(t, &multisig.TxnExecuted{
+			ID:       0,
+			To:       signer,
+			Value:    abi.NewTokenAmount(10),
+			Method:   builtin.MethodSend,
+			ExitCode: exitcode.Ok,
+		}, v.LastEvents()[0].Payload)
+	})
+
+	t.Run("failed message emits no events", func(t *testing.T) {
+		v, signer, msig := setup(t)
+		_, code := v.ApplyMessage(signer, msig, big.Zero(), builtin.MethodsMultisig.Propose, &multisig.ProposeParams{
+			To:     signer,
+			Value:  abi.NewTokenAmount(1000),
+			Method: builtin.MethodSend,
+		})
+		require.Equal(t, exitcode.ErrInsufficientFunds, code)
+		assert.Empty(t, v.LastEvents())
+	})
+}
+
+// Creates a VM with the singleton actors constructed, and the reward actor funded.
+func newVMWithSingletons(t *testing.T) *vm.VM {
+	ctx := context.Background()
+	v := vm.NewVM(ctx, ipld.NewADTStore(ctx))
+	rootKey := tutil.NewIDAddr(t, 80)
+
+	installActor(t, v, builtin.SystemActorAddr, builtin.SystemActorCodeID, nil, big.Zero())
+	installActor(t, v, builtin.InitActorAddr, builtin.InitActorCodeID, &initact.ConstructorParams{NetworkName: "test"}, big.Zero())
+	installActor(t, v, builtin.RewardActorAddr, builtin.RewardActorCodeID, nil, abi.NewTokenAmount(1e18))
+	installActor(t, v, builtin.CronActorAddr, builtin.CronActorCodeID, &cron.ConstructorParams{Entries: []cron.Entry{{
+		Receiver:  builtin.StoragePowerActorAddr,
+		MethodNum: builtin.MethodsPower.OnEpochTickEnd,
+	}}}, big.Zero())
+	installActor(t, v, builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID, nil, big.Zero())
+	installActor(t, v, builtin.StorageMarketActorAddr, builtin.StorageMarketActorCodeID, nil, big.Zero())
+	installActor(t, v, builtin.VerifiedRegistryActorAddr, builtin.VerifiedRegistryActorCodeID, &rootKey, big.Zero())
+
+	// The burnt funds actor only receives value, so is not constructed.
+	require.NoError(t, v.SetActor(builtin.BurntFundsActorAddr, &vm.Actor{
+		Code:    builtin.AccountActorCodeID,
+		Head:    v.EmptyObject(),
+		Balance: big.Zero(),
+	}))
+	return v
+}
+
+// Installs an actor in the state tree and invokes its constructor from the system actor.
+func installActor(t *testing.T, v *vm.VM, a addr.Address, code cid.Cid, params runtime.CBORMarshaler, balance abi.TokenAmount) {
+	require.NoError(t, v.SetActor(a, &vm.Actor{
+		Code:    code,
+		Head:    v.EmptyObject(),
+		Balance: balance,
+	}))
+	_, exit := v.ApplyMessage(builtin.SystemActorAddr, a, big.Zero(), builtin.MethodConstructor, params)
+	require.Equal(t, exitcode.Ok, exit, "failed to construct %s", builtin.ActorNameByCode(code))
+}
+
+// Creates an account actor for a pubkey address by sending it funds from the reward actor.
+func createAccount(t *testing.T, v *vm.VM, key addr.Address, balance abi.TokenAmount) addr.Address {
+	_, code := v.ApplyMessage(builtin.RewardActorAddr, key, balance, builtin.MethodSend, nil)
+	require.Equal(t, exitcode.Ok, code)
+	idAddr, found := v.NormalizeAddress(key)
+	require.True(t, found)
+	return idAddr
+}
+
+func getActor(t *testing.T, v *vm.VM, a addr.Address) *vm.Actor {
+	act, found, err := v.GetActor(a)
+	require.NoError(t, err)
+	require.True(t, found, "actor %v not found", a)
+	return act
+}